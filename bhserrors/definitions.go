@@ -8,6 +8,12 @@ var ErrGeneric = BHSError{Message: "internal server error", StatusCode: 500, Cod
 // ErrBindBody is an error when it fails to bind JSON body
 var ErrBindBody = BHSError{Message: "error during bind JSON body", StatusCode: 400, Code: "ErrBindBody"}
 
+// ErrRequestBodyTooLarge is when the request body is larger than the endpoint accepts
+var ErrRequestBodyTooLarge = BHSError{Message: "request body is too large", StatusCode: 413, Code: "ErrRequestBodyTooLarge"}
+
+// ErrRequestTimeout is when processing of the request exceeds the handler timeout of its route
+var ErrRequestTimeout = BHSError{Message: "request processing timed out", StatusCode: 503, Code: "ErrRequestTimeout"}
+
 // ////////////////////////////////// AUTH ERRORS
 
 // ErrMissingAuthHeader is when request does not have auth header
@@ -64,6 +70,33 @@ var ErrAncestorNotFound = BHSError{Message: "failed to get ancestor with given h
 // ErrHeadersNotPartOfTheSameChain is when provided headers are not part of the same chain
 var ErrHeadersNotPartOfTheSameChain = BHSError{Message: "the headers provided are not part of the same chain", StatusCode: 400, Code: "ErrHeadersNotPartOfTheSameChain"}
 
+// ErrChainSegmentTooLong is when requested chain segment has more headers than the bulk headers limit
+var ErrChainSegmentTooLong = BHSError{Message: "chain segment is longer than the bulk headers limit", StatusCode: 400, Code: "ErrChainSegmentTooLong"}
+
+// ErrInvalidSegmentBounds is when includeAncestor or includeDescendant parameter is not a boolean
+var ErrInvalidSegmentBounds = BHSError{Message: "includeAncestor and includeDescendant must be either true or false", StatusCode: 400, Code: "ErrInvalidSegmentBounds"}
+
+// ErrBranchDiffTooLong is when diverging branches have together more headers than the bulk headers limit
+var ErrBranchDiffTooLong = BHSError{Message: "diverging branches have more headers than the bulk headers limit", StatusCode: 400, Code: "ErrBranchDiffTooLong"}
+
+// ErrInvalidTimestamp is when the time parameter is neither unix timestamp nor RFC 3339 time
+var ErrInvalidTimestamp = BHSError{Message: "time must be either unix timestamp or RFC 3339 time", StatusCode: 400, Code: "ErrInvalidTimestamp"}
+
+// ErrNoHeaderAtTime is when the given time is before the median time past of the genesis header
+var ErrNoHeaderAtTime = BHSError{Message: "no header of the longest chain was active at given time", StatusCode: 404, Code: "ErrNoHeaderAtTime"}
+
+// ErrInvalidDifficultyRange is when from, to or interval parameter of the difficulty history is not valid
+var ErrInvalidDifficultyRange = BHSError{Message: "from and to must be heights with from not higher than to and interval a positive integer", StatusCode: 400, Code: "ErrInvalidDifficultyRange"}
+
+// ErrTooManyDifficultyPoints is when requested difficulty history has more points than the bulk headers limit
+var ErrTooManyDifficultyPoints = BHSError{Message: "difficulty history has more points than the bulk headers limit, use greater interval", StatusCode: 400, Code: "ErrTooManyDifficultyPoints"}
+
+// ErrInvalidCountsWindow is when a window of header counts is not a positive duration or there are too many of them
+var ErrInvalidCountsWindow = BHSError{Message: "window must be a positive duration, e.g. 24h, and at most 10 windows can be requested", StatusCode: 400, Code: "ErrInvalidCountsWindow"}
+
+// ErrInvalidHashrateWindow is when blocks parameter of the hashrate estimation is not a positive integer
+var ErrInvalidHashrateWindow = BHSError{Message: "blocks must be a positive integer", StatusCode: 400, Code: "ErrInvalidHashrateWindow"}
+
 // ErrHeaderWithGivenHashes is when getting header with given hashes fails
 var ErrHeaderWithGivenHashes = BHSError{Message: "error during getting headers with given hashes", StatusCode: 400, Code: "ErrHeaderWithGivenHashes"}
 
@@ -76,6 +109,45 @@ var ErrHeadersForGivenRangeNotFound = BHSError{Message: "could not find headers
 // ErrHeaderStopHeightNotFound is when stop height for given heade was not found
 var ErrHeaderStopHeightNotFound = BHSError{Message: "could not find stop height for given header", StatusCode: 404, Code: "ErrHeaderStopHeightNotFound"}
 
+// ErrTooManyMappedHeaders is when more hashes and heights are requested to be mapped than the bulk headers limit
+var ErrTooManyMappedHeaders = BHSError{Message: "too many hashes and heights requested at once", StatusCode: 400, Code: "ErrTooManyMappedHeaders"}
+
+// ErrGenerateNotRegtest is when headers are requested to be generated on other network than regtest
+var ErrGenerateNotRegtest = BHSError{Message: "headers can be generated only on the regtest network", StatusCode: 400, Code: "ErrGenerateNotRegtest"}
+
+// ErrInvalidGenerateCount is when the number of headers to generate is not a positive integer within the bulk headers limit
+var ErrInvalidGenerateCount = BHSError{Message: "count must be a positive integer not greater than the bulk headers limit", StatusCode: 400, Code: "ErrInvalidGenerateCount"}
+
+// ErrInvalidHeadersStart is when bulk headers request does not specify exactly one of height or hash to start from
+var ErrInvalidHeadersStart = BHSError{Message: "exactly one of height or hash must be provided", StatusCode: 400, Code: "ErrInvalidHeadersStart"}
+
+// ErrInvalidHeadersCount is when user provided incorrect count of requested headers
+var ErrInvalidHeadersCount = BHSError{Message: "count must be a positive integer", StatusCode: 400, Code: "ErrInvalidHeadersCount"}
+
+// ErrInvalidHeaderState is when user provided a header state other than LONGEST_CHAIN, STALE and ORPHAN
+var ErrInvalidHeaderState = BHSError{Message: "state must be one of LONGEST_CHAIN, STALE and ORPHAN", StatusCode: 400, Code: "ErrInvalidHeaderState"}
+
+// ErrInvalidHeadersSort is when user provided incorrect sort field or order of listed headers
+var ErrInvalidHeadersSort = BHSError{Message: "sort must be one of height, timestamp and chainwork and order either asc or desc", StatusCode: 400, Code: "ErrInvalidHeadersSort"}
+
+// ErrInvalidHeaderFields is when user requested a header field which does not exist
+var ErrInvalidHeaderFields = BHSError{Message: "fields must be comma separated names of header fields", StatusCode: 400, Code: "ErrInvalidHeaderFields"}
+
+// ErrInvalidIncludeRaw is when includeRaw parameter is not a boolean
+var ErrInvalidIncludeRaw = BHSError{Message: "includeRaw must be either true or false", StatusCode: 400, Code: "ErrInvalidIncludeRaw"}
+
+// ErrInvalidRawHeaders is when submitted headers are not raw 80 bytes headers
+var ErrInvalidRawHeaders = BHSError{Message: "headers must be raw 80 bytes headers, binary or hex encoded", StatusCode: 400, Code: "ErrInvalidRawHeaders"}
+
+// ErrTooManyHeaders is when more headers are submitted at once than the bulk headers limit
+var ErrTooManyHeaders = BHSError{Message: "too many headers submitted at once", StatusCode: 400, Code: "ErrTooManyHeaders"}
+
+// ErrAddHeader is when a submitted header cannot be added to the chain
+var ErrAddHeader = BHSError{Message: "failed to add header", StatusCode: 500, Code: "ErrAddHeader"}
+
+// ErrHeadersStartNotInLongestChain is when bulk headers request starts from a header which is not part of the longest chain
+var ErrHeadersStartNotInLongestChain = BHSError{Message: "headers can only be requested from a hash of the longest chain", StatusCode: 400, Code: "ErrHeadersStartNotInLongestChain"}
+
 // ////////////////////////////////// TIPS ERRORS
 
 // ErrGetTips is when it fails to get tips
@@ -103,3 +175,63 @@ var ErrGetAllWebhooks = BHSError{Message: "failed to get all webhooks", StatusCo
 
 // ErrDeleteWebhook is when it failed to delete a webhook
 var ErrDeleteWebhook = BHSError{Message: "failed to delete webhook", StatusCode: 400, Code: "ErrDeleteWebhook"}
+
+// ////////////////////////////////// MAINTENANCE ERRORS
+
+// ErrMaintenance is when database maintenance fails
+var ErrMaintenance = BHSError{Message: "database maintenance failed", StatusCode: 500, Code: "ErrMaintenance"}
+
+// ErrMaintenanceMode is when a write operation is requested while the maintenance mode is enabled
+var ErrMaintenanceMode = BHSError{Message: "service is in maintenance mode, write operations are paused", StatusCode: 503, Code: "ErrMaintenanceMode"}
+
+// ErrNotLeader is when a write operation is requested from an instance which doesn't hold the leader lease
+var ErrNotLeader = BHSError{Message: "instance is not the leader, write operations are accepted by the leader only", StatusCode: 503, Code: "ErrNotLeader"}
+
+// ErrMaintenanceInProgress is when database maintenance is requested while another one is running
+var ErrMaintenanceInProgress = BHSError{Message: "database maintenance is already in progress", StatusCode: 409, Code: "ErrMaintenanceInProgress"}
+
+// ErrInvalidResyncHeight is when re-sync is requested from a height which is negative or not below the tip
+var ErrInvalidResyncHeight = BHSError{Message: "height must be a non-negative integer lower than the tip height", StatusCode: 400, Code: "ErrInvalidResyncHeight"}
+
+// ErrResync is when deleting headers above the requested height fails
+var ErrResync = BHSError{Message: "failed to delete headers for re-sync", StatusCode: 500, Code: "ErrResync"}
+
+// ErrMissingSnapshotPath is when the snapshot restore is requested without the path of the snapshot file
+var ErrMissingSnapshotPath = BHSError{Message: "path of the snapshot file is required", StatusCode: 400, Code: "ErrMissingSnapshotPath"}
+
+// ErrSnapshotRestoreInProgress is when the snapshot restore is requested while another one is running
+var ErrSnapshotRestoreInProgress = BHSError{Message: "snapshot restore is already in progress", StatusCode: 409, Code: "ErrSnapshotRestoreInProgress"}
+
+// ErrSnapshotRestore is when importing the snapshot or swapping it with the headers fails
+var ErrSnapshotRestore = BHSError{Message: "failed to restore headers from snapshot", StatusCode: 500, Code: "ErrSnapshotRestore"}
+
+// ErrLowDiskSpace is when a write operation is requested while the free disk space is below the threshold
+var ErrLowDiskSpace = BHSError{Message: "free disk space is low, write operations are paused", StatusCode: 507, Code: "ErrLowDiskSpace"}
+
+// ErrDiskUsage is when measuring the disk usage fails
+var ErrDiskUsage = BHSError{Message: "failed to measure disk usage", StatusCode: 500, Code: "ErrDiskUsage"}
+
+// ////////////////////////////////// IDEMPOTENCY ERRORS
+
+// ErrInvalidIdempotencyKey is when the Idempotency-Key header is longer than the stored keys can be
+var ErrInvalidIdempotencyKey = BHSError{Message: "Idempotency-Key header must be at most 255 characters long", StatusCode: 400, Code: "ErrInvalidIdempotencyKey"}
+
+// ErrIdempotencyKeyMismatch is when the idempotency key was already used with another request
+var ErrIdempotencyKeyMismatch = BHSError{Message: "idempotency key was already used with a different request", StatusCode: 422, Code: "ErrIdempotencyKeyMismatch"}
+
+// ErrIdempotencyKeyInProgress is when a request is sent again with the idempotency key while the first one is processed
+var ErrIdempotencyKeyInProgress = BHSError{Message: "request with this idempotency key is still being processed", StatusCode: 409, Code: "ErrIdempotencyKeyInProgress"}
+
+// ErrIdempotencyKey is when storing or reading the idempotency key fails
+var ErrIdempotencyKey = BHSError{Message: "failed to process idempotency key", StatusCode: 500, Code: "ErrIdempotencyKey"}
+
+// ////////////////////////////////// ARCHIVE ERRORS
+
+// ErrArchivedHeaderNotFound is when the header is not in any archived branch
+var ErrArchivedHeaderNotFound = BHSError{Message: "header not found in archive", StatusCode: 404, Code: "ErrArchivedHeaderNotFound"}
+
+// ErrInvalidArchiveLimit is when the number of listed archived branches isn't positive
+var ErrInvalidArchiveLimit = BHSError{Message: "limit must be a positive number", StatusCode: 400, Code: "ErrInvalidArchiveLimit"}
+
+// ErrArchive is when reading the archive fails
+var ErrArchive = BHSError{Message: "failed to read archive", StatusCode: 500, Code: "ErrArchive"}
@@ -16,6 +16,8 @@ type cliFlags struct {
 	showHelp      bool `mapstructure:"showHelp"`
 	exportHeaders bool `mapstructure:"exportHeaders"`
 	dumpConfig    bool `mapstructure:"dumpConfig"`
+	// exportBinaryHeaders is the path of the raw binary headers dump to export, empty when not requested.
+	exportBinaryHeaders string `mapstructure:"exportBinaryHeaders"`
 }
 
 // LoadFlags loads flags from command line and binds them to the config
@@ -53,6 +55,7 @@ func initFlags(fs *pflag.FlagSet, cliFlags *cliFlags) {
 	fs.StringP(config.ConfigFilePathKey, "C", "", "custom config file path")
 
 	fs.BoolVarP(&cliFlags.exportHeaders, "export_headers", "e", false, "export headers from database to CSV file")
+	fs.StringVarP(&cliFlags.exportBinaryHeaders, "export_headers_bin", "b", "", "export headers of the longest chain to given file as raw 80 bytes headers")
 	fs.BoolVarP(&cliFlags.showHelp, "help", "h", false, "show help")
 	fs.BoolVarP(&cliFlags.showVersion, "version", "v", false, "show version")
 	fs.BoolVarP(&cliFlags.dumpConfig, "dump_config", "d", false, "dump config to file, specified by config_file flag")
@@ -79,6 +82,14 @@ func parseCliFlags(cli *cliFlags, cfg *config.AppConfig, appFlags *pflag.FlagSet
 		os.Exit(0)
 	}
 
+	if cli.exportBinaryHeaders != "" {
+		if err := database.ExportBinaryHeaders(cfg, cli.exportBinaryHeaders, &log); err != nil {
+			log.Error().Msgf("error while exporting headers: %v", err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if cli.dumpConfig {
 		configPath := viper.GetString(config.ConfigFilePathKey)
 		if configPath == "" {
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// Peers returns peers the service is connected to.
+func (c *Client) Peers(ctx context.Context) ([]Peer, error) {
+	var peers []Peer
+	if err := c.do(ctx, http.MethodGet, "/network/peer", nil, nil, &peers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// PeersCount returns the number of peers the service is connected to.
+func (c *Client) PeersCount(ctx context.Context) (int, error) {
+	var count int
+	if err := c.do(ctx, http.MethodGet, "/network/peer/count", nil, nil, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// TokenInfo returns information about the token used by the client.
+func (c *Client) TokenInfo(ctx context.Context) (*domains.Token, error) {
+	var token domains.Token
+	if err := c.do(ctx, http.MethodGet, "/access", nil, nil, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CreateToken generates a new token, it requires the admin token.
+func (c *Client) CreateToken(ctx context.Context) (*domains.Token, error) {
+	var token domains.Token
+	if err := c.do(ctx, http.MethodPost, "/access", nil, nil, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeToken removes given token, it requires the admin token.
+func (c *Client) RevokeToken(ctx context.Context, token string) error {
+	return c.do(ctx, http.MethodDelete, "/access/"+url.PathEscape(token), nil, nil, nil)
+}
+
+// RunMaintenance vacuums the database of the service, it requires the admin token.
+func (c *Client) RunMaintenance(ctx context.Context) (*MaintenanceResult, error) {
+	var result MaintenanceResult
+	if err := c.do(ctx, http.MethodPost, "/admin/maintenance", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MaintenanceMode returns whether the maintenance mode of the service is enabled, it requires the admin token.
+func (c *Client) MaintenanceMode(ctx context.Context) (bool, error) {
+	var mode maintenanceMode
+	if err := c.do(ctx, http.MethodGet, "/admin/maintenance/mode", nil, nil, &mode); err != nil {
+		return false, err
+	}
+	return mode.Enabled, nil
+}
+
+// SetMaintenanceMode enables or disables the maintenance mode, in which the service pauses syncing and write operations.
+// It requires the admin token.
+func (c *Client) SetMaintenanceMode(ctx context.Context, enabled bool) error {
+	return c.do(ctx, http.MethodPut, "/admin/maintenance/mode", nil, maintenanceMode{Enabled: enabled}, nil)
+}
+
+// Stats returns statistics of the service, like its disk usage, it requires the admin token.
+func (c *Client) Stats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+	if err := c.do(ctx, http.MethodGet, "/admin/stats", nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// SyncPaused returns whether the p2p sync of the service is paused, it requires the admin token.
+func (c *Client) SyncPaused(ctx context.Context) (bool, error) {
+	var pause syncPause
+	if err := c.do(ctx, http.MethodGet, "/admin/sync/pause", nil, nil, &pause); err != nil {
+		return false, err
+	}
+	return pause.Paused, nil
+}
+
+// SetSyncPaused pauses or resumes the p2p sync, peers stay connected while it's paused but their headers are ignored.
+// It requires the admin token.
+func (c *Client) SetSyncPaused(ctx context.Context, paused bool) error {
+	return c.do(ctx, http.MethodPut, "/admin/sync/pause", nil, syncPause{Paused: paused}, nil)
+}
+
+// Quarantined returns the latest headers which weren't added because of their timestamps, it requires the admin token.
+func (c *Client) Quarantined(ctx context.Context) ([]QuarantinedHeader, error) {
+	var headers []QuarantinedHeader
+	if err := c.do(ctx, http.MethodGet, "/admin/quarantine", nil, nil, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// GenerateHeaders mines count headers on top of the header with given hash, or the tip when it's empty.
+// It's available only on regtest and requires the admin token.
+func (c *Client) GenerateHeaders(ctx context.Context, count int, from string) ([]GeneratedHeader, error) {
+	body := generateHeadersRequest{Count: count, From: from}
+
+	var headers []GeneratedHeader
+	if err := c.do(ctx, http.MethodPost, "/admin/generate", nil, body, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// Resync deletes headers above given height and restarts syncing from there, it requires the admin token.
+func (c *Client) Resync(ctx context.Context, height int32) (*ResyncResult, error) {
+	var result ResyncResult
+	if err := c.do(ctx, http.MethodPost, "/admin/resync", nil, resyncRequest{Height: height}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RestoreSnapshot rebuilds the database from the prepared database file at the path on the server
+// and restarts syncing from its tip, it requires the admin token.
+func (c *Client) RestoreSnapshot(ctx context.Context, path string) (*SnapshotRestoreResult, error) {
+	var result SnapshotRestoreResult
+	if err := c.do(ctx, http.MethodPost, "/admin/snapshot/restore", nil, restoreSnapshotRequest{Path: path}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// generateHeadersRequest is a body of the generate headers request.
+type generateHeadersRequest struct {
+	Count int    `json:"count"`
+	From  string `json:"from,omitempty"`
+}
+
+// resyncRequest is a body of the resync request.
+type resyncRequest struct {
+	Height int32 `json:"height"`
+}
+
+// restoreSnapshotRequest is a body of the snapshot restore request.
+type restoreSnapshotRequest struct {
+	Path string `json:"path"`
+}
+
+// syncPause is a body of the sync pause request and response.
+type syncPause struct {
+	Paused bool `json:"paused"`
+}
+
+// maintenanceMode is a body of the maintenance mode request and response.
+type maintenanceMode struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,140 @@
+// Package client is a Go client of the Block Headers Service HTTP API and its websocket notifications.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	apiPrefix         = "/api/v1"
+	websocketPath     = "/connection/websocket"
+	defaultTimeout    = 30 * time.Second
+	headersChannel    = "headers"
+	authorizationName = "Authorization"
+)
+
+// Client calls endpoints of a single Block Headers Service instance.
+type Client struct {
+	baseURL    string
+	token      string
+	network    string
+	httpClient *http.Client
+}
+
+// Option configures the Client.
+type Option func(*Client)
+
+// WithToken sets the token sent as a Bearer token with every request and used to connect to the websocket.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient replaces the default http.Client, which has a 30 seconds timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithNetwork makes the Client call the API of an additional network served under /api/v1/{network}.
+func WithNetwork(network string) Option {
+	return func(c *Client) {
+		c.network = network
+	}
+}
+
+// New creates a Client of the service available at baseURL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is returned when the service responds with a non 2xx status.
+type Error struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("block headers service responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("block headers service responded with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// apiURL returns the url of the API endpoint under path with given query.
+func (c *Client) apiURL(path string, query url.Values) string {
+	u := c.baseURL + apiPrefix
+	if c.network != "" {
+		u += "/" + url.PathEscape(c.network)
+	}
+	u += path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// do sends the request with body encoded as JSON and decodes JSON response into result, which can be nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("cannot encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL(path, query), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set(authorizationName, "Bearer "+c.token)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		apiErr := &Error{StatusCode: res.StatusCode}
+		// the body is not always a JSON error, e.g. when a proxy in front of the service responds
+		_ = json.NewDecoder(res.Body).Decode(apiErr)
+		return apiErr
+	}
+
+	if result == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("cannot decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+const testHash = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+
+func TestHeaderByHashSendsToken(t *testing.T) {
+	// given
+	var authorization, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		path = r.URL.Path
+		_ = json.NewEncoder(w).Encode(BlockHeader{Hash: testHash, Nonce: 2083236893})
+	}))
+	defer srv.Close()
+	c := New(srv.URL, WithToken("secret"))
+
+	// when
+	header, err := c.HeaderByHash(context.Background(), testHash)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, authorization, "Bearer secret")
+	assert.Equal(t, path, "/api/v1/chain/header/"+testHash)
+	assert.Equal(t, header.Hash, testHash)
+	assert.Equal(t, header.Nonce, uint32(2083236893))
+}
+
+func TestErrorResponse(t *testing.T) {
+	// given
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"ErrHeaderNotFound","message":"header not found"}`))
+	}))
+	defer srv.Close()
+	c := New(srv.URL)
+
+	// when
+	_, err := c.HeaderState(context.Background(), testHash)
+
+	// then
+	var apiErr *Error
+	assert.Equal(t, errors.As(err, &apiErr), true)
+	assert.Equal(t, apiErr.StatusCode, http.StatusNotFound)
+	assert.Equal(t, apiErr.Code, "ErrHeaderNotFound")
+	assert.IsError(t, err, "block headers service responded with status 404: ErrHeaderNotFound: header not found")
+}
+
+func TestVerifyMerkleRootsOfNetwork(t *testing.T) {
+	// given
+	var path string
+	var items []domains.MerkleRootConfirmationRequestItem
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&items)
+		_, _ = w.Write([]byte(`{"confirmationState":"CONFIRMED","confirmations":[{"blockHash":"` + testHash +
+			`","blockHeight":0,"merkleRoot":"root","confirmation":"CONFIRMED"}]}`))
+	}))
+	defer srv.Close()
+	c := New(srv.URL+"/", WithNetwork("testnet"))
+
+	// when
+	res, err := c.VerifyMerkleRoots(context.Background(), []domains.MerkleRootConfirmationRequestItem{
+		{MerkleRoot: "root", BlockHeight: 0},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, path, "/api/v1/testnet/chain/merkleroot/verify")
+	assert.Equal(t, len(items), 1)
+	assert.Equal(t, items[0].MerkleRoot, "root")
+	assert.Equal(t, res.ConfirmationState, domains.Confirmed)
+	assert.Equal(t, res.Confirmations[0].Hash, testHash)
+}
+
+func TestWebsocketURL(t *testing.T) {
+	testCases := map[string]string{
+		"http://localhost:8080":  "ws://localhost:8080/connection/websocket",
+		"https://headers.io/bhs": "wss://headers.io/bhs/connection/websocket",
+	}
+
+	for baseURL, expected := range testCases {
+		t.Run(baseURL, func(t *testing.T) {
+			assert.Equal(t, New(baseURL).websocketURL(), expected)
+		})
+	}
+}
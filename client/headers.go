@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// bulkHeadersRequest is a body of the bulk headers request.
+type bulkHeadersRequest struct {
+	Height *int32 `json:"height,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	Count  int    `json:"count"`
+}
+
+// headersMappingRequest is a body of the headers mapping request.
+type headersMappingRequest struct {
+	Hashes  []string `json:"hashes"`
+	Heights []int32  `json:"heights"`
+}
+
+// HeaderByHash returns the header with given hash.
+func (c *Client) HeaderByHash(ctx context.Context, hash string) (*BlockHeader, error) {
+	var header BlockHeader
+	if err := c.do(ctx, http.MethodGet, "/chain/header/"+url.PathEscape(hash), nil, nil, &header); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// HeadersByHeight returns up to count headers of the longest chain starting from given height.
+func (c *Client) HeadersByHeight(ctx context.Context, height int32, count int) ([]BlockHeader, error) {
+	query := url.Values{}
+	query.Set("height", strconv.Itoa(int(height)))
+	query.Set("count", strconv.Itoa(count))
+
+	var headers []BlockHeader
+	if err := c.do(ctx, http.MethodGet, "/chain/header/byHeight", query, nil, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// HeadersBulkFromHeight returns up to count headers of the longest chain starting from given height,
+// the service truncates count to its http.bulk_headers_limit.
+func (c *Client) HeadersBulkFromHeight(ctx context.Context, height int32, count int) ([]BlockHeader, error) {
+	return c.headersBulk(ctx, bulkHeadersRequest{Height: &height, Count: count})
+}
+
+// HeadersBulkFromHash returns up to count headers of the longest chain starting from the header with given hash,
+// the service truncates count to its http.bulk_headers_limit.
+func (c *Client) HeadersBulkFromHash(ctx context.Context, hash string, count int) ([]BlockHeader, error) {
+	return c.headersBulk(ctx, bulkHeadersRequest{Hash: hash, Count: count})
+}
+
+func (c *Client) headersBulk(ctx context.Context, body bulkHeadersRequest) ([]BlockHeader, error) {
+	var headers []BlockHeader
+	if err := c.do(ctx, http.MethodPost, "/chain/header/bulk", nil, body, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// Ancestors returns headers between the header with ancestorHash and the header with hash.
+func (c *Client) Ancestors(ctx context.Context, hash, ancestorHash string) ([]BlockHeader, error) {
+	path := "/chain/header/" + url.PathEscape(hash) + "/" + url.PathEscape(ancestorHash) + "/ancestor"
+
+	var headers []BlockHeader
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// HeadersBetween returns headers from the header with ancestorHash to the header with descendantHash ordered by height,
+// the ends of the segment are included as requested. The service rejects segments longer than its http.bulk_headers_limit.
+func (c *Client) HeadersBetween(ctx context.Context, ancestorHash, descendantHash string, includeAncestor, includeDescendant bool) ([]BlockHeader, error) {
+	path := "/chain/header/between/" + url.PathEscape(ancestorHash) + "/" + url.PathEscape(descendantHash)
+	query := url.Values{}
+	query.Set("includeAncestor", strconv.FormatBool(includeAncestor))
+	query.Set("includeDescendant", strconv.FormatBool(includeDescendant))
+
+	var headers []BlockHeader
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &headers); err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// HeaderAtTime returns the highest header of the longest chain whose median time past is not after t.
+func (c *Client) HeaderAtTime(ctx context.Context, t time.Time) (*HeaderAtTime, error) {
+	query := url.Values{}
+	query.Set("time", strconv.FormatInt(t.Unix(), 10))
+
+	var header HeaderAtTime
+	if err := c.do(ctx, http.MethodGet, "/chain/header/byTime", query, nil, &header); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// BranchDiff returns the fork point of headers with given hashes and headers of both branches leading from it to them.
+func (c *Client) BranchDiff(ctx context.Context, hash, otherHash string) (*BranchDiff, error) {
+	var diff BranchDiff
+	if err := c.do(ctx, http.MethodGet, "/chain/header/diff/"+url.PathEscape(hash)+"/"+url.PathEscape(otherHash), nil, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// CommonAncestor returns the first header which is an ancestor of all headers with given hashes.
+func (c *Client) CommonAncestor(ctx context.Context, hashes []string) (*BlockHeader, error) {
+	var header BlockHeader
+	if err := c.do(ctx, http.MethodPost, "/chain/header/commonAncestor", nil, hashes, &header); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// HeaderState returns the header with given hash together with its state, height and chain work.
+func (c *Client) HeaderState(ctx context.Context, hash string) (*BlockHeaderState, error) {
+	var state BlockHeaderState
+	if err := c.do(ctx, http.MethodGet, "/chain/header/state/"+url.PathEscape(hash), nil, nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// MapHeaders returns heights and states of headers with given hashes and hashes of the longest chain at given heights.
+func (c *Client) MapHeaders(ctx context.Context, hashes []string, heights []int32) (*HeadersMapping, error) {
+	body := headersMappingRequest{Hashes: hashes, Heights: heights}
+
+	var mapping HeadersMapping
+	if err := c.do(ctx, http.MethodPost, "/chain/header/mapping", nil, body, &mapping); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+// HeadersCounts returns numbers of stored headers in each state, in total and within each of the windows before now.
+func (c *Client) HeadersCounts(ctx context.Context, windows ...time.Duration) (*HeadersCounts, error) {
+	query := url.Values{}
+	for _, w := range windows {
+		query.Add("window", w.String())
+	}
+
+	var counts HeadersCounts
+	if err := c.do(ctx, http.MethodGet, "/chain/header/counts", query, nil, &counts); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// SubmitHeaders adds raw 80 bytes headers the same way as headers received from peers,
+// parents have to precede their children. It requires the admin token.
+func (c *Client) SubmitHeaders(ctx context.Context, rawHeaders [][]byte) ([]SubmittedHeader, error) {
+	hexHeaders := make([]string, 0, len(rawHeaders))
+	for _, raw := range rawHeaders {
+		hexHeaders = append(hexHeaders, hex.EncodeToString(raw))
+	}
+
+	var results []SubmittedHeader
+	if err := c.do(ctx, http.MethodPost, "/chain/header", nil, hexHeaders, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// VerifyMerkleRoots checks if merkle roots are included in the longest chain at given heights.
+func (c *Client) VerifyMerkleRoots(ctx context.Context, items []domains.MerkleRootConfirmationRequestItem) (*MerkleRootsConfirmations, error) {
+	var confirmations MerkleRootsConfirmations
+	if err := c.do(ctx, http.MethodPost, "/chain/merkleroot/verify", nil, items, &confirmations); err != nil {
+		return nil, err
+	}
+	return &confirmations, nil
+}
+
+// MerkleRoots returns a page of merkle roots of the longest chain following lastEvaluatedKey,
+// which is empty for the first page. Zero batchSize makes the service use its default.
+func (c *Client) MerkleRoots(ctx context.Context, batchSize int, lastEvaluatedKey string) (*domains.MerkleRootsESKPagedResponse, error) {
+	query := url.Values{}
+	if batchSize > 0 {
+		query.Set("batchSize", strconv.Itoa(batchSize))
+	}
+	if lastEvaluatedKey != "" {
+		query.Set("lastEvaluatedKey", lastEvaluatedKey)
+	}
+
+	var page domains.MerkleRootsESKPagedResponse
+	if err := c.do(ctx, http.MethodGet, "/chain/merkleroot", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
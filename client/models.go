@@ -0,0 +1,213 @@
+package client
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// BlockHeader defines a single block header.
+type BlockHeader struct {
+	Hash             string `json:"hash"`
+	Version          int32  `json:"version"`
+	PreviousBlock    string `json:"prevBlockHash"`
+	MerkleRoot       string `json:"merkleRoot"`
+	Timestamp        uint32 `json:"creationTimestamp"`
+	DifficultyTarget uint32 `json:"difficultyTarget"`
+	Nonce            uint32 `json:"nonce"`
+	Work             string `json:"work"`
+}
+
+// BlockHeaderState is a block header with its state, height and chain work.
+type BlockHeaderState struct {
+	Header    BlockHeader `json:"header"`
+	State     string      `json:"state"`
+	ChainWork string      `json:"chainWork"`
+	Height    int32       `json:"height"`
+}
+
+// HeaderAtTime is the header of the longest chain active at some moment, with its median time past as unix timestamp.
+type HeaderAtTime struct {
+	Height         int32       `json:"height"`
+	MedianTimePast uint32      `json:"medianTimePast"`
+	Header         BlockHeader `json:"header"`
+}
+
+// BranchDiff holds headers of two branches above their fork point, ordered by height.
+type BranchDiff struct {
+	ForkPoint   BlockHeader   `json:"forkPoint"`
+	Branch      []BlockHeader `json:"branch"`
+	OtherBranch []BlockHeader `json:"otherBranch"`
+}
+
+// Difficulty is the difficulty of the longest chain at a single height.
+type Difficulty struct {
+	Height           int32   `json:"height"`
+	Timestamp        uint32  `json:"creationTimestamp"`
+	DifficultyTarget uint32  `json:"difficultyTarget"`
+	Difficulty       float64 `json:"difficulty"`
+}
+
+// Hashrate is the network hashrate in hashes per second estimated from the blocks from FromHeight up to ToHeight.
+type Hashrate struct {
+	FromHeight     int32    `json:"fromHeight"`
+	ToHeight       int32    `json:"toHeight"`
+	Work           *big.Int `json:"work"`
+	ElapsedSeconds int64    `json:"elapsedSeconds"`
+	Hashrate       *big.Int `json:"hashrate"`
+}
+
+// HeaderLocation is the height and state of the header with given hash.
+type HeaderLocation struct {
+	Hash   string `json:"hash"`
+	Height int32  `json:"height"`
+	State  string `json:"state"`
+}
+
+// HeadersMapping are headers found by requested hashes and heights, with hashes and heights which were not found.
+type HeadersMapping struct {
+	ByHash         []HeaderLocation `json:"byHash"`
+	ByHeight       []HeaderLocation `json:"byHeight"`
+	UnknownHashes  []string         `json:"unknownHashes"`
+	UnknownHeights []int32          `json:"unknownHeights"`
+}
+
+// HeadersCounts are numbers of stored headers in each state, in total and within recent windows.
+type HeadersCounts struct {
+	Total   map[string]int       `json:"total"`
+	Windows []HeadersCountWindow `json:"windows"`
+}
+
+// HeadersCountWindow are numbers of headers in each state from FromHeight, the first height created within the window.
+type HeadersCountWindow struct {
+	WindowSeconds int64          `json:"windowSeconds"`
+	FromHeight    int32          `json:"fromHeight"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// TipHeader defines a header which is a tip of a chain.
+type TipHeader struct {
+	Hash             string   `json:"hash"`
+	Version          int32    `json:"version"`
+	PreviousBlock    string   `json:"prevBlockHash"`
+	MerkleRoot       string   `json:"merkleRoot"`
+	Timestamp        uint32   `json:"creationTimestamp"`
+	DifficultyTarget uint32   `json:"difficultyTarget"`
+	Nonce            uint32   `json:"nonce"`
+	Work             *big.Int `json:"work"`
+}
+
+// Tip is a tip of a chain with its state, height and chain work.
+type Tip struct {
+	Header    TipHeader `json:"header"`
+	State     string    `json:"state"`
+	ChainWork *big.Int  `json:"chainWork"`
+	Height    int32     `json:"height"`
+}
+
+// SubmittedHeader is a result of adding a submitted header, Status is one of ADDED, EXISTS, REJECTED and QUARANTINED.
+type SubmittedHeader struct {
+	Hash   string `json:"hash"`
+	Status string `json:"status"`
+	State  string `json:"state,omitempty"`
+	Height int32  `json:"height"`
+}
+
+// MerkleRootConfirmation is a result of verification of a single merkle root.
+type MerkleRootConfirmation struct {
+	Hash         string                              `json:"blockHash"`
+	BlockHeight  int32                               `json:"blockHeight"`
+	MerkleRoot   string                              `json:"merkleRoot"`
+	Confirmation domains.MerkleRootConfirmationState `json:"confirmation"`
+}
+
+// MerkleRootsConfirmations is a result of verification of merkle roots,
+// ConfirmationState is CONFIRMED only when all of them are confirmed.
+type MerkleRootsConfirmations struct {
+	ConfirmationState domains.MerkleRootConfirmationState `json:"confirmationState"`
+	Confirmations     []MerkleRootConfirmation            `json:"confirmations"`
+}
+
+// WebhookAuth defines how the service authorizes itself when calling a webhook.
+type WebhookAuth struct {
+	Type   string `json:"type"`
+	Token  string `json:"token"`
+	Header string `json:"header"`
+}
+
+// webhookRequest is a body of the webhook registration.
+type webhookRequest struct {
+	URL          string      `json:"url"`
+	RequiredAuth WebhookAuth `json:"requiredAuth"`
+}
+
+// Webhook is a registered webhook.
+type Webhook struct {
+	URL               string    `json:"url"`
+	CreatedAt         time.Time `json:"createdAt"`
+	LastEmitStatus    string    `json:"lastEmitStatus"`
+	LastEmitTimestamp time.Time `json:"lastEmitTimestamp"`
+	ErrorsCount       int       `json:"errorsCount"`
+	Active            bool      `json:"active"`
+}
+
+// Peer is a peer the service is connected to.
+type Peer struct {
+	IP   string `json:"ip"`
+	Port int    `json:"port"`
+	// LatencyMicros is the round-trip time of the last ping, 0 until the peer answers one.
+	LatencyMicros int64 `json:"latencyMicros"`
+	SyncPeer      bool  `json:"syncPeer"`
+}
+
+// MaintenanceResult is a result of database maintenance.
+type MaintenanceResult struct {
+	DurationMs int64 `json:"durationMs"`
+}
+
+// ResyncResult is a result of re-syncing headers from a height.
+type ResyncResult struct {
+	Height  int32 `json:"height"`
+	Deleted int   `json:"deleted"`
+}
+
+// Stats are statistics of the service.
+type Stats struct {
+	Disk DiskUsage `json:"disk"`
+}
+
+// DiskUsage is the size of the database and the logs and the free disk space, writes are paused while it's below MinFreeBytes.
+type DiskUsage struct {
+	DatabaseBytes int64  `json:"databaseBytes"`
+	LogsBytes     int64  `json:"logsBytes"`
+	FreeBytes     uint64 `json:"freeBytes"`
+	MinFreeBytes  uint64 `json:"minFreeBytes"`
+	LowSpace      bool   `json:"lowSpace"`
+}
+
+// SnapshotRestoreResult is a result of rebuilding the database from a snapshot.
+type SnapshotRestoreResult struct {
+	Imported int    `json:"imported"`
+	Height   int32  `json:"height"`
+	Hash     string `json:"hash"`
+}
+
+// QuarantinedHeader is a received header which wasn't added because its timestamp broke the validation rules.
+type QuarantinedHeader struct {
+	Hash              string    `json:"hash"`
+	PreviousBlock     string    `json:"prevBlockHash"`
+	Height            int32     `json:"height"`
+	CreationTimestamp uint32    `json:"creationTimestamp"`
+	Reason            string    `json:"reason"`
+	QuarantinedAt     time.Time `json:"quarantinedAt"`
+}
+
+// GeneratedHeader is a header generated on regtest with its place in the chain.
+type GeneratedHeader struct {
+	Hash              string `json:"hash"`
+	PreviousBlock     string `json:"prevBlockHash"`
+	Height            int32  `json:"height"`
+	State             string `json:"state"`
+	CreationTimestamp uint32 `json:"creationTimestamp"`
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/centrifugal/centrifuge-go"
+)
+
+// Subscribe calls handler with every header event published on the websocket of the service
+// until ctx is done. The connection is reestablished after it is lost and the subscription
+// is recovered, so events published in the meantime are still delivered if the service keeps them in history.
+// Publications which are not header events are skipped.
+func (c *Client) Subscribe(ctx context.Context, handler func(*domains.HeaderEvent)) error {
+	ws := centrifuge.NewJsonClient(c.websocketURL(), centrifuge.Config{Token: c.token})
+	defer ws.Close()
+
+	sub, err := ws.NewSubscription(headersChannel, centrifuge.SubscriptionConfig{
+		Recoverable: true,
+		Positioned:  true,
+	})
+	if err != nil {
+		return err
+	}
+
+	sub.OnPublication(func(e centrifuge.PublicationEvent) {
+		var event domains.HeaderEvent
+		if err := json.Unmarshal(e.Data, &event); err != nil || event.Header == nil {
+			return
+		}
+		handler(&event)
+	})
+
+	if err := sub.Subscribe(); err != nil {
+		return err
+	}
+	if err := ws.Connect(); err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// websocketURL returns the url of the websocket endpoint, which is served with the same host as the API.
+func (c *Client) websocketURL() string {
+	switch {
+	case strings.HasPrefix(c.baseURL, "https://"):
+		return "wss://" + strings.TrimPrefix(c.baseURL, "https://") + websocketPath
+	case strings.HasPrefix(c.baseURL, "http://"):
+		return "ws://" + strings.TrimPrefix(c.baseURL, "http://") + websocketPath
+	default:
+		return c.baseURL + websocketPath
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Tips returns tips of all chains known to the service.
+func (c *Client) Tips(ctx context.Context) ([]Tip, error) {
+	var tips []Tip
+	if err := c.do(ctx, http.MethodGet, "/chain/tip", nil, nil, &tips); err != nil {
+		return nil, err
+	}
+	return tips, nil
+}
+
+// DifficultyHistory returns the difficulty of every interval-th header of the longest chain from given height up to the tip.
+func (c *Client) DifficultyHistory(ctx context.Context, from int32, interval int) ([]Difficulty, error) {
+	query := url.Values{}
+	query.Set("from", strconv.Itoa(int(from)))
+	query.Set("interval", strconv.Itoa(interval))
+
+	var history []Difficulty
+	if err := c.do(ctx, http.MethodGet, "/chain/difficulty", query, nil, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Hashrate returns the network hashrate estimated from the last blocks of the longest chain.
+func (c *Client) Hashrate(ctx context.Context, blocks int) (*Hashrate, error) {
+	query := url.Values{}
+	query.Set("blocks", strconv.Itoa(blocks))
+
+	var hashrate Hashrate
+	if err := c.do(ctx, http.MethodGet, "/chain/hashrate", query, nil, &hashrate); err != nil {
+		return nil, err
+	}
+	return &hashrate, nil
+}
+
+// LongestChainTip returns the tip of the longest chain.
+func (c *Client) LongestChainTip(ctx context.Context) (*Tip, error) {
+	var tip Tip
+	if err := c.do(ctx, http.MethodGet, "/chain/tip/longest", nil, nil, &tip); err != nil {
+		return nil, err
+	}
+	return &tip, nil
+}
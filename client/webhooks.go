@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// RegisterWebhook registers a webhook called with notifications about headers,
+// auth defines the token the service sends with every call.
+func (c *Client) RegisterWebhook(ctx context.Context, webhookURL string, auth WebhookAuth) (*Webhook, error) {
+	var webhook Webhook
+	body := webhookRequest{URL: webhookURL, RequiredAuth: auth}
+	if err := c.do(ctx, http.MethodPost, "/webhook", nil, body, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Webhook returns the registered webhook with given url.
+func (c *Client) Webhook(ctx context.Context, webhookURL string) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, http.MethodGet, "/webhook", url.Values{"url": {webhookURL}}, nil, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Webhooks returns all registered webhooks, it requires the admin token.
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := c.do(ctx, http.MethodGet, "/webhook/list", nil, nil, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// RevokeWebhook removes the webhook with given url.
+func (c *Client) RevokeWebhook(ctx context.Context, webhookURL string) error {
+	return c.do(ctx, http.MethodDelete, "/webhook", url.Values{"url": {webhookURL}}, nil, nil)
+}
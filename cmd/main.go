@@ -6,6 +6,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,8 +19,14 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/database"
 	sqlrepository "github.com/bitcoin-sv/block-headers-service/database/repository"
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/broker"
+	"github.com/bitcoin-sv/block-headers-service/internal/cache"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/redis"
+	"github.com/bitcoin-sv/block-headers-service/internal/tipmonitor"
 	p2pexp "github.com/bitcoin-sv/block-headers-service/internal/transports/p2p"
 	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/internal/zmq"
 	"github.com/bitcoin-sv/block-headers-service/logging"
 	"github.com/bitcoin-sv/block-headers-service/metrics"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -27,9 +34,14 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints"
 	httpserver "github.com/bitcoin-sv/block-headers-service/transports/http/server"
+	"github.com/bitcoin-sv/block-headers-service/transports/noderpc"
 	"github.com/bitcoin-sv/block-headers-service/transports/p2p"
 	peerpkg "github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
+	"github.com/bitcoin-sv/block-headers-service/transports/replica"
+	"github.com/bitcoin-sv/block-headers-service/transports/simulation"
 	"github.com/bitcoin-sv/block-headers-service/transports/websocket"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
 )
 
 // version version of the application that can be overridden with ldflags during build
@@ -98,11 +110,18 @@ func main() {
 
 	peers := make(map[*peerpkg.Peer]*peerpkg.SyncState)
 
-	headersStore := sql.NewHeadersDb(db, log)
-	repo := &repository.Repositories{
-		Headers:  sqlrepository.NewHeadersRepository(headersStore),
-		Tokens:   sqlrepository.NewTokensRepository(headersStore),
-		Webhooks: sqlrepository.NewWebhooksRepository(headersStore),
+	repo, closeRepo := newRepositories(db, cfg, log)
+
+	var sharedCache cache.Shared
+	closeCache := func() {}
+	if cfg.Redis.Enabled {
+		redisCache, err := redis.NewCache(cfg.Redis)
+		if err != nil {
+			log.Error().Msgf("cannot setup redis cache because of error: %v", err)
+			os.Exit(1)
+		}
+		sharedCache = redisCache
+		closeCache = redisCache.Close
 	}
 
 	hs := service.NewServices(service.Dept{
@@ -111,15 +130,46 @@ func main() {
 		AdminToken:   cfg.HTTP.AuthToken,
 		Logger:       log,
 		Config:       cfg,
+		SharedCache:  sharedCache,
 	})
 
+	// The elector is created before the routes, followers reject write requests until they're elected.
+	if cfg.HA.Enabled {
+		hs.Leader = service.NewLeaderElector(repo.Leases, cfg.HA, log)
+	}
+
+	if cfg.SelfTest.Enabled {
+		if err := hs.Headers.VerifyChainTail(cfg.SelfTest.Headers); err != nil {
+			log.Error().Msgf("self-test of the stored chain failed: %v", err)
+			os.Exit(1)
+		}
+	}
+
 	server := httpserver.NewHTTPServer(cfg.HTTP, log)
 
 	server.ApplyConfiguration(metrics.Register)
 
 	server.ApplyConfiguration(endpoints.SetupRoutes(hs, cfg.HTTP))
+	server.ApplyConfiguration(endpoints.SetupNetworkRoutes(cfg.P2P.Name(), hs, cfg.HTTP))
 
-	ws, err := websocket.NewServer(log, hs, cfg.HTTP.UseAuth)
+	networks := make([]*networkServices, 0, len(cfg.Networks))
+	for _, n := range cfg.Networks {
+		ns, err := newNetworkServices(cfg.ForNetwork(n), log)
+		if err != nil {
+			log.Error().Msgf("cannot setup %s network because of error: %v", n.ChainNetType, err)
+			os.Exit(1)
+		}
+		ns.hs.Leader = hs.Leader
+		server.ApplyConfiguration(endpoints.SetupNetworkRoutes(ns.cfg.P2P.Name(), ns.hs, cfg.HTTP))
+		networks = append(networks, ns)
+	}
+
+	var wsOpts []websocket.ServerOpt
+	if cfg.Redis.Enabled {
+		wsOpts = append(wsOpts, websocket.WithRedisBroker(cfg.Redis))
+	}
+
+	ws, err := websocket.NewServer(log, hs, cfg.HTTP.UseAuth, wsOpts...)
 	if err != nil {
 		log.Error().Msgf("failed to init a new websocket server: %v\n", err)
 		os.Exit(1)
@@ -127,7 +177,72 @@ func main() {
 	server.ApplyConfiguration(ws.SetupEntrypoint)
 
 	hs.Notifier.AddChannel(hs.Webhooks)
-	hs.Notifier.AddChannel(notification.NewWebsocketChannel(log, ws.Publisher(), cfg.Websocket))
+	// Replicas sharing Redis would publish every header once per replica, the primary publishes it for all of them.
+	if !cfg.Redis.Enabled || !cfg.Replica.Enabled {
+		hs.Notifier.AddChannel(notification.NewWebsocketChannel(log, ws.Publisher(), repo.Headers, cfg.Websocket))
+	}
+
+	closePublisher := func() {}
+	if cfg.Publisher.Broker != "" {
+		publisher, err := broker.NewPublisher(cfg.Publisher)
+		if err != nil {
+			log.Error().Msgf("cannot setup %s publisher because of error: %v", cfg.Publisher.Broker, err)
+			os.Exit(1)
+		}
+		closePublisher = publisher.Close
+		hs.Notifier.AddChannel(notification.NewPublisherChannel(log, publisher, repo.Headers, cfg.Publisher))
+	}
+
+	closeZMQ := func() {}
+	if cfg.ZMQ.Enabled() {
+		zmqChannel, closeSockets, err := newZMQChannel(cfg.ZMQ, repo.Headers, log)
+		if err != nil {
+			log.Error().Msgf("cannot setup zmq notifications because of error: %v", err)
+			os.Exit(1)
+		}
+		closeZMQ = closeSockets
+		hs.Notifier.AddChannel(zmqChannel)
+	}
+
+	stopTipMonitor := func() {}
+	if cfg.TipMonitor.Enabled {
+		monitor, err := newTipMonitor(cfg.TipMonitor, hs, log)
+		if err != nil {
+			log.Error().Msgf("cannot setup tip monitor because of error: %v", err)
+			os.Exit(1)
+		}
+		monitor.Start()
+		stopTipMonitor = monitor.Stop
+	}
+
+	stopStaleMonitor := func() {}
+	if cfg.StaleTip.Enabled {
+		monitor := tipmonitor.NewStaleMonitor(cfg.StaleTip, hs.Headers, hs.Notifier, log)
+		monitor.Start()
+		stopStaleMonitor = monitor.Stop
+	}
+
+	hs.DiskGuard.Start()
+	for _, ns := range networks {
+		ns.hs.DiskGuard.Start()
+	}
+
+	// Background services write to the shared database, with HA enabled only the leader runs them.
+	stopPartitioning := func() {}
+	startWriters := func() {
+		startBackgroundWriters(hs)
+		stopPartitioning = database.StartPartitionMaintenance(db, cfg.Db, hs.Maintenance, log)
+		for _, ns := range networks {
+			startBackgroundWriters(ns.hs)
+		}
+	}
+	stopWriters := func() {
+		stopBackgroundWriters(hs)
+		stopPartitioning()
+		for _, ns := range networks {
+			stopBackgroundWriters(ns.hs)
+		}
+	}
 
 	go func() {
 		if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -141,32 +256,86 @@ func main() {
 		os.Exit(1)
 	}
 
-	var p2pServer bsvP2PServer
+	var p2pServers []bsvP2PServer
+	var removeSyncers []func()
 
-	if cfg.P2P.Experimental {
-		p2pServer = p2pexp.NewServer(cfg.P2P, hs.Headers, hs.Chains, log)
-	} else {
-		p2pServer, err = p2p.NewServer(hs, peers, cfg.P2P, log)
+	startP2P := func() {
+		p2pServer, err := newP2PServer(cfg, hs, peers, log)
 		if err != nil {
 			log.Error().Msgf("failed to init a new p2p server: %v\n", err)
 			os.Exit(1)
 		}
+		p2pServers = append(p2pServers, p2pServer)
+		if syncer, ok := p2pServer.(service.Syncer); ok {
+			removeSyncers = append(removeSyncers, hs.Resync.AddSyncer(syncer))
+		}
+
+		for _, ns := range networks {
+			if ns.cfg.P2P.Disabled {
+				continue
+			}
+			nsServer := p2pexp.NewServer(ns.cfg.P2P, ns.hs.Headers, ns.hs.P2PChains, ns.log)
+			p2pServers = append(p2pServers, nsServer)
+			removeSyncers = append(removeSyncers, ns.hs.Resync.AddSyncer(nsServer))
+		}
+
+		for _, p2pServer := range p2pServers {
+			go func() {
+				if err := p2pServer.Start(); err != nil {
+					log.Error().Msgf("cannot start p2p server because of an error: %v", err)
+					os.Exit(1)
+				}
+			}()
+		}
 	}
 
-	go func() {
-		if err := p2pServer.Start(); err != nil {
-			log.Error().Msgf("cannot start p2p server because of an error: %v", err)
-			os.Exit(1)
+	shutdownP2P := func() {
+		for _, remove := range removeSyncers {
+			remove()
 		}
-	}()
+		for _, p2pServer := range p2pServers {
+			if err := p2pServer.Shutdown(); err != nil {
+				log.Error().Msgf("failed to stop p2p server: %v", err)
+			}
+		}
+	}
+
+	if hs.Leader != nil {
+		hs.Leader.Start(func() {
+			startWriters()
+			startP2P()
+		}, func() {
+			// Another instance may already be syncing, so stop writing and let the process be restarted as a follower.
+			shutdownP2P()
+			stopWriters()
+			closeRepo()
+			log.Error().Msg("leadership lost, shutting down")
+			os.Exit(1)
+		})
+	} else {
+		startWriters()
+		startP2P()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
 	<-quit
 
-	if err := p2pServer.Shutdown(); err != nil {
-		log.Error().Msgf("failed to stop p2p server: %v", err)
+	if hs.Leader != nil {
+		hs.Leader.Stop()
+	}
+
+	shutdownP2P()
+
+	stopTipMonitor()
+	stopStaleMonitor()
+	stopWriters()
+	hs.DiskGuard.Stop()
+	closeRepo()
+	for _, ns := range networks {
+		ns.hs.DiskGuard.Stop()
+		ns.closeRepo()
 	}
 
 	if err := ws.Shutdown(); err != nil {
@@ -176,4 +345,207 @@ func main() {
 	if err := server.Shutdown(); err != nil {
 		log.Error().Msgf("failed to stop http server: %v", err)
 	}
+
+	closePublisher()
+	closeZMQ()
+	closeCache()
+}
+
+func newP2PServer(cfg *config.AppConfig, hs *service.Services, peers map[*peerpkg.Peer]*peerpkg.SyncState, log *zerolog.Logger) (bsvP2PServer, error) {
+	if cfg.Replica.Enabled {
+		return replica.NewServer(cfg, hs.Headers, hs.Chains, log), nil
+	}
+	if cfg.Simulation.Enabled {
+		return simulation.NewServer(cfg, hs.Chains, log), nil
+	}
+	if cfg.NodeRPC.Enabled {
+		return noderpc.NewServer(cfg, hs.Headers, hs.Chains, log), nil
+	}
+	if cfg.P2P.Disabled {
+		if cfg.Federation.Enabled {
+			return replica.NewUpstreamServer(cfg, hs.Headers, hs.Chains, log), nil
+		}
+		return pushOnlyServer{log: log}, nil
+	}
+	if cfg.P2P.Experimental {
+		return p2pexp.NewServer(cfg.P2P, hs.Headers, hs.P2PChains, log), nil
+	}
+
+	p2pServer, err := p2p.NewServer(hs, peers, cfg.P2P, log)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Federation.Enabled {
+		return p2pServer, nil
+	}
+	return replica.NewFallbackServer(cfg, p2pServer, hs.Network, hs.Headers, hs.Chains, log), nil
+}
+
+// pushOnlyServer stands in for the p2p server when p2p is disabled and there is no other source of headers.
+type pushOnlyServer struct {
+	log *zerolog.Logger
+}
+
+func (s pushOnlyServer) Start() error {
+	s.log.Info().Msg("P2P is disabled, headers are added only with POST /api/v1/chain/header")
+	return nil
+}
+
+func (pushOnlyServer) Shutdown() error {
+	return nil
+}
+
+func newTipMonitor(cfg *config.TipMonitorConfig, hs *service.Services, log *zerolog.Logger) (*tipmonitor.Monitor, error) {
+	sources := make([]tipmonitor.Source, 0, len(cfg.Sources))
+	for _, sc := range cfg.Sources {
+		source, err := tipmonitor.NewSource(sc, cfg.RequestTimeout)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return tipmonitor.NewMonitor(cfg, sources, hs.Headers, hs.Notifier, log), nil
+}
+
+// newZMQChannel binds sockets of the configured ZeroMQ notifications, notifications with the same address share the socket.
+func newZMQChannel(cfg *config.ZMQConfig, headers repository.Headers, log *zerolog.Logger) (notification.Channel, func(), error) {
+	sockets := make(map[string]*zmq.Publisher)
+	closeSockets := func() {
+		for _, s := range sockets {
+			s.Close()
+		}
+	}
+
+	socket := func(address string) (notification.ZMQPublisher, error) {
+		if address == "" {
+			return nil, nil
+		}
+		if s, ok := sockets[address]; ok {
+			return s, nil
+		}
+		s, err := zmq.Listen(address, cfg.HighWaterMark, log)
+		if err != nil {
+			return nil, err
+		}
+		sockets[address] = s
+		return s, nil
+	}
+
+	hashBlock, err := socket(cfg.PubHashBlock)
+	if err != nil {
+		closeSockets()
+		return nil, nil, err
+	}
+	rawBlockHeader, err := socket(cfg.PubRawBlockHeader)
+	if err != nil {
+		closeSockets()
+		return nil, nil, err
+	}
+
+	return notification.NewZMQChannel(log, hashBlock, rawBlockHeader, headers), closeSockets, nil
+}
+
+// networkServices groups services of an additional network served by the application.
+type networkServices struct {
+	cfg       *config.AppConfig
+	hs        *service.Services
+	log       *zerolog.Logger
+	closeRepo func()
+}
+
+func newNetworkServices(cfg *config.AppConfig, log *zerolog.Logger) (*networkServices, error) {
+	netLog := log.With().Str("network", cfg.P2P.Name()).Logger()
+
+	db, err := database.Init(cfg, &netLog)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, closeRepo := newRepositories(db, cfg, &netLog)
+
+	hs := service.NewServices(service.Dept{
+		Repositories: repo,
+		Peers:        make(map[*peerpkg.Peer]*peerpkg.SyncState),
+		AdminToken:   cfg.HTTP.AuthToken,
+		Logger:       &netLog,
+		Config:       cfg,
+	})
+
+	if cfg.SelfTest.Enabled {
+		if err := hs.Headers.VerifyChainTail(cfg.SelfTest.Headers); err != nil {
+			closeRepo()
+			return nil, fmt.Errorf("self-test of the stored chain failed: %w", err)
+		}
+	}
+
+	hs.Notifier.AddChannel(hs.Webhooks)
+
+	return &networkServices{cfg: cfg, hs: hs, log: &netLog, closeRepo: closeRepo}, nil
+}
+
+// startBackgroundWriters starts the scheduled services which write to the database of the given services.
+func startBackgroundWriters(hs *service.Services) {
+	hs.Maintenance.Start()
+	hs.Idempotency.Start()
+	hs.Pruning.Start()
+	hs.Archive.Start()
+	hs.Webhooks.Start()
+	if hs.Outbox != nil {
+		hs.Outbox.Start()
+	}
+}
+
+// stopBackgroundWriters stops services started by startBackgroundWriters, it's safe to call it when they weren't started.
+func stopBackgroundWriters(hs *service.Services) {
+	hs.Maintenance.Stop()
+	if hs.Outbox != nil {
+		hs.Outbox.Stop()
+	}
+	hs.Idempotency.Stop()
+	hs.Pruning.Stop()
+	hs.Archive.Stop()
+	hs.Webhooks.Stop()
+}
+
+// snapshotCheckpoints returns the checkpoints of the network imported snapshots are validated against.
+func snapshotCheckpoints(cfg *config.P2PConfig) []chaincfg.Checkpoint {
+	if cfg.DisableCheckpoints {
+		return nil
+	}
+	return cfg.GetNetParams().Checkpoints
+}
+
+// newRepositories creates repositories of the given database, the returned func writes pending
+// headers and has to be called once headers are no longer received.
+func newRepositories(db *sqlx.DB, cfg *config.AppConfig, log *zerolog.Logger) (*repository.Repositories, func()) {
+	headersStore := sql.NewHeadersDb(db, log, sql.WithBusyRetry(cfg.Db.SQLite.BusyRetries, cfg.Db.SQLite.BusyBackoff))
+
+	var headers repository.Headers = sqlrepository.NewHeadersRepository(headersStore)
+	closeRepo := func() {}
+	if cfg.WriteQueue.Enabled {
+		queued := repository.NewQueuedHeaders(headers, cfg.WriteQueue.Size, cfg.WriteQueue.BatchSize, log)
+		headers = queued
+		closeRepo = queued.Close
+	}
+	// With HA enabled the headers are written by another instance, which would leave the cache stale.
+	if cfg.Cache.HeadersSize > 0 && !cfg.HA.Enabled {
+		headers = repository.NewCachedHeaders(headers, cfg.Cache.HeadersSize)
+	}
+	// The engine is validated by database.Init already, so the snapshots are left out only in theory.
+	var snapshots repository.Snapshots
+	if s, err := database.NewSnapshots(db, cfg.Db, snapshotCheckpoints(cfg.P2P), log); err == nil {
+		snapshots = s
+	}
+
+	return &repository.Repositories{
+		Headers:         headers,
+		Tokens:          sqlrepository.NewTokensRepository(headersStore),
+		Webhooks:        sqlrepository.NewWebhooksRepository(headersStore),
+		Leases:          sqlrepository.NewLeasesRepository(headersStore),
+		Maintenance:     sqlrepository.NewMaintenanceRepository(headersStore),
+		IdempotencyKeys: sqlrepository.NewIdempotencyKeysRepository(headersStore),
+		Archive:         sqlrepository.NewArchiveRepository(headersStore),
+		Outbox:          sqlrepository.NewOutboxRepository(headersStore),
+		Snapshots:       snapshots,
+	}, closeRepo
 }
@@ -0,0 +1,175 @@
+// Package main is a command line tool querying a running Block Headers Service.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/client"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/spf13/pflag"
+)
+
+const usage = `Usage: pulse-cli [flags] <command> [args]
+
+Commands:
+  tip                               show the tip of the longest chain
+  header <hash|height>              show the header with given hash or at given height of the longest chain
+  verify <merkleroot:height>...     verify merkle roots at given heights
+  peers                             show connected peers
+  webhook add <url> [webhook flags] register a webhook
+  webhook list                      show registered webhooks, requires the admin token
+
+Flags:
+`
+
+const webhookUsage = `Usage: pulse-cli webhook add <url> [webhook flags]
+
+Webhook flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes the command given by args and prints its result as JSON to stdout.
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := pflag.NewFlagSet("pulse-cli", pflag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.SetInterspersed(false)
+	url := fs.StringP("url", "u", envOr("BHS_URL", "http://localhost:8080"), "url of the service, defaults to BHS_URL env variable")
+	token := fs.StringP("token", "t", os.Getenv("BHS_TOKEN"), "auth token, defaults to BHS_TOKEN env variable")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of the request")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	c := client.New(*url, client.WithToken(*token))
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	result, err := execute(ctx, c, fs.Arg(0), fs.Args()[1:], stderr)
+	if errors.Is(err, errUsage) {
+		fs.Usage()
+	}
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+func execute(ctx context.Context, c *client.Client, command string, args []string, stderr io.Writer) (any, error) {
+	switch command {
+	case "tip":
+		return c.LongestChainTip(ctx)
+	case "header":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return header(ctx, c, args[0])
+	case "verify":
+		return verify(ctx, c, args)
+	case "peers":
+		return c.Peers(ctx)
+	case "webhook":
+		return webhook(ctx, c, args, stderr)
+	default:
+		return nil, errUsage
+	}
+}
+
+// header returns the header with given hash or the header of the longest chain at given height.
+func header(ctx context.Context, c *client.Client, hashOrHeight string) (any, error) {
+	height, err := strconv.ParseInt(hashOrHeight, 10, 32)
+	if err != nil {
+		return c.HeaderState(ctx, hashOrHeight)
+	}
+
+	headers, err := c.HeadersByHeight(ctx, int32(height), 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	return headers[0], nil
+}
+
+func verify(ctx context.Context, c *client.Client, args []string) (any, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+
+	items := make([]domains.MerkleRootConfirmationRequestItem, 0, len(args))
+	for _, arg := range args {
+		root, heightStr, found := strings.Cut(arg, ":")
+		height, err := strconv.ParseInt(heightStr, 10, 32)
+		if !found || err != nil {
+			return nil, fmt.Errorf("expected merkleroot:height but got %s", arg)
+		}
+		items = append(items, domains.MerkleRootConfirmationRequestItem{MerkleRoot: root, BlockHeight: int32(height)})
+	}
+	return c.VerifyMerkleRoots(ctx, items)
+}
+
+func webhook(ctx context.Context, c *client.Client, args []string, stderr io.Writer) (any, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return c.Webhooks(ctx)
+	case "add":
+		fs := pflag.NewFlagSet("webhook add", pflag.ContinueOnError)
+		fs.SetOutput(stderr)
+		authType := fs.String("auth-type", "", "type of auth sent to the webhook, bearer or custom header")
+		authToken := fs.String("auth-token", "", "token sent to the webhook")
+		authHeader := fs.String("auth-header", "", "header with the token when auth type is not bearer")
+		fs.Usage = func() {
+			fmt.Fprint(stderr, webhookUsage)
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 1 {
+			return nil, errUsage
+		}
+
+		auth := client.WebhookAuth{Type: *authType, Token: *authToken, Header: *authHeader}
+		return c.RegisterWebhook(ctx, fs.Arg(0), auth)
+	default:
+		return nil, errUsage
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestHeaderByHeight(t *testing.T) {
+	// given
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`[{"hash":"00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048"}]`))
+	}))
+	defer srv.Close()
+	var stdout bytes.Buffer
+
+	// when
+	err := run([]string{"--url", srv.URL, "header", "1"}, &stdout, io.Discard)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, query, "count=1&height=1")
+	var header struct{ Hash string }
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &header))
+	assert.Equal(t, header.Hash, "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048")
+}
+
+func TestVerifyRejectsMissingHeight(t *testing.T) {
+	// when
+	err := run([]string{"--url", "http://127.0.0.1:0", "verify", "merkleroot"}, io.Discard, io.Discard)
+
+	// then
+	assert.IsError(t, err, "expected merkleroot:height but got merkleroot")
+}
+
+func TestUnknownCommand(t *testing.T) {
+	// when
+	err := run([]string{"tips"}, io.Discard, io.Discard)
+
+	// then
+	assert.IsError(t, err, errUsage.Error())
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/cron"
 )
 
 const (
@@ -42,6 +45,46 @@ const (
 	DBPostgreSQL DbEngine = "postgres"
 )
 
+// MinPartitionSize is the smallest number of heights in a partition of the headers table,
+// smaller partitions would make the database handle thousands of tables.
+const MinPartitionSize = 1000
+
+// EventBroker message broker header events are published to.
+type EventBroker string
+
+const (
+	// EventBrokerNATS is the value representing NATS JetStream.
+	EventBrokerNATS EventBroker = "nats"
+	// EventBrokerKafka is the value representing Kafka, reached through the Kafka REST proxy.
+	EventBrokerKafka EventBroker = "kafka"
+	// EventBrokerMQTT is the value representing an MQTT broker.
+	EventBrokerMQTT EventBroker = "mqtt"
+)
+
+// TipSourceType kind of external source our tip is compared with.
+type TipSourceType string
+
+const (
+	// TipSourcePulse is the value representing another Block Headers Service.
+	TipSourcePulse TipSourceType = "pulse"
+	// TipSourceWhatsOnChain is the value representing the WhatsOnChain API.
+	TipSourceWhatsOnChain TipSourceType = "whatsonchain"
+	// TipSourceNodeRPC is the value representing the JSON-RPC of a node.
+	TipSourceNodeRPC TipSourceType = "node_rpc"
+)
+
+const (
+	// WebsocketHeadersChannel is the websocket channel header events are published to.
+	WebsocketHeadersChannel = "headers"
+	// WebsocketReorgsChannel is the websocket channel events about the longest chain being replaced are published to.
+	WebsocketReorgsChannel = "reorgs"
+	// WebsocketConfirmationsChannel is the websocket channel events about headers reaching the configured number of confirmations are published to.
+	WebsocketConfirmationsChannel = "confirmations"
+)
+
+// WebsocketChannels are names of all websocket channels events are published to.
+var WebsocketChannels = []string{WebsocketHeadersChannel, WebsocketReorgsChannel, WebsocketConfirmationsChannel}
+
 // Version returns the version of the application.
 func Version() string {
 	return version
@@ -49,14 +92,35 @@ func Version() string {
 
 // AppConfig returns strongly typed config values.
 type AppConfig struct {
-	Db         *DbConfig         `mapstructure:"db"`
-	P2P        *P2PConfig        `mapstructure:"p2p"`
-	MerkleRoot *MerkleRootConfig `mapstructure:"merkleroot"`
-	Webhook    *WebhookConfig    `mapstructure:"webhook"`
-	Websocket  *WebsocketConfig  `mapstructure:"websocket"`
-	HTTP       *HTTPConfig       `mapstructure:"http"`
-	Logging    *LoggingConfig    `mapstructure:"logging"`
-	Metrics    *MetricsConfig    `mapstructure:"metrics"`
+	Db          *DbConfig          `mapstructure:"db"`
+	P2P         *P2PConfig         `mapstructure:"p2p"`
+	MerkleRoot  *MerkleRootConfig  `mapstructure:"merkleroot"`
+	Webhook     *WebhookConfig     `mapstructure:"webhook"`
+	Outbox      *OutboxConfig      `mapstructure:"outbox"`
+	Websocket   *WebsocketConfig   `mapstructure:"websocket"`
+	HTTP        *HTTPConfig        `mapstructure:"http"`
+	Logging     *LoggingConfig     `mapstructure:"logging"`
+	Metrics     *MetricsConfig     `mapstructure:"metrics"`
+	HA          *HAConfig          `mapstructure:"ha"`
+	Replica     *ReplicaConfig     `mapstructure:"replica"`
+	NodeRPC     *NodeRPCConfig     `mapstructure:"node_rpc"`
+	Simulation  *SimulationConfig  `mapstructure:"simulation"`
+	Federation  *FederationConfig  `mapstructure:"federation"`
+	Cache       *CacheConfig       `mapstructure:"cache"`
+	WriteQueue  *WriteQueueConfig  `mapstructure:"write_queue"`
+	Maintenance *MaintenanceConfig `mapstructure:"maintenance"`
+	Pruning     *PruningConfig     `mapstructure:"pruning"`
+	Archive     *ArchiveConfig     `mapstructure:"archive"`
+	Redis       *RedisConfig       `mapstructure:"redis"`
+	Publisher   *PublisherConfig   `mapstructure:"publisher"`
+	ZMQ         *ZMQConfig         `mapstructure:"zmq"`
+	TipMonitor  *TipMonitorConfig  `mapstructure:"tip_monitor"`
+	StaleTip    *StaleTipConfig    `mapstructure:"stale_tip"`
+	SelfTest    *SelfTestConfig    `mapstructure:"self_test"`
+	DiskGuard   *DiskGuardConfig   `mapstructure:"disk_guard"`
+	Idempotency *IdempotencyConfig `mapstructure:"idempotency"`
+	// Networks are additional networks served by the same process next to the one configured by Db and P2P.
+	Networks []*NetworkConfig `mapstructure:"networks"`
 }
 
 // DbConfig represents a database connection.
@@ -78,6 +142,10 @@ type DbConfig struct {
 type SQLiteConfig struct {
 	// FilePath is the path to the database file.
 	FilePath string `mapstructure:"file_path"`
+	// BusyRetries is the number of times a write is retried when the database is locked by another write.
+	BusyRetries int `mapstructure:"busy_retries"`
+	// BusyBackoff is the initial delay between retries of a locked write, doubled on every retry.
+	BusyBackoff time.Duration `mapstructure:"busy_backoff"`
 }
 
 // PostgreSQLConfig represents a postgres config.
@@ -88,6 +156,35 @@ type PostgreSQLConfig struct {
 	Password string `mapstructure:"password"`
 	DbName   string `mapstructure:"db_name"`
 	Sslmode  string `mapstructure:"ssl_mode"`
+	// PasswordEnv is the name of an environment variable holding the password. It takes precedence over Password.
+	PasswordEnv string `mapstructure:"password_env"`
+	// PasswordFile is the path to a file holding the password, e.g. a mounted secret. It takes precedence over PasswordEnv.
+	PasswordFile string `mapstructure:"password_file"`
+	// PartitionSize is the number of heights kept in a single partition of the headers table, 0 disables partitioning.
+	// It can't be changed once the table is partitioned and must be at least MinPartitionSize.
+	PartitionSize int `mapstructure:"partition_size"`
+}
+
+// ResolvePassword returns the password used to connect to the database, read from the secret
+// file or environment variable when configured, so the config file itself doesn't have to contain it.
+func (c *PostgreSQLConfig) ResolvePassword() (string, error) {
+	if c.PasswordFile != "" {
+		secret, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("db: cannot read postgres password file: %w", err)
+		}
+		return strings.TrimRight(string(secret), "\r\n"), nil
+	}
+
+	if c.PasswordEnv != "" {
+		secret, ok := os.LookupEnv(c.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("db: postgres password environment variable %s is not set", c.PasswordEnv)
+		}
+		return secret, nil
+	}
+
+	return c.Password, nil
 }
 
 // MerkleRootConfig represents merkleroots verification config.
@@ -100,6 +197,20 @@ type MerkleRootConfig struct {
 type WebhookConfig struct {
 	// MaxTries is the maximum number of tries to send a webhook.
 	MaxTries int `mapstructure:"max_tries"`
+	// RetryInterval is the delay before retrying a failed delivery, it grows with every try.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+// OutboxConfig represents a config of storing header events in the same transaction as headers.
+type OutboxConfig struct {
+	// Enabled is a flag for storing header events in the outbox table with headers and dispatching them from there.
+	Enabled bool `mapstructure:"enabled"`
+	// PollInterval is the interval of checking the outbox for events left by a crash or by another instance.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// BatchSize is the maximum number of events claimed at once.
+	BatchSize int `mapstructure:"batch_size"`
+	// ClaimTimeout is the time after which events claimed by a dispatcher which didn't delete them are dispatched again.
+	ClaimTimeout time.Duration `mapstructure:"claim_timeout"`
 }
 
 // WebsocketConfig represents a websocket config.
@@ -108,6 +219,18 @@ type WebsocketConfig struct {
 	HistoryMax int `mapstructure:"history_max"`
 	// HistoryTTL is the maximum duration for keeping history in memory.
 	HistoryTTL int `mapstructure:"history_ttl"`
+	// Confirmations is the number of confirmations after which a header confirmed event is published.
+	Confirmations int `mapstructure:"confirmations"`
+	// Channels override the history of single channels by their names.
+	Channels map[string]*WebsocketChannelConfig `mapstructure:"channels"`
+}
+
+// WebsocketChannelConfig represents the history of a single websocket channel, zero values fall back to the websocket config.
+type WebsocketChannelConfig struct {
+	// HistoryMax is the maximum number of history items of the channel to keep in memory.
+	HistoryMax int `mapstructure:"history_max"`
+	// HistoryTTL is the maximum duration in minutes for keeping history of the channel in memory.
+	HistoryTTL int `mapstructure:"history_ttl"`
 }
 
 // HTTPConfig represents a HTTPConfig config.
@@ -124,6 +247,40 @@ type HTTPConfig struct {
 	AuthToken string `mapstructure:"auth_token"`
 	// ProfilingEndpointsEnabled is a flag for enabling additional endpoits for profiling with use of pprof.
 	ProfilingEndpointsEnabled bool `mapstructure:"debug_profiling"`
+	// TipCacheTTL is the duration for which tip responses are cached, 0 disables caching.
+	TipCacheTTL time.Duration `mapstructure:"tip_cache_ttl"`
+	// StreamThreshold is the number of requested headers above which the response is streamed, 0 disables streaming.
+	// Clients accepting application/x-ndjson always get a stream.
+	StreamThreshold int `mapstructure:"stream_threshold"`
+	// BulkHeadersLimit is the maximum number of headers returned by a single bulk headers request.
+	BulkHeadersLimit int `mapstructure:"bulk_headers_limit"`
+	// Compression is the config of gzip compression of API responses.
+	Compression CompressionConfig `mapstructure:"compression"`
+	// RouteTimeouts override the global timeouts for groups of routes.
+	RouteTimeouts []RouteTimeoutConfig `mapstructure:"route_timeouts"`
+}
+
+// RouteTimeoutConfig represents timeouts of a route and all routes below it, overriding the global ones.
+type RouteTimeoutConfig struct {
+	// Route is the API route, relative to the API prefix, e.g. /chain/merkleroot applies also to /chain/merkleroot/verify.
+	Route string `mapstructure:"route"`
+	// ReadTimeout is the maximum duration for reading the request body, 0 keeps the global one.
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+	// WriteTimeout is the maximum duration before timing out writes of the response, 0 keeps the global one.
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// HandlerTimeout is the maximum duration of streaming headers of the route, 0 means no limit.
+	// Only the streamed headers read the deadline, other handlers run until they're done.
+	HandlerTimeout time.Duration `mapstructure:"handler_timeout"`
+}
+
+// CompressionConfig represents a response compression config.
+type CompressionConfig struct {
+	// Enabled is a flag for enabling gzip compression of responses when accepted by the client.
+	Enabled bool `mapstructure:"enabled"`
+	// Level is the gzip compression level, from 1 (best speed) to 9 (best compression).
+	Level int `mapstructure:"level"`
+	// Routes are the API routes, relative to the API prefix, which responses are compressed.
+	Routes []string `mapstructure:"routes"`
 }
 
 // P2PConfig represents a p2p config.
@@ -132,14 +289,98 @@ type P2PConfig struct {
 	BanDuration time.Duration `mapstructure:"ban_duration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	// DisableCheckpoints is a flag for disabling built-in checkpoints.
 	DisableCheckpoints bool `mapstructure:"disable_checkpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
+	// DisableDifficultyValidation is a flag for accepting headers which bits don't follow the difficulty adjustment.
+	DisableDifficultyValidation bool `mapstructure:"disable_difficulty_validation" description:"Accept headers which bits don't match the difficulty adjustment algorithm.  Don't do this unless you know what you're doing."`
 	// BlocksForForkConfirmation is the minimum number of blocks to consider a block confirmed.
 	BlocksForForkConfirmation int `mapstructure:"blocks_for_confirmation" description:"Minimum number of blocks to consider a block confirmed"`
 	// DefaultConnectTimeout is the default connection timeout.
 	DefaultConnectTimeout time.Duration `mapstructure:"default_connect_timeout" description:"The default connection timeout"`
 	UserAgentName         string        `mapstructure:"user_agent_name" description:"The name that should be used during announcement of the client on the p2p network"`
 	UserAgentVersion      string        `mapstructure:"user_agent_version" description:"By default will be equal to application version, but can be overridden for development purposes"`
-	ChainNetType          NetworkType   `mapstructure:"chain_net_type" description:"Chain Network Type (mainnet, testnet, regtest, simnet), mainnet by default"`
+	ChainNetType          NetworkType   `mapstructure:"chain_net_type" description:"Chain Network Type (mainnet, testnet, regtest, simnet, custom), mainnet by default"`
 	Experimental          bool          `mapstructure:"experimental" description:"Turns on a new (highly experimental) way of getting headers with the usage of /internal/transports/p2p instead of /transports/p2p"`
+	// MaxPendingHeaders is the maximum number of received headers waiting to be processed, 0 means no limit.
+	MaxPendingHeaders int `mapstructure:"max_pending_headers" description:"Maximum number of received headers waiting to be processed, peers are not read from until they are processed"`
+	// SyncedThreshold is the maximum age of a header for the service to be considered synced when adding it, 0 disables the check.
+	SyncedThreshold time.Duration `mapstructure:"synced_threshold" description:"Notifications about headers older than this are coalesced, so only the latest of them is delivered during initial sync"`
+	// Disabled turns off connecting to peers, headers are then submitted over REST or fetched from the federation upstream.
+	Disabled bool `mapstructure:"disabled" description:"Disables p2p, headers are only submitted with POST /chain/header or fetched from the federation upstream"`
+	// CustomChain defines parameters of the chain when ChainNetType is custom.
+	CustomChain *CustomChainConfig `mapstructure:"custom_chain"`
+	// TimestampValidation defines rules for timestamps of received headers.
+	TimestampValidation *TimestampValidationConfig `mapstructure:"timestamp_validation"`
+	// PeerSelection defines how the peer to sync from is picked.
+	PeerSelection *PeerSelectionConfig `mapstructure:"peer_selection"`
+	// RateLimit defines limits of inbound messages per peer.
+	RateLimit *RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// RateLimitConfig represents per peer limits of inbound inv, headers and addr messages, a peer exceeding any of them is banned for BanDuration.
+type RateLimitConfig struct {
+	// Enabled turns on limiting inbound messages.
+	Enabled bool `mapstructure:"enabled"`
+	// BanDuration is how long a peer exceeding a limit is banned for.
+	BanDuration time.Duration `mapstructure:"ban_duration"`
+	// Inv limits inv messages.
+	Inv *MessageRateConfig `mapstructure:"inv"`
+	// Headers limits headers messages.
+	Headers *MessageRateConfig `mapstructure:"headers"`
+	// Addr limits addr messages.
+	Addr *MessageRateConfig `mapstructure:"addr"`
+}
+
+// MessageRateConfig represents a token bucket limit of a message type.
+type MessageRateConfig struct {
+	// Rate is the number of messages per second allowed on average.
+	Rate float64 `mapstructure:"rate"`
+	// Burst is the number of messages allowed at once.
+	Burst int `mapstructure:"burst"`
+}
+
+// PeerSelectionConfig represents how the peer to sync from is picked among the connected ones, by default it's a random one.
+type PeerSelectionConfig struct {
+	// PingInterval is how often peers are pinged to measure their round-trip latency.
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// PreferredPeer is the host:port of the peer synced from whenever it's connected and not behind the other peers.
+	PreferredPeer string `mapstructure:"preferred_peer"`
+	// PreferLowLatency picks the peer with the lowest measured latency instead of a random one.
+	PreferLowLatency bool `mapstructure:"prefer_low_latency"`
+}
+
+// TimestampValidationConfig represents rules for timestamps of received headers, headers breaking them are quarantined.
+type TimestampValidationConfig struct {
+	// MaxFutureDrift is how far ahead of the network adjusted time a header timestamp can be, 0 disables the check.
+	MaxFutureDrift time.Duration `mapstructure:"max_future_drift"`
+	// MedianTimePast requires a header timestamp to be after the median time past of its previous 11 headers.
+	MedianTimePast bool `mapstructure:"median_time_past"`
+	// QuarantineSize is the number of the latest quarantined headers kept for inspection.
+	QuarantineSize int `mapstructure:"quarantine_size"`
+}
+
+// CustomChainConfig represents parameters of a private chain, e.g. one used in test networks.
+type CustomChainConfig struct {
+	// GenesisHeader is the hex encoded 80 bytes genesis header.
+	GenesisHeader string `mapstructure:"genesis_header"`
+	// Magic is the network magic in the same form as the node defines it, e.g. 0xe8f3e1e3 for mainnet.
+	Magic uint32 `mapstructure:"magic"`
+	// DefaultPort is the port of peers and the port p2p listens on.
+	DefaultPort string `mapstructure:"default_port"`
+	// Seeds are host names or addresses of peers to connect to.
+	Seeds []string `mapstructure:"seeds"`
+	// PowLimitBits is the highest allowed target in the compact form.
+	PowLimitBits uint32 `mapstructure:"pow_limit_bits"`
+	// TargetTimePerBlock is the desired time between blocks.
+	TargetTimePerBlock time.Duration `mapstructure:"target_time_per_block"`
+	// TargetTimespan is the desired time between difficulty readjustments before DAA.
+	TargetTimespan time.Duration `mapstructure:"target_timespan"`
+	// DaaForkHeight is the height since which the difficulty is adjusted with every block.
+	DaaForkHeight int32 `mapstructure:"daa_fork_height"`
+	// ReduceMinDifficulty allows blocks with the lowest difficulty after MinDiffReductionTime without a block.
+	ReduceMinDifficulty bool `mapstructure:"reduce_min_difficulty"`
+	// MinDiffReductionTime is the time without a block after which the lowest difficulty is allowed.
+	MinDiffReductionTime time.Duration `mapstructure:"min_diff_reduction_time"`
+	// NoDifficultyAdjustment keeps the difficulty the same for all blocks.
+	NoDifficultyAdjustment bool `mapstructure:"no_difficulty_adjustment"`
 }
 
 // LoggingConfig represents a logging config.
@@ -160,6 +401,326 @@ type MetricsConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 }
 
+// HAConfig represents a high-availability config.
+type HAConfig struct {
+	// Enabled is a flag for enabling leader election between instances sharing the same database.
+	Enabled bool `mapstructure:"enabled"`
+	// InstanceID identifies this instance in the leader lease. Hostname and pid are used when empty.
+	InstanceID string `mapstructure:"instance_id"`
+	// LeaseTTL is the duration after which a lease not renewed by the leader can be taken over.
+	LeaseTTL time.Duration `mapstructure:"lease_ttl"`
+	// RenewInterval is the interval of acquiring or renewing the lease.
+	RenewInterval time.Duration `mapstructure:"renew_interval"`
+}
+
+// CacheConfig represents an in-memory cache config.
+type CacheConfig struct {
+	// HeadersSize is the number of recently used headers kept in memory, 0 disables the cache.
+	// The cache is not used in HA mode, as headers are written there by other instances.
+	HeadersSize int `mapstructure:"headers_size"`
+}
+
+// WriteQueueConfig represents a config of the queue between headers ingestion and the storage.
+type WriteQueueConfig struct {
+	// Enabled is a flag for writing received headers to the database asynchronously in batches.
+	// Headers still waiting in the queue are lost if the process is killed.
+	Enabled bool `mapstructure:"enabled"`
+	// Size is the maximum number of headers waiting in the queue, ingestion blocks when it's full.
+	Size int `mapstructure:"size"`
+	// BatchSize is the maximum number of headers written to the database at once.
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// MaintenanceConfig represents a database maintenance config.
+type MaintenanceConfig struct {
+	// Interval is the interval of vacuuming the database and refreshing its statistics, 0 disables scheduled maintenance.
+	Interval time.Duration `mapstructure:"interval"`
+	// ModeEnabled starts the service in the maintenance mode, which pauses syncing and write operations
+	// while read queries are still served. It can be switched at runtime with PUT /api/v1/admin/maintenance/mode.
+	ModeEnabled bool `mapstructure:"mode_enabled"`
+}
+
+// PruningConfig represents a config of pruning old stale and orphan branches.
+type PruningConfig struct {
+	// Schedule is a cron expression (minute, hour, day of month, month and day of week) of pruning runs, empty disables pruning.
+	Schedule string `mapstructure:"schedule"`
+	// Depth is the number of headers below the tip of the longest chain, branches ending deeper than that are pruned.
+	Depth int `mapstructure:"depth"`
+}
+
+// ArchiveConfig represents a config of moving old stale and orphan branches out of the headers table.
+type ArchiveConfig struct {
+	// Schedule is a cron expression (minute, hour, day of month, month and day of week) of archiving runs, empty disables archiving.
+	Schedule string `mapstructure:"schedule"`
+	// MinAge is the age of the tip of a branch, by its block timestamp, above which the branch is archived.
+	MinAge time.Duration `mapstructure:"min_age"`
+}
+
+// RedisConfig represents a config of the Redis server shared by instances of the service.
+type RedisConfig struct {
+	// Enabled is a flag for sharing cached responses and header events between instances through Redis.
+	// Websocket clients of every instance then get events about headers accepted by any of them.
+	Enabled bool `mapstructure:"enabled"`
+	// Address is the address of the Redis server, either host:port or a redis:// url.
+	Address string `mapstructure:"address"`
+	// Password is the password of the Redis server.
+	Password string `mapstructure:"password"`
+	// DB is the number of the Redis database.
+	DB int `mapstructure:"db"`
+	// Prefix is prepended to all keys and channels, so different deployments can share the same Redis.
+	Prefix string `mapstructure:"prefix"`
+}
+
+// PublisherConfig represents a config of publishing header events to a message broker.
+type PublisherConfig struct {
+	// Broker is the message broker the events are published to, publishing is disabled when it is empty.
+	Broker EventBroker `mapstructure:"broker"`
+	// Confirmations is the number of confirmations after which a header confirmed event is published.
+	Confirmations int `mapstructure:"confirmations"`
+	// Topics are the NATS subjects or Kafka topics of the events.
+	Topics PublisherTopics `mapstructure:"topics"`
+
+	NATS  NATSConfig  `mapstructure:"nats"`
+	Kafka KafkaConfig `mapstructure:"kafka"`
+	MQTT  MQTTConfig  `mapstructure:"mqtt"`
+}
+
+// PublisherTopics represents NATS subjects or Kafka topics of published events.
+type PublisherTopics struct {
+	// Header is the topic of events about added headers.
+	Header string `mapstructure:"header"`
+	// Reorg is the topic of events about the longest chain being replaced.
+	Reorg string `mapstructure:"reorg"`
+	// Confirmation is the topic of events about headers reaching the configured number of confirmations.
+	Confirmation string `mapstructure:"confirmation"`
+	// Tip is the topic of events about new tips of the longest chain, tip events aren't published when it is empty.
+	// MQTT brokers retain the latest of them, so clients get the current tip as soon as they subscribe.
+	Tip string `mapstructure:"tip"`
+}
+
+// NATSConfig represents a config of the NATS server events are published to.
+type NATSConfig struct {
+	// URL is the url of the NATS server, multiple urls can be separated with commas.
+	URL string `mapstructure:"url"`
+	// Stream is the name of JetStream stream created for the topics when it doesn't exist yet.
+	// When it is empty, the stream has to be managed outside of the service.
+	Stream string `mapstructure:"stream"`
+	// Token is the token used to authenticate to the NATS server.
+	Token string `mapstructure:"token"`
+}
+
+// KafkaConfig represents a config of the Kafka REST proxy events are published through.
+type KafkaConfig struct {
+	// RestProxyURL is the url of the Kafka REST proxy.
+	RestProxyURL string `mapstructure:"rest_proxy_url"`
+}
+
+// MQTTConfig represents a config of the MQTT broker events are published to.
+type MQTTConfig struct {
+	// URL is the url of the MQTT broker, e.g. tcp://localhost:1883, ssl://localhost:8883 or ws://localhost:8080.
+	URL string `mapstructure:"url"`
+	// ClientID is the client id of the service, the broker assigns one when it is empty.
+	// Instances connected to the same broker need different ids.
+	ClientID string `mapstructure:"client_id"`
+	// Username is the username used to authenticate to the broker.
+	Username string `mapstructure:"username"`
+	// Password is the password used to authenticate to the broker.
+	Password string `mapstructure:"password"`
+	// QoS is the MQTT quality of service of published events: 0, 1 or 2.
+	QoS byte `mapstructure:"qos"`
+}
+
+// NodeRPCConfig represents a config of syncing headers from a node over its JSON-RPC.
+type NodeRPCConfig struct {
+	// Enabled is a flag for syncing headers from the node's JSON-RPC instead of syncing over p2p.
+	Enabled bool `mapstructure:"enabled"`
+	// URL is the url of the node's JSON-RPC, e.g. http://node:8332.
+	URL string `mapstructure:"url"`
+	// User is the rpcuser of the node.
+	User string `mapstructure:"user"`
+	// Password is the rpcpassword of the node.
+	Password string `mapstructure:"password"`
+	// PollInterval is the interval of checking the node for new headers.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// BatchSize is the number of headers requested from the node in a single batch of calls.
+	BatchSize int `mapstructure:"batch_size"`
+	// RequestTimeout is the timeout of a single request to the node.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+}
+
+// SimulationConfig represents a config of replaying headers from a local file instead of syncing over p2p.
+type SimulationConfig struct {
+	// Enabled is a flag for replaying headers from the file instead of syncing over p2p.
+	Enabled bool `mapstructure:"enabled"`
+	// FilePath is the path of the file with raw 80 bytes headers starting from genesis, e.g. created with the -b flag.
+	FilePath string `mapstructure:"file_path"`
+	// Interval is the delay between replayed headers, 0 replays them as fast as possible.
+	Interval time.Duration `mapstructure:"interval"`
+	// ReorgEvery is the number of replayed headers after which a reorg is injected, 0 disables injected reorgs.
+	ReorgEvery int `mapstructure:"reorg_every"`
+	// ReorgDepth is the number of replayed headers replaced by an injected reorg.
+	ReorgDepth int `mapstructure:"reorg_depth"`
+}
+
+// TipMonitorConfig represents a config of comparing our tip with external reference sources.
+type TipMonitorConfig struct {
+	// Enabled is a flag for enabling the tip monitor.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the interval of comparing the tips.
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxBlocks is the highest difference of heights of the tips which is not considered a divergence.
+	MaxBlocks int `mapstructure:"max_blocks"`
+	// MaxTimeLag is the highest difference of timestamps of the tips which is not considered a divergence.
+	MaxTimeLag time.Duration `mapstructure:"max_time_lag"`
+	// RequestTimeout is the timeout of a single request to a source.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	// Sources are the external sources our tip is compared with.
+	Sources []TipSourceConfig `mapstructure:"sources"`
+}
+
+// StaleTipConfig represents a config of alerting when the longest chain isn't extended for a long time.
+type StaleTipConfig struct {
+	// Enabled is a flag for exporting the time since the last header and alerting when the chain is stale.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the interval of checking the tip.
+	Interval time.Duration `mapstructure:"interval"`
+	// Threshold is the time since the timestamp of the tip after which the chain is considered stale.
+	Threshold time.Duration `mapstructure:"threshold"`
+}
+
+// SelfTestConfig represents a config of verifying the stored chain on startup.
+type SelfTestConfig struct {
+	// Enabled is a flag for verifying the tail of the stored longest chain before the service starts serving data.
+	Enabled bool `mapstructure:"enabled"`
+	// Headers is the number of the latest headers of the longest chain to verify.
+	Headers int `mapstructure:"headers"`
+}
+
+// DiskGuardConfig represents a config of measuring the disk usage and pausing writes when the disk is almost full.
+type DiskGuardConfig struct {
+	// Enabled is a flag for pausing writes while the free disk space is below MinFreeMB.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the interval of measuring the disk usage.
+	Interval time.Duration `mapstructure:"interval"`
+	// MinFreeMB is the free disk space in megabytes below which writes are paused.
+	MinFreeMB uint64 `mapstructure:"min_free_mb"`
+	// LogDir is the directory logs are written to, e.g. by the process supervisor, its size is reported when set.
+	LogDir string `mapstructure:"log_dir"`
+}
+
+// IdempotencyConfig represents a config of replaying responses of mutating requests retried with the same Idempotency-Key header.
+type IdempotencyConfig struct {
+	// Enabled is a flag for storing responses of requests with the Idempotency-Key header.
+	Enabled bool `mapstructure:"enabled"`
+	// TTL is the duration for which the response is replayed, after it the key can be used again.
+	TTL time.Duration `mapstructure:"ttl"`
+	// CleanupInterval is the interval of deleting expired keys.
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// TipSourceConfig represents a config of an external source of the tip.
+type TipSourceConfig struct {
+	// Name identifies the source in alerts and metrics, the type is used when empty.
+	Name string `mapstructure:"name"`
+	// Type is the kind of the source, one of pulse, whatsonchain or node_rpc.
+	Type TipSourceType `mapstructure:"type"`
+	// URL is the base url of the source, WhatsOnChain defaults to its mainnet API.
+	URL string `mapstructure:"url"`
+	// AuthToken is the token authorizing requests to another Block Headers Service or the WhatsOnChain API key.
+	AuthToken string `mapstructure:"auth_token"`
+	// User is the rpcuser of the node.
+	User string `mapstructure:"user"`
+	// Password is the rpcpassword of the node.
+	Password string `mapstructure:"password"`
+}
+
+// ZMQConfig represents a config of ZeroMQ notifications compatible with the ones of the node.
+type ZMQConfig struct {
+	// PubHashBlock is the address of the socket publishing hashes of new tips as hashblock messages,
+	// e.g. tcp://127.0.0.1:28332, the socket isn't created when it is empty.
+	PubHashBlock string `mapstructure:"pub_hash_block"`
+	// PubRawBlockHeader is the address of the socket publishing serialized headers of new tips as rawblockheader messages,
+	// it can be the same as PubHashBlock.
+	PubRawBlockHeader string `mapstructure:"pub_raw_block_header"`
+	// HighWaterMark is the maximum number of messages queued for a subscriber, further messages are dropped.
+	HighWaterMark int `mapstructure:"high_water_mark"`
+}
+
+// Enabled checks if any of ZeroMQ sockets is configured.
+func (c *ZMQConfig) Enabled() bool {
+	return c != nil && (c.PubHashBlock != "" || c.PubRawBlockHeader != "")
+}
+
+// ReplicaConfig represents a read-only replica config.
+type ReplicaConfig struct {
+	// Enabled is a flag for replicating headers from a primary instance instead of syncing over p2p.
+	Enabled bool `mapstructure:"enabled"`
+	// PrimaryURL is the base url of the primary instance, e.g. http://primary:8080.
+	PrimaryURL string `mapstructure:"primary_url"`
+	// AuthToken is the token used to authorize requests to the primary instance.
+	AuthToken string `mapstructure:"auth_token"`
+	// PollInterval is the interval of checking the primary for new headers.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// BatchSize is the number of headers requested from the primary at once.
+	BatchSize int `mapstructure:"batch_size"`
+	// RequestTimeout is the timeout of a single request to the primary.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+}
+
+// FederationConfig represents a config of the upstream instance used as a fallback headers source.
+type FederationConfig struct {
+	// Enabled is a flag for syncing headers from the upstream instance while no p2p peers are connected.
+	Enabled bool `mapstructure:"enabled"`
+	// UpstreamURL is the base url of the upstream instance, e.g. http://upstream:8080.
+	UpstreamURL string `mapstructure:"upstream_url"`
+	// AuthToken is the token used to authorize requests to the upstream instance.
+	AuthToken string `mapstructure:"auth_token"`
+	// FallbackAfter is how long no p2p peer has to be connected before headers are fetched from the upstream instance.
+	FallbackAfter time.Duration `mapstructure:"fallback_after"`
+	// PollInterval is the interval of checking the peers and the upstream instance for new headers.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// BatchSize is the number of headers requested from the upstream instance at once.
+	BatchSize int `mapstructure:"batch_size"`
+	// RequestTimeout is the timeout of a single request to the upstream instance.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+}
+
+// NetworkConfig represents a config of an additional network served by the application.
+// Each network has its own database and p2p stack and is exposed under /api/v1/{chain_net_type}.
+type NetworkConfig struct {
+	// ChainNetType is the type of the network [mainnet|testnet|regtest|simnet].
+	ChainNetType NetworkType `mapstructure:"chain_net_type"`
+	// Db is the database of the network, only engine and connection settings are taken from it.
+	Db *DbConfig `mapstructure:"db"`
+}
+
+// Name returns the name of the network used as a path prefix of its API.
+func (c *P2PConfig) Name() string {
+	return string(c.ChainNetType)
+}
+
+// ForNetwork returns a copy of the application config with database and p2p settings of the given network.
+// Settings missing in the network config are inherited from the main network. Additional networks always
+// use the experimental p2p stack, since the default one relies on process wide state.
+func (c *AppConfig) ForNetwork(n *NetworkConfig) *AppConfig {
+	cfg := *c
+
+	db := *c.Db
+	db.Engine = n.Db.Engine
+	db.SQLite.FilePath = n.Db.SQLite.FilePath
+	db.Postgres = n.Db.Postgres
+	db.PreparedDb = false
+
+	p2p := *c.P2P
+	p2p.ChainNetType = n.ChainNetType
+	p2p.Experimental = true
+
+	cfg.Db = &db
+	cfg.P2P = &p2p
+	cfg.Networks = nil
+	return &cfg
+}
+
 // WithoutAuthorization sets an authorization to be disabled.
 func (c *AppConfig) WithoutAuthorization() *AppConfig {
 	c.HTTP.UseAuth = false
@@ -172,6 +733,122 @@ func (c *AppConfig) Validate() error {
 		return err
 	}
 
+	if err := c.HA.Validate(c.Db); err != nil {
+		return err
+	}
+
+	if err := c.P2P.Validate(c.Replica, c.NodeRPC); err != nil {
+		return err
+	}
+
+	if err := c.Replica.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.NodeRPC.Validate(c.Replica); err != nil {
+		return err
+	}
+
+	if err := c.Simulation.Validate(c.P2P, c.Replica, c.NodeRPC, c.Federation); err != nil {
+		return err
+	}
+
+	if err := c.Federation.Validate(c.Replica, c.NodeRPC, c.P2P); err != nil {
+		return err
+	}
+
+	if err := c.WriteQueue.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Webhook.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Outbox.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Websocket.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Redis.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Publisher.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.ZMQ.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.HTTP.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.TipMonitor.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.StaleTip.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.SelfTest.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.DiskGuard.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Idempotency.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Pruning.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Archive.Validate(c.Pruning, c.P2P.GetNetParams().TargetTimePerBlock); err != nil {
+		return err
+	}
+
+	names := map[string]bool{c.P2P.Name(): true}
+	for _, n := range c.Networks {
+		if err := n.Validate(); err != nil {
+			return err
+		}
+		if names[string(n.ChainNetType)] {
+			return fmt.Errorf("networks: network %s is configured more than once", n.ChainNetType)
+		}
+		names[string(n.ChainNetType)] = true
+
+		if err := c.ForNetwork(n).Db.Validate(); err != nil {
+			return fmt.Errorf("networks: %s: %w", n.ChainNetType, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *HAConfig) Validate(db *DbConfig) error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if db.Engine != DBPostgreSQL {
+		return fmt.Errorf("ha: leader election requires a shared %s database", DBPostgreSQL)
+	}
+
+	if c.RenewInterval <= 0 || c.LeaseTTL <= c.RenewInterval {
+		return errors.New("ha: renew_interval must be positive and shorter than lease_ttl")
+	}
+
 	return nil
 }
 
@@ -200,6 +877,15 @@ func (c *DbConfig) Validate() error {
 		if c.Postgres.Host == "" || c.Postgres.Port == 0 || c.Postgres.User == "" || c.Postgres.DbName == "" {
 			return fmt.Errorf("db: postgres configuration should be filled properly to use postgres engine %s", DBPostgreSQL)
 		}
+		if _, err := c.Postgres.ResolvePassword(); err != nil {
+			return err
+		}
+		if c.Postgres.PartitionSize < 0 {
+			return errors.New("db: postgres partition_size cannot be negative")
+		}
+		if c.Postgres.PartitionSize > 0 && c.Postgres.PartitionSize < MinPartitionSize {
+			return fmt.Errorf("db: postgres partition_size must be at least %d heights", MinPartitionSize)
+		}
 
 	default:
 		return errors.New("db: unsupported type")
@@ -208,6 +894,455 @@ func (c *DbConfig) Validate() error {
 	return nil
 }
 
+// Validate validates the configuration.
+func (c *NetworkConfig) Validate() error {
+	if c == nil || c.Db == nil {
+		return errors.New("networks: db configuration of a network cannot be empty")
+	}
+
+	switch c.ChainNetType {
+	case MainNet, TestNet, RegTestNet, SimulationNet:
+	default:
+		return fmt.Errorf("networks: unsupported chain_net_type %q", c.ChainNetType)
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *ReplicaConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.PrimaryURL == "" {
+		return errors.New("replica: primary_url cannot be empty when replica mode is enabled")
+	}
+
+	if c.BatchSize <= 0 || c.PollInterval <= 0 {
+		return errors.New("replica: batch_size and poll_interval must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *NodeRPCConfig) Validate(replica *ReplicaConfig) error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if replica != nil && replica.Enabled {
+		return errors.New("node_rpc: cannot be enabled together with replica mode")
+	}
+
+	if c.URL == "" {
+		return errors.New("node_rpc: url cannot be empty when syncing from node rpc is enabled")
+	}
+
+	if c.BatchSize <= 0 || c.PollInterval <= 0 {
+		return errors.New("node_rpc: batch_size and poll_interval must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *OutboxConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.PollInterval <= 0 || c.BatchSize <= 0 || c.ClaimTimeout <= 0 {
+		return errors.New("outbox: poll_interval, batch_size and claim_timeout must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *WebhookConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MaxTries <= 0 || c.RetryInterval <= 0 {
+		return errors.New("webhook: max_tries and retry_interval must be positive")
+	}
+
+	return nil
+}
+
+// History returns the maximum number of history items and how long they are kept for given channel.
+func (c *WebsocketConfig) History(channel string) (int, time.Duration) {
+	size, ttl := c.HistoryMax, c.HistoryTTL
+	if ch := c.Channels[channel]; ch != nil {
+		if ch.HistoryMax != 0 {
+			size = ch.HistoryMax
+		}
+		if ch.HistoryTTL != 0 {
+			ttl = ch.HistoryTTL
+		}
+	}
+	return size, time.Duration(ttl) * time.Minute
+}
+
+// Validate validates the configuration.
+func (c *WebsocketConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Confirmations <= 0 {
+		return errors.New("websocket: confirmations must be positive")
+	}
+
+	for name, ch := range c.Channels {
+		if !slices.Contains(WebsocketChannels, name) {
+			return fmt.Errorf("websocket: channels: unknown channel %s, supported channels are %s", name, strings.Join(WebsocketChannels, ", "))
+		}
+		if ch != nil && (ch.HistoryMax < 0 || ch.HistoryTTL < 0) {
+			return fmt.Errorf("websocket: channels: %s: history_max and history_ttl cannot be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *SimulationConfig) Validate(p2p *P2PConfig, replica *ReplicaConfig, nodeRPC *NodeRPCConfig, federation *FederationConfig) error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if (replica != nil && replica.Enabled) || (nodeRPC != nil && nodeRPC.Enabled) || (federation != nil && federation.Enabled) {
+		return errors.New("simulation: cannot be enabled together with replica, node_rpc or federation")
+	}
+
+	if c.FilePath == "" {
+		return errors.New("simulation: file_path cannot be empty when simulation is enabled")
+	}
+
+	if c.Interval < 0 || c.ReorgEvery < 0 || (c.ReorgEvery > 0 && c.ReorgDepth <= 0) {
+		return errors.New("simulation: interval and reorg_every cannot be negative and reorg_depth must be positive when reorgs are injected")
+	}
+
+	// Injected reorgs are headers without a valid proof of work, they can't end up in a database of a real network.
+	if c.ReorgEvery > 0 && !simulatedReorgsAllowed(p2p) {
+		return errors.New("simulation: reorg_every can be set only with p2p chain_net_type regtest, simnet or custom")
+	}
+
+	return nil
+}
+
+func simulatedReorgsAllowed(p2p *P2PConfig) bool {
+	if p2p == nil {
+		return false
+	}
+	switch p2p.ChainNetType {
+	case RegTestNet, SimulationNet, CustomNet:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate validates the configuration.
+func (c *P2PConfig) Validate(replica *ReplicaConfig, nodeRPC *NodeRPCConfig) error {
+	if c == nil {
+		return nil
+	}
+
+	if tv := c.TimestampValidation; tv != nil && (tv.MaxFutureDrift < 0 || tv.QuarantineSize < 0) {
+		return errors.New("p2p: timestamp_validation: max_future_drift and quarantine_size cannot be negative")
+	}
+
+	if ps := c.PeerSelection; ps != nil {
+		if ps.PingInterval <= 0 {
+			return errors.New("p2p: peer_selection: ping_interval must be positive")
+		}
+		if _, _, err := net.SplitHostPort(ps.PreferredPeer); ps.PreferredPeer != "" && err != nil {
+			return fmt.Errorf("p2p: peer_selection: preferred_peer must be host:port: %w", err)
+		}
+	}
+
+	if rl := c.RateLimit; rl != nil && rl.Enabled {
+		if rl.BanDuration <= 0 {
+			return errors.New("p2p: rate_limit: ban_duration must be positive")
+		}
+		names := []string{"inv", "headers", "addr"}
+		for i, limit := range []*MessageRateConfig{rl.Inv, rl.Headers, rl.Addr} {
+			if limit == nil || limit.Rate <= 0 || limit.Burst <= 0 {
+				return fmt.Errorf("p2p: rate_limit: %s: rate and burst must be positive", names[i])
+			}
+		}
+	}
+
+	if c.ChainNetType != CustomNet {
+		return nil
+	}
+
+	// The legacy p2p server is bound to mainnet parameters, other sources use parameters of the configured chain.
+	if !c.Experimental && !c.Disabled && (replica == nil || !replica.Enabled) && (nodeRPC == nil || !nodeRPC.Enabled) {
+		return errors.New("p2p: custom chain requires experimental or disabled p2p, replica or node_rpc")
+	}
+
+	if _, err := c.CustomChain.NetParams(); err != nil {
+		return fmt.Errorf("p2p: custom_chain: %w", err)
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *FederationConfig) Validate(replica *ReplicaConfig, nodeRPC *NodeRPCConfig, p2p *P2PConfig) error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	// The fallback stands in for p2p, which is not running in the other modes.
+	if (replica != nil && replica.Enabled) || (nodeRPC != nil && nodeRPC.Enabled) {
+		return errors.New("federation: cannot be enabled together with replica or node_rpc")
+	}
+
+	// Peers of the experimental p2p server are not counted, it would always look unreachable.
+	if p2p != nil && p2p.Experimental && !p2p.Disabled {
+		return errors.New("federation: cannot be enabled together with experimental p2p")
+	}
+
+	if c.UpstreamURL == "" {
+		return errors.New("federation: upstream_url cannot be empty when federation is enabled")
+	}
+
+	if c.BatchSize <= 0 || c.PollInterval <= 0 || c.FallbackAfter <= 0 {
+		return errors.New("federation: batch_size, poll_interval and fallback_after must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *HTTPConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for _, rt := range c.RouteTimeouts {
+		if !strings.HasPrefix(rt.Route, "/") {
+			return fmt.Errorf("http: route %q of route_timeouts must start with /", rt.Route)
+		}
+		if rt.ReadTimeout < 0 || rt.WriteTimeout < 0 || rt.HandlerTimeout < 0 {
+			return fmt.Errorf("http: timeouts of route %s cannot be negative", rt.Route)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *StaleTipConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.Interval <= 0 || c.Threshold <= 0 {
+		return errors.New("stale_tip: interval and threshold must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *DiskGuardConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.Interval <= 0 || c.MinFreeMB == 0 {
+		return errors.New("disk_guard: interval and min_free_mb must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *IdempotencyConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.TTL <= 0 || c.CleanupInterval <= 0 {
+		return errors.New("idempotency: ttl and cleanup_interval must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *TipMonitorConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if len(c.Sources) == 0 {
+		return errors.New("tip_monitor: at least one source is required when tip monitor is enabled")
+	}
+
+	if c.Interval <= 0 || c.MaxBlocks < 0 || c.MaxTimeLag <= 0 {
+		return errors.New("tip_monitor: interval and max_time_lag must be positive and max_blocks cannot be negative")
+	}
+
+	for _, s := range c.Sources {
+		switch s.Type {
+		case TipSourceWhatsOnChain:
+		case TipSourcePulse, TipSourceNodeRPC:
+			if s.URL == "" {
+				return fmt.Errorf("tip_monitor: url is required for %s source", s.Type)
+			}
+		default:
+			return fmt.Errorf("tip_monitor: unsupported source type %s", s.Type)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *SelfTestConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.Headers <= 0 {
+		return errors.New("self_test: headers must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *PruningConfig) Validate() error {
+	if c == nil || c.Schedule == "" {
+		return nil
+	}
+
+	if _, err := cron.Parse(c.Schedule); err != nil {
+		return fmt.Errorf("pruning: schedule: %w", err)
+	}
+
+	if c.Depth <= 0 {
+		return errors.New("pruning: depth must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration. Branches pruned before they reach the min age would never be archived,
+// so with pruning enabled its depth, in blocks of the given interval, must cover the min age.
+func (c *ArchiveConfig) Validate(pruning *PruningConfig, blockInterval time.Duration) error {
+	if c == nil || c.Schedule == "" {
+		return nil
+	}
+
+	if _, err := cron.Parse(c.Schedule); err != nil {
+		return fmt.Errorf("archive: schedule: %w", err)
+	}
+
+	if c.MinAge <= 0 {
+		return errors.New("archive: min_age must be positive")
+	}
+
+	if pruning != nil && pruning.Schedule != "" && blockInterval > 0 {
+		if minDepth := int((c.MinAge + blockInterval - 1) / blockInterval); pruning.Depth < minDepth {
+			return fmt.Errorf("archive: pruning.depth %d deletes branches before they're %s old, it must be at least %d or pruning disabled", pruning.Depth, c.MinAge, minDepth)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *WriteQueueConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.Size <= 0 || c.BatchSize <= 0 {
+		return errors.New("write_queue: size and batch_size must be positive")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *RedisConfig) Validate() error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	if c.Address == "" {
+		return errors.New("redis: address is required when redis is enabled")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *PublisherConfig) Validate() error {
+	if c == nil || c.Broker == "" {
+		return nil
+	}
+
+	switch c.Broker {
+	case EventBrokerNATS:
+		if c.NATS.URL == "" {
+			return errors.New("publisher: nats url is required when nats broker is used")
+		}
+	case EventBrokerKafka:
+		if c.Kafka.RestProxyURL == "" {
+			return errors.New("publisher: kafka rest_proxy_url is required when kafka broker is used")
+		}
+	case EventBrokerMQTT:
+		if c.MQTT.URL == "" {
+			return errors.New("publisher: mqtt url is required when mqtt broker is used")
+		}
+		if c.MQTT.QoS > 2 {
+			return errors.New("publisher: mqtt qos must be 0, 1 or 2")
+		}
+	default:
+		return fmt.Errorf("publisher: unsupported broker %s", c.Broker)
+	}
+
+	if c.Confirmations <= 0 {
+		return errors.New("publisher: confirmations must be positive")
+	}
+
+	if c.Topics.Header == "" || c.Topics.Reorg == "" || c.Topics.Confirmation == "" {
+		return errors.New("publisher: all topics are required")
+	}
+
+	return nil
+}
+
+// Validate validates the configuration.
+func (c *ZMQConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	for _, address := range []string{c.PubHashBlock, c.PubRawBlockHeader} {
+		if address != "" && !strings.HasPrefix(address, "tcp://") {
+			return fmt.Errorf("zmq: unsupported address %s, only tcp:// is supported", address)
+		}
+	}
+
+	if c.HighWaterMark <= 0 {
+		return errors.New("zmq: high_water_mark must be positive")
+	}
+
+	return nil
+}
+
 func fileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
 	return !os.IsNotExist(err)
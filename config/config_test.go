@@ -0,0 +1,202 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestArchiveValidationWithPruning(t *testing.T) {
+	testCases := map[string]struct {
+		pruning       *PruningConfig
+		expectedError string
+	}{
+		"pruning disabled": {
+			pruning: &PruningConfig{Depth: 10},
+		},
+		"pruning deeper than min age": {
+			pruning: &PruningConfig{Schedule: "0 3 * * *", Depth: 144},
+		},
+		"pruning before min age": {
+			pruning:       &PruningConfig{Schedule: "0 3 * * *", Depth: 143},
+			expectedError: "archive: pruning.depth 143 deletes branches before they're 24h0m0s old, it must be at least 144 or pruning disabled",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := &ArchiveConfig{Schedule: "0 4 * * 0", MinAge: 24 * time.Hour}
+
+			// when
+			err := cfg.Validate(tc.pruning, 10*time.Minute)
+
+			// then
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.IsError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestPostgresPartitionSizeValidation(t *testing.T) {
+	testCases := map[string]struct {
+		partitionSize int
+		expectedError string
+	}{
+		"partitioning disabled": {
+			partitionSize: 0,
+		},
+		"minimal partition size": {
+			partitionSize: MinPartitionSize,
+		},
+		"too small partitions": {
+			partitionSize: 100,
+			expectedError: "db: postgres partition_size must be at least 1000 heights",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := &DbConfig{Engine: DBPostgreSQL, Postgres: getPostgresDefaults()}
+			cfg.Postgres.PartitionSize = tc.partitionSize
+
+			// when
+			err := cfg.Validate()
+
+			// then
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.IsError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestSimulationReorgsValidation(t *testing.T) {
+	testCases := map[string]struct {
+		network       NetworkType
+		reorgEvery    int
+		expectedError string
+	}{
+		"replay on mainnet": {
+			network: MainNet,
+		},
+		"reorgs on regtest": {
+			network:    RegTestNet,
+			reorgEvery: 100,
+		},
+		"reorgs on simnet": {
+			network:    SimulationNet,
+			reorgEvery: 100,
+		},
+		"reorgs on a custom network": {
+			network:    CustomNet,
+			reorgEvery: 100,
+		},
+		"reorgs on mainnet": {
+			network:       MainNet,
+			reorgEvery:    100,
+			expectedError: "simulation: reorg_every can be set only with p2p chain_net_type regtest, simnet or custom",
+		},
+		"reorgs on testnet": {
+			network:       TestNet,
+			reorgEvery:    100,
+			expectedError: "simulation: reorg_every can be set only with p2p chain_net_type regtest, simnet or custom",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := &SimulationConfig{Enabled: true, FilePath: "headers.bin", ReorgEvery: tc.reorgEvery, ReorgDepth: 2}
+
+			// when
+			err := cfg.Validate(&P2PConfig{ChainNetType: tc.network}, nil, nil, nil)
+
+			// then
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.IsError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestPostgresResolvePassword(t *testing.T) {
+	const passwordEnv = "BHS_TEST_POSTGRES_PASSWORD"
+
+	testCases := map[string]struct {
+		fileContent   string
+		envValue      *string
+		config        PostgreSQLConfig
+		expected      string
+		expectedError string
+	}{
+		"literal password": {
+			config:   PostgreSQLConfig{Password: "literal"},
+			expected: "literal",
+		},
+		"env over literal": {
+			envValue: stringPtr("from-env"),
+			config:   PostgreSQLConfig{Password: "literal", PasswordEnv: passwordEnv},
+			expected: "from-env",
+		},
+		"file over env and literal": {
+			fileContent: "from-file",
+			envValue:    stringPtr("from-env"),
+			config:      PostgreSQLConfig{Password: "literal", PasswordEnv: passwordEnv},
+			expected:    "from-file",
+		},
+		"trailing newline of the file trimmed": {
+			fileContent: "from-file\r\n",
+			expected:    "from-file",
+		},
+		"env var set to empty": {
+			envValue: stringPtr(""),
+			config:   PostgreSQLConfig{Password: "literal", PasswordEnv: passwordEnv},
+			expected: "",
+		},
+		"missing env var": {
+			config:        PostgreSQLConfig{Password: "literal", PasswordEnv: passwordEnv},
+			expectedError: "db: postgres password environment variable " + passwordEnv + " is not set",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := tc.config
+			if tc.fileContent != "" {
+				cfg.PasswordFile = filepath.Join(t.TempDir(), "password")
+				assert.NoError(t, os.WriteFile(cfg.PasswordFile, []byte(tc.fileContent), 0o600))
+			}
+			if tc.envValue != nil {
+				t.Setenv(passwordEnv, *tc.envValue)
+			}
+
+			// when
+			password, err := cfg.ResolvePassword()
+
+			// then
+			if tc.expectedError != "" {
+				assert.IsError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, password, tc.expected)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
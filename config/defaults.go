@@ -1,6 +1,7 @@
 package config
 
 import (
+	"compress/gzip"
 	"time"
 )
 
@@ -10,14 +11,33 @@ const DefaultAppToken = "mQZQ6WmxURxWz5ch" // #nosec G101
 // GetDefaultAppConfig returns the default configuration for the application
 func GetDefaultAppConfig() *AppConfig {
 	return &AppConfig{
-		Db:         getDbDefaults(),
-		HTTP:       getHTTPConfigDefaults(),
-		MerkleRoot: getMerkleRootDefaults(),
-		Websocket:  getWebsocketDefaults(),
-		Webhook:    getWebhookDefaults(),
-		P2P:        getP2PDefaults(),
-		Logging:    getLoggingDefaults(),
-		Metrics:    getMetricsDefaults(),
+		Db:          getDbDefaults(),
+		HTTP:        getHTTPConfigDefaults(),
+		MerkleRoot:  getMerkleRootDefaults(),
+		Websocket:   getWebsocketDefaults(),
+		Webhook:     getWebhookDefaults(),
+		Outbox:      getOutboxDefaults(),
+		P2P:         getP2PDefaults(),
+		Logging:     getLoggingDefaults(),
+		Metrics:     getMetricsDefaults(),
+		HA:          getHADefaults(),
+		Replica:     getReplicaDefaults(),
+		NodeRPC:     getNodeRPCDefaults(),
+		Simulation:  getSimulationDefaults(),
+		Federation:  getFederationDefaults(),
+		Cache:       getCacheDefaults(),
+		WriteQueue:  getWriteQueueDefaults(),
+		Maintenance: getMaintenanceDefaults(),
+		Pruning:     getPruningDefaults(),
+		Archive:     getArchiveDefaults(),
+		Redis:       getRedisDefaults(),
+		Publisher:   getPublisherDefaults(),
+		ZMQ:         getZMQDefaults(),
+		TipMonitor:  getTipMonitorDefaults(),
+		StaleTip:    getStaleTipDefaults(),
+		SelfTest:    getSelfTestDefaults(),
+		DiskGuard:   getDiskGuardDefaults(),
+		Idempotency: getIdempotencyDefaults(),
 	}
 }
 
@@ -28,7 +48,9 @@ func getDbDefaults() *DbConfig {
 		PreparedDb:         false,
 		PreparedDbFilePath: "./data/blockheaders.csv.gz",
 		SQLite: SQLiteConfig{
-			FilePath: "./data/blockheaders.db",
+			FilePath:    "./data/blockheaders.db",
+			BusyRetries: 5,
+			BusyBackoff: 50 * time.Millisecond,
 		},
 		Postgres: getPostgresDefaults(),
 	}
@@ -42,6 +64,19 @@ func getHTTPConfigDefaults() *HTTPConfig {
 		UseAuth:                   true,
 		AuthToken:                 DefaultAppToken,
 		ProfilingEndpointsEnabled: true,
+		TipCacheTTL:               0,
+		StreamThreshold:           1000,
+		BulkHeadersLimit:          2000,
+		Compression: CompressionConfig{
+			Enabled: true,
+			Level:   gzip.DefaultCompression,
+			Routes: []string{
+				"/chain/header/byHeight",
+				"/chain/header/:hash/:ancestorHash/ancestor",
+				"/chain/merkleroot",
+				"/chain/merkleroot/verify",
+			},
+		},
 	}
 }
 
@@ -53,27 +88,82 @@ func getMerkleRootDefaults() *MerkleRootConfig {
 
 func getWebsocketDefaults() *WebsocketConfig {
 	return &WebsocketConfig{
-		HistoryMax: 300,
-		HistoryTTL: 10,
+		HistoryMax:    300,
+		HistoryTTL:    10,
+		Confirmations: 6,
 	}
 }
 
 func getWebhookDefaults() *WebhookConfig {
 	return &WebhookConfig{
-		MaxTries: 10,
+		MaxTries:      10,
+		RetryInterval: 30 * time.Second,
+	}
+}
+
+func getOutboxDefaults() *OutboxConfig {
+	return &OutboxConfig{
+		Enabled:      false,
+		PollInterval: 5 * time.Second,
+		BatchSize:    500,
+		ClaimTimeout: time.Minute,
 	}
 }
 
 func getP2PDefaults() *P2PConfig {
 	return &P2PConfig{
-		BanDuration:               time.Hour * 24,
-		BlocksForForkConfirmation: 10,
-		DefaultConnectTimeout:     30 * time.Second,
-		DisableCheckpoints:        false,
-		UserAgentName:             ApplicationName,
-		UserAgentVersion:          Version(),
-		ChainNetType:              MainNet,
-		Experimental:              false,
+		BanDuration:                 time.Hour * 24,
+		BlocksForForkConfirmation:   10,
+		DefaultConnectTimeout:       30 * time.Second,
+		DisableCheckpoints:          false,
+		DisableDifficultyValidation: false,
+		UserAgentName:               ApplicationName,
+		UserAgentVersion:            Version(),
+		ChainNetType:                MainNet,
+		Experimental:                false,
+		MaxPendingHeaders:           20000,
+		SyncedThreshold:             24 * time.Hour,
+		Disabled:                    false,
+		CustomChain:                 getCustomChainDefaults(),
+		TimestampValidation:         getTimestampValidationDefaults(),
+		PeerSelection:               getPeerSelectionDefaults(),
+		RateLimit:                   getRateLimitDefaults(),
+	}
+}
+
+func getRateLimitDefaults() *RateLimitConfig {
+	return &RateLimitConfig{
+		Enabled:     false,
+		BanDuration: 10 * time.Minute,
+		Inv:         &MessageRateConfig{Rate: 10, Burst: 100},
+		Headers:     &MessageRateConfig{Rate: 5, Burst: 50},
+		Addr:        &MessageRateConfig{Rate: 0.1, Burst: 10},
+	}
+}
+
+func getPeerSelectionDefaults() *PeerSelectionConfig {
+	return &PeerSelectionConfig{
+		PingInterval:     2 * time.Minute,
+		PreferredPeer:    "",
+		PreferLowLatency: false,
+	}
+}
+
+func getTimestampValidationDefaults() *TimestampValidationConfig {
+	return &TimestampValidationConfig{
+		MaxFutureDrift: 2 * time.Hour,
+		MedianTimePast: true,
+		QuarantineSize: 100,
+	}
+}
+
+func getCustomChainDefaults() *CustomChainConfig {
+	return &CustomChainConfig{
+		DefaultPort:          "18444",
+		PowLimitBits:         0x207fffff,
+		TargetTimePerBlock:   10 * time.Minute,
+		TargetTimespan:       14 * 24 * time.Hour,
+		MinDiffReductionTime: 20 * time.Minute,
 	}
 }
 
@@ -92,13 +182,188 @@ func getMetricsDefaults() *MetricsConfig {
 	}
 }
 
+func getHADefaults() *HAConfig {
+	return &HAConfig{
+		Enabled:       false,
+		InstanceID:    "",
+		LeaseTTL:      15 * time.Second,
+		RenewInterval: 5 * time.Second,
+	}
+}
+
+func getCacheDefaults() *CacheConfig {
+	return &CacheConfig{
+		HeadersSize: 1000,
+	}
+}
+
+func getWriteQueueDefaults() *WriteQueueConfig {
+	return &WriteQueueConfig{
+		Enabled:   false,
+		Size:      10000,
+		BatchSize: 500,
+	}
+}
+
+func getPruningDefaults() *PruningConfig {
+	return &PruningConfig{
+		Schedule: "",
+		Depth:    1000,
+	}
+}
+
+func getArchiveDefaults() *ArchiveConfig {
+	return &ArchiveConfig{
+		Schedule: "",
+		MinAge:   30 * 24 * time.Hour,
+	}
+}
+
+func getMaintenanceDefaults() *MaintenanceConfig {
+	return &MaintenanceConfig{
+		Interval: 24 * time.Hour,
+	}
+}
+
+func getReplicaDefaults() *ReplicaConfig {
+	return &ReplicaConfig{
+		Enabled:        false,
+		PrimaryURL:     "",
+		AuthToken:      "",
+		PollInterval:   10 * time.Second,
+		BatchSize:      2000,
+		RequestTimeout: 30 * time.Second,
+	}
+}
+
+func getFederationDefaults() *FederationConfig {
+	return &FederationConfig{
+		Enabled:        false,
+		UpstreamURL:    "",
+		AuthToken:      "",
+		FallbackAfter:  2 * time.Minute,
+		PollInterval:   10 * time.Second,
+		BatchSize:      2000,
+		RequestTimeout: 30 * time.Second,
+	}
+}
+
+func getNodeRPCDefaults() *NodeRPCConfig {
+	return &NodeRPCConfig{
+		Enabled:        false,
+		URL:            "http://localhost:8332",
+		User:           "",
+		Password:       "",
+		PollInterval:   10 * time.Second,
+		BatchSize:      1000,
+		RequestTimeout: 30 * time.Second,
+	}
+}
+
+func getSimulationDefaults() *SimulationConfig {
+	return &SimulationConfig{
+		Enabled:    false,
+		FilePath:   "",
+		Interval:   time.Second,
+		ReorgEvery: 0,
+		ReorgDepth: 2,
+	}
+}
+
 func getPostgresDefaults() PostgreSQLConfig {
 	return PostgreSQLConfig{
-		Host:     "localhost",
-		Port:     5432,
-		User:     "user",
-		Password: "password",
-		DbName:   "bhs",
-		Sslmode:  "disable",
+		Host:          "localhost",
+		Port:          5432,
+		User:          "user",
+		Password:      "password",
+		DbName:        "bhs",
+		Sslmode:       "disable",
+		PasswordEnv:   "",
+		PasswordFile:  "",
+		PartitionSize: 0,
+	}
+}
+
+func getRedisDefaults() *RedisConfig {
+	return &RedisConfig{
+		Enabled: false,
+		Address: "localhost:6379",
+		DB:      0,
+		Prefix:  ApplicationName,
+	}
+}
+
+func getPublisherDefaults() *PublisherConfig {
+	return &PublisherConfig{
+		Broker:        "",
+		Confirmations: 6,
+		Topics: PublisherTopics{
+			Header:       "headers.added",
+			Reorg:        "headers.reorg",
+			Confirmation: "headers.confirmed",
+			Tip:          "headers.tip",
+		},
+		NATS: NATSConfig{
+			URL:    "nats://localhost:4222",
+			Stream: "HEADERS",
+		},
+		Kafka: KafkaConfig{
+			RestProxyURL: "http://localhost:8082",
+		},
+		MQTT: MQTTConfig{
+			URL: "tcp://localhost:1883",
+			QoS: 1,
+		},
+	}
+}
+
+func getZMQDefaults() *ZMQConfig {
+	return &ZMQConfig{
+		PubHashBlock:      "",
+		PubRawBlockHeader: "",
+		HighWaterMark:     1000,
+	}
+}
+
+func getTipMonitorDefaults() *TipMonitorConfig {
+	return &TipMonitorConfig{
+		Enabled:        false,
+		Interval:       time.Minute,
+		MaxBlocks:      3,
+		MaxTimeLag:     30 * time.Minute,
+		RequestTimeout: 10 * time.Second,
+		Sources:        []TipSourceConfig{},
+	}
+}
+
+func getStaleTipDefaults() *StaleTipConfig {
+	return &StaleTipConfig{
+		Enabled:   true,
+		Interval:  30 * time.Second,
+		Threshold: time.Hour,
+	}
+}
+
+func getDiskGuardDefaults() *DiskGuardConfig {
+	return &DiskGuardConfig{
+		Enabled:   true,
+		Interval:  time.Minute,
+		MinFreeMB: 256,
+		LogDir:    "",
+	}
+}
+
+func getSelfTestDefaults() *SelfTestConfig {
+	return &SelfTestConfig{
+		Enabled: false,
+		Headers: 2016,
+	}
+}
+
+func getIdempotencyDefaults() *IdempotencyConfig {
+	return &IdempotencyConfig{
+		Enabled:         true,
+		TTL:             24 * time.Hour,
+		CleanupInterval: time.Hour,
 	}
 }
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 )
 
 // NetworkType is a string that represents the network type.
@@ -16,6 +23,8 @@ const (
 	TestNet NetworkType = "testnet"
 	// SimulationNet represents the simulation network.
 	SimulationNet NetworkType = "simnet"
+	// CustomNet represents a private network with parameters defined by p2p.custom_chain.
+	CustomNet NetworkType = "custom"
 )
 
 // GetNetParams returns the network parameters for current network.
@@ -29,11 +38,68 @@ func (c *P2PConfig) GetNetParams() *chaincfg.Params {
 		return &chaincfg.TestNet3Params
 	case SimulationNet:
 		return &chaincfg.SimNetParams
+	case CustomNet:
+		params, err := c.CustomChain.NetParams()
+		if err != nil {
+			// Configuration is validated on startup, so it can only happen with a config which skipped it.
+			panic(fmt.Sprintf("invalid custom chain parameters: %v", err))
+		}
+		return params
 	default:
 		return &chaincfg.MainNetParams
 	}
 }
 
+// NetParams returns the network parameters of the custom chain.
+func (c *CustomChainConfig) NetParams() (*chaincfg.Params, error) {
+	if c == nil {
+		return nil, errors.New("custom_chain configuration cannot be empty")
+	}
+
+	raw, err := hex.DecodeString(c.GenesisHeader)
+	if err != nil || len(raw) != wire.MaxBlockHeaderPayload {
+		return nil, fmt.Errorf("genesis_header must be %d bytes of hex encoded header", wire.MaxBlockHeaderPayload)
+	}
+	var genesis wire.MsgBlock
+	if err := genesis.Header.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("cannot deserialize genesis_header: %w", err)
+	}
+	genesisHash := genesis.Header.BlockHash()
+
+	if c.Magic == 0 {
+		return nil, errors.New("magic cannot be empty")
+	}
+	if c.DefaultPort == "" {
+		return nil, errors.New("default_port cannot be empty")
+	}
+	if c.PowLimitBits == 0 || c.TargetTimePerBlock <= 0 || c.TargetTimespan < c.TargetTimePerBlock {
+		return nil, errors.New("pow_limit_bits and target_time_per_block must be positive and target_timespan cannot be shorter than target_time_per_block")
+	}
+
+	seeds := make([]chaincfg.DNSSeed, 0, len(c.Seeds))
+	for _, host := range c.Seeds {
+		seeds = append(seeds, chaincfg.DNSSeed{Host: host})
+	}
+
+	return &chaincfg.Params{
+		Name:                     string(CustomNet),
+		Net:                      wire.BitcoinNet(c.Magic),
+		DefaultPort:              c.DefaultPort,
+		DNSSeeds:                 seeds,
+		GenesisBlock:             &genesis,
+		GenesisHash:              &genesisHash,
+		PowLimit:                 domains.CompactToBig(c.PowLimitBits),
+		PowLimitBits:             c.PowLimitBits,
+		DaaForkHeight:            c.DaaForkHeight,
+		TargetTimespan:           c.TargetTimespan,
+		TargetTimePerBlock:       c.TargetTimePerBlock,
+		RetargetAdjustmentFactor: 4,
+		ReduceMinDifficulty:      c.ReduceMinDifficulty,
+		NoDifficultyAdjustment:   c.NoDifficultyAdjustment,
+		MinDiffReductionTime:     c.MinDiffReductionTime,
+	}, nil
+}
+
 // ActiveNetParams is a pointer to the parameters specific to the
 // currently active bitcoin network.
 // TODO: remove this after switching to new p2p server.
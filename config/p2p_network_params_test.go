@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+)
+
+const regtestGenesisHeader = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4adae5494dffff7f2002000000"
+
+func TestCustomChainNetParams(t *testing.T) {
+	// given
+	cfg := &P2PConfig{ChainNetType: CustomNet, CustomChain: getCustomChainDefaults()}
+	cfg.CustomChain.GenesisHeader = regtestGenesisHeader
+	cfg.CustomChain.Magic = 0xfabfb5da
+	cfg.CustomChain.Seeds = []string{"10.0.0.1"}
+
+	// when
+	params := cfg.GetNetParams()
+
+	// then
+	assert.Equal(t, *params.GenesisHash, *chaincfg.RegressionNetParams.GenesisHash)
+	assert.Equal(t, params.Net, wire.BitcoinNet(0xfabfb5da))
+	assert.Equal(t, params.PowLimit.Cmp(domains.CompactToBig(0x207fffff)), 0)
+	assert.Equal(t, params.DNSSeeds[0].Host, "10.0.0.1")
+}
+
+func TestCustomChainValidation(t *testing.T) {
+	testCases := map[string]struct {
+		experimental  bool
+		genesisHeader string
+		expectedError string
+	}{
+		"legacy p2p": {
+			genesisHeader: regtestGenesisHeader,
+			expectedError: "p2p: custom chain requires experimental or disabled p2p, replica or node_rpc",
+		},
+		"truncated genesis header": {
+			experimental:  true,
+			genesisHeader: regtestGenesisHeader[:100],
+			expectedError: "p2p: custom_chain: genesis_header must be 80 bytes of hex encoded header",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := &P2PConfig{ChainNetType: CustomNet, Experimental: tc.experimental, CustomChain: getCustomChainDefaults()}
+			cfg.CustomChain.GenesisHeader = tc.genesisHeader
+			cfg.CustomChain.Magic = 0xfabfb5da
+
+			// when
+			err := cfg.Validate(nil, nil)
+
+			// then
+			assert.IsError(t, err, tc.expectedError)
+		})
+	}
+}
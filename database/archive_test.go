@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+)
+
+func TestSQLiteArchiveBranches(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+	ctx := context.Background()
+
+	for height := 0; height <= 4; height++ {
+		insertHeader(t, adapter, height+1, height, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 10, 3, domains.Stale)
+	insertHeader(t, adapter, 11, 4, domains.Stale)
+	_, err := adapter.db.Exec(
+		`INSERT INTO notification_outbox(id, header_hash, operation, event, latest_only, claimed_until, created_at)
+		VALUES (1, ?, 'ADD', '{"operation":"ADD"}', false, 0, 0), (2, ?, 'ADD', '{}', false, 0, 0)`,
+		fmt.Sprintf("%064x", 11), fmt.Sprintf("%064x", 1),
+	)
+	assert.NoError(t, err)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	branch := &domains.ArchivedBranch{State: domains.Stale, Length: 2, ArchivedAt: time.UnixMilli(time.Now().UnixMilli())}
+	for _, id := range []int{11, 10} {
+		h, err := repo.GetHeaderByHash(ctx, fmt.Sprintf("%064x", id))
+		assert.NoError(t, err)
+		branch.Headers = append(branch.Headers, h.ToBlockHeader())
+	}
+	branch.TipHash = branch.Headers[0].Hash.String()
+	branch.TipHeight = branch.Headers[0].Height
+	branch.TipTimestamp = branch.Headers[0].Timestamp
+	dbBranch, err := dto.ToDbArchivedBranch(branch)
+	assert.NoError(t, err)
+
+	// when
+	archived, err := repo.ArchiveBranches(ctx, []*dto.DbArchivedBranch{dbBranch})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, archived, 2)
+	count, err := repo.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, count, 5)
+	pending, err := repo.ClaimOutboxEvents(ctx, time.Now(), time.Now(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, len(pending), 1)
+	assert.Equal(t, pending[0].HeaderHash, fmt.Sprintf("%064x", 1))
+
+	// when
+	listed, err := repo.GetArchivedBranches(ctx, 10)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(listed), 1)
+	assert.Equal(t, listed[0].TipHash.String(), fmt.Sprintf("%064x", 11))
+	assert.Equal(t, listed[0].Headers == nil, true)
+	assert.Equal(t, listed[0].Events == nil, true)
+
+	// when
+	found, err := repo.GetArchivedBranchOf(ctx, fmt.Sprintf("%064x", 10))
+
+	// then
+	assert.NoError(t, err)
+	restored, err := found.ToArchivedBranch()
+	assert.NoError(t, err)
+	assert.Equal(t, restored.TipHeight, int32(4))
+	assert.Equal(t, restored.ArchivedAt, branch.ArchivedAt)
+	assert.Equal(t, len(restored.Headers), 2)
+	assert.Equal(t, restored.Headers[1].Hash, branch.Headers[1].Hash)
+	assert.Equal(t, restored.Headers[1].State, domains.Stale)
+	assert.Equal(t, len(restored.Events), 1)
+	assert.Equal(t, restored.Events[0].HeaderHash, fmt.Sprintf("%064x", 11))
+	assert.Equal(t, string(restored.Events[0].Event), `{"operation":"ADD"}`)
+
+	// when
+	_, err = repo.GetArchivedBranchOf(ctx, fmt.Sprintf("%064x", 1))
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrArchivedHeaderNotFound), true)
+}
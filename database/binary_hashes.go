@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// Hash columns were created as hex strings and are converted to binary once, after migrations.
+// The conversion differs between the engines, so it can't be a part of the shared migration files.
+const (
+	sqlSQLiteHashColumnType = `SELECT type FROM pragma_table_info('headers') WHERE name = 'hash'`
+
+	sqlSQLiteHeadersIndexes = `SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = 'headers' AND sql IS NOT NULL`
+
+	// sqlSQLiteCreateHeadersTable creates a table of the headers schema with the given name.
+	sqlSQLiteCreateHeadersTable = `
+	CREATE TABLE %s(
+		hash BLOB PRIMARY KEY
+		,height INTEGER
+		,version INTEGER
+		,merkleroot BLOB
+		,nonce BIGINT
+		,bits VARCHAR(255)
+		,chainwork VARCHAR(255)
+		,previous_block BLOB
+		,timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		,cumulated_work VARCHAR(255)
+		,header_state VARCHAR(50) DEFAULT 'LONGEST_CHAIN'
+	)`
+
+	sqlSQLiteCopyToBinaryHeaders = `
+	INSERT INTO headers_binary(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work, header_state)
+	SELECT unhex(hash), height, version, unhex(merkleroot), nonce, bits, chainwork, unhex(previous_block), timestamp, cumulated_work, header_state
+	FROM headers`
+
+	sqlPostgresHashColumnType = `SELECT data_type FROM information_schema.columns WHERE table_name = $1 AND column_name = 'hash'`
+
+	sqlPostgresConvertHashes = `
+	ALTER TABLE headers
+		ALTER COLUMN hash TYPE BYTEA USING decode(hash, 'hex'),
+		ALTER COLUMN merkleroot TYPE BYTEA USING decode(merkleroot, 'hex'),
+		ALTER COLUMN previous_block TYPE BYTEA USING decode(previous_block, 'hex')`
+)
+
+func (a *sqLiteAdapter) convertHashesToBinary(log *zerolog.Logger) error {
+	var columnType string
+	if err := a.db.Get(&columnType, sqlSQLiteHashColumnType); err != nil {
+		return err
+	}
+	if columnType == "BLOB" {
+		return nil
+	}
+
+	log.Info().Msgf("Converting hashes in %s table to binary", sql.HeadersTableName)
+
+	// SQLite can't change the type of a column, so the table is rebuilt together with its indexes.
+	var indexes []string
+	if err := a.db.Select(&indexes, sqlSQLiteHeadersIndexes); err != nil {
+		return err
+	}
+
+	statements := []string{
+		fmt.Sprintf(sqlSQLiteCreateHeadersTable, "headers_binary"),
+		sqlSQLiteCopyToBinaryHeaders,
+		`DROP TABLE headers`,
+		`ALTER TABLE headers_binary RENAME TO headers`,
+	}
+	statements = append(statements, indexes...)
+
+	return execInTx(a.db, statements)
+}
+
+func (a *postgreSQLAdapter) convertHashesToBinary(log *zerolog.Logger) error {
+	var columnType string
+	if err := a.db.Get(&columnType, sqlPostgresHashColumnType, sql.HeadersTableName); err != nil {
+		return err
+	}
+	if columnType == "bytea" {
+		return nil
+	}
+
+	log.Info().Msgf("Converting hashes in %s table to binary", sql.HeadersTableName)
+
+	return execInTx(a.db, []string{sqlPostgresConvertHashes})
+}
+
+func execInTx(db *sqlx.DB, statements []string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, s := range statements {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("%s: %w", s, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestSQLiteConvertHashesToBinary(t *testing.T) {
+	// given
+	const hash = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	const merkleRoot = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	const previousBlock = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	cfg := &config.DbConfig{
+		SchemaPath: "migrations",
+		SQLite:     config.SQLiteConfig{FilePath: filepath.Join(t.TempDir(), "headers.db")},
+	}
+	adapter := &sqLiteAdapter{}
+	assert.NoError(t, adapter.connect(cfg))
+	defer adapter.db.Close()
+	assert.NoError(t, adapter.doMigrations(cfg))
+
+	_, err := adapter.db.Exec(
+		`INSERT INTO headers(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work)
+		VALUES (?, 0, 1, ?, 2083236893, 486604799, '4295032833', ?, '2009-01-03 18:15:05', '4295032833')`,
+		hash, merkleRoot, previousBlock,
+	)
+	assert.NoError(t, err)
+	log := zerolog.Nop()
+
+	// when
+	err = adapter.convertHashesToBinary(&log)
+
+	// then
+	assert.NoError(t, err)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+	h, err := repo.GetHeaderByHash(context.Background(), hash)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Hash.String(), hash)
+	assert.Equal(t, h.MerkleRoot.String(), merkleRoot)
+	assert.Equal(t, h.PreviousBlock.String(), previousBlock)
+
+	var indexes []string
+	assert.NoError(t, adapter.db.Select(&indexes, sqlSQLiteHeadersIndexes))
+	assert.Equal(t, len(indexes), 5)
+
+	// the conversion is done only once
+	assert.NoError(t, adapter.convertHashesToBinary(&log))
+}
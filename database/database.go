@@ -2,7 +2,6 @@ package database
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/jmoiron/sqlx"
@@ -16,7 +15,11 @@ import (
 type dbAdapter interface {
 	connect(cfg *config.DbConfig) error
 	doMigrations(cfg *config.DbConfig) error
-	importHeaders(inputFile *os.File, log *zerolog.Logger) (int, error)
+	convertHashesToBinary(log *zerolog.Logger) error
+	importHeaders(reader recordReader, log *zerolog.Logger) (int, error)
+	importHeadersInto(reader recordReader, table string) (int, error)
+	createShadowHeaders(table string) error
+	swapShadowHeaders(table string) error
 	getDBx() *sqlx.DB
 }
 
@@ -42,6 +45,10 @@ func Init(cfg *config.AppConfig, log *zerolog.Logger) (*sqlx.DB, error) {
 		return nil, err
 	}
 
+	if err := adapter.convertHashesToBinary(&dbLog); err != nil {
+		return nil, fmt.Errorf("failed to convert hashes to binary: %w", err)
+	}
+
 	if cfg.Db.PreparedDb {
 		if err := importHeaders(adapter, cfg, &dbLog); err != nil {
 			return nil, err
@@ -52,6 +59,13 @@ func Init(cfg *config.AppConfig, log *zerolog.Logger) (*sqlx.DB, error) {
 		}
 	}
 
+	// Partitioning goes after the import, so the imported headers are spread over partitions at once.
+	if pg, ok := adapter.(*postgreSQLAdapter); ok && cfg.Db.Postgres.PartitionSize > 0 {
+		if err := pg.partitionHeaders(cfg.Db, &dbLog); err != nil {
+			return nil, err
+		}
+	}
+
 	return adapter.getDBx(), nil
 }
 
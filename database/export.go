@@ -15,7 +15,7 @@ const (
 	selectHeadersSQL = `
 	SELECT
 		version,
-		merkleroot,
+		lower(hex(merkleroot)) as merkleroot,
 		nonce,
 		bits,
 		strftime('%s', timestamp) as timestamp
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// The headers dump is the raw binary format used by other clients to seed their header chains:
+// 80 bytes serialized headers of the longest chain, one after another starting from genesis,
+// so the height of a header is its offset divided by 80.
+
+const (
+	selectLongestChainSQL = `
+	SELECT height, version, merkleroot, nonce, bits, timestamp, previous_block
+	FROM headers
+	WHERE header_state = 'LONGEST_CHAIN'
+	ORDER BY height asc
+	`
+)
+
+// ExportBinaryHeaders exports headers of the longest chain from the database to a raw binary headers dump.
+func ExportBinaryHeaders(cfg *config.AppConfig, path string, log *zerolog.Logger) error {
+	log.Info().Msgf("Exporting headers from database to binary file %s", path)
+
+	adapter, err := newDbAdapter(cfg.Db)
+	if err != nil {
+		return err
+	}
+
+	if err = adapter.connect(cfg.Db); err != nil {
+		return err
+	}
+
+	db := adapter.getDBx()
+	defer func() {
+		_ = db.Close()
+	}()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	count, err := writeHeadersDump(db, file)
+	if cErr := file.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Exported %d headers to %s", count, path)
+	return nil
+}
+
+func writeHeadersDump(db *sqlx.DB, w io.Writer) (int, error) {
+	rows, err := db.QueryxContext(context.Background(), selectLongestChainSQL)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	buf := bufio.NewWriter(w)
+	count := 0
+	for rows.Next() {
+		var row dto.DbBlockHeader
+		if err := rows.StructScan(&row); err != nil {
+			return count, err
+		}
+		// A gap would shift every following header to the height of its predecessor.
+		if int(row.Height) != count {
+			return count, fmt.Errorf("longest chain has no header at height %d", count)
+		}
+
+		h := row.ToBlockHeader()
+		header := wire.BlockHeader{
+			Version:    h.Version,
+			PrevBlock:  h.PreviousBlock,
+			MerkleRoot: h.MerkleRoot,
+			Timestamp:  h.Timestamp,
+			Bits:       h.Bits,
+			Nonce:      h.Nonce,
+		}
+		if err := header.Serialize(buf); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, buf.Flush()
+}
+
+// isGzipFile checks if the file starts with the gzip magic number, the file is read from the beginning afterwards.
+func isGzipFile(f *os.File) (bool, error) {
+	magic := make([]byte, 2)
+	n, err := f.ReadAt(magic, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return n == 2 && magic[0] == 0x1f && magic[1] == 0x8b, nil
+}
+
+// binaryRecordReader reads a headers dump as records of the prepared database CSV file,
+// checking every header connects to the previous one.
+type binaryRecordReader struct {
+	r      *bufio.Reader
+	buf    [wire.MaxBlockHeaderPayload]byte
+	prev   chainhash.Hash
+	height int
+}
+
+func newBinaryRecordReader(r io.Reader) *binaryRecordReader {
+	return &binaryRecordReader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next header as version, merkleroot, nonce, bits and timestamp.
+func (b *binaryRecordReader) Read() ([]string, error) {
+	if _, err := io.ReadFull(b.r, b.buf[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("cannot read header at height %d: %w", b.height, err)
+	}
+
+	var h wire.BlockHeader
+	if err := h.Deserialize(bytes.NewReader(b.buf[:])); err != nil {
+		return nil, fmt.Errorf("cannot read header at height %d: %w", b.height, err)
+	}
+	if h.PrevBlock != b.prev {
+		return nil, fmt.Errorf("header at height %d does not connect to the previous header", b.height)
+	}
+
+	b.prev = h.BlockHash()
+	b.height++
+
+	return []string{
+		strconv.FormatInt(int64(h.Version), 10),
+		h.MerkleRoot.String(),
+		strconv.FormatUint(uint64(h.Nonce), 10),
+		strconv.FormatUint(uint64(h.Bits), 10),
+		strconv.FormatInt(h.Timestamp.Unix(), 10),
+	}, nil
+}
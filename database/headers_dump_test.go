@@ -0,0 +1,90 @@
+package database
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/rs/zerolog"
+)
+
+func TestHeadersDumpRoundTrip(t *testing.T) {
+	// given
+	dump := headersDumpOf(t, 3)
+
+	cfg := &config.DbConfig{
+		SchemaPath: "migrations",
+		SQLite:     config.SQLiteConfig{FilePath: filepath.Join(t.TempDir(), "headers.db")},
+	}
+	adapter := &sqLiteAdapter{}
+	assert.NoError(t, adapter.connect(cfg))
+	defer adapter.db.Close()
+	assert.NoError(t, adapter.doMigrations(cfg))
+	log := zerolog.Nop()
+
+	imported, err := adapter.importHeaders(newBinaryRecordReader(bytes.NewReader(dump)), &log)
+	assert.NoError(t, err)
+	assert.Equal(t, imported, 3)
+
+	// when
+	var exported bytes.Buffer
+	count, err := writeHeadersDump(adapter.db, &exported)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, 3)
+	assert.EqualBytes(t, exported.Bytes(), dump)
+}
+
+func TestBinaryRecordReaderRejectsDisconnectedHeader(t *testing.T) {
+	// given
+	dump := headersDumpOf(t, 3)
+	// drop the header on height 1
+	dump = append(dump[:wire.MaxBlockHeaderPayload], dump[2*wire.MaxBlockHeaderPayload:]...)
+	reader := newBinaryRecordReader(bytes.NewReader(dump))
+
+	// when
+	_, err := reader.Read()
+	assert.NoError(t, err)
+	_, err = reader.Read()
+
+	// then
+	assert.IsError(t, err, "header at height 1 does not connect to the previous header")
+}
+
+func TestBinaryRecordReaderRejectsTruncatedHeader(t *testing.T) {
+	// given
+	dump := headersDumpOf(t, 1)
+	reader := newBinaryRecordReader(bytes.NewReader(dump[:40]))
+
+	// when
+	_, err := reader.Read()
+
+	// then
+	assert.IsError(t, err, "cannot read header at height 0: unexpected EOF")
+}
+
+// headersDumpOf returns a dump of mainnet genesis followed by n-1 headers, each connected to the previous one.
+func headersDumpOf(t *testing.T, n int) []byte {
+	t.Helper()
+
+	var dump bytes.Buffer
+	h := chaincfg.MainNetParams.GenesisBlock.Header
+	for i := 0; i < n; i++ {
+		assert.NoError(t, h.Serialize(&dump))
+		h = wire.BlockHeader{
+			Version:    h.Version,
+			PrevBlock:  h.BlockHash(),
+			MerkleRoot: h.MerkleRoot,
+			Timestamp:  h.Timestamp.Add(10 * time.Minute),
+			Bits:       h.Bits,
+			Nonce:      uint32(i),
+		}
+	}
+	return dump.Bytes()
+}
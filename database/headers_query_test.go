@@ -0,0 +1,328 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+)
+
+func TestSQLiteHeadersByHeightRangeQuery(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+
+	headers := []struct {
+		height        int
+		state         domains.HeaderState
+		timestamp     string
+		cumulatedWork string
+	}{
+		{height: 1, state: domains.LongestChain, timestamp: "2009-01-09 02:54:25", cumulatedWork: "9"},
+		{height: 2, state: domains.LongestChain, timestamp: "2009-01-09 02:55:44", cumulatedWork: "100"},
+		{height: 2, state: domains.Stale, timestamp: "2009-01-09 02:54:00", cumulatedWork: "10"},
+	}
+	for i, h := range headers {
+		_, err := adapter.db.Exec(
+			`INSERT INTO headers(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work)
+			VALUES (unhex(?), ?, 1, unhex(?), 0, 486604799, '1', unhex(?), ?, ?, ?)`,
+			fmt.Sprintf("%064x", i+1), h.height, fmt.Sprintf("%064x", 0), fmt.Sprintf("%064x", 0), h.timestamp, string(h.state), h.cumulatedWork,
+		)
+		assert.NoError(t, err)
+	}
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	testCases := map[string]struct {
+		query         domains.HeadersQuery
+		cumulatedWork []string
+	}{
+		"chain work descending": {
+			query:         domains.HeadersQuery{SortBy: domains.SortByChainWork, Descending: true},
+			cumulatedWork: []string{"100", "10", "9"},
+		},
+		"timestamp ascending": {
+			query:         domains.HeadersQuery{SortBy: domains.SortByTimestamp},
+			cumulatedWork: []string{"10", "9", "100"},
+		},
+		"longest chain by height descending": {
+			query:         domains.HeadersQuery{States: []domains.HeaderState{domains.LongestChain}, SortBy: domains.SortByHeight, Descending: true},
+			cumulatedWork: []string{"100", "9"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result, err := repo.GetHeaderByHeightRange(0, 10, tc.query)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, len(result), len(tc.cumulatedWork))
+			for i, h := range result {
+				assert.Equal(t, h.CumulatedWork, tc.cumulatedWork[i])
+			}
+		})
+	}
+}
+
+func TestSQLiteStreamHeadersByHeightRangeInChunks(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+	ctx := context.Background()
+
+	for id := 1; id <= 5; id++ {
+		insertHeader(t, adapter, id, id/2, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 6, 1, domains.Stale)
+	repo := sql.NewHeadersDb(adapter.db, &log, sql.WithStreamChunk(2))
+
+	testCases := map[string]struct {
+		query  domains.HeadersQuery
+		hashes []int
+	}{
+		"order of height": {
+			query:  domains.HeadersQuery{},
+			hashes: []int{1, 2, 3, 6, 4, 5},
+		},
+		"height descending": {
+			query:  domains.HeadersQuery{SortBy: domains.SortByHeight, Descending: true},
+			hashes: []int{5, 4, 6, 3, 2, 1},
+		},
+		"chain work descending": {
+			query:  domains.HeadersQuery{SortBy: domains.SortByChainWork, Descending: true},
+			hashes: []int{6, 5, 4, 3, 2, 1},
+		},
+		"stale only": {
+			query:  domains.HeadersQuery{States: []domains.HeaderState{domains.Stale}},
+			hashes: []int{6},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			var hashes []string
+			err := repo.StreamHeaderByHeightRange(ctx, 0, 10, tc.query, func(bh *dto.DbBlockHeader) error {
+				hashes = append(hashes, bh.Hash.String())
+				return nil
+			})
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, len(hashes), len(tc.hashes))
+			for i, id := range tc.hashes {
+				assert.Equal(t, hashes[i], fmt.Sprintf("%064x", id))
+			}
+		})
+	}
+}
+
+func TestSQLiteSampledHeadersByHeightRange(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+
+	for height := 0; height <= 6; height++ {
+		insertHeader(t, adapter, height+1, height, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 10, 3, domains.Stale)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	result, err := repo.GetSampledHeadersByHeightRange(1, 6, 2)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(result), 3)
+	for i, h := range result {
+		assert.Equal(t, h.Height, int32(1+2*i))
+		assert.Equal(t, h.State, string(domains.LongestChain))
+	}
+}
+
+func TestSQLiteCountByState(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+
+	for height := 0; height <= 6; height++ {
+		insertHeader(t, adapter, height+1, height, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 10, 3, domains.Stale)
+	insertHeader(t, adapter, 11, 5, domains.Stale)
+	insertHeader(t, adapter, 12, 6, domains.Orphan)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	counts, err := repo.CountByState(4)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(counts), 3)
+	assert.Equal(t, counts[domains.LongestChain], 3)
+	assert.Equal(t, counts[domains.Stale], 1)
+	assert.Equal(t, counts[domains.Orphan], 1)
+}
+
+func TestSQLiteHeadersByHashesAndHeights(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+
+	for height := 0; height <= 4; height++ {
+		insertHeader(t, adapter, height+1, height, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 10, 3, domains.Stale)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	byHash, err := repo.GetHeadersByHashes([]string{fmt.Sprintf("%064x", 2), fmt.Sprintf("%064x", 10), fmt.Sprintf("%064x", 99)})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(byHash), 2)
+
+	// when
+	byHeight, err := repo.GetLongestChainHeadersByHeights([]int32{3, 4, 7})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(byHeight), 2)
+	for _, h := range byHeight {
+		assert.Equal(t, h.State, string(domains.LongestChain))
+	}
+}
+
+func TestSQLiteDeleteHeaders(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+
+	for height := 0; height <= 4; height++ {
+		insertHeader(t, adapter, height+1, height, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 10, 3, domains.Stale)
+	insertHeader(t, adapter, 11, 4, domains.Stale)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	deleted, err := repo.DeleteHeaders(context.Background(), []string{fmt.Sprintf("%064x", 10), fmt.Sprintf("%064x", 11), fmt.Sprintf("%064x", 99)})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 2)
+	count, err := repo.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, count, 5)
+}
+
+func TestSQLiteDeleteHeadersAboveHeight(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+
+	for height := 0; height <= 4; height++ {
+		insertHeader(t, adapter, height+1, height, domains.LongestChain)
+	}
+	insertHeader(t, adapter, 10, 2, domains.Stale)
+	insertHeader(t, adapter, 11, 3, domains.Orphan)
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	deleted, err := repo.DeleteHeadersAboveHeight(context.Background(), 2)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 3)
+	height, err := repo.Height(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, height, 2)
+}
+
+// migratedSQLite connects to a new SQLite database with all migrations applied, closed with the end of the test.
+func migratedSQLite(t *testing.T) *sqLiteAdapter {
+	cfg := &config.DbConfig{
+		SchemaPath: "migrations",
+		SQLite:     config.SQLiteConfig{FilePath: filepath.Join(t.TempDir(), "headers.db")},
+	}
+	adapter := &sqLiteAdapter{}
+	assert.NoError(t, adapter.connect(cfg))
+	t.Cleanup(func() { _ = adapter.db.Close() })
+	assert.NoError(t, adapter.doMigrations(cfg))
+
+	log := zerolog.Nop()
+	assert.NoError(t, adapter.convertHashesToBinary(&log))
+	return adapter
+}
+
+func insertHeader(t *testing.T, adapter *sqLiteAdapter, id int, height int, state domains.HeaderState) {
+	_, err := adapter.db.Exec(
+		`INSERT INTO headers(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work)
+		VALUES (unhex(?), ?, 1, unhex(?), 0, 486604799, '1', unhex(?), '2009-01-09 02:54:25', ?, '1')`,
+		fmt.Sprintf("%064x", id), height, fmt.Sprintf("%064x", 0), fmt.Sprintf("%064x", 0), string(state),
+	)
+	assert.NoError(t, err)
+}
+
+func TestSQLiteChainWalksFollowParentHeights(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	for id := 1; id <= 5; id++ {
+		insertChildHeader(t, adapter, id, id-1, id-1, domains.LongestChain)
+	}
+	insertChildHeader(t, adapter, 6, 3, 3, domains.Stale)
+	insertChildHeader(t, adapter, 7, 6, 4, domains.Stale)
+
+	log := zerolog.Nop()
+	repo := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	ancestor, err := repo.GetAncestorOnHeight(hashOf(7), 1)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, ancestor.Hash.String(), hashOf(2))
+
+	// when
+	previous, err := repo.GetPreviousHeader(context.Background(), hashOf(6))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, previous.Hash.String(), hashOf(3))
+
+	// when
+	chain, err := repo.GetChainBetweenTwoHashes(hashOf(2), hashOf(7))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(chain), 4)
+
+	// when
+	stale, err := repo.GetStaleHeadersBackFrom(hashOf(7))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(stale), 2)
+}
+
+// insertChildHeader inserts a header with the hash of id, following the header with the hash of parentID.
+func insertChildHeader(t *testing.T, adapter *sqLiteAdapter, id int, parentID int, height int, state domains.HeaderState) {
+	_, err := adapter.db.Exec(
+		`INSERT INTO headers(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work)
+		VALUES (unhex(?), ?, 1, unhex(?), 0, 486604799, '1', unhex(?), '2009-01-09 02:54:25', ?, '1')`,
+		hashOf(id), height, fmt.Sprintf("%064x", 0), hashOf(parentID), string(state),
+	)
+	assert.NoError(t, err)
+}
+
+func hashOf(id int) string {
+	return fmt.Sprintf("%064x", id)
+}
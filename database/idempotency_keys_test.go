@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+)
+
+func TestSQLiteIdempotencyKeys(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+	repo := sql.NewHeadersDb(adapter.db, &log)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+	key := &dto.DbIdempotencyKey{Key: "key", RequestHash: "request", CreatedAt: now.UnixMilli(), ExpiresAt: now.Add(time.Hour).UnixMilli()}
+
+	// when
+	claimed, err := repo.ClaimIdempotencyKey(ctx, key, now)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CompleteIdempotencyKey(ctx, &dto.DbIdempotencyKey{Key: "key", StatusCode: 200, ContentType: "application/json", Body: `{"url":"http://first"}`}))
+	stored, err := repo.ClaimIdempotencyKey(ctx, key, now)
+	assert.NoError(t, err)
+
+	// then
+	assert.Equal(t, claimed == nil, true)
+	assert.Equal(t, stored.RequestHash, "request")
+	assert.Equal(t, stored.StatusCode, 200)
+	assert.Equal(t, stored.ContentType, "application/json")
+	assert.Equal(t, stored.Body, `{"url":"http://first"}`)
+
+	// when
+	expired, err := repo.ClaimIdempotencyKey(ctx, key, now.Add(time.Hour))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expired == nil, true)
+
+	// when
+	deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, now.Add(time.Hour))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 1)
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"math/big"
@@ -13,6 +14,7 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/repository/dto"
 	"github.com/bitcoin-sv/block-headers-service/service"
@@ -35,28 +37,83 @@ func importHeaders(db dbAdapter, cfg *config.AppConfig, log *zerolog.Logger) err
 		return nil
 	}
 
-	tmpHeadersFile, tmpHeadersFilePath, err := getHeadersFile(cfg.Db.PreparedDbFilePath, log)
+	reader, closeReader, err := openHeadersFile(cfg.Db.PreparedDbFilePath, log)
 	if err != nil {
 		return err
 	}
-	defer dropHeadersFile(tmpHeadersFile, tmpHeadersFilePath, log)
+	defer closeReader()
 
 	log.Info().Msg("Inserting headers from file to the database")
 
-	importCount, err := db.importHeaders(tmpHeadersFile, log)
+	importCount, err := db.importHeaders(reader, log)
 	if err != nil {
 		return err
 	}
 
 	log.Info().Msgf("Inserted total of %d rows", importCount)
 
-	if err := validateDbConsistency(importCount, hRepository, db.getDBx()); err != nil {
+	newestCheckpoint := config.Checkpoints[len(config.Checkpoints)-1]
+	if err := validateDbConsistency(importCount, db.getDBx(), sql.HeadersTableName, &newestCheckpoint); err != nil {
+		return err
+	}
+
+	// Statistics of the freshly filled table are stale, which leads the planner to full scans.
+	if err := hRepository.Maintain(context.Background()); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// recordReader reads headers from the prepared database file as records of its CSV columns.
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// openHeadersFile opens the prepared database file, which is either a gzipped CSV file or a raw binary headers dump.
+func openHeadersFile(preparedDbFilePath string, log *zerolog.Logger) (recordReader, func(), error) {
+	if !fileExistsAndIsReadable(preparedDbFilePath) {
+		return nil, nil, fmt.Errorf("file %s does not exist or is not readable", preparedDbFilePath)
+	}
+
+	file, err := os.Open(filepath.Clean(preparedDbFilePath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gzipped, err := isGzipFile(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	if !gzipped {
+		log.Info().Msgf("Reading binary headers dump %s", preparedDbFilePath)
+		return newBinaryRecordReader(file), func() { _ = file.Close() }, nil
+	}
+	_ = file.Close()
+
+	tmpHeadersFile, tmpHeadersFilePath, err := getHeadersFile(preparedDbFilePath, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeFile := func() { dropHeadersFile(tmpHeadersFile, tmpHeadersFilePath, log) }
+
+	// Read from the beginning of the file
+	if _, err = tmpHeadersFile.Seek(0, 0); err != nil {
+		closeFile()
+		return nil, nil, err
+	}
+
+	reader := csv.NewReader(tmpHeadersFile)
+	if _, err = reader.Read(); err != nil { // Skipping the column headers line
+		closeFile()
+		return nil, nil, err
+	}
+
+	return reader, closeFile, nil
+}
+
 func getHeadersFile(preparedDbFilePath string, log *zerolog.Logger) (*os.File, string, error) {
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -166,16 +223,16 @@ func calculateFields(dbBlock *domains.BlockHeaderSource, cumulatedChainWork stri
 
 	dbBlockHeader := dto.DbBlockHeader{
 		Height:        int32(rowIndex),
-		Hash:          blockhash.String(),
+		Hash:          dto.NewDbHash(chainhash.Hash(blockhash)),
 		Version:       dbBlock.Version,
-		MerkleRoot:    dbBlock.MerkleRoot.String(),
+		MerkleRoot:    dto.NewDbHash(dbBlock.MerkleRoot),
 		Timestamp:     dbBlock.Timestamp,
 		Bits:          dbBlock.Bits,
 		Nonce:         dbBlock.Nonce,
 		State:         "LONGEST_CHAIN",
 		Chainwork:     chainWork.String(),
 		CumulatedWork: cumulatedChainWorkBigInt.String(),
-		PreviousBlock: dbBlock.PrevBlock.String(),
+		PreviousBlock: dto.NewDbHash(dbBlock.PrevBlock),
 	}
 	return &dbBlockHeader
 }
@@ -191,31 +248,45 @@ func parseBigInt(s string) *big.Int {
 	return bi
 }
 
-func validateDbConsistency(importCount int, repo *sql.HeadersDb, db *sqlx.DB) error {
-	ctx := context.Background()
-
-	if dbHeadersCount, _ := repo.Count(ctx); dbHeadersCount != importCount {
+// validateDbConsistency checks the headers imported into the table, the checkpoint block is skipped when it's nil.
+func validateDbConsistency(importCount int, db *sqlx.DB, table string, checkpoint *chaincfg.Checkpoint) error {
+	if dbHeadersCount, _ := countHeaders(db, table); dbHeadersCount != importCount {
 		return fmt.Errorf("database is not consistent with csv file, imported %d headers, number of headers in database %d", importCount, dbHeadersCount)
 	}
 
-	if maxHeight, _ := repo.Height(ctx); maxHeight != importCount-1 {
+	if maxHeight, _ := maxHeaderHeight(db, table); maxHeight != importCount-1 {
 		return fmt.Errorf("database is not consistent with csv file, current maximum header height (%d) is different from imported headers number -1 (%d)", maxHeight, importCount)
 	}
 
-	if err := validateHeightUniqueness(db); err != nil {
+	if err := validateHeightUniqueness(db, table); err != nil {
 		return fmt.Errorf("database is not consistent with csv file, %w", err)
 	}
 
-	if err := validateNewestCheckpointBlock(db); err != nil {
+	if checkpoint == nil {
+		return nil
+	}
+	if err := validateCheckpointBlock(db, table, checkpoint); err != nil {
 		return fmt.Errorf("database is not consistent with csv file, %w", err)
 	}
 
 	return nil
 }
 
-func validateHeightUniqueness(db *sqlx.DB) error {
+func countHeaders(db *sqlx.DB, table string) (int, error) {
+	var count int
+	err := db.Get(&count, fmt.Sprintf("SELECT COUNT(1) FROM %s", table))
+	return count, err
+}
+
+func maxHeaderHeight(db *sqlx.DB, table string) (int, error) {
+	var height int
+	err := db.Get(&height, fmt.Sprintf("SELECT COALESCE(MAX(height), 0) FROM %s", table))
+	return height, err
+}
+
+func validateHeightUniqueness(db *sqlx.DB, table string) error {
 	tmpIndex := "tmp_height_unique"
-	_, err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON headers (height)", tmpIndex))
+	_, err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (height)", tmpIndex, table))
 	if err != nil {
 		return errors.New("height values are not unique(they should be just after import)")
 	}
@@ -227,16 +298,15 @@ func validateHeightUniqueness(db *sqlx.DB) error {
 	return nil
 }
 
-func validateNewestCheckpointBlock(db *sqlx.DB) error {
-	newestCheckpointBlock := config.Checkpoints[len(config.Checkpoints)-1]
-	newestCheckpointBlockQuery := fmt.Sprintf("SELECT hash FROM %s WHERE height = %d", sql.HeadersTableName, newestCheckpointBlock.Height)
-	var hashResult string
-	err := db.Get(&hashResult, newestCheckpointBlockQuery)
+func validateCheckpointBlock(db *sqlx.DB, table string, checkpoint *chaincfg.Checkpoint) error {
+	checkpointBlockQuery := fmt.Sprintf("SELECT hash FROM %s WHERE height = %d", table, checkpoint.Height)
+	var hashResult dto.DbHash
+	err := db.Get(&hashResult, checkpointBlockQuery)
 	if err != nil {
-		return fmt.Errorf("newest checkpoint block with height \"%d\" is not present in the database", newestCheckpointBlock.Height)
+		return fmt.Errorf("checkpoint block with height \"%d\" is not present in the database", checkpoint.Height)
 	}
-	if newestCheckpointBlock.Hash.String() != hashResult {
-		return fmt.Errorf("newest checkpoint block has different hash \"%s\" than hash \"%s\" of block in database with the same height (%d)", newestCheckpointBlock.Hash.String(), hashResult, newestCheckpointBlock.Height)
+	if checkpoint.Hash.String() != hashResult.String() {
+		return fmt.Errorf("checkpoint block has different hash \"%s\" than hash \"%s\" of block in database with the same height (%d)", checkpoint.Hash.String(), hashResult, checkpoint.Height)
 	}
 	return nil
 }
@@ -43,16 +43,16 @@ func TestPrepareRecordHappyPath(t *testing.T) {
 			},
 			expectedBlock: &dto.DbBlockHeader{
 				Height:        0,
-				Hash:          "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+				Hash:          dto.ParseDbHash("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"),
 				Version:       1,
-				MerkleRoot:    "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+				MerkleRoot:    dto.ParseDbHash("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"),
 				Timestamp:     timestampInLocalTime("2009-01-03 19:15:05+01:00"),
 				Bits:          486604799,
 				Nonce:         2083236893,
 				State:         "LONGEST_CHAIN",
 				Chainwork:     "4295032833",
 				CumulatedWork: "4295032833",
-				PreviousBlock: "0000000000000000000000000000000000000000000000000000000000000000",
+				PreviousBlock: dto.ParseDbHash("0000000000000000000000000000000000000000000000000000000000000000"),
 			},
 			expectedErrorMessage: "",
 		},
@@ -80,16 +80,16 @@ func TestPrepareRecordHappyPath(t *testing.T) {
 			},
 			expectedBlock: &dto.DbBlockHeader{
 				Height:        556770,
-				Hash:          "00000000000000000005569f09a80c66c8ebf514fdd1c03e803799c2420a4f5a",
+				Hash:          dto.ParseDbHash("00000000000000000005569f09a80c66c8ebf514fdd1c03e803799c2420a4f5a"),
 				Version:       536870912,
-				MerkleRoot:    "17e0aefc0154e0a3cdc4a837c66d9c0e0f0e4a44a703fd6e654e8cbc62c0b28f",
+				MerkleRoot:    dto.ParseDbHash("17e0aefc0154e0a3cdc4a837c66d9c0e0f0e4a44a703fd6e654e8cbc62c0b28f"),
 				Timestamp:     timestampInLocalTime("2018-11-15 19:44:57+01:00"),
 				Bits:          402796026,
 				Nonce:         4081063765,
 				State:         "LONGEST_CHAIN",
 				Chainwork:     "2166624730970898396303",
 				CumulatedWork: "255349410425588691745638430",
-				PreviousBlock: "0000000000000000005013e7cc2889ada8b01f24dfc325d1398be82197fc623b",
+				PreviousBlock: dto.ParseDbHash("0000000000000000005013e7cc2889ada8b01f24dfc325d1398be82197fc623b"),
 			},
 			expectedErrorMessage: "",
 		},
@@ -107,16 +107,16 @@ func TestPrepareRecordHappyPath(t *testing.T) {
 			},
 			expectedBlock: &dto.DbBlockHeader{
 				Height:        833233,
-				Hash:          "00000000000000000676a9b9cdb44820a04c780ca152737124e36341b6c4cdd2",
+				Hash:          dto.ParseDbHash("00000000000000000676a9b9cdb44820a04c780ca152737124e36341b6c4cdd2"),
 				Version:       536870912,
-				MerkleRoot:    "e9446d4ebeb301aeb5a2f375ac062bf3581269d783362cf066f08bbe6040a885",
+				MerkleRoot:    dto.ParseDbHash("e9446d4ebeb301aeb5a2f375ac062bf3581269d783362cf066f08bbe6040a885"),
 				Timestamp:     timestampInLocalTime("2024-02-26 14:33:43+01:00"),
 				Bits:          403300437,
 				Nonce:         3035389718,
 				State:         "LONGEST_CHAIN",
 				Chainwork:     "478151526252246136711",
 				CumulatedWork: "409554917150373038158892043",
-				PreviousBlock: "0000000000000000031817e0b646350cac1b8770d6cba60717e86185cadb15cc",
+				PreviousBlock: dto.ParseDbHash("0000000000000000031817e0b646350cac1b8770d6cba60717e86185cadb15cc"),
 			},
 			expectedErrorMessage: "",
 		},
@@ -132,11 +132,11 @@ func TestPrepareRecordHappyPath(t *testing.T) {
 					t.Errorf("Error while preparing record: %v", err)
 				}
 				result = append(result, *block)
-				tc.data.previousBlockHash = block.Hash
+				tc.data.previousBlockHash = block.Hash.String()
 				tc.data.cumulatedChainWork = block.CumulatedWork
 				tc.data.rowIndex++
 			}
-			assert.Equal[dto.DbBlockHeader](t, result[tc.data.numberOfBlocks-1], *tc.expectedBlock)
+			assert.Equal(t, &result[tc.data.numberOfBlocks-1], tc.expectedBlock)
 			assert.NoError(t, err)
 		})
 	}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+func TestMaintainSwitchesSQLiteToIncrementalVacuum(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "headers.db")
+	legacy, err := sqlx.Open(sqliteDriverName, "file:"+path)
+	assert.NoError(t, err)
+	_, err = legacy.Exec(`CREATE TABLE legacy (id INTEGER PRIMARY KEY)`)
+	assert.NoError(t, err)
+	assert.NoError(t, legacy.Close())
+
+	adapter := &sqLiteAdapter{}
+	assert.NoError(t, adapter.connect(&config.DbConfig{SQLite: config.SQLiteConfig{FilePath: path}}))
+	t.Cleanup(func() { _ = adapter.db.Close() })
+	assert.Equal(t, autoVacuumOf(t, adapter.db), 0)
+
+	log := zerolog.Nop()
+	headers := sql.NewHeadersDb(adapter.db, &log)
+
+	// when
+	err = headers.Maintain(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, autoVacuumOf(t, adapter.db), 2)
+
+	// when
+	err = headers.Maintain(context.Background())
+
+	// then
+	assert.NoError(t, err)
+}
+
+func autoVacuumOf(t *testing.T, db *sqlx.DB) int {
+	t.Helper()
+	var mode int
+	assert.NoError(t, db.Get(&mode, `PRAGMA auto_vacuum`))
+	return mode
+}
@@ -0,0 +1,2 @@
+CREATE INDEX idx_timestamp ON headers (timestamp);
+CREATE INDEX idx_cumulated_work ON headers (length(cumulated_work), cumulated_work);
@@ -0,0 +1,9 @@
+CREATE TABLE webhook_deliveries(
+    id               VARCHAR(32) PRIMARY KEY
+    ,url             VARCHAR(255) NOT NULL
+    ,event           TEXT NOT NULL
+    ,attempts        INTEGER NOT NULL DEFAULT 0
+    ,next_attempt_at BIGINT NOT NULL
+    ,created_at      BIGINT NOT NULL
+);
+CREATE INDEX idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
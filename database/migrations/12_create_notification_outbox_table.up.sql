@@ -0,0 +1,10 @@
+CREATE TABLE notification_outbox(
+    id             BIGINT PRIMARY KEY
+    ,header_hash   VARCHAR(64) NOT NULL
+    ,operation     VARCHAR(32) NOT NULL
+    ,event         TEXT NOT NULL
+    ,latest_only   BOOLEAN NOT NULL DEFAULT FALSE
+    ,claimed_until BIGINT NOT NULL DEFAULT 0
+    ,created_at    BIGINT NOT NULL
+);
+CREATE INDEX idx_notification_outbox_claimed_until ON notification_outbox (claimed_until);
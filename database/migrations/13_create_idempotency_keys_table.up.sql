@@ -0,0 +1,10 @@
+CREATE TABLE idempotency_keys(
+    idempotency_key VARCHAR(255) PRIMARY KEY
+    ,request_hash   VARCHAR(64) NOT NULL
+    ,status_code    INTEGER NOT NULL DEFAULT 0
+    ,content_type   VARCHAR(255) NOT NULL DEFAULT ''
+    ,body           TEXT NOT NULL DEFAULT ''
+    ,created_at     BIGINT NOT NULL
+    ,expires_at     BIGINT NOT NULL
+);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,17 @@
+-- BYTEA is binary in PostgreSQL, SQLite stores the blobs written to it as they are.
+CREATE TABLE archived_branches(
+    tip_hash       BYTEA PRIMARY KEY
+    ,tip_height    INTEGER NOT NULL
+    ,length        INTEGER NOT NULL
+    ,header_state  VARCHAR(50) NOT NULL
+    ,tip_timestamp BIGINT NOT NULL
+    ,archived_at   BIGINT NOT NULL
+    ,headers       BYTEA NOT NULL
+    ,events        BYTEA
+);
+CREATE INDEX idx_archived_branches_tip_height ON archived_branches (tip_height);
+
+CREATE TABLE archived_headers(
+    hash      BYTEA PRIMARY KEY
+    ,tip_hash BYTEA NOT NULL
+);
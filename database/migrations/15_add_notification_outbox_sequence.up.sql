@@ -0,0 +1,4 @@
+CREATE TABLE notification_outbox_sequence(
+    last_id BIGINT NOT NULL
+);
+INSERT INTO notification_outbox_sequence(last_id) SELECT COALESCE(MAX(id), 0) FROM notification_outbox;
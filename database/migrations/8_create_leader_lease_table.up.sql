@@ -0,0 +1,5 @@
+CREATE TABLE leader_lease(
+    name        VARCHAR(64) PRIMARY KEY
+    ,holder     VARCHAR(255) NOT NULL
+    ,expires_at BIGINT NOT NULL
+);
@@ -0,0 +1,3 @@
+CREATE INDEX idx_merkleroot_height_state_hash ON headers (merkleroot, height, header_state, hash);
+DROP INDEX idx_merkle_root_hash;
+CREATE INDEX idx_state_height ON headers (header_state, height);
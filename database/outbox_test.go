@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+)
+
+func TestSQLiteOutboxEventsStoredWithHeaders(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+	repo := sql.NewHeadersDb(adapter.db, &log)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+	chain, _ := fixtures.LongestChain()
+	header := dto.ToDbBlockHeader(chain[1])
+	event := func(id int64) *dto.DbOutboxEvent {
+		return &dto.DbOutboxEvent{ID: id, HeaderHash: header.Hash.String(), Operation: "ADD", Event: `{"operation":"ADD"}`, CreatedAt: now.UnixMilli()}
+	}
+
+	// when
+	err := repo.CreateMultipleWithEvents(ctx, []dto.DbBlockHeader{header}, nil, []*dto.DbOutboxEvent{event(0)})
+	assert.NoError(t, err)
+	err = repo.CreateMultipleWithEvents(ctx, []dto.DbBlockHeader{header}, nil, []*dto.DbOutboxEvent{event(0)})
+	assert.NoError(t, err)
+
+	// then
+	claimed, err := repo.ClaimOutboxEvents(ctx, now, now.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, len(claimed), 1)
+	assert.Equal(t, claimed[0].ID, int64(1))
+	assert.Equal(t, claimed[0].Event, `{"operation":"ADD"}`)
+
+	// when
+	claimedAgain, err := repo.ClaimOutboxEvents(ctx, now, now.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	afterTimeout, err := repo.ClaimOutboxEvents(ctx, now.Add(time.Minute), now.Add(2*time.Minute), 10)
+	assert.NoError(t, err)
+
+	// then
+	assert.Equal(t, len(claimedAgain), 0)
+	assert.Equal(t, len(afterTimeout), 1)
+
+	// when
+	assert.NoError(t, repo.DeleteOutboxEvents(ctx, []int64{1}))
+	remaining, err := repo.ClaimOutboxEvents(ctx, now.Add(time.Hour), now.Add(2*time.Hour), 10)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(remaining), 0)
+}
+
+func TestSQLiteOutboxEventsStoredWithStateChanges(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+	repo := sql.NewHeadersDb(adapter.db, &log)
+	ctx := context.Background()
+	insertHeader(t, adapter, 1, 0, domains.LongestChain)
+	insertHeader(t, adapter, 2, 1, domains.LongestChain)
+	insertHeader(t, adapter, 3, 1, domains.Stale)
+	chain, _ := fixtures.LongestChain()
+	header := dto.ToDbBlockHeader(chain[2])
+	event := func(operation string) *dto.DbOutboxEvent {
+		return &dto.DbOutboxEvent{HeaderHash: header.Hash.String(), Operation: operation, Event: "{}", CreatedAt: time.Now().UnixMilli()}
+	}
+	changes := []dto.DbStateChange{
+		{Hashes: []string{fmt.Sprintf("%064x", 2)}, State: string(domains.Stale)},
+		{Hashes: []string{fmt.Sprintf("%064x", 3)}, State: string(domains.LongestChain)},
+	}
+
+	// when
+	err := repo.CreateMultipleWithEvents(ctx, []dto.DbBlockHeader{header}, changes, []*dto.DbOutboxEvent{event("REORG"), event("ADD")})
+
+	// then
+	assert.NoError(t, err)
+	stale, err := repo.GetHeaderByHash(ctx, fmt.Sprintf("%064x", 2))
+	assert.NoError(t, err)
+	assert.Equal(t, stale.State, string(domains.Stale))
+	longest, err := repo.GetHeaderByHash(ctx, fmt.Sprintf("%064x", 3))
+	assert.NoError(t, err)
+	assert.Equal(t, longest.State, string(domains.LongestChain))
+
+	claimed, err := repo.ClaimOutboxEvents(ctx, time.Now(), time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, len(claimed), 2)
+	assert.Equal(t, claimed[0].Operation, "REORG")
+	assert.Equal(t, claimed[1].ID, claimed[0].ID+1)
+}
@@ -1,11 +1,10 @@
 package database
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"strings"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
@@ -29,10 +28,12 @@ const postgresBatchSize = 500_000
 
 func (a *postgreSQLAdapter) connect(cfg *config.DbConfig) error {
 	dbCfg := cfg.Postgres
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		dbCfg.Host, dbCfg.Port, dbCfg.User, dbCfg.Password, dbCfg.DbName, dbCfg.Sslmode)
+	password, err := dbCfg.ResolvePassword()
+	if err != nil {
+		return err
+	}
 
-	db, err := sqlx.Open(postgresDriverName, dsn)
+	db, err := sqlx.Open(postgresDriverName, postgresDSN(dbCfg, password))
 	if err != nil {
 		return err
 	}
@@ -41,6 +42,18 @@ func (a *postgreSQLAdapter) connect(cfg *config.DbConfig) error {
 	return nil
 }
 
+// postgresDSN returns the key=value connection string, with values quoted so a password can contain spaces and quotes.
+func postgresDSN(cfg config.PostgreSQLConfig, password string) string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		dsnValue(cfg.Host), cfg.Port, dsnValue(cfg.User), dsnValue(password), dsnValue(cfg.DbName), dsnValue(cfg.Sslmode))
+}
+
+// dsnValue quotes the value of a connection string parameter, escaping backslashes and single quotes as lib/pq requires.
+func dsnValue(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
 func (a *postgreSQLAdapter) doMigrations(cfg *config.DbConfig) error {
 	driver, err := postgres.WithInstance(a.db.DB, &postgres.Config{})
 	if err != nil {
@@ -69,7 +82,7 @@ func (a *postgreSQLAdapter) getDBx() *sqlx.DB {
 	return a.db
 }
 
-func (a *postgreSQLAdapter) importHeaders(inputFile *os.File, _ *zerolog.Logger) (affectedRows int, err error) {
+func (a *postgreSQLAdapter) importHeaders(reader recordReader, _ *zerolog.Logger) (affectedRows int, err error) {
 	// prepare db for bulk insterts
 	restoreIndexes, err := a.dropTableIndexes(sql.HeadersTableName)
 	if err != nil {
@@ -81,24 +94,18 @@ func (a *postgreSQLAdapter) importHeaders(inputFile *os.File, _ *zerolog.Logger)
 		}
 	}()
 
-	if _, err = inputFile.Seek(0, 0); err != nil {
-		return
-	}
-
-	reader := csv.NewReader(inputFile)
-	_, err = reader.Read() // Skipping the column headers line
-	if err != nil {
-		return
-	}
+	return a.importHeadersInto(reader, sql.HeadersTableName)
+}
 
-	// insert headers
+// importHeadersInto copies headers read from the reader into the table in batches.
+func (a *postgreSQLAdapter) importHeadersInto(reader recordReader, table string) (affectedRows int, err error) {
 	previousBlockHash := chainhash.Hash{}.String()
 	var cumulatedChainWork string
 	rowIndex := 0
 	guard := 0
 
 	for {
-		rowIndex, previousBlockHash, cumulatedChainWork, err = a.copyHeaders(reader, postgresBatchSize, previousBlockHash, cumulatedChainWork, rowIndex)
+		rowIndex, previousBlockHash, cumulatedChainWork, err = a.copyHeaders(reader, table, postgresBatchSize, previousBlockHash, cumulatedChainWork, rowIndex)
 		if err != nil {
 			affectedRows = rowIndex
 			return
@@ -121,11 +128,11 @@ func (a *postgreSQLAdapter) dropTableIndexes(table string) (func() error, error)
 	return dropIndexes(a.db, &q)
 }
 
-func (a *postgreSQLAdapter) copyHeaders(reader *csv.Reader, batchSize int, previousBlockHash string, cumulatedLastBlockChainWork string, rowIndex int) (lastRowIndex int, lastBlockHash string, cumulatedChainWork string, err error) {
+func (a *postgreSQLAdapter) copyHeaders(reader recordReader, table string, batchSize int, previousBlockHash string, cumulatedLastBlockChainWork string, rowIndex int) (lastRowIndex int, lastBlockHash string, cumulatedChainWork string, err error) {
 	lastRowIndex = rowIndex
 	lastBlockHash = previousBlockHash
 	copyQuery := pq.CopyIn(
-		sql.HeadersTableName,
+		table,
 		/* columns */ "height", "hash", "version", "merkleroot", "timestamp", "bits", "nonce", "header_state", "chainwork", "cumulated_work", "previous_block",
 	)
 
@@ -181,7 +188,7 @@ func (a *postgreSQLAdapter) copyHeaders(reader *csv.Reader, batchSize int, previ
 		}
 
 		cumulatedChainWork = b.CumulatedWork
-		lastBlockHash = b.Hash
+		lastBlockHash = b.Hash.String()
 		lastRowIndex++
 	}
 
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/lib/pq"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	testCases := map[string]struct {
+		password    string
+		expectedDSN string
+	}{
+		"plain password": {
+			password:    "secret",
+			expectedDSN: `host='localhost' port=5432 user='bhs' password='secret' dbname='headers' sslmode='disable'`,
+		},
+		"empty password": {
+			password:    "",
+			expectedDSN: `host='localhost' port=5432 user='bhs' password='' dbname='headers' sslmode='disable'`,
+		},
+		"password with spaces and an equals sign": {
+			password:    "s3cret pass=word",
+			expectedDSN: `host='localhost' port=5432 user='bhs' password='s3cret pass=word' dbname='headers' sslmode='disable'`,
+		},
+		"password with quotes and backslashes": {
+			password:    `it's\secret`,
+			expectedDSN: `host='localhost' port=5432 user='bhs' password='it\'s\\secret' dbname='headers' sslmode='disable'`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			cfg := config.PostgreSQLConfig{Host: "localhost", Port: 5432, User: "bhs", DbName: "headers", Sslmode: "disable"}
+
+			// when
+			dsn := postgresDSN(cfg, tc.password)
+
+			// then
+			assert.Equal(t, dsn, tc.expectedDSN)
+			_, err := pq.NewConnector(dsn)
+			assert.NoError(t, err)
+		})
+	}
+}
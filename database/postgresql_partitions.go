@@ -0,0 +1,178 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// partitionsAhead is the number of partitions kept above the current height, so fast sync
+	// never writes a header there is no partition for in between the maintenance runs.
+	partitionsAhead = 20
+
+	partitionMaintenanceInterval = time.Minute
+
+	sqlIsHeadersPartitioned = `
+	SELECT EXISTS (
+		SELECT 1 FROM pg_partitioned_table pt
+		JOIN pg_class c ON c.oid = pt.partrelid
+		WHERE c.relname = $1
+	)`
+
+	sqlHeadersIndexes = `SELECT indexdef FROM pg_indexes WHERE tablename = $1 AND indexname != $2`
+
+	sqlMaxHeight = `SELECT COALESCE(MAX(height), 0) FROM headers`
+)
+
+// Maintenance tells if the service is in the maintenance mode.
+type Maintenance interface {
+	ModeEnabled() bool
+}
+
+// partitionHeaders converts the headers table into a table partitioned by height ranges of
+// the configured size, when it is not partitioned yet, and creates partitions for upcoming heights.
+func (a *postgreSQLAdapter) partitionHeaders(cfg *config.DbConfig, log *zerolog.Logger) error {
+	size := cfg.Postgres.PartitionSize
+
+	var partitioned bool
+	if err := a.db.Get(&partitioned, sqlIsHeadersPartitioned, sql.HeadersTableName); err != nil {
+		return err
+	}
+
+	if !partitioned {
+		log.Info().Msgf("Partitioning %s table by %d heights", sql.HeadersTableName, size)
+		if err := convertToPartitioned(a.db, size); err != nil {
+			return fmt.Errorf("failed to partition %s table: %w", sql.HeadersTableName, err)
+		}
+	}
+
+	return newHeadersPartitions(a.db, size).ensure()
+}
+
+// convertToPartitioned moves all headers into a new table partitioned by height, recreating its indexes.
+// The primary key has to contain the partition key, so it becomes (hash, height).
+func convertToPartitioned(db *sqlx.DB, size int) error {
+	var indexes []string
+	if err := db.Select(&indexes, sqlHeadersIndexes, sql.HeadersTableName, sql.HeadersTableName+"_pkey"); err != nil {
+		return err
+	}
+
+	var maxHeight int
+	if err := db.Get(&maxHeight, sqlMaxHeight); err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	statements := []string{
+		`ALTER TABLE headers RENAME TO headers_unpartitioned`,
+		`CREATE TABLE headers (LIKE headers_unpartitioned INCLUDING DEFAULTS) PARTITION BY RANGE (height)`,
+	}
+	for from := 0; from <= maxHeight; from += size {
+		statements = append(statements, createPartitionSQL(from, size))
+	}
+	statements = append(statements,
+		`INSERT INTO headers SELECT * FROM headers_unpartitioned`,
+		`DROP TABLE headers_unpartitioned`,
+		// Keys and indexes are created once the old table, holding the same names, is dropped.
+		`ALTER TABLE headers ADD PRIMARY KEY (hash, height)`,
+	)
+	statements = append(statements, indexes...)
+
+	for _, s := range statements {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("%s: %w", s, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// headersPartitions creates partitions of the headers table for upcoming heights. It remembers the partitions
+// it created, so only the new ones are created as the chain grows. Partitions are never dropped, since each
+// of them holds headers of the longest chain, those above the tip are only truncated by a re-sync.
+type headersPartitions struct {
+	db   *sqlx.DB
+	size int
+	// next is the first height without a partition created or checked by this instance.
+	next int
+}
+
+func newHeadersPartitions(db *sqlx.DB, size int) *headersPartitions {
+	return &headersPartitions{db: db, size: size}
+}
+
+// ensure creates missing partitions up to partitionsAhead partitions above the highest stored header.
+func (p *headersPartitions) ensure() error {
+	var maxHeight int
+	if err := p.db.Get(&maxHeight, sqlMaxHeight); err != nil {
+		return err
+	}
+
+	for _, from := range p.missing(maxHeight) {
+		if _, err := p.db.Exec(createPartitionSQL(from, p.size)); err != nil {
+			return err
+		}
+		p.next = from + p.size
+	}
+	return nil
+}
+
+// missing returns the first heights of partitions needed for headers up to maxHeight which weren't created yet.
+func (p *headersPartitions) missing(maxHeight int) []int {
+	var froms []int
+	last := (maxHeight/p.size + partitionsAhead) * p.size
+	for from := p.next; from <= last; from += p.size {
+		froms = append(froms, from)
+	}
+	return froms
+}
+
+func createPartitionSQL(from, size int) string {
+	return fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s_p%d PARTITION OF %s FOR VALUES FROM (%d) TO (%d)`,
+		sql.HeadersTableName, from, sql.HeadersTableName, from, from+size,
+	)
+}
+
+// StartPartitionMaintenance periodically creates partitions for upcoming heights when the headers
+// table is partitioned, except in the maintenance mode. The returned func stops the maintenance.
+func StartPartitionMaintenance(db *sqlx.DB, cfg *config.DbConfig, maintenance Maintenance, log *zerolog.Logger) (stop func()) {
+	if cfg.Engine != config.DBPostgreSQL || cfg.Postgres.PartitionSize == 0 {
+		return func() {}
+	}
+
+	partitions := newHeadersPartitions(db, cfg.Postgres.PartitionSize)
+	quit := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(partitionMaintenanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if maintenance.ModeEnabled() {
+					continue
+				}
+				if err := partitions.ensure(); err != nil {
+					log.Error().Msgf("failed to create headers partitions: %v", err)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() { close(quit) }
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestHeadersPartitionsCreatesOnlyNewPartitions(t *testing.T) {
+	// given
+	partitions := newHeadersPartitions(nil, 1000)
+
+	// when
+	missing := partitions.missing(1500)
+
+	// then
+	assert.Equal(t, len(missing), partitionsAhead+2)
+	assert.Equal(t, missing[0], 0)
+	assert.Equal(t, missing[len(missing)-1], (partitionsAhead+1)*1000)
+
+	// when
+	partitions.next = missing[len(missing)-1] + 1000
+	missing = partitions.missing(1999)
+
+	// then
+	assert.Equal(t, len(missing), 0)
+
+	// when
+	missing = partitions.missing(3000)
+
+	// then
+	assert.Equal(t, len(missing), 2)
+	assert.Equal(t, missing[0], (partitionsAhead+2)*1000)
+	assert.Equal(t, missing[1], (partitionsAhead+3)*1000)
+}
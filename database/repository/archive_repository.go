@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// ArchiveRepository provide access to repositories and implements methods for archived branches.
+type ArchiveRepository struct {
+	db *sql.HeadersDb
+}
+
+// GetArchivedBranches returns at most limit archived branches from db, from the highest tip, without their headers.
+func (r *ArchiveRepository) GetArchivedBranches(limit int) ([]*domains.ArchivedBranch, error) {
+	dbBranches, err := r.db.GetArchivedBranches(context.Background(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]*domains.ArchivedBranch, 0, len(dbBranches))
+	for _, b := range dbBranches {
+		branch, err := b.ToArchivedBranch()
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// GetArchivedBranchOf returns the archived branch with the header of given hash from db, together with its headers.
+func (r *ArchiveRepository) GetArchivedBranchOf(hash string) (*domains.ArchivedBranch, error) {
+	b, err := r.db.GetArchivedBranchOf(context.Background(), hash)
+	if err != nil {
+		return nil, err
+	}
+	return b.ToArchivedBranch()
+}
+
+// NewArchiveRepository creates and returns ArchiveRepository instance.
+func NewArchiveRepository(db *sql.HeadersDb) *ArchiveRepository {
+	return &ArchiveRepository{db: db}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/notification"
 	dto "github.com/bitcoin-sv/block-headers-service/repository/dto"
 )
 
@@ -36,6 +37,31 @@ func (r *HeaderRepository) AddMultipleHeadersToDatabase(headers []domains.BlockH
 	return err
 }
 
+// AddHeadersWithEvents adds new headers to db together with state changes of the stored ones and events about them in one transaction.
+// Events of headers which already exist are omitted with the headers.
+func (r *HeaderRepository) AddHeadersWithEvents(headers []domains.BlockHeader, changes []domains.StateChange, events []*notification.OutboxEvent) error {
+	dbHeaders := make([]dto.DbBlockHeader, 0, len(headers))
+	for _, header := range headers {
+		dbHeaders = append(dbHeaders, dto.ToDbBlockHeader(header))
+	}
+
+	dbChanges := make([]dto.DbStateChange, 0, len(changes))
+	for _, c := range changes {
+		dbChanges = append(dbChanges, dto.ToDbStateChange(c))
+	}
+
+	dbEvents := make([]*dto.DbOutboxEvent, 0, len(events))
+	for _, e := range events {
+		dbEvent, err := dto.ToDbOutboxEvent(e)
+		if err != nil {
+			return err
+		}
+		dbEvents = append(dbEvents, dbEvent)
+	}
+
+	return r.db.CreateMultipleWithEvents(context.Background(), dbHeaders, dbChanges, dbEvents)
+}
+
 // UpdateState changes state value to provided one for each of headers with provided hash.
 func (r *HeaderRepository) UpdateState(hashes []chainhash.Hash, state domains.HeaderState) error {
 	hs := make([]string, len(hashes))
@@ -47,6 +73,40 @@ func (r *HeaderRepository) UpdateState(hashes []chainhash.Hash, state domains.He
 	return err
 }
 
+// DeleteHeaders deletes headers with provided hashes and returns how many of them were deleted.
+func (r *HeaderRepository) DeleteHeaders(hashes []chainhash.Hash) (int, error) {
+	hs := make([]string, len(hashes))
+	for i, h := range hashes {
+		hs[i] = h.String()
+	}
+
+	return r.db.DeleteHeaders(context.Background(), hs)
+}
+
+// DeleteHeadersAboveHeight deletes headers in all states higher than given height and returns how many of them were deleted.
+func (r *HeaderRepository) DeleteHeadersAboveHeight(height int32) (int, error) {
+	return r.db.DeleteHeadersAboveHeight(context.Background(), height)
+}
+
+// ArchiveBranches moves headers of the branches from the headers table to the archive and returns how many of them were moved.
+func (r *HeaderRepository) ArchiveBranches(branches []*domains.ArchivedBranch) (int, error) {
+	dbBranches := make([]*dto.DbArchivedBranch, 0, len(branches))
+	for _, b := range branches {
+		dbBranch, err := dto.ToDbArchivedBranch(b)
+		if err != nil {
+			return 0, err
+		}
+		dbBranches = append(dbBranches, dbBranch)
+	}
+
+	return r.db.ArchiveBranches(context.Background(), dbBranches)
+}
+
+// ReplaceHeaders replaces all stored headers with the swap function, which operates on the database directly.
+func (r *HeaderRepository) ReplaceHeaders(swap func() error) error {
+	return swap()
+}
+
 // GetHeaderByHeight returns header from db by given height.
 func (r *HeaderRepository) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
 	bh, err := r.db.GetHeaderByHeight(context.Background(), height, string(domains.LongestChain))
@@ -56,15 +116,22 @@ func (r *HeaderRepository) GetHeaderByHeight(height int32) (*domains.BlockHeader
 	return nil, err
 }
 
-// GetHeaderByHeightRange returns headers from db in specified height range.
-func (r *HeaderRepository) GetHeaderByHeightRange(from int, to int) ([]*domains.BlockHeader, error) {
-	dbHeaders, err := r.db.GetHeaderByHeightRange(from, to)
+// GetHeaderByHeightRange returns headers from db in specified height range which satisfy the query.
+func (r *HeaderRepository) GetHeaderByHeightRange(from int, to int, query domains.HeadersQuery) ([]*domains.BlockHeader, error) {
+	dbHeaders, err := r.db.GetHeaderByHeightRange(from, to, query)
 	if err == nil {
 		return dto.ConvertToBlockHeader(dbHeaders), nil
 	}
 	return nil, err
 }
 
+// StreamHeadersByHeightRange passes headers from db in specified height range which satisfy the query one by one to fn.
+func (r *HeaderRepository) StreamHeadersByHeightRange(from int, to int, query domains.HeadersQuery, fn func(*domains.BlockHeader) error) error {
+	return r.db.StreamHeaderByHeightRange(context.Background(), from, to, query, func(bh *dto.DbBlockHeader) error {
+		return fn(bh.ToBlockHeader())
+	})
+}
+
 // GetLongestChainHeadersFromHeight returns from db the headers from "longest chain" starting from given height.
 func (r *HeaderRepository) GetLongestChainHeadersFromHeight(height int32) ([]*domains.BlockHeader, error) {
 	dbHeaders, err := r.db.GetLongestChainHeadersFromHeight(height)
@@ -159,7 +226,7 @@ func (r *HeaderRepository) GetMerkleRoots(batchSize int, lastEvaluatedKey string
 		return merkleroots, nil
 	}
 
-	lastEvaluatedKeyFromDb := merklerootsFromDb[len(merklerootsFromDb)-1].MerkleRoot
+	lastEvaluatedKeyFromDb := merklerootsFromDb[len(merklerootsFromDb)-1].MerkleRoot.String()
 
 	if tip.MerkleRoot.String() != lastEvaluatedKeyFromDb {
 		merkleroots.Page.LastEvaluatedKey = lastEvaluatedKeyFromDb //indicating we still have some data available from db
@@ -167,7 +234,7 @@ func (r *HeaderRepository) GetMerkleRoots(batchSize int, lastEvaluatedKey string
 
 	for i, merkleroot := range merklerootsFromDb {
 		merkleroots.Content[i].BlockHeight = merkleroot.Height
-		merkleroots.Content[i].MerkleRoot = merkleroot.MerkleRoot
+		merkleroots.Content[i].MerkleRoot = merkleroot.MerkleRoot.String()
 	}
 
 	return merkleroots, nil
@@ -228,6 +295,38 @@ func (r *HeaderRepository) GetHeadersByHeightRange(from int, to int) ([]*domains
 	return dto.ConvertToBlockHeader(bh), nil
 }
 
+// GetSampledHeadersByHeightRange returns every interval-th header of the longest chain in specified height range.
+func (r *HeaderRepository) GetSampledHeadersByHeightRange(from int, to int, interval int) ([]*domains.BlockHeader, error) {
+	bh, err := r.db.GetSampledHeadersByHeightRange(from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ConvertToBlockHeader(bh), nil
+}
+
+// GetHeadersByHashes returns the headers with given hashes, unknown hashes are skipped.
+func (r *HeaderRepository) GetHeadersByHashes(hashes []string) ([]*domains.BlockHeader, error) {
+	dbHeaders, err := r.db.GetHeadersByHashes(hashes)
+	if err == nil {
+		return dto.ConvertToBlockHeader(dbHeaders), nil
+	}
+	return nil, err
+}
+
+// GetLongestChainHeadersByHeights returns the headers of the longest chain at given heights, heights above the tip are skipped.
+func (r *HeaderRepository) GetLongestChainHeadersByHeights(heights []int32) ([]*domains.BlockHeader, error) {
+	dbHeaders, err := r.db.GetLongestChainHeadersByHeights(heights)
+	if err == nil {
+		return dto.ConvertToBlockHeader(dbHeaders), nil
+	}
+	return nil, err
+}
+
+// GetHeadersCountByState returns the number of headers in each state from given height.
+func (r *HeaderRepository) GetHeadersCountByState(fromHeight int32) (map[domains.HeaderState]int, error) {
+	return r.db.CountByState(fromHeight)
+}
+
 // GetHeadersStopHeight returns height of hashstop header from db.
 func (r *HeaderRepository) GetHeadersStopHeight(hashStop string) (int, error) {
 	hs, err := r.db.GetHeadersStopHeight(hashStop)
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+// IdempotencyKeysRepository provide access to repositories and implements methods for idempotency keys.
+type IdempotencyKeysRepository struct {
+	db *sql.HeadersDb
+}
+
+// ClaimIdempotencyKey stores the key in db unless it's already stored and not expired, returning the stored one then.
+func (r *IdempotencyKeysRepository) ClaimIdempotencyKey(key *domains.IdempotencyKey, now time.Time) (*domains.IdempotencyKey, error) {
+	existing, err := r.db.ClaimIdempotencyKey(context.Background(), dto.ToDbIdempotencyKey(key), now)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	return existing.ToIdempotencyKey(), nil
+}
+
+// CompleteIdempotencyKey stores the response of the request made with the key in db.
+func (r *IdempotencyKeysRepository) CompleteIdempotencyKey(key *domains.IdempotencyKey) error {
+	return r.db.CompleteIdempotencyKey(context.Background(), dto.ToDbIdempotencyKey(key))
+}
+
+// DeleteIdempotencyKey deletes the key from db.
+func (r *IdempotencyKeysRepository) DeleteIdempotencyKey(key string) error {
+	return r.db.DeleteIdempotencyKey(context.Background(), key)
+}
+
+// DeleteExpiredIdempotencyKeys deletes keys expired at given time from db.
+func (r *IdempotencyKeysRepository) DeleteExpiredIdempotencyKeys(now time.Time) (int, error) {
+	return r.db.DeleteExpiredIdempotencyKeys(context.Background(), now)
+}
+
+// NewIdempotencyKeysRepository creates and returns IdempotencyKeysRepository instance.
+func NewIdempotencyKeysRepository(db *sql.HeadersDb) *IdempotencyKeysRepository {
+	return &IdempotencyKeysRepository{db: db}
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+)
+
+// LeaseRepository provide access to repositories and implements methods for leader leases.
+type LeaseRepository struct {
+	db *sql.HeadersDb
+}
+
+// TryAcquireLease takes or renews the lease with given name for the holder.
+func (r *LeaseRepository) TryAcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return r.db.TryAcquireLease(context.Background(), name, holder, ttl)
+}
+
+// ReleaseLease gives up the lease with given name if it is owned by the holder.
+func (r *LeaseRepository) ReleaseLease(name, holder string) error {
+	return r.db.ReleaseLease(context.Background(), name, holder)
+}
+
+// NewLeasesRepository creates and returns LeaseRepository instance.
+func NewLeasesRepository(db *sql.HeadersDb) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+)
+
+// MaintenanceRepository provide access to repositories and implements database maintenance.
+type MaintenanceRepository struct {
+	db *sql.HeadersDb
+}
+
+// Maintain vacuums the database and refreshes its statistics.
+func (r *MaintenanceRepository) Maintain() error {
+	return r.db.Maintain(context.Background())
+}
+
+// NewMaintenanceRepository creates and returns MaintenanceRepository instance.
+func NewMaintenanceRepository(db *sql.HeadersDb) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
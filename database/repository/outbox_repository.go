@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+)
+
+// OutboxRepository provide access to repositories and implements methods for events stored with headers.
+type OutboxRepository struct {
+	db *sql.HeadersDb
+}
+
+// ClaimOutboxEvents claims at most limit events in db not claimed at the given time until the other given time, oldest first.
+func (r *OutboxRepository) ClaimOutboxEvents(now time.Time, until time.Time, limit int) ([]*notification.OutboxEvent, error) {
+	dbEvents, err := r.db.ClaimOutboxEvents(context.Background(), now, until, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*notification.OutboxEvent, 0, len(dbEvents))
+	for _, e := range dbEvents {
+		event, err := e.ToOutboxEvent()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// DeleteOutboxEvents deletes events by ids from db.
+func (r *OutboxRepository) DeleteOutboxEvents(ids []int64) error {
+	return r.db.DeleteOutboxEvents(context.Background(), ids)
+}
+
+// NewOutboxRepository creates and returns OutboxRepository instance.
+func NewOutboxRepository(db *sql.HeadersDb) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
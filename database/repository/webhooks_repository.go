@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -56,6 +57,38 @@ func (r *WebhooksRepository) UpdateWebhook(w *notification.Webhook) error {
 	return err
 }
 
+// AddDeliveries stores pending deliveries of an event in db.
+func (r *WebhooksRepository) AddDeliveries(deliveries []*notification.WebhookDelivery) error {
+	dbDeliveries := make([]*dto.DbWebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		dbDeliveries = append(dbDeliveries, dto.ToDbWebhookDelivery(d))
+	}
+	return r.db.CreateWebhookDeliveries(context.Background(), dbDeliveries)
+}
+
+// GetDueDeliveries returns at most limit deliveries from db which should be attempted until given time.
+func (r *WebhooksRepository) GetDueDeliveries(until time.Time, limit int) ([]*notification.WebhookDelivery, error) {
+	dbDeliveries, err := r.db.GetDueWebhookDeliveries(context.Background(), until, limit)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*notification.WebhookDelivery, 0, len(dbDeliveries))
+	for _, d := range dbDeliveries {
+		deliveries = append(deliveries, d.ToWebhookDelivery())
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery counts an attempt of the delivery in db and postpones the next one, it returns false when it was claimed by someone else.
+func (r *WebhooksRepository) ClaimDelivery(d *notification.WebhookDelivery, next time.Time) (bool, error) {
+	return r.db.ClaimWebhookDelivery(context.Background(), d.ID, d.Attempts, next)
+}
+
+// DeleteDelivery deletes delivery by id from db.
+func (r *WebhooksRepository) DeleteDelivery(id string) error {
+	return r.db.DeleteWebhookDelivery(context.Background(), id)
+}
+
 // NewWebhooksRepository creates and returns WebhooksRepository instance.
 func NewWebhooksRepository(db *sql.HeadersDb) *WebhooksRepository {
 	return &WebhooksRepository{db: db}
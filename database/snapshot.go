@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// shadowHeadersTable is the table a snapshot is imported into, next to the headers table which is still in use.
+const shadowHeadersTable = "headers_shadow"
+
+const (
+	sqlDropShadowHeaders = `DROP TABLE IF EXISTS %s`
+
+	sqlPostgresCreateShadowHeaders = `CREATE TABLE %s (LIKE headers INCLUDING DEFAULTS)`
+)
+
+// Snapshots rebuilds the headers table from a prepared database file while the service keeps running.
+// The file is imported into a shadow table first, so the headers table is replaced only once the import succeeds.
+type Snapshots struct {
+	adapter     dbAdapter
+	cfg         *config.DbConfig
+	checkpoints []chaincfg.Checkpoint
+	log         zerolog.Logger
+
+	// mu guards the shadow table, which is shared by all imports.
+	mu sync.Mutex
+}
+
+// NewSnapshots creates Snapshots of the connected database, imported files are validated against the checkpoints
+// of the network the database belongs to.
+func NewSnapshots(db *sqlx.DB, cfg *config.DbConfig, checkpoints []chaincfg.Checkpoint, log *zerolog.Logger) (*Snapshots, error) {
+	var adapter dbAdapter
+	switch cfg.Engine {
+	case config.DBSQLite:
+		adapter = &sqLiteAdapter{db: db}
+	case config.DBPostgreSQL:
+		adapter = &postgreSQLAdapter{db: db}
+	default:
+		return nil, fmt.Errorf("unsupported database engine %s", cfg.Engine)
+	}
+
+	return &Snapshots{
+		adapter:     adapter,
+		cfg:         cfg,
+		checkpoints: checkpoints,
+		log:         log.With().Str("subservice", "snapshots").Logger(),
+	}, nil
+}
+
+// ImportShadow imports the prepared database file into the shadow table and returns the number of imported headers.
+// The import is validated the same way as the one on startup, against the newest checkpoint the file reaches.
+func (s *Snapshots) ImportShadow(path string) (importCount int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reader, closeReader, err := openHeadersFile(path, &s.log)
+	if err != nil {
+		return 0, err
+	}
+	defer closeReader()
+
+	if err = s.adapter.createShadowHeaders(shadowHeadersTable); err != nil {
+		return 0, fmt.Errorf("failed to create %s table: %w", shadowHeadersTable, err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if _, dErr := s.adapter.getDBx().Exec(fmt.Sprintf(sqlDropShadowHeaders, shadowHeadersTable)); dErr != nil {
+			s.log.Warn().Msgf("failed to drop %s table: %v", shadowHeadersTable, dErr)
+		}
+	}()
+
+	s.log.Info().Msgf("Inserting headers from %s to %s table", path, shadowHeadersTable)
+	if importCount, err = s.adapter.importHeadersInto(reader, shadowHeadersTable); err != nil {
+		return importCount, err
+	}
+	s.log.Info().Msgf("Inserted total of %d rows", importCount)
+
+	checkpoint := newestCheckpointUpTo(s.checkpoints, int32(importCount-1))
+	err = validateDbConsistency(importCount, s.adapter.getDBx(), shadowHeadersTable, checkpoint)
+	return importCount, err
+}
+
+// SwapShadow replaces the headers table with the shadow table filled by ImportShadow.
+func (s *Snapshots) SwapShadow() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.adapter.swapShadowHeaders(shadowHeadersTable); err != nil {
+		return fmt.Errorf("failed to swap %s table: %w", shadowHeadersTable, err)
+	}
+
+	if pg, ok := s.adapter.(*postgreSQLAdapter); ok && s.cfg.Postgres.PartitionSize > 0 {
+		if err := pg.partitionHeaders(s.cfg, &s.log); err != nil {
+			return err
+		}
+	}
+
+	// Statistics of the swapped table are stale, which leads the planner to full scans.
+	return sql.NewHeadersDb(s.adapter.getDBx(), &s.log).Maintain(context.Background())
+}
+
+// newestCheckpointUpTo returns the newest checkpoint not above the height or nil if there is none.
+func newestCheckpointUpTo(checkpoints []chaincfg.Checkpoint, height int32) *chaincfg.Checkpoint {
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if checkpoints[i].Height <= height {
+			return &checkpoints[i]
+		}
+	}
+	return nil
+}
+
+func (a *sqLiteAdapter) createShadowHeaders(table string) error {
+	return execInTx(a.db, []string{
+		fmt.Sprintf(sqlDropShadowHeaders, table),
+		fmt.Sprintf(sqlSQLiteCreateHeadersTable, table),
+	})
+}
+
+// swapShadowHeaders drops the headers table and renames the shadow one in its place, recreating the indexes.
+func (a *sqLiteAdapter) swapShadowHeaders(table string) error {
+	var indexes []string
+	if err := a.db.Select(&indexes, sqlSQLiteHeadersIndexes); err != nil {
+		return err
+	}
+
+	statements := []string{
+		`DROP TABLE headers`,
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO headers`, table),
+	}
+	statements = append(statements, indexes...)
+
+	return execInTx(a.db, statements)
+}
+
+func (a *postgreSQLAdapter) createShadowHeaders(table string) error {
+	return execInTx(a.db, []string{
+		fmt.Sprintf(sqlDropShadowHeaders, table),
+		fmt.Sprintf(sqlPostgresCreateShadowHeaders, table),
+		fmt.Sprintf(`ALTER TABLE %s ADD PRIMARY KEY (hash)`, table),
+	})
+}
+
+// swapShadowHeaders drops the headers table and renames the shadow one in its place, together with its primary key,
+// and recreates the indexes. A partitioned headers table is partitioned again afterwards, by the caller.
+func (a *postgreSQLAdapter) swapShadowHeaders(table string) error {
+	var indexes []string
+	if err := a.db.Select(&indexes, sqlHeadersIndexes, sql.HeadersTableName, sql.HeadersTableName+"_pkey"); err != nil {
+		return err
+	}
+
+	statements := []string{
+		`DROP TABLE headers`,
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO headers`, table),
+		fmt.Sprintf(`ALTER TABLE headers RENAME CONSTRAINT %s_pkey TO headers_pkey`, table),
+	}
+	statements = append(statements, indexes...)
+
+	return execInTx(a.db, statements)
+}
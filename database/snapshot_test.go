@@ -0,0 +1,111 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestSnapshotsSwapShadow(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	for i := 0; i < 5; i++ {
+		insertHeader(t, adapter, i+1, i, domains.LongestChain)
+	}
+	indexes := countIndexes(t, adapter)
+
+	path := filepath.Join(t.TempDir(), "snapshot.bin")
+	assert.NoError(t, os.WriteFile(path, headersDumpOf(t, 3), 0o600))
+
+	log := zerolog.Nop()
+	snapshots, err := NewSnapshots(adapter.db, &config.DbConfig{Engine: config.DBSQLite}, nil, &log)
+	assert.NoError(t, err)
+
+	// when
+	imported, err := snapshots.ImportShadow(path)
+	assert.NoError(t, err)
+	count, _ := countHeaders(adapter.db, "headers")
+	assert.Equal(t, count, 5)
+
+	err = snapshots.SwapShadow()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, imported, 3)
+	count, _ = countHeaders(adapter.db, "headers")
+	assert.Equal(t, count, 3)
+	assert.Equal(t, countIndexes(t, adapter), indexes)
+}
+
+func TestSnapshotsImportShadowFailure(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	insertHeader(t, adapter, 1, 0, domains.LongestChain)
+
+	log := zerolog.Nop()
+	snapshots, err := NewSnapshots(adapter.db, &config.DbConfig{Engine: config.DBSQLite}, nil, &log)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "missing.bin")
+
+	// when
+	_, err = snapshots.ImportShadow(path)
+
+	// then
+	assert.IsError(t, err, "file "+path+" does not exist or is not readable")
+	err = snapshots.SwapShadow()
+	assert.IsError(t, err, "failed to swap headers_shadow table: ALTER TABLE headers_shadow RENAME TO headers: no such table: headers_shadow")
+	count, _ := countHeaders(adapter.db, "headers")
+	assert.Equal(t, count, 1)
+}
+
+func countIndexes(t *testing.T, adapter *sqLiteAdapter) int {
+	t.Helper()
+	var count int
+	assert.NoError(t, adapter.db.Get(&count, `SELECT COUNT(1) FROM sqlite_master WHERE type = 'index' AND tbl_name = 'headers' AND sql IS NOT NULL`))
+	return count
+}
+
+func TestNewestCheckpointUpTo(t *testing.T) {
+	checkpoints := []chaincfg.Checkpoint{{Height: 10}, {Height: 20}}
+
+	testCases := map[string]struct {
+		checkpoints []chaincfg.Checkpoint
+		height      int32
+		expected    *chaincfg.Checkpoint
+	}{
+		"below the first checkpoint": {
+			checkpoints: checkpoints,
+			height:      9,
+		},
+		"at a checkpoint": {
+			checkpoints: checkpoints,
+			height:      20,
+			expected:    &checkpoints[1],
+		},
+		"between checkpoints": {
+			checkpoints: checkpoints,
+			height:      15,
+			expected:    &checkpoints[0],
+		},
+		"network without checkpoints": {
+			height: 15,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			checkpoint := newestCheckpointUpTo(tc.checkpoints, tc.height)
+
+			// then
+			assert.Equal(t, checkpoint, tc.expected)
+		})
+	}
+}
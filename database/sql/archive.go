@@ -0,0 +1,152 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	sqlInsertArchivedBranch = `
+	INSERT INTO archived_branches(tip_hash, tip_height, length, header_state, tip_timestamp, archived_at, headers, events)
+	VALUES(:tip_hash, :tip_height, :length, :header_state, :tip_timestamp, :archived_at, :headers, :events)
+	`
+
+	sqlInsertArchivedHeader = `
+	INSERT INTO archived_headers(hash, tip_hash)
+	VALUES(?, ?)
+	ON CONFLICT DO NOTHING
+	`
+
+	sqlSelectOutboxEventsOfHeaders = `
+	SELECT id, header_hash, operation, event, latest_only, claimed_until, created_at
+	FROM notification_outbox
+	WHERE header_hash IN (?)
+	ORDER BY id
+	`
+
+	sqlDeleteOutboxEventsOfHeaders = `
+	DELETE FROM notification_outbox
+	WHERE header_hash IN (?)
+	`
+
+	sqlSelectArchivedBranches = `
+	SELECT tip_hash, tip_height, length, header_state, tip_timestamp, archived_at, NULL AS headers, NULL AS events
+	FROM archived_branches
+	ORDER BY tip_height DESC, tip_hash
+	LIMIT ?
+	`
+
+	sqlSelectArchivedBranchOf = `
+	SELECT b.tip_hash, b.tip_height, b.length, b.header_state, b.tip_timestamp, b.archived_at, b.headers, b.events
+	FROM archived_branches b
+	JOIN archived_headers h ON h.tip_hash = b.tip_hash
+	WHERE h.hash = ?
+	`
+)
+
+// ArchiveBranches method will move headers of the branches from the headers table to the archive in one transaction
+// and return the number of headers deleted from the headers table. Events about the headers which are still
+// in the outbox are moved to the archive with them.
+func (h *HeadersDb) ArchiveBranches(ctx context.Context, branches []*dto.DbArchivedBranch) (int, error) {
+	if len(branches) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	err := h.retryOnBusy(ctx, func() error {
+		deleted = 0
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		for _, b := range branches {
+			if b.Events, err = h.takeOutboxEvents(ctx, tx, b.Hashes); err != nil {
+				return errors.Wrapf(err, "failed to archive events of branch %s", b.TipHash)
+			}
+			if _, err := tx.NamedExecContext(ctx, sqlInsertArchivedBranch, *b); err != nil {
+				return errors.Wrapf(err, "failed to archive branch %s", b.TipHash)
+			}
+			for _, hash := range b.Hashes {
+				if _, err := tx.ExecContext(ctx, h.db.Rebind(sqlInsertArchivedHeader), hash, b.TipHash); err != nil {
+					return errors.Wrapf(err, "failed to archive header %s", hash)
+				}
+			}
+
+			query, args, err := sqlx.In(sqlDeleteHeaders, b.Hashes)
+			if err != nil {
+				return errors.Wrap(err, "failed to delete archived headers")
+			}
+			res, err := tx.ExecContext(ctx, h.db.Rebind(query), args...)
+			if err != nil {
+				return errors.Wrap(err, "failed to delete archived headers")
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += affected
+		}
+
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
+	return int(deleted), err
+}
+
+// takeOutboxEvents deletes the events about the headers from the outbox and returns them compressed, nil when there were none.
+func (h *HeadersDb) takeOutboxEvents(ctx context.Context, tx *sqlx.Tx, hashes []dto.DbHash) ([]byte, error) {
+	hexHashes := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		hexHashes = append(hexHashes, hash.String())
+	}
+
+	query, args, err := sqlx.In(sqlSelectOutboxEventsOfHeaders, hexHashes)
+	if err != nil {
+		return nil, err
+	}
+	var events []*dto.DbOutboxEvent
+	if err := tx.SelectContext(ctx, &events, h.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	query, args, err = sqlx.In(sqlDeleteOutboxEventsOfHeaders, hexHashes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, h.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return dto.CompressArchivedEvents(events)
+}
+
+// GetArchivedBranches method will return at most limit archived branches from the highest tip, without their headers.
+func (h *HeadersDb) GetArchivedBranches(ctx context.Context, limit int) ([]*dto.DbArchivedBranch, error) {
+	var branches []*dto.DbArchivedBranch
+	if err := h.db.SelectContext(ctx, &branches, h.db.Rebind(sqlSelectArchivedBranches), limit); err != nil {
+		return nil, bhserrors.ErrArchive.Wrap(err)
+	}
+	return branches, nil
+}
+
+// GetArchivedBranchOf method will return the archived branch with the header of given hash, together with its headers.
+func (h *HeadersDb) GetArchivedBranchOf(ctx context.Context, hash string) (*dto.DbArchivedBranch, error) {
+	var branch dto.DbArchivedBranch
+	if err := h.db.GetContext(ctx, &branch, h.db.Rebind(sqlSelectArchivedBranchOf), dto.ParseDbHash(hash)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, bhserrors.ErrArchivedHeaderNotFound.Wrap(err)
+		}
+		return nil, bhserrors.ErrArchive.Wrap(err)
+	}
+	return &branch, nil
+}
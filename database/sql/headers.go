@@ -3,6 +3,9 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/domains"
@@ -30,6 +33,24 @@ const (
 	WHERE hash IN (?)
 	`
 
+	sqlDeleteHeaders = `
+	DELETE FROM headers
+	WHERE hash IN (?)
+	`
+
+	sqlDeleteHeadersAboveHeight = `
+	DELETE FROM headers
+	WHERE height > ?
+	`
+
+	sqlHeadersPartitions = `
+	SELECT c.relname
+	FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	JOIN pg_class p ON p.oid = i.inhparent
+	WHERE p.relname = $1
+	`
+
 	sqlHeader = `
 	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
 	FROM headers
@@ -54,6 +75,12 @@ const (
 	WHERE height BETWEEN ? AND ?
 	`
 
+	sqlHeaderByHeightRangeInStates = `
+	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
+	FROM headers
+	WHERE height BETWEEN ? AND ? AND header_state IN (?)
+	`
+
 	sqlLongestChainHeadersFromHeight = `
 	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
 	FROM headers
@@ -68,7 +95,7 @@ const (
 		UNION ALL
 		SELECT h.hash, h.height, h.version, h.merkleroot, h.nonce, h.bits, h.chainwork, h.previous_block, h.timestamp, h.header_state, h.cumulated_work
 		FROM headers h JOIN recur r
-		  ON h.hash = r.previous_block
+		  ON h.hash = r.previous_block AND h.height = r.height - 1
 	)
 	select hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
 	from recur
@@ -107,6 +134,7 @@ const (
 		 headers prev
 	WHERE h.hash = ?
 	  AND h.previous_block = prev.hash
+	  AND prev.height = h.height - 1
   	`
 
 	sqlSelectTip = `
@@ -123,7 +151,7 @@ const (
         UNION ALL
         SELECT h.hash, h.height, h.version, h.merkleroot, h.nonce, h.bits, h.chainwork, h.previous_block, h.timestamp, h.cumulated_work, a.level + 1 level
         FROM headers h JOIN ancestors a
-          ON h.hash = a.previous_block AND h.height >= ?
+          ON h.hash = a.previous_block AND h.height = a.height - 1 AND h.height >= ?
       )
     SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work
     FROM ancestors
@@ -148,26 +176,39 @@ const (
 				   `
 
 	sqlChainBetweenTwoHashes = `
-	WITH RECURSIVE ancestors(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work, level) AS (
-		SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work, 0 level
+	WITH RECURSIVE ancestors(hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work, level) AS (
+		SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work, 0 level
 		FROM headers
 		WHERE hash = ?
 		UNION ALL
-		SELECT h.hash, h.height, h.version, h.merkleroot, h.nonce, h.bits, h.chainwork, h.previous_block, h.timestamp, h.cumulated_work, a.level + 1 level
+		SELECT h.hash, h.height, h.version, h.merkleroot, h.nonce, h.bits, h.chainwork, h.previous_block, h.timestamp, h.header_state, h.cumulated_work, a.level + 1 level
 		FROM headers h JOIN ancestors a
-			ON h.hash = a.previous_block AND h.hash != ?
+			ON h.hash = a.previous_block AND h.height = a.height - 1 AND h.hash != ?
 		)
-	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work
+	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
 	FROM ancestors
 	UNION ALL
-	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, cumulated_work
+	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
 	FROM headers
 	WHERE hash = ?
 	`
 
 	sqlTipOfChainHeight = `SELECT MAX(height) FROM headers WHERE header_state = 'LONGEST_CHAIN'`
 
-	sqlVerifyHash = `SELECT hash FROM headers WHERE merkleroot = $1 AND height = $2 AND header_state = 'LONGEST_CHAIN'`
+	// sqlVerifyHashes is completed with a VALUES row of (idx, merkleroot, height) for every verified merkle root.
+	// Casts are required for postgres, which can't infer types of parameters in VALUES.
+	sqlVerifyHashes = `
+	WITH requested(idx, merkleroot, height) AS (VALUES %s)
+	SELECT r.merkleroot, r.height AS blockheight, h.hash
+	FROM requested r
+	LEFT JOIN headers h ON h.merkleroot = r.merkleroot AND h.height = r.height AND h.header_state = 'LONGEST_CHAIN'
+	ORDER BY r.idx
+	`
+	sqlVerifyHashesRow = `(CAST(? AS INTEGER), CAST(? AS %s), CAST(? AS INTEGER))`
+
+	// merkleRootsVerifyBatchSize is the number of merkle roots verified by a single query,
+	// keeping the number of parameters below the limits of the database engines.
+	merkleRootsVerifyBatchSize = 1000
 
 	sqlMerkleRootsFromHeight = `SELECT merkleroot, height FROM headers WHERE height > ? AND header_state = 'LONGEST_CHAIN' ORDER BY height ASC LIMIT ?`
 	sqlGetSingleMerkleroot   = `SELECT merkleroot, height, header_state FROM headers WHERE merkleroot = ?`
@@ -185,75 +226,211 @@ const (
 	FROM headers
 	WHERE height BETWEEN ? AND ? AND header_state = 'LONGEST_CHAIN';
 	`
+
+	sqlSampledHeadersByHeightRangeLongestChain = `
+	SELECT 
+		hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
+	FROM headers
+	WHERE height BETWEEN ? AND ? AND (height - ?) % ? = 0 AND header_state = 'LONGEST_CHAIN'
+	ORDER BY height;
+	`
+
+	sqlHeadersByHashes = `
+	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
+	FROM headers
+	WHERE hash IN (?)
+	`
+
+	sqlLongestChainHeadersByHeights = `
+	SELECT hash, height, version, merkleroot, nonce, bits, chainwork, previous_block, timestamp, header_state, cumulated_work
+	FROM headers
+	WHERE height IN (?) AND header_state = 'LONGEST_CHAIN'
+	`
+
+	sqlHeadersCountByStateFromHeight = `
+	SELECT header_state, COUNT(1) AS count
+	FROM headers
+	WHERE height >= ?
+	GROUP BY header_state
+	`
 )
 
+// sqlHeadersSortColumns are columns ordering headers by given field, chain work is stored as a decimal
+// string, so it's ordered by its length first. Each of them is backed by an index.
+var sqlHeadersSortColumns = map[domains.HeadersSortField][]string{
+	domains.SortByHeight:    {"height"},
+	domains.SortByTimestamp: {"timestamp"},
+	domains.SortByChainWork: {"length(cumulated_work)", "cumulated_work"},
+}
+
 // HeadersDb represents a database connection and map of related sql queries.
 type HeadersDb struct {
-	db  *sqlx.DB
-	log *zerolog.Logger
+	db          *sqlx.DB
+	log         *zerolog.Logger
+	busyRetries int
+	busyBackoff time.Duration
+	streamChunk int
 }
 
 // NewHeadersDb will setup and return a new headers store.
-func NewHeadersDb(db *sqlx.DB, log *zerolog.Logger) *HeadersDb {
+func NewHeadersDb(db *sqlx.DB, log *zerolog.Logger, opts ...HeadersDbOpt) *HeadersDb {
 	headerLogger := log.With().Str("subservice", "headers-db").Logger()
-	return &HeadersDb{
-		db:  db,
-		log: &headerLogger,
+	h := &HeadersDb{
+		db:          db,
+		log:         &headerLogger,
+		busyRetries: defaultBusyRetries,
+		busyBackoff: defaultBusyBackoff,
+		streamChunk: defaultStreamChunk,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // Create method will add new record into db.
 func (h *HeadersDb) Create(ctx context.Context, req dto.DbBlockHeader) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-	if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, req); err != nil {
-		return errors.Wrap(err, "failed to insert header")
-	}
-	return errors.Wrap(tx.Commit(), "failed to commit tx")
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+		if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, req); err != nil {
+			return errors.Wrap(err, "failed to insert header")
+		}
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
 }
 
 // CreateMultiple method will add multiple new records into db.
 func (h *HeadersDb) CreateMultiple(ctx context.Context, headers []dto.DbBlockHeader) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	for _, record := range headers {
-		if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, record); err != nil {
-			return errors.Wrap(err, "failed to insert header")
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		for _, record := range headers {
+			if _, err := tx.NamedExecContext(ctx, sqlInsertHeader, record); err != nil {
+				return errors.Wrap(err, "failed to insert header")
+			}
 		}
-	}
 
-	return errors.Wrap(tx.Commit(), "failed to commit tx")
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
 }
 
 // UpdateState will update state of headers of hashes to given state.
 func (h *HeadersDb) UpdateState(ctx context.Context, hashes []string, state string) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		query, args, err := sqlx.In(sqlUpdateState, state, dto.ParseDbHashes(hashes))
+		if err != nil {
+			return errors.Wrapf(err, "failed to update headers state to %s", state)
+		}
+		if _, err := tx.ExecContext(ctx, h.db.Rebind(query), args...); err != nil {
+			return errors.Wrapf(err, "failed to update headers state to %s", state)
+		}
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
+}
+
+// DeleteHeaders will delete headers with given hashes from db and return the number of deleted rows.
+func (h *HeadersDb) DeleteHeaders(ctx context.Context, hashes []string) (int, error) {
+	if len(hashes) == 0 {
+		return 0, nil
 	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
 
-	query, args, err := sqlx.In(sqlUpdateState, state, hashes)
-	if err != nil {
-		return errors.Wrapf(err, "failed to update headers state to %s", state)
+	var deleted int64
+	err := h.retryOnBusy(ctx, func() error {
+		query, args, err := sqlx.In(sqlDeleteHeaders, dto.ParseDbHashes(hashes))
+		if err != nil {
+			return errors.Wrap(err, "failed to delete headers")
+		}
+		res, err := h.db.ExecContext(ctx, h.db.Rebind(query), args...)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete headers")
+		}
+		deleted, err = res.RowsAffected()
+		return errors.Wrap(err, "failed to delete headers")
+	})
+	return int(deleted), err
+}
+
+// DeleteHeadersAboveHeight will delete headers in all states higher than given height from db and return the number of deleted rows.
+// Partitions of a partitioned headers table which start above the height are truncated instead of deleting their rows one by one.
+func (h *HeadersDb) DeleteHeadersAboveHeight(ctx context.Context, height int32) (int, error) {
+	var deleted int64
+	err := h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		truncated, err := h.truncatePartitionsAbove(ctx, tx, height)
+		if err != nil {
+			return errors.Wrapf(err, "failed to truncate partitions above height %d", height)
+		}
+		res, err := tx.ExecContext(ctx, h.db.Rebind(sqlDeleteHeadersAboveHeight), height)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete headers above height %d", height)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete headers above height %d", height)
+		}
+		deleted = truncated + affected
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
+	return int(deleted), err
+}
+
+// truncatePartitionsAbove truncates the partitions of the headers table starting above the height
+// and returns the number of headers they held. Partitions are kept, as they're needed once the headers are synced again.
+func (h *HeadersDb) truncatePartitionsAbove(ctx context.Context, tx *sqlx.Tx, height int32) (int64, error) {
+	if h.db.DriverName() != "postgres" {
+		return 0, nil
+	}
+	var partitions []string
+	if err := tx.SelectContext(ctx, &partitions, sqlHeadersPartitions, HeadersTableName); err != nil {
+		return 0, err
 	}
-	if _, err := tx.ExecContext(ctx, h.db.Rebind(query), args...); err != nil {
-		return errors.Wrapf(err, "failed to update headers state to %s", state)
+
+	var truncated int64
+	for _, partition := range partitions {
+		var from int32
+		if _, err := fmt.Sscanf(partition, HeadersTableName+"_p%d", &from); err != nil || from <= height {
+			continue
+		}
+		var count int64
+		if err := tx.GetContext(ctx, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", partition)); err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("TRUNCATE %s", partition)); err != nil {
+			return 0, err
+		}
+		truncated += count
 	}
-	return errors.Wrap(tx.Commit(), "failed to commit tx")
+	return truncated, nil
 }
 
 // Height will return the current highest block height we have stored in the db.
@@ -278,7 +455,7 @@ func (h *HeadersDb) Count(ctx context.Context) (int, error) {
 // GetHeaderByHash will return header from db with given hash.
 func (h *HeadersDb) GetHeaderByHash(ctx context.Context, hash string) (*dto.DbBlockHeader, error) {
 	var bh dto.DbBlockHeader
-	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlHeader), hash); err != nil {
+	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlHeader), dto.ParseDbHash(hash)); err != nil {
 		return nil, bhserrors.ErrHeaderNotFound.Wrap(err)
 	}
 	return &bh, nil
@@ -296,15 +473,50 @@ func (h *HeadersDb) GetHeaderByHeight(ctx context.Context, height int32, state s
 	return &bh, nil
 }
 
-// GetHeaderByHeightRange will return headers from db for given height range (including sended height).
-func (h *HeadersDb) GetHeaderByHeightRange(from int, to int) ([]*dto.DbBlockHeader, error) {
+// GetHeaderByHeightRange will return headers from db for given height range (including sended height),
+// narrowed down and ordered by the headers query.
+func (h *HeadersDb) GetHeaderByHeightRange(from int, to int, hq domains.HeadersQuery) ([]*dto.DbBlockHeader, error) {
+	query, args, err := headerByHeightRangeQuery(from, to, hq)
+	if err != nil {
+		return nil, bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
+	}
+
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlHeaderByHeightRange), from, to); err != nil {
+	if err := h.db.Select(&bh, h.db.Rebind(query), args...); err != nil {
 		return nil, bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
 	}
 	return bh, nil
 }
 
+// StreamHeaderByHeightRange reads headers from db for given height range (including sended height)
+// and passes them one by one to fn, without loading the whole range into memory. Headers are read in chunks,
+// each by a separate query continuing after the last read header, so no read is held open while fn is slow.
+// Without sorting given by the query headers are passed in the order of height.
+func (h *HeadersDb) StreamHeaderByHeightRange(ctx context.Context, from int, to int, hq domains.HeadersQuery, fn func(*dto.DbBlockHeader) error) error {
+	var after *dto.DbHash
+	for {
+		query, args, err := headerByHeightRangeChunkQuery(from, to, hq, after, h.streamChunk)
+		if err != nil {
+			return bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
+		}
+
+		var chunk []*dto.DbBlockHeader
+		if err := h.db.SelectContext(ctx, &chunk, h.db.Rebind(query), args...); err != nil {
+			return bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
+		}
+		for _, bh := range chunk {
+			if err := fn(bh); err != nil {
+				return err
+			}
+		}
+
+		if len(chunk) < h.streamChunk {
+			return nil
+		}
+		after = &chunk[len(chunk)-1].Hash
+	}
+}
+
 // GetLongestChainHeadersFromHeight returns from db the headers from "longest chain" starting from given height.
 func (h *HeadersDb) GetLongestChainHeadersFromHeight(height int32) ([]*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
@@ -320,7 +532,7 @@ func (h *HeadersDb) GetLongestChainHeadersFromHeight(height int32) ([]*dto.DbBlo
 // GetStaleHeadersBackFrom returns from db all the headers with state STALE, starting from header with hash and preceding that one.
 func (h *HeadersDb) GetStaleHeadersBackFrom(hash string) ([]*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlStaleHeadersFrom), hash); err != nil {
+	if err := h.db.Select(&bh, h.db.Rebind(sqlStaleHeadersFrom), dto.ParseDbHash(hash)); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.Errorf("header with %s hash does not exist", hash)
 		}
@@ -338,7 +550,7 @@ func (h *HeadersDb) GenesisExists(_ context.Context) bool {
 // GetPreviousHeader will return previous header for this with given hash.
 func (h *HeadersDb) GetPreviousHeader(ctx context.Context, hash string) (*dto.DbBlockHeader, error) {
 	var bh dto.DbBlockHeader
-	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlSelectPreviousBlock), hash); err != nil {
+	if err := h.db.GetContext(ctx, &bh, h.db.Rebind(sqlSelectPreviousBlock), dto.ParseDbHash(hash)); err != nil {
 		return nil, bhserrors.ErrHeaderNotFound.Wrap(err)
 	}
 	return &bh, nil
@@ -361,7 +573,7 @@ func (h *HeadersDb) GetTip(_ context.Context) (*dto.DbBlockHeader, error) {
 // GetAncestorOnHeight provides ancestor for a hash on a specified height.
 func (h *HeadersDb) GetAncestorOnHeight(hash string, height int32) (*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlSelectAncestorOnHeight), hash, int(height), int(height)); err != nil {
+	if err := h.db.Select(&bh, h.db.Rebind(sqlSelectAncestorOnHeight), dto.ParseDbHash(hash), int(height), int(height)); err != nil {
 		return nil, bhserrors.ErrAncestorNotFound.Wrap(err)
 	}
 	if len(bh) == 0 {
@@ -382,7 +594,8 @@ func (h *HeadersDb) GetAllTips() ([]*dto.DbBlockHeader, error) {
 // GetChainBetweenTwoHashes calculates and returnes chain between 2 hashes.
 func (h *HeadersDb) GetChainBetweenTwoHashes(low string, high string) ([]*dto.DbBlockHeader, error) {
 	var bh []*dto.DbBlockHeader
-	if err := h.db.Select(&bh, h.db.Rebind(sqlChainBetweenTwoHashes), high, low, low); err != nil {
+	lowHash := dto.ParseDbHash(low)
+	if err := h.db.Select(&bh, h.db.Rebind(sqlChainBetweenTwoHashes), dto.ParseDbHash(high), lowHash, lowHash); err != nil {
 		return nil, bhserrors.ErrHeadersForGivenRangeNotFound.Wrap(err)
 	}
 	if len(bh) == 0 {
@@ -395,18 +608,24 @@ func (h *HeadersDb) GetChainBetweenTwoHashes(low string, high string) ([]*dto.Db
 func (h *HeadersDb) GetMerkleRootsConfirmations(
 	request []domains.MerkleRootConfirmationRequestItem,
 ) ([]*dto.DbMerkleRootConfirmation, error) {
-	confirmations := make([]*dto.DbMerkleRootConfirmation, 0)
+	confirmations := make([]*dto.DbMerkleRootConfirmation, 0, len(request))
 	tipHeight, err := h.getChainTipHeight()
 	if err != nil {
 		return nil, bhserrors.ErrGetChainTipHeight.Wrap(err)
 	}
 
-	for _, item := range request {
-		confirmation, err := h.getMerkleRootConfirmation(item, tipHeight)
+	for start := 0; start < len(request); start += merkleRootsVerifyBatchSize {
+		batch := request[start:min(start+merkleRootsVerifyBatchSize, len(request))]
+		batchConfirmations, err := h.getMerkleRootsConfirmations(batch)
 		if err != nil {
-			continue
+			// A failed batch doesn't fail the others, its merkle roots are reported as unable to verify.
+			h.log.Error().Err(err).Msgf("Cannot verify merkle roots %d-%d of %d", start, start+len(batch)-1, len(request))
+			batchConfirmations = unverifiedMerkleRoots(batch)
+		}
+		for _, c := range batchConfirmations {
+			c.TipHeight = tipHeight
 		}
-		confirmations = append(confirmations, confirmation)
+		confirmations = append(confirmations, batchConfirmations...)
 	}
 
 	return confirmations, nil
@@ -414,7 +633,7 @@ func (h *HeadersDb) GetMerkleRootsConfirmations(
 
 // GetHeadersStartHeight returns hash and height from db with given locators.
 func (h *HeadersDb) GetHeadersStartHeight(hashTable []string) (int, error) {
-	query, args, err := sqlx.In(sqlGetHeadersHeight, hashTable)
+	query, args, err := sqlx.In(sqlGetHeadersHeight, dto.ParseDbHashes(hashTable))
 	if err != nil {
 		h.log.Error().Err(err).Msg("Error while constructing query")
 		return 0, err
@@ -432,7 +651,7 @@ func (h *HeadersDb) GetHeadersStartHeight(hashTable []string) (int, error) {
 // GetHeadersStopHeight will return header from db with given hash.
 func (h *HeadersDb) GetHeadersStopHeight(hashStop string) (int, error) {
 	var dbHashStopHeight int
-	if err := h.db.Get(&dbHashStopHeight, h.db.Rebind(sqlHeaderHeightFromHashAndState), hashStop, longestChainState); err != nil {
+	if err := h.db.Get(&dbHashStopHeight, h.db.Rebind(sqlHeaderHeightFromHashAndState), dto.ParseDbHash(hashStop), longestChainState); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, nil
 		}
@@ -451,29 +670,184 @@ func (h *HeadersDb) GetHeadersByHeightRange(from int, to int) ([]*dto.DbBlockHea
 	return listOfHeaders, nil
 }
 
+// GetSampledHeadersByHeightRange returns from db every interval-th header of the longest chain in specified height range, starting with from.
+func (h *HeadersDb) GetSampledHeadersByHeightRange(from int, to int, interval int) ([]*dto.DbBlockHeader, error) {
+	var listOfHeaders []*dto.DbBlockHeader
+	if err := h.db.Select(&listOfHeaders, h.db.Rebind(sqlSampledHeadersByHeightRangeLongestChain), from, to, from, interval); err != nil {
+		return nil, errors.Wrapf(err, "failed to get every %d header using given range from: %d to: %d", interval, from, to)
+	}
+	return listOfHeaders, nil
+}
+
+// GetHeadersByHashes returns from db the headers with given hashes, unknown hashes are skipped.
+func (h *HeadersDb) GetHeadersByHashes(hashes []string) ([]*dto.DbBlockHeader, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(sqlHeadersByHashes, dto.ParseDbHashes(hashes))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the query of headers by hashes")
+	}
+
+	var bh []*dto.DbBlockHeader
+	if err := h.db.Select(&bh, h.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %d headers by hashes", len(hashes))
+	}
+	return bh, nil
+}
+
+// GetLongestChainHeadersByHeights returns from db the headers of the longest chain at given heights,
+// heights above the tip are skipped.
+func (h *HeadersDb) GetLongestChainHeadersByHeights(heights []int32) ([]*dto.DbBlockHeader, error) {
+	if len(heights) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(sqlLongestChainHeadersByHeights, heights)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the query of headers by heights")
+	}
+
+	var bh []*dto.DbBlockHeader
+	if err := h.db.Select(&bh, h.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %d headers of the longest chain by heights", len(heights))
+	}
+	return bh, nil
+}
+
+// CountByState returns from db the number of headers in each state from given height.
+func (h *HeadersDb) CountByState(fromHeight int32) (map[domains.HeaderState]int, error) {
+	var rows []struct {
+		State string `db:"header_state"`
+		Count int    `db:"count"`
+	}
+	if err := h.db.Select(&rows, h.db.Rebind(sqlHeadersCountByStateFromHeight), fromHeight); err != nil {
+		return nil, errors.Wrapf(err, "failed to count headers by state from height %d", fromHeight)
+	}
+
+	counts := make(map[domains.HeaderState]int, len(rows))
+	for _, r := range rows {
+		counts[domains.HeaderState(r.State)] = r.Count
+	}
+	return counts, nil
+}
+
+// headerByHeightRangeQuery builds the query of headers in given height range, narrowed down and ordered by the headers query.
+func headerByHeightRangeQuery(from int, to int, hq domains.HeadersQuery) (string, []interface{}, error) {
+	query, args, err := headerByHeightRangeInStatesQuery(from, to, hq)
+	if err != nil || hq.SortBy == "" {
+		return query, args, err
+	}
+	columns, direction, err := headersOrder(hq)
+	if err != nil {
+		return "", nil, err
+	}
+	return query + orderBy(columns, direction), args, nil
+}
+
+// headerByHeightRangeChunkQuery returns the query of at most limit headers of the range following the header
+// with after hash in the order of the headers query, or the first ones if after is nil. Headers are ordered
+// by the hash too, so the position of the header in the order is unique.
+func headerByHeightRangeChunkQuery(from int, to int, hq domains.HeadersQuery, after *dto.DbHash, limit int) (string, []interface{}, error) {
+	query, args, err := headerByHeightRangeInStatesQuery(from, to, hq)
+	if err != nil {
+		return "", nil, err
+	}
+	columns, direction := []string{"height"}, "ASC"
+	if hq.SortBy != "" {
+		if columns, direction, err = headersOrder(hq); err != nil {
+			return "", nil, err
+		}
+	}
+	keys := append(append([]string{}, columns...), "hash")
+
+	if after != nil {
+		operator := ">"
+		if direction == "DESC" {
+			operator = "<"
+		}
+		key := strings.Join(keys, ", ")
+		query += fmt.Sprintf("AND (%s) %s (SELECT %s FROM headers WHERE hash = ?)\n", key, operator, key)
+		args = append(args, *after)
+	}
+	return query + orderBy(keys, direction) + " LIMIT ?", append(args, limit), nil
+}
+
+func headerByHeightRangeInStatesQuery(from int, to int, hq domains.HeadersQuery) (string, []interface{}, error) {
+	if len(hq.States) == 0 {
+		return sqlHeaderByHeightRange, []interface{}{from, to}, nil
+	}
+	return sqlx.In(sqlHeaderByHeightRangeInStates, from, to, statesOf(hq))
+}
+
+func headersOrder(hq domains.HeadersQuery) ([]string, string, error) {
+	columns, ok := sqlHeadersSortColumns[hq.SortBy]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported sort field %s", hq.SortBy)
+	}
+	if hq.Descending {
+		return columns, "DESC", nil
+	}
+	return columns, "ASC", nil
+}
+
+func orderBy(columns []string, direction string) string {
+	order := make([]string, 0, len(columns))
+	for _, c := range columns {
+		order = append(order, c+" "+direction)
+	}
+	return "ORDER BY " + strings.Join(order, ", ")
+}
+
+func statesOf(hq domains.HeadersQuery) []string {
+	states := make([]string, 0, len(hq.States))
+	for _, s := range hq.States {
+		states = append(states, string(s))
+	}
+	return states
+}
+
 func (h *HeadersDb) getChainTipHeight() (int32, error) {
 	var tipHeight int32
 	err := h.db.Get(&tipHeight, sqlTipOfChainHeight)
 	return tipHeight, err
 }
 
-func (h *HeadersDb) getMerkleRootConfirmation(item domains.MerkleRootConfirmationRequestItem, tipHeight int32) (*dto.DbMerkleRootConfirmation, error) {
-	confirmation := &dto.DbMerkleRootConfirmation{
-		MerkleRoot:  item.MerkleRoot,
-		BlockHeight: item.BlockHeight,
-		TipHeight:   tipHeight,
+func (h *HeadersDb) getMerkleRootsConfirmations(batch []domains.MerkleRootConfirmationRequestItem) ([]*dto.DbMerkleRootConfirmation, error) {
+	row := fmt.Sprintf(sqlVerifyHashesRow, h.binaryType())
+	rows := make([]string, 0, len(batch))
+	args := make([]interface{}, 0, 3*len(batch))
+	for i, item := range batch {
+		rows = append(rows, row)
+		args = append(args, i, dto.ParseDbHash(item.MerkleRoot), item.BlockHeight)
 	}
+	query := fmt.Sprintf(sqlVerifyHashes, strings.Join(rows, ","))
 
-	var hash sql.NullString
-	err := h.db.Get(&hash, sqlVerifyHash, item.MerkleRoot, item.BlockHeight)
-
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return nil, err
+	var confirmations []*dto.DbMerkleRootConfirmation
+	if err := h.db.Select(&confirmations, h.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrap(err, "failed to verify merkle roots")
 	}
+	return confirmations, nil
+}
 
-	confirmation.Hash = hash
+// unverifiedMerkleRoots returns confirmations of merkle roots which couldn't be looked up.
+func unverifiedMerkleRoots(batch []domains.MerkleRootConfirmationRequestItem) []*dto.DbMerkleRootConfirmation {
+	confirmations := make([]*dto.DbMerkleRootConfirmation, 0, len(batch))
+	for _, item := range batch {
+		confirmations = append(confirmations, &dto.DbMerkleRootConfirmation{
+			MerkleRoot:  dto.ParseDbHash(item.MerkleRoot),
+			BlockHeight: item.BlockHeight,
+			Unverified:  true,
+		})
+	}
+	return confirmations
+}
 
-	return confirmation, nil
+// binaryType returns the name of the binary column type of the database engine.
+func (h *HeadersDb) binaryType() string {
+	if h.db.DriverName() == "postgres" {
+		return "BYTEA"
+	}
+	return "BLOB"
 }
 
 // GetMerkleRoots method will retrieve as many merkleroots as batchSize from the db from lastEvaluatedKey exclusive
@@ -500,7 +874,7 @@ func (h *HeadersDb) getLastEvaluatedMerklerootHeight(lastEvaluatedKey string) (i
 	}
 
 	var lastEvaluatedMerkleroot dto.DbBlockHeader
-	err := h.db.Get(&lastEvaluatedMerkleroot, h.db.Rebind(sqlGetSingleMerkleroot), lastEvaluatedKey)
+	err := h.db.Get(&lastEvaluatedMerkleroot, h.db.Rebind(sqlGetSingleMerkleroot), dto.ParseDbHash(lastEvaluatedKey))
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return 0, bhserrors.ErrMerklerootNotFound
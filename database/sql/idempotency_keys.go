@@ -0,0 +1,120 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/pkg/errors"
+)
+
+const (
+	sqlDeleteExpiredIdempotencyKey = `
+	DELETE FROM idempotency_keys
+	WHERE idempotency_key = ? AND expires_at <= ?
+	`
+
+	sqlInsertIdempotencyKey = `
+	INSERT INTO idempotency_keys(idempotency_key, request_hash, status_code, content_type, body, created_at, expires_at)
+	VALUES(:idempotency_key, :request_hash, :status_code, :content_type, :body, :created_at, :expires_at)
+	ON CONFLICT DO NOTHING
+	`
+
+	sqlSelectIdempotencyKey = `
+	SELECT idempotency_key, request_hash, status_code, content_type, body, created_at, expires_at
+	FROM idempotency_keys
+	WHERE idempotency_key = ?
+	`
+
+	sqlCompleteIdempotencyKey = `
+	UPDATE idempotency_keys
+	SET status_code = ?, content_type = ?, body = ?
+	WHERE idempotency_key = ?
+	`
+
+	sqlDeleteIdempotencyKey = `
+	DELETE FROM idempotency_keys
+	WHERE idempotency_key = ?
+	`
+
+	sqlDeleteExpiredIdempotencyKeys = `
+	DELETE FROM idempotency_keys
+	WHERE expires_at <= ?
+	`
+)
+
+// ClaimIdempotencyKey method will store the key unless it's already stored and not expired at given time.
+// It returns nil when the key was stored, otherwise the one stored before.
+func (h *HeadersDb) ClaimIdempotencyKey(ctx context.Context, key *dto.DbIdempotencyKey, now time.Time) (*dto.DbIdempotencyKey, error) {
+	var existing *dto.DbIdempotencyKey
+	err := h.retryOnBusy(ctx, func() error {
+		existing = nil
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		if _, err := tx.ExecContext(ctx, h.db.Rebind(sqlDeleteExpiredIdempotencyKey), key.Key, now.UnixMilli()); err != nil {
+			return err
+		}
+		res, err := tx.NamedExecContext(ctx, sqlInsertIdempotencyKey, *key)
+		if err != nil {
+			return err
+		}
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if inserted == 0 {
+			var stored dto.DbIdempotencyKey
+			if err := tx.GetContext(ctx, &stored, h.db.Rebind(sqlSelectIdempotencyKey), key.Key); err != nil {
+				return err
+			}
+			existing = &stored
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to claim idempotency key %s", key.Key)
+	}
+
+	return existing, nil
+}
+
+// CompleteIdempotencyKey method will store the response of the request made with the key.
+func (h *HeadersDb) CompleteIdempotencyKey(ctx context.Context, key *dto.DbIdempotencyKey) error {
+	return h.retryOnBusy(ctx, func() error {
+		_, err := h.db.ExecContext(ctx, h.db.Rebind(sqlCompleteIdempotencyKey), key.StatusCode, key.ContentType, key.Body, key.Key)
+		return errors.Wrapf(err, "failed to complete idempotency key %s", key.Key)
+	})
+}
+
+// DeleteIdempotencyKey method will remove the key, so the request can be made with it again.
+func (h *HeadersDb) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	return h.retryOnBusy(ctx, func() error {
+		_, err := h.db.ExecContext(ctx, h.db.Rebind(sqlDeleteIdempotencyKey), key)
+		return errors.Wrapf(err, "failed to delete idempotency key %s", key)
+	})
+}
+
+// DeleteExpiredIdempotencyKeys method will remove keys expired at given time, returning the number of removed ones.
+func (h *HeadersDb) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int, error) {
+	var deleted int64
+	err := h.retryOnBusy(ctx, func() error {
+		res, err := h.db.ExecContext(ctx, h.db.Rebind(sqlDeleteExpiredIdempotencyKeys), now.UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete expired idempotency keys")
+	}
+
+	return int(deleted), nil
+}
@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	sqlAcquireLease = `
+	INSERT INTO leader_lease(name, holder, expires_at)
+	VALUES(?, ?, ?)
+	ON CONFLICT (name) DO UPDATE
+	SET holder = excluded.holder, expires_at = excluded.expires_at
+	WHERE leader_lease.holder = excluded.holder OR leader_lease.expires_at < ?
+	`
+
+	sqlReleaseLease = `
+	DELETE FROM leader_lease
+	WHERE name = ? AND holder = ?
+	`
+)
+
+// TryAcquireLease takes or renews the named lease for the holder. It returns true
+// when the holder owns the lease after the call, false when another holder has an unexpired lease.
+func (h *HeadersDb) TryAcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl).UnixMilli()
+
+	res, err := h.db.ExecContext(ctx, h.db.Rebind(sqlAcquireLease), name, holder, expiresAt, now.UnixMilli())
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to acquire lease %s", name)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to acquire lease %s", name)
+	}
+
+	return affected == 1, nil
+}
+
+// ReleaseLease removes the named lease if it is owned by the holder.
+func (h *HeadersDb) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := h.db.ExecContext(ctx, h.db.Rebind(sqlReleaseLease), name, holder); err != nil {
+		return errors.Wrapf(err, "failed to release lease %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// sqliteIncrementalVacuum is the value of PRAGMA auto_vacuum of a database file which frees pages incrementally.
+const sqliteIncrementalVacuum = 2
+
+var (
+	sqliteMaintenance = []string{
+		`PRAGMA incremental_vacuum`,
+		`ANALYZE`,
+	}
+
+	// sqliteSwitchingMaintenance rebuilds a file created before auto_vacuum=INCREMENTAL was set on the connection,
+	// which switches the file to it, so later runs reclaim free pages incrementally.
+	sqliteSwitchingMaintenance = []string{
+		`VACUUM`,
+		`ANALYZE`,
+	}
+
+	postgresMaintenance = []string{
+		`VACUUM (ANALYZE)`,
+	}
+)
+
+// Maintain vacuums the database and refreshes statistics used by the query planner.
+func (h *HeadersDb) Maintain(ctx context.Context) error {
+	statements, err := h.maintenanceStatements(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statements {
+		if _, err := h.db.ExecContext(ctx, s); err != nil {
+			return errors.Wrapf(err, "failed to run %s", s)
+		}
+	}
+	return nil
+}
+
+func (h *HeadersDb) maintenanceStatements(ctx context.Context) ([]string, error) {
+	if h.db.DriverName() == "postgres" {
+		return postgresMaintenance, nil
+	}
+
+	var autoVacuum int
+	if err := h.db.GetContext(ctx, &autoVacuum, `PRAGMA auto_vacuum`); err != nil {
+		return nil, errors.Wrap(err, "failed to read auto_vacuum mode")
+	}
+	if autoVacuum != sqliteIncrementalVacuum {
+		return sqliteSwitchingMaintenance, nil
+	}
+	return sqliteMaintenance, nil
+}
@@ -0,0 +1,176 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	sqlInsertOutboxEvent = `
+	INSERT INTO notification_outbox(id, header_hash, operation, event, latest_only, claimed_until, created_at)
+	VALUES(:id, :header_hash, :operation, :event, :latest_only, :claimed_until, :created_at)
+	`
+
+	sqlReserveOutboxIDs = `
+	UPDATE notification_outbox_sequence
+	SET last_id = last_id + ?
+	`
+
+	sqlSelectLastOutboxID = `
+	SELECT last_id
+	FROM notification_outbox_sequence
+	`
+
+	sqlSelectUnclaimedOutboxEvents = `
+	SELECT id, header_hash, operation, event, latest_only, claimed_until, created_at
+	FROM notification_outbox
+	WHERE claimed_until <= ?
+	ORDER BY id
+	LIMIT ?
+	`
+
+	sqlClaimOutboxEvent = `
+	UPDATE notification_outbox
+	SET claimed_until = ?
+	WHERE id = ? AND claimed_until = ?
+	`
+
+	sqlDeleteOutboxEvents = `
+	DELETE FROM notification_outbox
+	WHERE id IN (?)
+	`
+)
+
+// CreateMultipleWithEvents method will add multiple new records into db together with state changes of the stored ones
+// and events about them in one transaction, so a reorg is never stored without its header and events.
+// Events are stored only with the headers which were actually inserted, so a header received again doesn't emit them twice.
+// They get IDs from the sequence in the database, which is locked until the commit, so the IDs follow the order of commits
+// also when instances share the database.
+func (h *HeadersDb) CreateMultipleWithEvents(ctx context.Context, headers []dto.DbBlockHeader, changes []dto.DbStateChange, events []*dto.DbOutboxEvent) error {
+	byHeader := make(map[string][]*dto.DbOutboxEvent, len(events))
+	for _, e := range events {
+		byHeader[e.HeaderHash] = append(byHeader[e.HeaderHash], e)
+	}
+
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		for _, c := range changes {
+			if len(c.Hashes) == 0 {
+				continue
+			}
+			query, args, err := sqlx.In(sqlUpdateState, c.State, dto.ParseDbHashes(c.Hashes))
+			if err != nil {
+				return errors.Wrapf(err, "failed to update headers state to %s", c.State)
+			}
+			if _, err := tx.ExecContext(ctx, h.db.Rebind(query), args...); err != nil {
+				return errors.Wrapf(err, "failed to update headers state to %s", c.State)
+			}
+		}
+
+		var stored []*dto.DbOutboxEvent
+		for _, record := range headers {
+			res, err := tx.NamedExecContext(ctx, sqlInsertHeader, record)
+			if err != nil {
+				return errors.Wrap(err, "failed to insert header")
+			}
+			inserted, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if inserted == 0 {
+				continue
+			}
+			stored = append(stored, byHeader[record.Hash.String()]...)
+		}
+
+		if len(stored) > 0 {
+			if _, err := tx.ExecContext(ctx, h.db.Rebind(sqlReserveOutboxIDs), len(stored)); err != nil {
+				return errors.Wrap(err, "failed to reserve outbox event ids")
+			}
+			var lastID int64
+			if err := tx.GetContext(ctx, &lastID, sqlSelectLastOutboxID); err != nil {
+				return errors.Wrap(err, "failed to reserve outbox event ids")
+			}
+			firstID := lastID - int64(len(stored)) + 1
+			for i, e := range stored {
+				e.ID = firstID + int64(i)
+				if _, err := tx.NamedExecContext(ctx, sqlInsertOutboxEvent, *e); err != nil {
+					return errors.Wrapf(err, "failed to store event of header %s", e.HeaderHash)
+				}
+			}
+		}
+
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
+}
+
+// ClaimOutboxEvents method will claim at most limit events not claimed at the given time until the other given time, oldest first.
+// It returns only the events it claimed, skipping the ones claimed by someone else since they were read.
+func (h *HeadersDb) ClaimOutboxEvents(ctx context.Context, now, until time.Time, limit int) ([]*dto.DbOutboxEvent, error) {
+	var claimed []*dto.DbOutboxEvent
+	err := h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		var events []*dto.DbOutboxEvent
+		if err := tx.SelectContext(ctx, &events, h.db.Rebind(sqlSelectUnclaimedOutboxEvents), now.UnixMilli(), limit); err != nil {
+			return err
+		}
+
+		claimed = make([]*dto.DbOutboxEvent, 0, len(events))
+		for _, e := range events {
+			res, err := tx.ExecContext(ctx, h.db.Rebind(sqlClaimOutboxEvent), until.UnixMilli(), e.ID, e.ClaimedUntil)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected == 1 {
+				e.ClaimedUntil = until.UnixMilli()
+				claimed = append(claimed, e)
+			}
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to claim outbox events")
+	}
+
+	return claimed, nil
+}
+
+// DeleteOutboxEvents method will remove the events from the outbox.
+func (h *HeadersDb) DeleteOutboxEvents(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(sqlDeleteOutboxEvents, ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare deleting outbox events")
+	}
+
+	return h.retryOnBusy(ctx, func() error {
+		_, err := h.db.ExecContext(ctx, h.db.Rebind(query), args...)
+		return errors.Wrap(err, "failed to delete outbox events")
+	})
+}
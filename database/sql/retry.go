@@ -0,0 +1,64 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	defaultBusyRetries = 5
+	defaultBusyBackoff = 50 * time.Millisecond
+	defaultStreamChunk = 1000
+)
+
+// HeadersDbOpt is an option which modifies HeadersDb created by NewHeadersDb.
+type HeadersDbOpt func(*HeadersDb)
+
+// WithBusyRetry sets how many times a write is retried when the database is locked
+// and the initial backoff between attempts, which doubles with every retry.
+func WithBusyRetry(retries int, backoff time.Duration) HeadersDbOpt {
+	return func(h *HeadersDb) {
+		h.busyRetries = retries
+		h.busyBackoff = backoff
+	}
+}
+
+// WithStreamChunk sets how many headers are read by one query when they're streamed.
+func WithStreamChunk(size int) HeadersDbOpt {
+	return func(h *HeadersDb) {
+		h.streamChunk = size
+	}
+}
+
+// retryOnBusy runs the write fn again, with exponential backoff, as long as it fails
+// because sqlite is busy with another write and the retries limit is not reached.
+func (h *HeadersDb) retryOnBusy(ctx context.Context, fn func() error) error {
+	backoff := h.busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= h.busyRetries || !isBusy(err) {
+			return err
+		}
+
+		h.log.Debug().Msgf("database is locked, retrying in %s (attempt %d of %d)", backoff, attempt+1, h.busyRetries)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return strings.Contains(err.Error(), "database is locked")
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog"
+)
+
+func TestRetryOnBusy(t *testing.T) {
+	log := zerolog.Nop()
+
+	t.Run("retries until the write succeeds", func(t *testing.T) {
+		// given
+		h := NewHeadersDb(nil, &log, WithBusyRetry(3, time.Millisecond))
+		attempts := 0
+
+		// when
+		err := h.retryOnBusy(context.Background(), func() error {
+			attempts++
+			if attempts < 3 {
+				return bhserrors.ErrCreateWebhook.Wrap(sqlite3.Error{Code: sqlite3.ErrBusy})
+			}
+			return nil
+		})
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, attempts, 3)
+	})
+
+	t.Run("gives up after the retries limit", func(t *testing.T) {
+		// given
+		h := NewHeadersDb(nil, &log, WithBusyRetry(2, time.Millisecond))
+		attempts := 0
+
+		// when
+		err := h.retryOnBusy(context.Background(), func() error {
+			attempts++
+			return sqlite3.Error{Code: sqlite3.ErrLocked}
+		})
+
+		// then
+		assert.Equal(t, isBusy(err), true)
+		assert.Equal(t, attempts, 3)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		// given
+		h := NewHeadersDb(nil, &log, WithBusyRetry(3, time.Millisecond))
+		attempts := 0
+
+		// when
+		err := h.retryOnBusy(context.Background(), func() error {
+			attempts++
+			return errors.New("constraint failed")
+		})
+
+		// then
+		assert.IsError(t, err, "constraint failed")
+		assert.Equal(t, attempts, 1)
+	})
+}
@@ -29,23 +29,25 @@ const (
 
 // CreateToken method will add new record into db.
 func (h *HeadersDb) CreateToken(ctx context.Context, token *dto.DbToken) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
 
-	if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertToken), *token); err != nil {
-		return bhserrors.ErrCreateToken.Wrap(err)
-	}
+		if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertToken), *token); err != nil {
+			return bhserrors.ErrCreateToken.Wrap(err)
+		}
 
-	if err = tx.Commit(); err != nil {
-		return bhserrors.ErrCreateToken.Wrap(err)
-	}
+		if err = tx.Commit(); err != nil {
+			return bhserrors.ErrCreateToken.Wrap(err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // GetTokenByValue method will search and return token by value.
@@ -59,22 +61,24 @@ func (h *HeadersDb) GetTokenByValue(ctx context.Context, token string) (*dto.DbT
 
 // DeleteToken method will delete token from db.
 func (h *HeadersDb) DeleteToken(ctx context.Context, token string) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
 
-	if _, err = tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteToken), map[string]interface{}{"token": token}); err != nil {
-		return bhserrors.ErrDeleteToken.Wrap(err)
-	}
+		if _, err = tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteToken), map[string]interface{}{"token": token}); err != nil {
+			return bhserrors.ErrDeleteToken.Wrap(err)
+		}
 
-	if err = tx.Commit(); err != nil {
-		return bhserrors.ErrDeleteToken.Wrap(err)
+		if err = tx.Commit(); err != nil {
+			return bhserrors.ErrDeleteToken.Wrap(err)
 
-	}
+		}
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,96 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/pkg/errors"
+)
+
+const (
+	sqlInsertWebhookDelivery = `
+	INSERT INTO webhook_deliveries(id, url, event, attempts, next_attempt_at, created_at)
+	VALUES(:id, :url, :event, :attempts, :next_attempt_at, :created_at)
+	`
+
+	sqlSelectDueWebhookDeliveries = `
+	SELECT id, url, event, attempts, next_attempt_at, created_at
+	FROM webhook_deliveries
+	WHERE next_attempt_at <= ?
+	ORDER BY created_at
+	LIMIT ?
+	`
+
+	sqlClaimWebhookDelivery = `
+	UPDATE webhook_deliveries
+	SET attempts = attempts + 1, next_attempt_at = ?
+	WHERE id = ? AND attempts = ?
+	`
+
+	sqlDeleteWebhookDelivery = `
+	DELETE FROM webhook_deliveries
+	WHERE id = ?
+	`
+)
+
+// CreateWebhookDeliveries method will add deliveries of an event into the outbox in one transaction.
+func (h *HeadersDb) CreateWebhookDeliveries(ctx context.Context, deliveries []*dto.DbWebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		for _, d := range deliveries {
+			if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertWebhookDelivery), *d); err != nil {
+				return errors.Wrapf(err, "failed to store delivery to webhook %s", d.URL)
+			}
+		}
+
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
+}
+
+// GetDueWebhookDeliveries method will return at most limit deliveries which should be attempted until given time, oldest first.
+func (h *HeadersDb) GetDueWebhookDeliveries(ctx context.Context, until time.Time, limit int) ([]*dto.DbWebhookDelivery, error) {
+	var deliveries []*dto.DbWebhookDelivery
+	if err := h.db.SelectContext(ctx, &deliveries, h.db.Rebind(sqlSelectDueWebhookDeliveries), until.UnixMilli(), limit); err != nil {
+		return nil, errors.Wrap(err, "failed to get due webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery method will count an attempt of the delivery and postpone the next one to given time.
+// It returns false when the delivery was already attempted by someone else since it was read, or doesn't exist anymore.
+func (h *HeadersDb) ClaimWebhookDelivery(ctx context.Context, id string, attempts int, next time.Time) (bool, error) {
+	var affected int64
+	err := h.retryOnBusy(ctx, func() error {
+		res, err := h.db.ExecContext(ctx, h.db.Rebind(sqlClaimWebhookDelivery), next.UnixMilli(), id, attempts)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to claim webhook delivery %s", id)
+	}
+
+	return affected == 1, nil
+}
+
+// DeleteWebhookDelivery method will remove the delivery from the outbox.
+func (h *HeadersDb) DeleteWebhookDelivery(ctx context.Context, id string) error {
+	return h.retryOnBusy(ctx, func() error {
+		_, err := h.db.ExecContext(ctx, h.db.Rebind(sqlDeleteWebhookDelivery), id)
+		return errors.Wrapf(err, "failed to delete webhook delivery %s", id)
+	})
+}
@@ -41,23 +41,25 @@ const (
 
 // CreateWebhook method will add new webhook into db.
 func (h *HeadersDb) CreateWebhook(ctx context.Context, rWebhook *dto.DbWebhook) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertWebhook), *rWebhook); err != nil {
-		return bhserrors.ErrCreateWebhook.Wrap(err)
-	}
-
-	if err = tx.Commit(); err != nil {
-		return bhserrors.ErrCreateWebhook.Wrap(err)
-	}
-
-	return nil
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		if _, err := tx.NamedExecContext(ctx, h.db.Rebind(sqlInsertWebhook), *rWebhook); err != nil {
+			return bhserrors.ErrCreateWebhook.Wrap(err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return bhserrors.ErrCreateWebhook.Wrap(err)
+		}
+
+		return nil
+	})
 }
 
 // GetWebhookByURL method will search and return webhook by url.
@@ -82,44 +84,48 @@ func (h *HeadersDb) GetAllWebhooks(ctx context.Context) ([]*dto.DbWebhook, error
 
 // DeleteWebhookByURL method will delete webhook by url from db.
 func (h *HeadersDb) DeleteWebhookByURL(ctx context.Context, url string) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	params := map[string]interface{}{"url": url}
-
-	if _, err = tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteWebhookByURL), params); err != nil {
-		return bhserrors.ErrDeleteWebhook.Wrap(err)
-	}
-
-	if err = tx.Commit(); err != nil {
-		return bhserrors.ErrDeleteWebhook.Wrap(err)
-	}
-
-	return nil
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		params := map[string]interface{}{"url": url}
+
+		if _, err = tx.NamedExecContext(ctx, h.db.Rebind(sqlDeleteWebhookByURL), params); err != nil {
+			return bhserrors.ErrDeleteWebhook.Wrap(err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return bhserrors.ErrDeleteWebhook.Wrap(err)
+		}
+
+		return nil
+	})
 }
 
 // UpdateWebhook method will update webhook in db.
 func (h *HeadersDb) UpdateWebhook(ctx context.Context, url string, lastEmitTimestamp time.Time, lastEmitStatus string, errorsCount int, active bool) error {
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	query, args, err := sqlx.In(sqlUpdateWebhook, lastEmitStatus, lastEmitTimestamp, errorsCount, active, url)
-	if err != nil {
-		return errors.Wrapf(err, "failed to update webhook with url %s", url)
-	}
-	if _, err := tx.ExecContext(ctx, h.db.Rebind(query), args...); err != nil {
-		return errors.Wrapf(err, "failed to update webhook with name %s", url)
-	}
-
-	return errors.Wrap(tx.Commit(), "failed to commit tx")
+	return h.retryOnBusy(ctx, func() error {
+		tx, err := h.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		query, args, err := sqlx.In(sqlUpdateWebhook, lastEmitStatus, lastEmitTimestamp, errorsCount, active, url)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update webhook with url %s", url)
+		}
+		if _, err := tx.ExecContext(ctx, h.db.Rebind(query), args...); err != nil {
+			return errors.Wrapf(err, "failed to update webhook with name %s", url)
+		}
+
+		return errors.Wrap(tx.Commit(), "failed to commit tx")
+	})
 }
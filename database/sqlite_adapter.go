@@ -1,12 +1,9 @@
 package database
 
 import (
-	"context"
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
-	"os"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/database/sql"
@@ -35,8 +32,14 @@ type sqLitePragmaValues struct {
 const sqliteDriverName = "sqlite3"
 const sqliteBatchSize = 500
 
+const sqlSQLiteInsertImportedHeader = `
+	INSERT INTO %s(hash, height, version, merkleroot, nonce, bits, header_state, chainwork, previous_block, timestamp, cumulated_work)
+	VALUES(:hash, :height, :version, :merkleroot, :nonce, :bits, :header_state, :chainwork, :previous_block, :timestamp, :cumulated_work)
+	ON CONFLICT DO NOTHING`
+
 func (a *sqLiteAdapter) connect(cfg *config.DbConfig) error {
-	dsn := fmt.Sprintf("file:%s?_foreign_keys=true&pooling=true", cfg.SQLite.FilePath)
+	// Incremental auto vacuum lets the scheduled maintenance reclaim free pages without rebuilding the whole file.
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=true&_auto_vacuum=incremental&pooling=true", cfg.SQLite.FilePath)
 	db, err := sqlx.Open(sqliteDriverName, dsn)
 	if err != nil {
 		return err
@@ -75,7 +78,7 @@ func (a *sqLiteAdapter) getDBx() *sqlx.DB {
 	return a.db
 }
 
-func (a *sqLiteAdapter) importHeaders(inputFile *os.File, log *zerolog.Logger) (affectedRows int, err error) {
+func (a *sqLiteAdapter) importHeaders(reader recordReader, _ *zerolog.Logger) (affectedRows int, err error) {
 	// prepare db to bulk insterts
 	restorePragmas, err := modifySqLitePragmas(a.db)
 	if err != nil {
@@ -97,26 +100,18 @@ func (a *sqLiteAdapter) importHeaders(inputFile *os.File, log *zerolog.Logger) (
 		}
 	}()
 
-	// Read from the beginning of the file
-	if _, err = inputFile.Seek(0, 0); err != nil {
-		return
-	}
-
-	reader := csv.NewReader(inputFile)
-	_, err = reader.Read() // Skipping the column headers line
-	if err != nil {
-		return
-	}
-
-	repo := sql.NewHeadersDb(a.db, log)
+	return a.importHeadersInto(reader, sql.HeadersTableName)
+}
 
+// importHeadersInto inserts headers read from the reader into the table in batches.
+func (a *sqLiteAdapter) importHeadersInto(reader recordReader, table string) (affectedRows int, err error) {
 	previousBlockHash := chainhash.Hash{}.String()
 	var cumulatedChainWork string
 	rowIndex := 0
 	guard := 0
 
 	for {
-		rowIndex, previousBlockHash, cumulatedChainWork, err = a.insertHeaders(reader, repo, sqliteBatchSize, previousBlockHash, cumulatedChainWork, rowIndex)
+		rowIndex, previousBlockHash, cumulatedChainWork, err = a.insertHeaders(reader, table, sqliteBatchSize, previousBlockHash, cumulatedChainWork, rowIndex)
 		if err != nil {
 			affectedRows = rowIndex
 			return
@@ -199,7 +194,7 @@ func (a *sqLiteAdapter) dropTableIndexes(table string) (func() error, error) {
 	return dropIndexes(a.db, &q)
 }
 
-func (a *sqLiteAdapter) insertHeaders(reader *csv.Reader, repo *sql.HeadersDb, batchSize int, previousBlockHash string, cumulatedLastBlockChainWork string, rowIndex int) (lastRowIndex int, lastBlockHash string, cumulatedChainwork string, err error) {
+func (a *sqLiteAdapter) insertHeaders(reader recordReader, table string, batchSize int, previousBlockHash string, cumulatedLastBlockChainWork string, rowIndex int) (lastRowIndex int, lastBlockHash string, cumulatedChainwork string, err error) {
 	lastRowIndex = rowIndex
 	lastBlockHash = previousBlockHash
 	batch := make([]dto.DbBlockHeader, 0, batchSize)
@@ -227,13 +222,29 @@ func (a *sqLiteAdapter) insertHeaders(reader *csv.Reader, repo *sql.HeadersDb, b
 		batch = append(batch, *block)
 
 		cumulatedChainwork = block.CumulatedWork
-		lastBlockHash = block.Hash
+		lastBlockHash = block.Hash.String()
 		lastRowIndex++
 	}
 
-	if err = repo.CreateMultiple(context.Background(), batch); err != nil {
-		return
+	err = a.insertBatch(table, batch)
+	return
+}
+
+func (a *sqLiteAdapter) insertBatch(table string, batch []dto.DbBlockHeader) error {
+	tx, err := a.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := fmt.Sprintf(sqlSQLiteInsertImportedHeader, table)
+	for _, record := range batch {
+		if _, err := tx.NamedExec(query, record); err != nil {
+			return fmt.Errorf("failed to insert header: %w", err)
+		}
 	}
 
-	return
+	return tx.Commit()
 }
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/database/sql"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+	"github.com/rs/zerolog"
+)
+
+func TestSQLiteWebhookDeliveries(t *testing.T) {
+	// given
+	adapter := migratedSQLite(t)
+	log := zerolog.Nop()
+	repo := sql.NewHeadersDb(adapter.db, &log)
+	ctx := context.Background()
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	err := repo.CreateWebhookDeliveries(ctx, []*dto.DbWebhookDelivery{
+		{ID: "due", URL: "http://first", Event: `{"height":1}`, NextAttemptAt: now.UnixMilli(), CreatedAt: now.UnixMilli()},
+		{ID: "later", URL: "http://second", Event: `{"height":1}`, NextAttemptAt: now.Add(time.Minute).UnixMilli(), CreatedAt: now.UnixMilli()},
+	})
+	assert.NoError(t, err)
+
+	// when
+	due, err := repo.GetDueWebhookDeliveries(ctx, now, 10)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(due), 1)
+	assert.Equal(t, due[0].ID, "due")
+	assert.Equal(t, due[0].Event, `{"height":1}`)
+
+	// when
+	claimed, err := repo.ClaimWebhookDelivery(ctx, "due", 0, now.Add(time.Minute))
+	assert.NoError(t, err)
+	claimedAgain, err := repo.ClaimWebhookDelivery(ctx, "due", 0, now.Add(time.Minute))
+	assert.NoError(t, err)
+
+	// then
+	assert.Equal(t, claimed, true)
+	assert.Equal(t, claimedAgain, false)
+	due, err = repo.GetDueWebhookDeliveries(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, len(due), 0)
+
+	// when
+	assert.NoError(t, repo.DeleteWebhookDelivery(ctx, "due"))
+	due, err = repo.GetDueWebhookDeliveries(ctx, now.Add(time.Minute), 10)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(due), 1)
+	assert.Equal(t, due[0].ID, "later")
+}
@@ -0,0 +1,31 @@
+package domains
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ArchivedBranch represents a stale or orphan branch moved from the headers to the archive.
+type ArchivedBranch struct {
+	TipHash   string
+	TipHeight int32
+	// Length is the number of archived headers, headers shared with a branch which isn't archived are kept.
+	Length int
+	// State is the state of the tip, STALE or ORPHAN.
+	State        HeaderState
+	TipTimestamp time.Time
+	ArchivedAt   time.Time
+	// Headers are the archived headers from the tip back, nil when only the branches are listed.
+	Headers []*BlockHeader
+	// Events are the events about the headers which were still in the outbox, nil when only the branches are listed.
+	Events []*ArchivedEvent
+}
+
+// ArchivedEvent is an event about an archived header, which was moved from the outbox together with the header.
+type ArchivedEvent struct {
+	HeaderHash string
+	Operation  string
+	// Event is the event as it would be delivered.
+	Event     json.RawMessage
+	CreatedAt time.Time
+}
@@ -1,6 +1,10 @@
 package domains
 
-import "math/big"
+import (
+	"math/big"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+)
 
 // calcWork calculate chainwork for header based on given bits.
 func calcWork(bits uint32) *big.Int {
@@ -67,6 +71,19 @@ func (ccw *CumulatedChainWork) Add(cw *ChainWork) CumulatedChainWork {
 	return CumulatedChainWork(*sum)
 }
 
+// maxDifficultyTarget is the target of difficulty 1, which is the target of the genesis block.
+var maxDifficultyTarget = new(big.Float).SetInt(CompactToBig(0x1d00ffff))
+
+// Difficulty returns how many times harder is the target represented by bits than the target of difficulty 1.
+func Difficulty(bits uint32) float64 {
+	target := CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return 0
+	}
+	difficulty, _ := new(big.Float).Quo(maxDifficultyTarget, new(big.Float).SetInt(target)).Float64()
+	return difficulty
+}
+
 // CompactToBig  takes a compact representation of a 256-bit number used in Bitcoin,
 // converts it to a big.Int, and returns the resulting big.Int value.
 func CompactToBig(compact uint32) *big.Int {
@@ -96,3 +113,51 @@ func CompactToBig(compact uint32) *big.Int {
 
 	return bn
 }
+
+// BigToCompact converts a whole number to the compact representation used in Bitcoin, it's the inverse of CompactToBig.
+func BigToCompact(n *big.Int) uint32 {
+	// No need to do any work if it's zero.
+	if n.Sign() == 0 {
+		return 0
+	}
+
+	// Since the base for the exponent is 256, the exponent can be treated
+	// as the number of bytes.  So, shift the number right or left
+	// accordingly.  This is equivalent to:
+	// mantissa = mantissa / 256^(exponent-3)
+	var mantissa uint32
+	exponent := uint(len(n.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(n.Bits()[0])
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		// Use a copy to avoid modifying the caller's original number.
+		tn := new(big.Int).Set(n)
+		mantissa = uint32(tn.Rsh(tn, 8*(exponent-3)).Bits()[0])
+	}
+
+	// When the mantissa already has the sign bit set, the number is too
+	// large to fit into the available 23-bits, so divide the number by 256
+	// and increment the exponent accordingly.
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+
+	// Pack the exponent, sign bit, and mantissa into an unsigned 32-bit
+	// int and return it.
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+	return compact
+}
+
+// HashToBig interprets the hash as a little endian number, as proof of work compares it with the target.
+func HashToBig(hash *chainhash.Hash) *big.Int {
+	buf := *hash
+	for i := 0; i < chainhash.HashSize/2; i++ {
+		buf[i], buf[chainhash.HashSize-1-i] = buf[chainhash.HashSize-1-i], buf[i]
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
@@ -141,3 +141,25 @@ func bigIntFromHex(hex string) *big.Int {
 	i.SetString(hex, 16)
 	return i
 }
+
+func TestDifficulty(t *testing.T) {
+	testCases := map[uint32]string{
+		0x1d00ffff: "1.0000",
+		0x1b04864c: "14484.1624",
+		0x1a05db8b: "2864140.5078",
+	}
+	for bits, expectedDifficulty := range testCases {
+		t.Run(fmt.Sprintf("should evaluate bits %x as difficulty %s", bits, expectedDifficulty), func(t *testing.T) {
+			assert.Equal(t, fmt.Sprintf("%.4f", Difficulty(bits)), expectedDifficulty)
+		})
+	}
+}
+
+func TestBigToCompact(t *testing.T) {
+	testCases := []uint32{0, 0x1d00ffff, 0x1b04864c, 0x1a05db8b, 0x207fffff, 0x03123456}
+	for _, bits := range testCases {
+		t.Run(fmt.Sprintf("should convert target of bits %x back to the same bits", bits), func(t *testing.T) {
+			assert.Equal(t, BigToCompact(CompactToBig(bits)), bits)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package domains
+
+const (
+	// EventDiskSpaceLow event type for the free disk space falling below the threshold, writes are paused then.
+	EventDiskSpaceLow HeaderEventType = "DISK_SPACE_LOW"
+	// EventDiskSpaceRecovered event type for the free disk space getting above the threshold again.
+	EventDiskSpaceRecovered HeaderEventType = "DISK_SPACE_RECOVERED"
+)
+
+// DiskUsage represents sizes of the database and the log directory and the free space of the disk holding them.
+type DiskUsage struct {
+	// DatabaseBytes is the size of the SQLite database files, 0 for PostgreSQL.
+	DatabaseBytes int64
+	// LogsBytes is the size of the log directory, 0 when none is configured.
+	LogsBytes int64
+	// FreeBytes is the space available on the disk.
+	FreeBytes uint64
+	// MinFreeBytes is the free space below which writes are paused.
+	MinFreeBytes uint64
+	// LowSpace is true when writes are paused because of the low free space.
+	LowSpace bool
+}
+
+// DiskSpaceEvent represents data of an alert about the free disk space.
+type DiskSpaceEvent struct {
+	Operation    HeaderEventType `json:"operation"`
+	FreeBytes    uint64          `json:"freeBytes"`
+	MinFreeBytes uint64          `json:"minFreeBytes"`
+}
@@ -0,0 +1,27 @@
+package domains
+
+import "math/big"
+
+// Fork is a known stale chain branching off the longest chain.
+type Fork struct {
+	// Tip is the last header of the fork.
+	Tip *BlockHeader
+	// ForkPoint is the header of the longest chain the fork branches off, nil for an orphan fork.
+	ForkPoint *BlockHeader
+	// Length is the number of headers of the fork above the fork point, or the number of known headers of an orphan fork.
+	Length int32
+	// Orphan is set when a header of the fork is missing, so the fork can't be traced back to the longest chain.
+	Orphan bool
+	// WorkDeficit is the cumulated work the fork lacks to match the longest chain.
+	WorkDeficit *big.Int
+}
+
+// BranchDiff holds headers of two branches which are not shared by them.
+type BranchDiff struct {
+	// ForkPoint is the last header shared by both branches.
+	ForkPoint *BlockHeader
+	// Branch holds headers above the fork point leading to the first tip, ordered by height.
+	Branch []*BlockHeader
+	// OtherBranch holds headers above the fork point leading to the second tip, ordered by height.
+	OtherBranch []*BlockHeader
+}
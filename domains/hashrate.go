@@ -0,0 +1,36 @@
+package domains
+
+import (
+	"math/big"
+	"time"
+)
+
+// Hashrate is the network hashrate estimated from work done between two headers of the longest chain.
+type Hashrate struct {
+	From *BlockHeader
+	To   *BlockHeader
+	// Work is the number of hashes needed to produce headers after From up to To.
+	Work    *big.Int
+	Elapsed time.Duration
+	// HashesPerSecond is zero when timestamps of the headers don't allow to estimate it.
+	HashesPerSecond *big.Int
+}
+
+// EstimateHashrate divides work done between the headers by time elapsed between them.
+func EstimateHashrate(from, to *BlockHeader) *Hashrate {
+	work := new(big.Int).Sub(to.CumulatedWork, from.CumulatedWork)
+	elapsed := to.Timestamp.Sub(from.Timestamp)
+
+	hashrate := big.NewInt(0)
+	if seconds := int64(elapsed / time.Second); seconds > 0 {
+		hashrate.Div(work, big.NewInt(seconds))
+	}
+
+	return &Hashrate{
+		From:            from,
+		To:              to,
+		Work:            work,
+		Elapsed:         elapsed,
+		HashesPerSecond: hashrate,
+	}
+}
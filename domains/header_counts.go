@@ -0,0 +1,21 @@
+package domains
+
+import "time"
+
+// HeaderStateCounts are numbers of headers in each state.
+type HeaderStateCounts map[HeaderState]int
+
+// HeadersCountWindow are numbers of headers in each state added within the window before now.
+type HeadersCountWindow struct {
+	Window time.Duration
+	// FromHeight is the height of the first header of the longest chain created within the window,
+	// headers on this height and above are counted.
+	FromHeight int32
+	Counts     HeaderStateCounts
+}
+
+// HeadersCounts are numbers of all stored headers in each state and of headers in recent windows.
+type HeadersCounts struct {
+	Total   HeaderStateCounts
+	Windows []HeadersCountWindow
+}
@@ -11,12 +11,34 @@ type HeaderEventType string
 const (
 	// EventHeaderAdded event type for header added.
 	EventHeaderAdded HeaderEventType = "ADD"
+	// EventHeaderConfirmed event type for header reaching the required number of confirmations.
+	EventHeaderConfirmed HeaderEventType = "CONFIRMED"
+	// EventTip event type for header becoming the tip of the longest chain.
+	EventTip HeaderEventType = "TIP"
+	// EventReorg event type for the longest chain replaced by another chain.
+	EventReorg HeaderEventType = "REORG"
 )
 
 // HeaderEvent represents header event data.
 type HeaderEvent struct {
-	Operation HeaderEventType     `json:"operation"`
-	Header    *HeaderEventDetails `json:"header"`
+	Operation     HeaderEventType     `json:"operation"`
+	Header        *HeaderEventDetails `json:"header"`
+	Confirmations int                 `json:"confirmations,omitempty"`
+	// EventID is the ID of the event delivered from the notification outbox, receivers deduplicate redelivered events by it.
+	EventID int64 `json:"eventId,omitempty"`
+}
+
+// ReorgEvent represents data of an event about the longest chain replaced by another chain.
+type ReorgEvent struct {
+	Operation HeaderEventType `json:"operation"`
+	// ForkHeight is the height of the last header shared by both chains.
+	ForkHeight int32 `json:"forkHeight"`
+	// Depth is the number of headers which are no longer in the longest chain.
+	Depth  int                 `json:"depth"`
+	OldTip *HeaderEventDetails `json:"oldTip"`
+	NewTip *HeaderEventDetails `json:"newTip"`
+	// EventID is the ID of the event delivered from the notification outbox, receivers deduplicate redelivered events by it.
+	EventID int64 `json:"eventId,omitempty"`
 }
 
 // HeaderEventDetails defines a header as a detailed part of an event.
@@ -36,16 +58,40 @@ type HeaderEventDetails struct {
 func HeaderAdded(h *BlockHeader) *HeaderEvent {
 	return &HeaderEvent{
 		Operation: EventHeaderAdded,
-		Header: &HeaderEventDetails{
-			Height:        h.Height,
-			Hash:          h.Hash.String(),
-			Version:       h.Version,
-			MerkleRoot:    h.MerkleRoot.String(),
-			Timestamp:     h.Timestamp,
-			Nonce:         h.Nonce,
-			State:         h.State,
-			CumulatedWork: h.CumulatedWork,
-			PreviousBlock: h.PreviousBlock.String(),
-		},
+		Header:    headerEventDetails(h),
+	}
+}
+
+// HeaderConfirmed makes event about block header reaching given number of confirmations.
+func HeaderConfirmed(h *BlockHeader, confirmations int) *HeaderEvent {
+	return &HeaderEvent{
+		Operation:     EventHeaderConfirmed,
+		Header:        headerEventDetails(h),
+		Confirmations: confirmations,
+	}
+}
+
+// Reorg makes event about the longest chain ending with oldTip replaced by the chain ending with newTip.
+func Reorg(oldTip, newTip *BlockHeader, forkHeight int32) *ReorgEvent {
+	return &ReorgEvent{
+		Operation:  EventReorg,
+		ForkHeight: forkHeight,
+		Depth:      int(oldTip.Height - forkHeight),
+		OldTip:     headerEventDetails(oldTip),
+		NewTip:     headerEventDetails(newTip),
+	}
+}
+
+func headerEventDetails(h *BlockHeader) *HeaderEventDetails {
+	return &HeaderEventDetails{
+		Height:        h.Height,
+		Hash:          h.Hash.String(),
+		Version:       h.Version,
+		MerkleRoot:    h.MerkleRoot.String(),
+		Timestamp:     h.Timestamp,
+		Nonce:         h.Nonce,
+		State:         h.State,
+		CumulatedWork: h.CumulatedWork,
+		PreviousBlock: h.PreviousBlock.String(),
 	}
 }
@@ -25,6 +25,84 @@ func (s *HeaderState) String() string {
 	return string(*s)
 }
 
+// ParseHeaderState parses the state of headers which are stored in the database.
+func ParseHeaderState(s string) (HeaderState, bool) {
+	switch state := HeaderState(s); state {
+	case Orphan, Stale, LongestChain:
+		return state, true
+	default:
+		return "", false
+	}
+}
+
+// StateChange changes the state of the stored headers with given hashes.
+type StateChange struct {
+	Hashes []chainhash.Hash
+	State  HeaderState
+}
+
+// HeadersSortField is a field by which listed headers are ordered.
+type HeadersSortField string
+
+const (
+	// SortByHeight orders headers by their height.
+	SortByHeight HeadersSortField = "height"
+	// SortByTimestamp orders headers by their creation timestamp.
+	SortByTimestamp HeadersSortField = "timestamp"
+	// SortByChainWork orders headers by the cumulated chain work of their chain.
+	SortByChainWork HeadersSortField = "chainwork"
+)
+
+// ParseHeadersSortField parses the field by which listed headers can be ordered.
+func ParseHeadersSortField(s string) (HeadersSortField, bool) {
+	switch field := HeadersSortField(s); field {
+	case SortByHeight, SortByTimestamp, SortByChainWork:
+		return field, true
+	default:
+		return "", false
+	}
+}
+
+// HeadersQuery narrows down and orders headers returned by header listing.
+type HeadersQuery struct {
+	// States limits headers to given states, headers in any state are returned when empty.
+	States []HeaderState
+	// SortBy is the field by which headers are ordered, when empty headers are returned in the order of storage.
+	SortBy HeadersSortField
+	// Descending reverses the order given by SortBy.
+	Descending bool
+}
+
+// Matches checks if the header satisfies the query.
+func (q HeadersQuery) Matches(h *BlockHeader) bool {
+	if len(q.States) == 0 {
+		return true
+	}
+	for _, s := range q.States {
+		if h.State == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Less checks if header a precedes header b in the order given by the query.
+func (q HeadersQuery) Less(a, b *BlockHeader) bool {
+	var cmp int
+	switch q.SortBy {
+	case SortByHeight:
+		cmp = int(a.Height) - int(b.Height)
+	case SortByTimestamp:
+		cmp = a.Timestamp.Compare(b.Timestamp)
+	case SortByChainWork:
+		cmp = a.CumulatedWork.Cmp(b.CumulatedWork)
+	}
+	if q.Descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
 // BlockHeader defines a single block header, used in SPV validations.
 type BlockHeader struct {
 	Height        int32          `json:"-"`
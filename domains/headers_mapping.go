@@ -0,0 +1,11 @@
+package domains
+
+// HeadersMapping maps requested hashes and heights of the longest chain to stored headers.
+type HeadersMapping struct {
+	// ByHash are headers with the requested hashes in the order of the request.
+	ByHash []*BlockHeader
+	// ByHeight are headers of the longest chain at the requested heights in the order of the request.
+	ByHeight       []*BlockHeader
+	UnknownHashes  []string
+	UnknownHeights []int32
+}
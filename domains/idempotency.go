@@ -0,0 +1,22 @@
+package domains
+
+import "time"
+
+// IdempotencyKey represents a mutating request sent with the Idempotency-Key header and its stored response.
+type IdempotencyKey struct {
+	Key string
+	// RequestHash identifies the request the key was used with, the key can't be reused for another one.
+	RequestHash string
+	// StatusCode of the stored response, 0 while the request is still processed.
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	// ExpiresAt is the time after which the response isn't replayed anymore and the key can be used again.
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the response of the request is stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
@@ -0,0 +1,31 @@
+package domains
+
+import (
+	"slices"
+	"time"
+)
+
+// MedianTimeBlocks is the number of headers, ending with the header itself, whose timestamps make its median time past.
+const MedianTimeBlocks = 11
+
+// HeaderAtTime is the header of the longest chain active at some moment.
+type HeaderAtTime struct {
+	Header *BlockHeader
+	// MedianTimePast is the median time past of the header, which is not after the moment.
+	MedianTimePast time.Time
+}
+
+// MedianTimePast returns the median of timestamps of the headers. Unlike timestamps of single headers,
+// the median time past never decreases along the chain.
+func MedianTimePast(headers []*BlockHeader) time.Time {
+	if len(headers) == 0 {
+		return time.Time{}
+	}
+
+	timestamps := make([]time.Time, 0, len(headers))
+	for _, h := range headers {
+		timestamps = append(timestamps, h.Timestamp)
+	}
+	slices.SortFunc(timestamps, func(a, b time.Time) int { return a.Compare(b) })
+	return timestamps[len(timestamps)/2]
+}
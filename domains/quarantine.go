@@ -0,0 +1,11 @@
+package domains
+
+import "time"
+
+// QuarantinedHeader is a received header which wasn't added because its timestamp broke the validation rules.
+type QuarantinedHeader struct {
+	Header *BlockHeader
+	// Reason describes the broken rule.
+	Reason        string
+	QuarantinedAt time.Time
+}
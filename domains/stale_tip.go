@@ -0,0 +1,18 @@
+package domains
+
+const (
+	// EventChainStale event type for the longest chain not being extended for longer than the threshold.
+	EventChainStale HeaderEventType = "CHAIN_STALE"
+	// EventChainResumed event type for the longest chain being extended again after it was stale.
+	EventChainResumed HeaderEventType = "CHAIN_RESUMED"
+)
+
+// StaleTipEvent represents data of an alert about the tip of the longest chain getting old.
+type StaleTipEvent struct {
+	Operation HeaderEventType `json:"operation"`
+	Tip       *ReferenceTip   `json:"tip"`
+	// SecondsSinceLastHeader is the number of seconds elapsed since the timestamp of the tip.
+	SecondsSinceLastHeader int64 `json:"secondsSinceLastHeader"`
+	// Threshold is the number of seconds after which the chain is considered stale.
+	Threshold int64 `json:"threshold"`
+}
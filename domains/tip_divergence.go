@@ -0,0 +1,47 @@
+package domains
+
+import "time"
+
+const (
+	// EventTipDiverged event type for our tip diverging from the tip of an external source.
+	EventTipDiverged HeaderEventType = "TIP_DIVERGED"
+	// EventTipConverged event type for our tip getting back in line with the tip of an external source.
+	EventTipConverged HeaderEventType = "TIP_CONVERGED"
+)
+
+// ReferenceTip is the tip reported by an external source.
+type ReferenceTip struct {
+	Height    int32     `json:"height"`
+	Hash      string    `json:"hash"`
+	Timestamp time.Time `json:"creationTimestamp"`
+}
+
+// TipDivergenceEvent represents data of an alert about our tip compared with the tip of an external source.
+type TipDivergenceEvent struct {
+	Operation HeaderEventType `json:"operation"`
+	// Source is the name of the external source.
+	Source    string        `json:"source"`
+	LocalTip  *ReferenceTip `json:"localTip"`
+	SourceTip *ReferenceTip `json:"sourceTip"`
+	// Blocks is the number of blocks the source is ahead of us, negative when it is behind.
+	Blocks int32 `json:"blocks"`
+	// TimeLag is the number of seconds the source tip is newer than ours, negative when it is older.
+	TimeLag int64 `json:"timeLag"`
+}
+
+// TipDivergence makes event comparing our tip with the tip of the source.
+func TipDivergence(diverged bool, source string, local, reference *ReferenceTip) *TipDivergenceEvent {
+	operation := EventTipConverged
+	if diverged {
+		operation = EventTipDiverged
+	}
+
+	return &TipDivergenceEvent{
+		Operation: operation,
+		Source:    source,
+		LocalTip:  local,
+		SourceTip: reference,
+		Blocks:    reference.Height - local.Height,
+		TimeLag:   int64(reference.Timestamp.Sub(local.Timestamp).Seconds()),
+	}
+}
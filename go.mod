@@ -24,11 +24,15 @@ require (
 	github.com/centrifugal/centrifuge v0.34.0
 	github.com/centrifugal/centrifuge-go v0.10.3
 	github.com/dchest/uniuri v1.2.0
+	github.com/eclipse/paho.mqtt.golang v1.5.0
 	github.com/kinbiko/jsonassert v1.2.0
 	github.com/lib/pq v1.10.9
+	github.com/nats-io/nats.go v1.39.1
 	github.com/prometheus/client_golang v1.20.5
+	github.com/redis/rueidis v1.0.53
 	github.com/stretchr/testify v1.10.0
 	github.com/testcontainers/testcontainers-go v0.35.0
+	google.golang.org/protobuf v1.36.3
 )
 
 require (
@@ -73,6 +77,8 @@ require (
 	github.com/moby/term v0.5.0 // indirect
 	github.com/morikuni/aec v1.0.0 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/nats-io/nkeys v0.4.9 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
 	github.com/opencontainers/go-digest v1.0.0 // indirect
 	github.com/opencontainers/image-spec v1.1.0 // indirect
 	github.com/planetscale/vtprotobuf v0.6.0 // indirect
@@ -81,7 +87,6 @@ require (
 	github.com/prometheus/client_model v0.6.1 // indirect
 	github.com/prometheus/common v0.55.0 // indirect
 	github.com/prometheus/procfs v0.15.1 // indirect
-	github.com/redis/rueidis v1.0.53 // indirect
 	github.com/sagikazarmark/locafero v0.6.0 // indirect
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
 	github.com/segmentio/asm v1.2.0 // indirect
@@ -149,7 +154,6 @@ require (
 	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 	golang.org/x/tools v0.24.0 // indirect
-	google.golang.org/protobuf v1.36.3 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,31 @@
+// Package broker provides publishers of header events to message brokers.
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+)
+
+// Publisher publishes messages to a message broker.
+type Publisher interface {
+	// Publish publishes the message to the topic, key identifies the message within the topic.
+	Publish(ctx context.Context, topic, key string, data []byte) error
+	// Close releases the connection to the broker.
+	Close()
+}
+
+// NewPublisher creates Publisher of the configured broker.
+func NewPublisher(cfg *config.PublisherConfig) (Publisher, error) {
+	switch cfg.Broker {
+	case config.EventBrokerNATS:
+		return NewNATSPublisher(cfg)
+	case config.EventBrokerKafka:
+		return NewKafkaPublisher(cfg), nil
+	case config.EventBrokerMQTT:
+		return NewMQTTPublisher(cfg)
+	default:
+		return nil, fmt.Errorf("broker: unsupported broker %s", cfg.Broker)
+	}
+}
@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+)
+
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaPublisher is a Publisher producing messages to Kafka through the Kafka REST proxy.
+type KafkaPublisher struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewKafkaPublisher creates KafkaPublisher using the configured Kafka REST proxy.
+func NewKafkaPublisher(cfg *config.PublisherConfig) *KafkaPublisher {
+	return &KafkaPublisher{
+		client:  &http.Client{},
+		baseURL: strings.TrimSuffix(cfg.Kafka.RestProxyURL, "/"),
+	}
+}
+
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Publish produces the message to the topic, the key selects the partition of the message.
+func (p *KafkaPublisher) Publish(ctx context.Context, topic, key string, data []byte) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: key, Value: data}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka: cannot produce to %s: %w", topic, err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("kafka: cannot produce to %s: %s %s", topic, res.Status, msg)
+	}
+	return nil
+}
+
+// Close does nothing, there is no connection kept to the REST proxy.
+func (p *KafkaPublisher) Close() {}
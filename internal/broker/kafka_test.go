@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestKafkaPublisherProducesRecord(t *testing.T) {
+	// given
+	var path, contentType string
+	var records kafkaRecords
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&records)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	p := NewKafkaPublisher(&config.PublisherConfig{Kafka: config.KafkaConfig{RestProxyURL: proxy.URL + "/"}})
+
+	// when
+	err := p.Publish(context.Background(), "headers.added", "hash", []byte(`{"operation":"ADD"}`))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, path, "/topics/headers.added")
+	assert.Equal(t, contentType, kafkaContentType)
+	assert.Equal(t, len(records.Records), 1)
+	assert.Equal(t, records.Records[0].Key, "hash")
+	assert.Equal(t, string(records.Records[0].Value), `{"operation":"ADD"}`)
+}
+
+func TestKafkaPublisherFailure(t *testing.T) {
+	// given
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer proxy.Close()
+
+	p := NewKafkaPublisher(&config.PublisherConfig{Kafka: config.KafkaConfig{RestProxyURL: proxy.URL}})
+
+	// when
+	err := p.Publish(context.Background(), "headers.added", "hash", []byte(`{}`))
+
+	// then
+	if err == nil {
+		t.Fatal("Expect an error when the proxy rejects the record")
+	}
+}
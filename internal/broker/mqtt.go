@@ -0,0 +1,61 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	mqttConnectTimeout = 10 * time.Second
+	// mqttDisconnectQuiesce is the time in milliseconds given to in-flight messages on disconnect.
+	mqttDisconnectQuiesce = 250
+)
+
+// MQTTPublisher is a Publisher publishing messages to an MQTT broker.
+type MQTTPublisher struct {
+	client   mqtt.Client
+	qos      byte
+	tipTopic string
+}
+
+// NewMQTTPublisher connects to the configured MQTT broker.
+func NewMQTTPublisher(cfg *config.PublisherConfig) (*MQTTPublisher, error) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.MQTT.URL).
+		SetClientID(cfg.MQTT.ClientID).
+		SetUsername(cfg.MQTT.Username).
+		SetPassword(cfg.MQTT.Password).
+		SetAutoReconnect(true)
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(mqttConnectTimeout) {
+		return nil, fmt.Errorf("mqtt: cannot connect to %s: timeout", cfg.MQTT.URL)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("mqtt: cannot connect to %s: %w", cfg.MQTT.URL, err)
+	}
+
+	return &MQTTPublisher{client: client, qos: cfg.MQTT.QoS, tipTopic: cfg.Topics.Tip}, nil
+}
+
+// Publish publishes the message with the configured QoS, MQTT has no keys so the key is not used.
+// Messages on the tip topic are retained by the broker.
+func (p *MQTTPublisher) Publish(ctx context.Context, topic, _ string, data []byte) error {
+	token := p.client.Publish(topic, p.qos, topic == p.tipTopic, data)
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close disconnects from the broker.
+func (p *MQTTPublisher) Close() {
+	p.client.Disconnect(mqttDisconnectQuiesce)
+}
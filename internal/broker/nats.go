@@ -0,0 +1,75 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const natsTimeout = 10 * time.Second
+
+// NATSPublisher is a Publisher publishing messages to NATS JetStream.
+type NATSPublisher struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+// NewNATSPublisher connects to the configured NATS server and creates the stream for the topics when it is configured.
+func NewNATSPublisher(cfg *config.PublisherConfig) (*NATSPublisher, error) {
+	opts := []nats.Option{nats.Name(config.ApplicationName), nats.MaxReconnects(-1)}
+	if cfg.NATS.Token != "" {
+		opts = append(opts, nats.Token(cfg.NATS.Token))
+	}
+
+	conn, err := nats.Connect(cfg.NATS.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats: cannot connect: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: cannot use jetstream: %w", err)
+	}
+
+	if cfg.NATS.Stream != "" {
+		if err := createStream(js, cfg); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return &NATSPublisher{conn: conn, js: js}, nil
+}
+
+// createStream creates the stream capturing all topics, a stream which already exists is left as it is.
+func createStream(js jetstream.JetStream, cfg *config.PublisherConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsTimeout)
+	defer cancel()
+
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.NATS.Stream,
+		Subjects: []string{cfg.Topics.Header, cfg.Topics.Reorg, cfg.Topics.Confirmation},
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("nats: cannot create stream %s: %w", cfg.NATS.Stream, err)
+	}
+	return nil
+}
+
+// Publish publishes the message and waits for JetStream to store it.
+// The message id is derived from the topic and the key, so JetStream drops duplicates.
+func (p *NATSPublisher) Publish(ctx context.Context, topic, key string, data []byte) error {
+	_, err := p.js.Publish(ctx, topic, data, jetstream.WithMsgID(topic+":"+key))
+	return err
+}
+
+// Close drains the connection.
+func (p *NATSPublisher) Close() {
+	_ = p.conn.Drain()
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	// given
+	c := NewLRU[string, int](2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	// when
+	_, _ = c.Get("a")
+	c.Put("c", 3)
+
+	// then
+	_, okA := c.Get("a")
+	_, okB := c.Get("b")
+	_, okC := c.Get("c")
+	assert.Equal(t, okA, true)
+	assert.Equal(t, okB, false)
+	assert.Equal(t, okC, true)
+	assert.Equal(t, c.Len(), 2)
+}
+
+func TestLRURemoveAndPurge(t *testing.T) {
+	// given
+	c := NewLRU[int, string](10)
+	c.Put(1, "one")
+	c.Put(2, "two")
+	c.Put(3, "three")
+
+	// when
+	c.Remove(1)
+
+	// then
+	_, ok := c.Get(1)
+	assert.Equal(t, ok, false)
+	assert.Equal(t, c.Len(), 2)
+
+	// when
+	c.Purge()
+
+	// then
+	assert.Equal(t, c.Len(), 0)
+}
+
+func TestLRUWithZeroCapacityStoresNothing(t *testing.T) {
+	// given
+	c := NewLRU[int, int](0)
+
+	// when
+	c.Put(1, 1)
+
+	// then
+	_, ok := c.Get(1)
+	assert.Equal(t, ok, false)
+}
@@ -0,0 +1,13 @@
+package cache
+
+import "time"
+
+// Shared is a cache of serialized values shared between instances of the service.
+type Shared interface {
+	// Get returns the value stored under the key.
+	Get(key string) ([]byte, bool)
+	// Set stores the value under the key for the ttl.
+	Set(key string, value []byte, ttl time.Duration)
+	// Invalidate drops all stored values.
+	Invalidate()
+}
@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 30, 15, 0, time.UTC) // Wednesday
+
+	testCases := map[string]struct {
+		expr string
+		next time.Time
+	}{
+		"every minute": {
+			expr: "* * * * *",
+			next: time.Date(2024, time.January, 31, 10, 31, 0, 0, time.UTC),
+		},
+		"daily": {
+			expr: "0 3 * * *",
+			next: time.Date(2024, time.February, 1, 3, 0, 0, 0, time.UTC),
+		},
+		"every 15 minutes": {
+			expr: "*/15 * * * *",
+			next: time.Date(2024, time.January, 31, 10, 45, 0, 0, time.UTC),
+		},
+		"list and range of hours": {
+			expr: "0 8,12-14 * * *",
+			next: time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC),
+		},
+		"weekly on sunday": {
+			expr: "30 2 * * 0",
+			next: time.Date(2024, time.February, 4, 2, 30, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			expr: "0 0 15 * 5",
+			next: time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC),
+		},
+		"leap day": {
+			expr: "0 0 29 2 *",
+			next: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			s, err := Parse(tc.expr)
+			assert.NoError(t, err)
+
+			// when
+			next := s.Next(from)
+
+			// then
+			assert.Equal(t, next, tc.next)
+		})
+	}
+}
+
+func TestNextNeverMatching(t *testing.T) {
+	// given
+	s, err := Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+
+	// when
+	next := s.Next(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	// then
+	assert.Equal(t, next.IsZero(), true)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		t.Run(expr, func(t *testing.T) {
+			// when
+			_, err := Parse(expr)
+
+			// then
+			if err == nil {
+				t.Fatalf("expected error for %q", expr)
+			}
+		})
+	}
+}
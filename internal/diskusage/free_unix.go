@@ -0,0 +1,14 @@
+//go:build !windows
+
+package diskusage
+
+import "syscall"
+
+// Free returns the number of bytes available to unprivileged users on the filesystem holding the path.
+func Free(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil //nolint:unconvert // the field types differ between platforms
+}
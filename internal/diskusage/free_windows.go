@@ -0,0 +1,10 @@
+//go:build windows
+
+package diskusage
+
+import "errors"
+
+// Free isn't supported on windows, where the service isn't deployed.
+func Free(_ string) (uint64, error) {
+	return 0, errors.New("measuring free disk space is not supported on windows")
+}
@@ -0,0 +1,35 @@
+// Package diskusage provides measuring sizes of files and the free space of the disk they're stored on.
+package diskusage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Size returns the total size of the given files and directories, including the files within directories.
+// Paths which don't exist are skipped, e.g. the SQLite WAL file after a checkpoint.
+func Size(paths ...string) (int64, error) {
+	var total int64
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+			return nil
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, err
+		}
+	}
+	return total, nil
+}
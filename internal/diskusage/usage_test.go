@@ -0,0 +1,36 @@
+package diskusage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestSize(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	db := filepath.Join(dir, "blockheaders.db")
+	logs := filepath.Join(dir, "logs")
+	assert.NoError(t, os.WriteFile(db, make([]byte, 100), 0o600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(logs, "old"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(logs, "bhs.log"), make([]byte, 20), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(logs, "old", "bhs.log.1"), make([]byte, 30), 0o600))
+
+	// when
+	size, err := Size(db, db+"-wal", logs)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, size, int64(150))
+}
+
+func TestFree(t *testing.T) {
+	// when
+	free, err := Free(t.TempDir())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, free > 0, true)
+}
@@ -0,0 +1,104 @@
+// Package redis provides the integration with Redis shared by instances of the service.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/redis/rueidis"
+)
+
+// redisTimeout bounds every call to Redis, a slow Redis should fall back to the database rather than stall requests.
+const redisTimeout = time.Second
+
+// Cache is a cache.Shared kept in Redis.
+//
+// Keys are stored under a generation number, so all of them are invalidated
+// at once by incrementing it, without having to look them up.
+type Cache struct {
+	client rueidis.Client
+	prefix string
+}
+
+// NewCache connects to the configured Redis server and returns Cache using it.
+func NewCache(cfg *config.RedisConfig) (*Cache, error) {
+	opt := rueidis.ClientOption{
+		InitAddress:  []string{cfg.Address},
+		Password:     cfg.Password,
+		SelectDB:     cfg.DB,
+		DisableCache: true,
+	}
+	if strings.Contains(cfg.Address, "://") {
+		var err error
+		if opt, err = rueidis.ParseURL(cfg.Address); err != nil {
+			return nil, fmt.Errorf("redis: invalid address: %w", err)
+		}
+		opt.DisableCache = true
+	}
+
+	client, err := rueidis.NewClient(opt)
+	if err != nil {
+		return nil, fmt.Errorf("redis: cannot connect: %w", err)
+	}
+	return &Cache{client: client, prefix: cfg.Prefix + ":cache:"}, nil
+}
+
+// Get returns the value stored under the key, Redis errors are treated as a miss.
+func (r *Cache) Get(key string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return nil, false
+	}
+
+	value, err := r.client.Do(ctx, r.client.B().Get().Key(r.key(gen, key)).Build()).AsBytes()
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores the value under the key for the ttl, Redis errors are ignored.
+func (r *Cache) Set(key string, value []byte, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return
+	}
+
+	cmd := r.client.B().Set().Key(r.key(gen, key)).Value(rueidis.BinaryString(value)).PxMilliseconds(ttl.Milliseconds()).Build()
+	_ = r.client.Do(ctx, cmd).Error()
+}
+
+// Invalidate drops all stored values by moving to the next generation, old keys expire on their own.
+func (r *Cache) Invalidate() {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	_ = r.client.Do(ctx, r.client.B().Incr().Key(r.prefix+"generation").Build()).Error()
+}
+
+// Close closes the connection to Redis.
+func (r *Cache) Close() {
+	r.client.Close()
+}
+
+func (r *Cache) generation(ctx context.Context) (int64, error) {
+	gen, err := r.client.Do(ctx, r.client.B().Get().Key(r.prefix+"generation").Build()).AsInt64()
+	if rueidis.IsRedisNil(err) {
+		return 0, nil
+	}
+	return gen, err
+}
+
+func (r *Cache) key(gen int64, key string) string {
+	return r.prefix + strconv.FormatInt(gen, 10) + ":" + key
+}
@@ -120,7 +120,7 @@ func NewTestBlockHeaderService(t *testing.T, ops ...bhsOpt) (*TestBlockHeaderSer
 	server.ApplyConfiguration(ws.SetupEntrypoint)
 
 	hs.Notifier.AddChannel(hs.Webhooks)
-	hs.Notifier.AddChannel(notification.NewWebsocketChannel(&testLog, ws.Publisher(), cfg.Websocket))
+	hs.Notifier.AddChannel(notification.NewWebsocketChannel(&testLog, ws.Publisher(), repo.Headers, cfg.Websocket))
 
 	if err := ws.Start(); err != nil {
 		panic(fmt.Sprintf("cannot start websocket server because of an error: %v", err))
@@ -12,3 +12,9 @@ func (w *When) NewHeaderReceived(bs domains.BlockHeaderSource) error {
 	_, err := w.services.Chains.Add(bs)
 	return err
 }
+
+// NewHeaderReceivedFromPeer simulates the header received by the p2p sync.
+func (w *When) NewHeaderReceivedFromPeer(bs domains.BlockHeaderSource) error {
+	_, err := w.services.P2PChains.Add(bs)
+	return err
+}
@@ -1,8 +1,14 @@
 package testapp
 
 import (
+	"path/filepath"
+	"time"
+
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/cache"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/rs/zerolog"
 )
 
 // WithAPIAuthorizationDisabled allows to not use authorization in Block Headers Service.
@@ -12,6 +18,49 @@ func WithAPIAuthorizationDisabled() ConfigOpt {
 	}
 }
 
+// WithTipCacheTTL enables caching of tip responses for the given duration.
+func WithTipCacheTTL(ttl time.Duration) ConfigOpt {
+	return func(c *config.AppConfig) {
+		c.HTTP.TipCacheTTL = ttl
+	}
+}
+
+// WithBulkHeadersLimit sets the maximum number of headers returned by a bulk headers request.
+func WithBulkHeadersLimit(limit int) ConfigOpt {
+	return func(c *config.AppConfig) {
+		c.HTTP.BulkHeadersLimit = limit
+	}
+}
+
+// WithDiskGuard stores the SQLite database in the given directory, measured by the disk guard,
+// and pauses writes when less than minFreeMB is free there.
+func WithDiskGuard(dir string, minFreeMB uint64) ConfigOpt {
+	return func(c *config.AppConfig) {
+		c.Db.SQLite.FilePath = filepath.Join(dir, "blockheaders.db")
+		c.DiskGuard.MinFreeMB = minFreeMB
+	}
+}
+
+// WithSharedCache makes the services use the given cache shared between instances.
+func WithSharedCache(c cache.Shared) ServicesOpt {
+	return func(s *service.Services) {
+		s.SharedCache = c
+	}
+}
+
+// WithFollower makes the service a follower of a leader elector which isn't started, so it never becomes the leader.
+func WithFollower() ServicesOpt {
+	return func(s *service.Services) {
+		log := zerolog.Nop()
+		s.Leader = service.NewLeaderElector(testrepository.NewLeaseTestRepository(), &config.HAConfig{
+			Enabled:       true,
+			InstanceID:    "follower",
+			LeaseTTL:      time.Minute,
+			RenewInterval: time.Minute,
+		}, &log)
+	}
+}
+
 // WithLongestChain fills the initialized header test repository with 4 additional blocks.
 func WithLongestChain() RepoOpt {
 	return func(r *testrepository.TestRepositories) {
@@ -0,0 +1,50 @@
+package testrepository
+
+import (
+	"slices"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// ArchiveTestRepository in memory ArchiveRepository representation for unit testing.
+type ArchiveTestRepository struct {
+	branches []*domains.ArchivedBranch
+}
+
+// NewArchiveTestRepository constructor for ArchiveTestRepository.
+func NewArchiveTestRepository() *ArchiveTestRepository {
+	return &ArchiveTestRepository{}
+}
+
+func (r *ArchiveTestRepository) add(b *domains.ArchivedBranch) {
+	r.branches = append(r.branches, b)
+}
+
+// GetArchivedBranches returns at most limit archived branches from the highest tip, without their headers.
+func (r *ArchiveTestRepository) GetArchivedBranches(limit int) ([]*domains.ArchivedBranch, error) {
+	sorted := slices.Clone(r.branches)
+	slices.SortStableFunc(sorted, func(a, b *domains.ArchivedBranch) int {
+		return int(b.TipHeight - a.TipHeight)
+	})
+
+	branches := make([]*domains.ArchivedBranch, 0, min(limit, len(sorted)))
+	for _, b := range sorted[:min(limit, len(sorted))] {
+		listed := *b
+		listed.Headers = nil
+		branches = append(branches, &listed)
+	}
+	return branches, nil
+}
+
+// GetArchivedBranchOf returns the archived branch with the header of given hash.
+func (r *ArchiveTestRepository) GetArchivedBranchOf(hash string) (*domains.ArchivedBranch, error) {
+	for _, b := range r.branches {
+		for _, h := range b.Headers {
+			if h.Hash.String() == hash {
+				return b, nil
+			}
+		}
+	}
+	return nil, bhserrors.ErrArchivedHeaderNotFound
+}
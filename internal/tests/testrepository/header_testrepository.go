@@ -9,11 +9,16 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/notification"
 )
 
 // HeaderTestRepository in memory HeadersRepository representation for unit testing.
 type HeaderTestRepository struct {
 	db *[]domains.BlockHeader
+	// Outbox keeps the events added together with headers.
+	Outbox *OutboxTestRepository
+	// Archive keeps the branches moved out of db.
+	Archive *ArchiveTestRepository
 }
 
 // AddHeaderToDatabase adds new header to db.
@@ -34,6 +39,25 @@ func (r *HeaderTestRepository) AddMultipleHeadersToDatabase(headers []domains.Bl
 	return nil
 }
 
+// AddHeadersWithEvents changes states of the headers, adds new headers to db and events of the added ones to the outbox.
+func (r *HeaderTestRepository) AddHeadersWithEvents(headers []domains.BlockHeader, changes []domains.StateChange, events []*notification.OutboxEvent) error {
+	for _, c := range changes {
+		_ = r.UpdateState(c.Hashes, c.State)
+	}
+	for _, header := range headers {
+		if findHeader(header.Hash.String(), *r.db) != nil {
+			continue
+		}
+		*r.db = append(*r.db, header)
+		for _, e := range events {
+			if e.HeaderHash == header.Hash.String() {
+				r.Outbox.Add(e)
+			}
+		}
+	}
+	return nil
+}
+
 // UpdateState changes state value to provided one for each of headers with provided hash.
 func (r *HeaderTestRepository) UpdateState(hs []chainhash.Hash, s domains.HeaderState) error {
 	for _, h := range hs {
@@ -46,6 +70,52 @@ func (r *HeaderTestRepository) UpdateState(hs []chainhash.Hash, s domains.Header
 	return nil
 }
 
+// DeleteHeaders removes headers with provided hashes.
+func (r *HeaderTestRepository) DeleteHeaders(hs []chainhash.Hash) (int, error) {
+	deleted := 0
+	kept := (*r.db)[:0]
+	for _, hdb := range *r.db {
+		if slices.Contains(hs, hdb.Hash) {
+			deleted++
+			continue
+		}
+		kept = append(kept, hdb)
+	}
+	*r.db = kept
+	return deleted, nil
+}
+
+// DeleteHeadersAboveHeight removes headers higher than provided height.
+func (r *HeaderTestRepository) DeleteHeadersAboveHeight(height int32) (int, error) {
+	before := len(*r.db)
+	*r.db = slices.DeleteFunc(*r.db, func(h domains.BlockHeader) bool {
+		return h.Height > height
+	})
+	return before - len(*r.db), nil
+}
+
+// ArchiveBranches removes headers of the branches from db and keeps the branches in the archive.
+func (r *HeaderTestRepository) ArchiveBranches(branches []*domains.ArchivedBranch) (int, error) {
+	var hashes []chainhash.Hash
+	for _, b := range branches {
+		archived := *b
+		// Headers may point into db, which is compacted by deleting them.
+		archived.Headers = make([]*domains.BlockHeader, 0, len(b.Headers))
+		for _, h := range b.Headers {
+			header := *h
+			archived.Headers = append(archived.Headers, &header)
+			hashes = append(hashes, h.Hash)
+		}
+		r.Archive.add(&archived)
+	}
+	return r.DeleteHeaders(hashes)
+}
+
+// ReplaceHeaders calls the swap function, which is expected to replace the headers itself.
+func (r *HeaderTestRepository) ReplaceHeaders(swap func() error) error {
+	return swap()
+}
+
 // GetHeaderByHeight returns header from db by given height.
 func (r *HeaderTestRepository) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
 	for _, header := range *r.db {
@@ -56,21 +126,42 @@ func (r *HeaderTestRepository) GetHeaderByHeight(height int32) (*domains.BlockHe
 	return nil, errors.New("could not find height")
 }
 
-// GetHeaderByHeightRange returns headers from db in specified height range.
-func (r *HeaderTestRepository) GetHeaderByHeightRange(from int, to int) ([]*domains.BlockHeader, error) {
+// GetHeaderByHeightRange returns headers from db in specified height range which satisfy the query.
+func (r *HeaderTestRepository) GetHeaderByHeightRange(from int, to int, query domains.HeadersQuery) ([]*domains.BlockHeader, error) {
+	filteredHeaders := r.headersInRange(from, to, query)
+
+	if len(filteredHeaders) > 0 {
+		return filteredHeaders, nil
+	}
+
+	return nil, bhserrors.ErrHeadersForGivenRangeNotFound
+}
+
+// StreamHeadersByHeightRange passes headers from db in specified height range which satisfy the query one by one to fn.
+func (r *HeaderTestRepository) StreamHeadersByHeightRange(from int, to int, query domains.HeadersQuery, fn func(*domains.BlockHeader) error) error {
+	for _, header := range r.headersInRange(from, to, query) {
+		if err := fn(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *HeaderTestRepository) headersInRange(from int, to int, query domains.HeadersQuery) []*domains.BlockHeader {
 	filteredHeaders := make([]*domains.BlockHeader, 0)
 
 	for i, header := range *r.db {
-		if header.Height >= int32(from) && header.Height <= int32(to) {
+		if header.Height >= int32(from) && header.Height <= int32(to) && query.Matches(&header) {
 			filteredHeaders = append(filteredHeaders, &(*r.db)[i])
 		}
 	}
 
-	if len(filteredHeaders) > 0 {
-		return filteredHeaders, nil
+	if query.SortBy != "" {
+		sort.SliceStable(filteredHeaders, func(i, j int) bool {
+			return query.Less(filteredHeaders[i], filteredHeaders[j])
+		})
 	}
-
-	return nil, bhserrors.ErrHeadersForGivenRangeNotFound
+	return filteredHeaders
 }
 
 // GetLongestChainHeadersFromHeight returns from db the headers from "longest chain" starting from given height.
@@ -189,7 +280,7 @@ func (r *HeaderTestRepository) GetAllTips() ([]*domains.BlockHeader, error) {
 func (r *HeaderTestRepository) GetChainBetweenTwoHashes(low string, high string) ([]*domains.BlockHeader, error) {
 	hLow := findHeader(low, *r.db)
 	hHigh := findHeader(high, *r.db)
-	headers, err := r.GetHeaderByHeightRange(int(hLow.Height), int(hHigh.Height))
+	headers, err := r.GetHeaderByHeightRange(int(hLow.Height), int(hHigh.Height), domains.HeadersQuery{})
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +446,56 @@ func (r *HeaderTestRepository) GetHeadersByHeightRange(from int, to int) ([]*dom
 	return filteredHeaders, nil
 }
 
+// GetHeadersByHashes returns the headers with given hashes, unknown hashes are skipped.
+func (r *HeaderTestRepository) GetHeadersByHashes(hashes []string) ([]*domains.BlockHeader, error) {
+	var headers []*domains.BlockHeader
+	for i, h := range *r.db {
+		if slices.Contains(hashes, h.Hash.String()) {
+			headers = append(headers, &(*r.db)[i])
+		}
+	}
+	return headers, nil
+}
+
+// GetLongestChainHeadersByHeights returns the headers of the longest chain at given heights, heights above the tip are skipped.
+func (r *HeaderTestRepository) GetLongestChainHeadersByHeights(heights []int32) ([]*domains.BlockHeader, error) {
+	var headers []*domains.BlockHeader
+	for i, h := range *r.db {
+		if h.State == domains.LongestChain && slices.Contains(heights, h.Height) {
+			headers = append(headers, &(*r.db)[i])
+		}
+	}
+	return headers, nil
+}
+
+// GetHeadersCountByState returns the number of headers in each state from given height.
+func (r *HeaderTestRepository) GetHeadersCountByState(fromHeight int32) (map[domains.HeaderState]int, error) {
+	counts := make(map[domains.HeaderState]int)
+	for _, h := range *r.db {
+		if h.Height >= fromHeight {
+			counts[h.State]++
+		}
+	}
+	return counts, nil
+}
+
+// GetSampledHeadersByHeightRange returns every interval-th header from db in specified height range.
+func (r *HeaderTestRepository) GetSampledHeadersByHeightRange(from int, to int, interval int) ([]*domains.BlockHeader, error) {
+	headers, err := r.GetHeadersByHeightRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	sampled := make([]*domains.BlockHeader, 0)
+	for _, h := range headers {
+		if (int(h.Height)-from)%interval == 0 {
+			sampled = append(sampled, h)
+		}
+	}
+	sort.SliceStable(sampled, func(i, j int) bool { return sampled[i].Height < sampled[j].Height })
+	return sampled, nil
+}
+
 // GetHeadersStopHeight returns height of hashstop header from db.
 func (r *HeaderTestRepository) GetHeadersStopHeight(hashStop string) (int, error) {
 	for i := len(*r.db) - 1; i >= 0; i-- {
@@ -403,6 +544,8 @@ func findHeader(hash string, headers []domains.BlockHeader) *domains.BlockHeader
 // NewHeadersTestRepository constructor for HeaderTestRepository.
 func NewHeadersTestRepository(db *[]domains.BlockHeader) *HeaderTestRepository {
 	return &HeaderTestRepository{
-		db: db,
+		db:      db,
+		Outbox:  NewOutboxTestRepository(),
+		Archive: NewArchiveTestRepository(),
 	}
 }
@@ -0,0 +1,65 @@
+package testrepository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// IdempotencyKeysTestRepository in memory IdempotencyKeysRepository representation for unit testing.
+type IdempotencyKeysTestRepository struct {
+	mu   sync.Mutex
+	keys map[string]domains.IdempotencyKey
+}
+
+// ClaimIdempotencyKey stores the key unless it's already stored and not expired, returning the stored one then.
+func (r *IdempotencyKeysTestRepository) ClaimIdempotencyKey(key *domains.IdempotencyKey, now time.Time) (*domains.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.keys[key.Key]; ok && stored.ExpiresAt.After(now) {
+		return &stored, nil
+	}
+	r.keys[key.Key] = *key
+	return nil, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request made with the key.
+func (r *IdempotencyKeysTestRepository) CompleteIdempotencyKey(key *domains.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.keys[key.Key]; ok {
+		stored.StatusCode, stored.ContentType, stored.Body = key.StatusCode, key.ContentType, key.Body
+		r.keys[key.Key] = stored
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey deletes the key.
+func (r *IdempotencyKeysTestRepository) DeleteIdempotencyKey(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, key)
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes keys expired at given time.
+func (r *IdempotencyKeysTestRepository) DeleteExpiredIdempotencyKeys(now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for k, stored := range r.keys {
+		if !stored.ExpiresAt.After(now) {
+			delete(r.keys, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// NewIdempotencyKeysTestRepository constructor for IdempotencyKeysTestRepository.
+func NewIdempotencyKeysTestRepository() *IdempotencyKeysTestRepository {
+	return &IdempotencyKeysTestRepository{keys: make(map[string]domains.IdempotencyKey)}
+}
@@ -0,0 +1,48 @@
+package testrepository
+
+import (
+	"errors"
+	"time"
+)
+
+// LeaseTestRepository in memory LeasesRepository representation for unit testing.
+type LeaseTestRepository struct {
+	holders map[string]string
+	expires map[string]time.Time
+	// Err, when set, is returned by TryAcquireLease to simulate database failures.
+	Err error
+}
+
+// TryAcquireLease takes or renews the lease with given name for the holder.
+func (r *LeaseTestRepository) TryAcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	if r.Err != nil {
+		return false, r.Err
+	}
+
+	now := time.Now()
+	if current, ok := r.holders[name]; ok && current != holder && r.expires[name].After(now) {
+		return false, nil
+	}
+
+	r.holders[name] = holder
+	r.expires[name] = now.Add(ttl)
+	return true, nil
+}
+
+// ReleaseLease gives up the lease with given name if it is owned by the holder.
+func (r *LeaseTestRepository) ReleaseLease(name, holder string) error {
+	if r.holders[name] != holder {
+		return errors.New("lease is not owned by holder")
+	}
+	delete(r.holders, name)
+	delete(r.expires, name)
+	return nil
+}
+
+// NewLeaseTestRepository constructor for LeaseTestRepository.
+func NewLeaseTestRepository() *LeaseTestRepository {
+	return &LeaseTestRepository{
+		holders: make(map[string]string),
+		expires: make(map[string]time.Time),
+	}
+}
@@ -0,0 +1,23 @@
+package testrepository
+
+// MaintenanceTestRepository in memory MaintenanceRepository representation for unit testing.
+type MaintenanceTestRepository struct {
+	// Runs is the number of maintenance runs.
+	Runs int
+	// Err, when set, is returned by Maintain to simulate database failures.
+	Err error
+}
+
+// Maintain records the maintenance run.
+func (r *MaintenanceTestRepository) Maintain() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	r.Runs++
+	return nil
+}
+
+// NewMaintenanceTestRepository constructor for MaintenanceTestRepository.
+func NewMaintenanceTestRepository() *MaintenanceTestRepository {
+	return &MaintenanceTestRepository{}
+}
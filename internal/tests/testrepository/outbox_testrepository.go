@@ -0,0 +1,73 @@
+package testrepository
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/notification"
+)
+
+// OutboxTestRepository in memory OutboxRepository representation for unit testing.
+type OutboxTestRepository struct {
+	mu      sync.Mutex
+	events  []*notification.OutboxEvent
+	claimed map[int64]time.Time
+	lastID  int64
+}
+
+// NewOutboxTestRepository constructor for OutboxTestRepository.
+func NewOutboxTestRepository() *OutboxTestRepository {
+	return &OutboxTestRepository{claimed: make(map[int64]time.Time)}
+}
+
+// Add stores the events in the outbox, giving them increasing IDs like the database does.
+func (r *OutboxTestRepository) Add(events ...*notification.OutboxEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range events {
+		r.lastID++
+		e.ID = r.lastID
+	}
+	r.events = append(r.events, events...)
+}
+
+// Events returns the events which weren't deleted yet, oldest first.
+func (r *OutboxTestRepository) Events() []*notification.OutboxEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// ClaimOutboxEvents claims at most limit events not claimed at the given time until the other given time.
+func (r *OutboxTestRepository) ClaimOutboxEvents(now time.Time, until time.Time, limit int) ([]*notification.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []*notification.OutboxEvent
+	for _, e := range r.events {
+		if len(claimed) == limit {
+			break
+		}
+		if r.claimed[e.ID].After(now) {
+			continue
+		}
+		r.claimed[e.ID] = until
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+// DeleteOutboxEvents removes the events with given ids.
+func (r *OutboxTestRepository) DeleteOutboxEvents(ids []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = slices.DeleteFunc(r.events, func(e *notification.OutboxEvent) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	for _, id := range ids {
+		delete(r.claimed, id)
+	}
+	return nil
+}
@@ -9,9 +9,12 @@ import (
 
 // TestRepositories is a struct used for testing block headers service repositories.
 type TestRepositories struct {
-	Headers  *HeaderTestRepository
-	Tokens   *TokensTestRepository
-	Webhooks *WebhooksTestRepository
+	Headers         *HeaderTestRepository
+	Tokens          *TokensTestRepository
+	Webhooks        *WebhooksTestRepository
+	Leases          *LeaseTestRepository
+	Maintenance     *MaintenanceTestRepository
+	IdempotencyKeys *IdempotencyKeysTestRepository
 }
 
 // NewTestRepositories creates repository.Repositories for unit testing usage.
@@ -27,17 +30,24 @@ func NewCleanTestRepositories() TestRepositories {
 	var tokensTable []domains.Token
 
 	return TestRepositories{
-		Headers:  NewHeadersTestRepository(&db),
-		Tokens:   NewTokensTestRepository(&tokensTable),
-		Webhooks: NewWebhooksTestRepository(&[]notification.Webhook{}),
+		Headers:         NewHeadersTestRepository(&db),
+		Tokens:          NewTokensTestRepository(&tokensTable),
+		Webhooks:        NewWebhooksTestRepository(&[]notification.Webhook{}),
+		Leases:          NewLeaseTestRepository(),
+		Maintenance:     NewMaintenanceTestRepository(),
+		IdempotencyKeys: NewIdempotencyKeysTestRepository(),
 	}
 }
 
 // ToDomainRepo creates a domain repository.Repositories struct to comply with block headers service structs.
 func (t *TestRepositories) ToDomainRepo() *repository.Repositories {
 	return &repository.Repositories{
-		Headers:  t.Headers,
-		Tokens:   t.Tokens,
-		Webhooks: t.Webhooks,
+		Headers:         t.Headers,
+		Tokens:          t.Tokens,
+		Webhooks:        t.Webhooks,
+		Leases:          t.Leases,
+		Maintenance:     t.Maintenance,
+		IdempotencyKeys: t.IdempotencyKeys,
+		Archive:         t.Headers.Archive,
 	}
 }
@@ -2,6 +2,9 @@ package testrepository
 
 import (
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -10,6 +13,9 @@ import (
 // WebhooksTestRepository in memory WebhooksRepository representation for unit testing.
 type WebhooksTestRepository struct {
 	db *[]notification.Webhook
+
+	mu         sync.Mutex
+	deliveries []notification.WebhookDelivery
 }
 
 // AddWebhookToDatabase adds new webhook to db.
@@ -39,20 +45,86 @@ func (r *WebhooksTestRepository) DeleteWebhookByURL(url string) error {
 }
 
 // GetWebhookByURL returns webhook from db by given url.
-func (r *WebhooksTestRepository) GetWebhookByURL(_ string) (*notification.Webhook, error) {
-	return nil, nil
+func (r *WebhooksTestRepository) GetWebhookByURL(url string) (*notification.Webhook, error) {
+	for i, w := range *r.db {
+		if w.URL == url {
+			return &(*r.db)[i], nil
+		}
+	}
+	return nil, bhserrors.ErrWebhookNotFound
 }
 
 // GetAllWebhooks returns all webhooks from db.
 func (r *WebhooksTestRepository) GetAllWebhooks() ([]*notification.Webhook, error) {
-	return nil, nil
+	webhooks := make([]*notification.Webhook, 0, len(*r.db))
+	for i := range *r.db {
+		webhooks = append(webhooks, &(*r.db)[i])
+	}
+	return webhooks, nil
 }
 
 // UpdateWebhook updates webhook in db.
-func (r *WebhooksTestRepository) UpdateWebhook(_ *notification.Webhook) error {
+func (r *WebhooksTestRepository) UpdateWebhook(webhook *notification.Webhook) error {
+	for i, w := range *r.db {
+		if w.URL == webhook.URL {
+			(*r.db)[i] = *webhook
+		}
+	}
+	return nil
+}
+
+// AddDeliveries stores pending deliveries of an event.
+func (r *WebhooksTestRepository) AddDeliveries(deliveries []*notification.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range deliveries {
+		r.deliveries = append(r.deliveries, *d)
+	}
 	return nil
 }
 
+// GetDueDeliveries returns at most limit deliveries which should be attempted until given time.
+func (r *WebhooksTestRepository) GetDueDeliveries(until time.Time, limit int) ([]*notification.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := make([]*notification.WebhookDelivery, 0)
+	for _, d := range r.deliveries {
+		if len(due) < limit && !d.NextAttemptAt.After(until) {
+			due = append(due, &d)
+		}
+	}
+	return due, nil
+}
+
+// ClaimDelivery counts an attempt of the delivery and postpones the next one, it returns false when it was claimed by someone else.
+func (r *WebhooksTestRepository) ClaimDelivery(delivery *notification.WebhookDelivery, next time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, d := range r.deliveries {
+		if d.ID == delivery.ID && d.Attempts == delivery.Attempts {
+			r.deliveries[i].Attempts++
+			r.deliveries[i].NextAttemptAt = next
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteDelivery deletes delivery by id.
+func (r *WebhooksTestRepository) DeleteDelivery(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d notification.WebhookDelivery) bool { return d.ID == id })
+	return nil
+}
+
+// Deliveries returns pending deliveries.
+func (r *WebhooksTestRepository) Deliveries() []notification.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.deliveries)
+}
+
 // NewWebhooksTestRepository constructor for WebhooksTestRepository.
 func NewWebhooksTestRepository(db *[]notification.Webhook) *WebhooksTestRepository {
 	return &WebhooksTestRepository{
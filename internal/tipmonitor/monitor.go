@@ -0,0 +1,119 @@
+// Package tipmonitor compares the tip of our longest chain with tips of external reference sources
+// and alerts when they diverge.
+package tipmonitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/rs/zerolog"
+)
+
+// TipProvider provides the tip of our longest chain.
+type TipProvider interface {
+	GetTip() *domains.BlockHeader
+}
+
+// Notifier delivers alert events.
+type Notifier interface {
+	Notify(event any)
+}
+
+// Monitor periodically compares our tip with the configured sources.
+type Monitor struct {
+	sources  []Source
+	tips     TipProvider
+	notifier Notifier
+	cfg      *config.TipMonitorConfig
+	log      zerolog.Logger
+
+	diverged map[string]bool
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor creates and returns Monitor comparing the tip with given sources.
+func NewMonitor(cfg *config.TipMonitorConfig, sources []Source, tips TipProvider, notifier Notifier, log *zerolog.Logger) *Monitor {
+	return &Monitor{
+		sources:  sources,
+		tips:     tips,
+		notifier: notifier,
+		cfg:      cfg,
+		log:      log.With().Str("service", "tip-monitor").Logger(),
+		diverged: make(map[string]bool),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start compares the tips in the background on the configured interval.
+func (m *Monitor) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.Check()
+			case <-m.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops comparing the tips and waits for a running comparison to finish.
+func (m *Monitor) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// Check compares our tip with every source, an alert is sent when a source starts or stops diverging.
+// Sources which can't be reached are skipped, so they don't trigger alerts on their own.
+func (m *Monitor) Check() {
+	tip := m.tips.GetTip()
+	if tip == nil {
+		return
+	}
+	local := &domains.ReferenceTip{Height: tip.Height, Hash: tip.Hash.String(), Timestamp: tip.Timestamp}
+
+	for _, s := range m.sources {
+		reference, err := s.Tip()
+		if err != nil {
+			m.log.Warn().Msgf("cannot get tip of %s: %v", s.Name(), err)
+			continue
+		}
+
+		blocks := reference.Height - local.Height
+		timeLag := reference.Timestamp.Sub(local.Timestamp)
+		diverged := abs(int64(blocks)) > int64(m.cfg.MaxBlocks) || abs(int64(timeLag)) > int64(m.cfg.MaxTimeLag)
+		metrics.SetTipDivergence(s.Name(), blocks, timeLag, diverged)
+
+		if diverged == m.diverged[s.Name()] {
+			continue
+		}
+		m.diverged[s.Name()] = diverged
+
+		if diverged {
+			m.log.Warn().Msgf("Tip diverged from %s by %d blocks and %s, local tip %s at %d, source tip %s at %d",
+				s.Name(), blocks, timeLag, local.Hash, local.Height, reference.Hash, reference.Height)
+		} else {
+			m.log.Info().Msgf("Tip is in line with %s again", s.Name())
+		}
+		m.notifier.Notify(domains.TipDivergence(diverged, s.Name(), local, reference))
+	}
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
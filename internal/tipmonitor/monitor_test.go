@@ -0,0 +1,127 @@
+package tipmonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+type fixedTip struct {
+	tip *domains.BlockHeader
+}
+
+func (f *fixedTip) GetTip() *domains.BlockHeader {
+	return f.tip
+}
+
+type fakeSource struct {
+	tip *domains.ReferenceTip
+	err error
+}
+
+func (s *fakeSource) Name() string {
+	return "fake"
+}
+
+func (s *fakeSource) Tip() (*domains.ReferenceTip, error) {
+	return s.tip, s.err
+}
+
+type events []any
+
+func (e *events) Notify(event any) {
+	*e = append(*e, event)
+}
+
+func TestCheckAlertsOnlyWhenDivergenceChanges(t *testing.T) {
+	// given
+	now := time.Now()
+	local := &fixedTip{tip: &domains.BlockHeader{Height: 100, Timestamp: now}}
+	source := &fakeSource{tip: &domains.ReferenceTip{Height: 102, Timestamp: now.Add(20 * time.Minute)}}
+	notified := &events{}
+	m := newTestMonitor(source, local, notified)
+
+	// when
+	m.Check()
+	source.tip = &domains.ReferenceTip{Height: 105, Hash: "ahead", Timestamp: now.Add(50 * time.Minute)}
+	m.Check()
+	m.Check()
+	source.err = errors.New("unreachable")
+	m.Check()
+	source.err = nil
+	local.tip = &domains.BlockHeader{Height: 105, Timestamp: now.Add(50 * time.Minute)}
+	m.Check()
+
+	// then
+	assert.Equal(t, len(*notified), 2)
+
+	diverged := (*notified)[0].(*domains.TipDivergenceEvent)
+	assert.Equal(t, diverged.Operation, domains.EventTipDiverged)
+	assert.Equal(t, diverged.Source, "fake")
+	assert.Equal(t, diverged.Blocks, int32(5))
+	assert.Equal(t, diverged.TimeLag, int64(3000))
+	assert.Equal(t, diverged.SourceTip.Hash, "ahead")
+
+	converged := (*notified)[1].(*domains.TipDivergenceEvent)
+	assert.Equal(t, converged.Operation, domains.EventTipConverged)
+	assert.Equal(t, converged.Blocks, int32(0))
+}
+
+func TestCheckAlertsOnTimeLag(t *testing.T) {
+	// given
+	now := time.Now()
+	local := &fixedTip{tip: &domains.BlockHeader{Height: 100, Timestamp: now}}
+	source := &fakeSource{tip: &domains.ReferenceTip{Height: 100, Timestamp: now.Add(-time.Hour)}}
+	notified := &events{}
+	m := newTestMonitor(source, local, notified)
+
+	// when
+	m.Check()
+
+	// then
+	assert.Equal(t, len(*notified), 1)
+	assert.Equal(t, (*notified)[0].(*domains.TipDivergenceEvent).TimeLag, int64(-3600))
+}
+
+func TestWhatsOnChainSourceTip(t *testing.T) {
+	// given
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Authorization"), "key")
+		switch r.URL.Path {
+		case "/chain/info":
+			_ = json.NewEncoder(w).Encode(map[string]any{"blocks": 800000, "bestblockhash": "tip", "mediantime": 1})
+		case "/block/hash/tip":
+			_ = json.NewEncoder(w).Encode(map[string]any{"hash": "tip", "time": 1700000000})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+
+	source, err := NewSource(config.TipSourceConfig{Type: config.TipSourceWhatsOnChain, URL: api.URL, AuthToken: "key"}, time.Second)
+	assert.NoError(t, err)
+
+	// when
+	tip, err := source.Tip()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, source.Name(), "whatsonchain")
+	assert.Equal(t, tip.Height, int32(800000))
+	assert.Equal(t, tip.Hash, "tip")
+	assert.Equal(t, tip.Timestamp.Unix(), int64(1700000000))
+}
+
+func newTestMonitor(source Source, tips TipProvider, notifier Notifier) *Monitor {
+	cfg := config.GetDefaultAppConfig().TipMonitor
+	log := zerolog.Nop()
+	return NewMonitor(cfg, []Source{source}, tips, notifier, &log)
+}
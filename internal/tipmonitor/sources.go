@@ -0,0 +1,165 @@
+package tipmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/transports/noderpc"
+)
+
+const (
+	longestTipPath      = "/api/v1/chain/tip/longest"
+	whatsOnChainMainnet = "https://api.whatsonchain.com/v1/bsv/main"
+)
+
+// Source is an external source of the tip of the longest chain.
+type Source interface {
+	// Name identifies the source in alerts and metrics.
+	Name() string
+	// Tip returns the current tip of the source.
+	Tip() (*domains.ReferenceTip, error)
+}
+
+// NewSource creates the source described by the config.
+func NewSource(cfg config.TipSourceConfig, timeout time.Duration) (Source, error) {
+	name := cfg.Name
+	if name == "" {
+		name = string(cfg.Type)
+	}
+	httpClient := &http.Client{Timeout: timeout}
+
+	switch cfg.Type {
+	case config.TipSourcePulse:
+		return &pulseSource{name: name, url: strings.TrimSuffix(cfg.URL, "/"), token: cfg.AuthToken, client: httpClient}, nil
+	case config.TipSourceWhatsOnChain:
+		url := cfg.URL
+		if url == "" {
+			url = whatsOnChainMainnet
+		}
+		return &whatsOnChainSource{name: name, url: strings.TrimSuffix(url, "/"), apiKey: cfg.AuthToken, client: httpClient}, nil
+	case config.TipSourceNodeRPC:
+		return &nodeRPCSource{name: name, client: noderpc.NewClient(cfg.URL, cfg.User, cfg.Password, timeout)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tip source type %s", cfg.Type)
+	}
+}
+
+// pulseSource reads the tip of another Block Headers Service.
+type pulseSource struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (s *pulseSource) Name() string {
+	return s.name
+}
+
+func (s *pulseSource) Tip() (*domains.ReferenceTip, error) {
+	var tip struct {
+		Header struct {
+			Hash      string `json:"hash"`
+			Timestamp uint32 `json:"creationTimestamp"`
+		} `json:"header"`
+		Height int32 `json:"height"`
+	}
+
+	auth := ""
+	if s.token != "" {
+		auth = "Bearer " + s.token
+	}
+	if err := getJSON(s.client, s.url+longestTipPath, auth, &tip); err != nil {
+		return nil, err
+	}
+
+	return &domains.ReferenceTip{
+		Height:    tip.Height,
+		Hash:      tip.Header.Hash,
+		Timestamp: time.Unix(int64(tip.Header.Timestamp), 0),
+	}, nil
+}
+
+// whatsOnChainSource reads the tip from the WhatsOnChain API.
+type whatsOnChainSource struct {
+	name   string
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func (s *whatsOnChainSource) Name() string {
+	return s.name
+}
+
+func (s *whatsOnChainSource) Tip() (*domains.ReferenceTip, error) {
+	var info struct {
+		Blocks        int32  `json:"blocks"`
+		BestBlockHash string `json:"bestblockhash"`
+	}
+	if err := getJSON(s.client, s.url+"/chain/info", s.apiKey, &info); err != nil {
+		return nil, err
+	}
+
+	// The chain info carries only the median time, the time of the tip itself comes with its header.
+	var header struct {
+		Time int64 `json:"time"`
+	}
+	if err := getJSON(s.client, s.url+"/block/hash/"+info.BestBlockHash, s.apiKey, &header); err != nil {
+		return nil, err
+	}
+
+	return &domains.ReferenceTip{
+		Height:    info.Blocks,
+		Hash:      info.BestBlockHash,
+		Timestamp: time.Unix(header.Time, 0),
+	}, nil
+}
+
+// nodeRPCSource reads the tip from the JSON-RPC of a node.
+type nodeRPCSource struct {
+	name   string
+	client *noderpc.Client
+}
+
+func (s *nodeRPCSource) Name() string {
+	return s.name
+}
+
+func (s *nodeRPCSource) Tip() (*domains.ReferenceTip, error) {
+	height, hash, timestamp, err := s.client.BestBlock()
+	if err != nil {
+		return nil, err
+	}
+	return &domains.ReferenceTip{Height: height, Hash: hash, Timestamp: timestamp}, nil
+}
+
+func getJSON(client *http.Client, url, authorization string, v any) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,92 @@
+package tipmonitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/rs/zerolog"
+)
+
+// StaleMonitor periodically checks how old the tip of our longest chain is and alerts when it gets older than the threshold.
+type StaleMonitor struct {
+	tips     TipProvider
+	notifier Notifier
+	cfg      *config.StaleTipConfig
+	log      zerolog.Logger
+
+	stale bool
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewStaleMonitor creates and returns StaleMonitor of the tip.
+func NewStaleMonitor(cfg *config.StaleTipConfig, tips TipProvider, notifier Notifier, log *zerolog.Logger) *StaleMonitor {
+	return &StaleMonitor{
+		tips:     tips,
+		notifier: notifier,
+		cfg:      cfg,
+		log:      log.With().Str("service", "stale-tip-monitor").Logger(),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start checks the tip in the background on the configured interval.
+func (m *StaleMonitor) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.Check()
+			case <-m.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the tip and waits for a running check to finish.
+func (m *StaleMonitor) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// Check updates the time since the last header, an alert is sent when the chain becomes stale or is extended again.
+func (m *StaleMonitor) Check() {
+	tip := m.tips.GetTip()
+	if tip == nil {
+		return
+	}
+
+	elapsed := time.Since(tip.Timestamp)
+	stale := elapsed > m.cfg.Threshold
+	metrics.SetSinceLastHeader(elapsed, stale)
+
+	if stale == m.stale {
+		return
+	}
+	m.stale = stale
+
+	operation := domains.EventChainResumed
+	if stale {
+		operation = domains.EventChainStale
+		m.log.Warn().Msgf("No new header for %s, tip %s at %d", elapsed.Truncate(time.Second), tip.Hash, tip.Height)
+	} else {
+		m.log.Info().Msgf("Chain is extended again, tip %s at %d", tip.Hash, tip.Height)
+	}
+	m.notifier.Notify(&domains.StaleTipEvent{
+		Operation:              operation,
+		Tip:                    &domains.ReferenceTip{Height: tip.Height, Hash: tip.Hash.String(), Timestamp: tip.Timestamp},
+		SecondsSinceLastHeader: int64(elapsed.Seconds()),
+		Threshold:              int64(m.cfg.Threshold.Seconds()),
+	})
+}
@@ -0,0 +1,46 @@
+package tipmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestStaleCheckAlertsOnlyWhenStalenessChanges(t *testing.T) {
+	// given
+	now := time.Now()
+	local := &fixedTip{tip: &domains.BlockHeader{Height: 100, Timestamp: now.Add(-10 * time.Minute)}}
+	notified := &events{}
+	m := newTestStaleMonitor(local, notified)
+
+	// when
+	m.Check()
+	local.tip = &domains.BlockHeader{Height: 100, Timestamp: now.Add(-2 * time.Hour)}
+	m.Check()
+	m.Check()
+	local.tip = &domains.BlockHeader{Height: 101, Timestamp: now}
+	m.Check()
+
+	// then
+	assert.Equal(t, len(*notified), 2)
+
+	stale := (*notified)[0].(*domains.StaleTipEvent)
+	assert.Equal(t, stale.Operation, domains.EventChainStale)
+	assert.Equal(t, stale.Tip.Height, int32(100))
+	assert.Equal(t, stale.SecondsSinceLastHeader >= 7200, true)
+	assert.Equal(t, stale.Threshold, int64(3600))
+
+	resumed := (*notified)[1].(*domains.StaleTipEvent)
+	assert.Equal(t, resumed.Operation, domains.EventChainResumed)
+	assert.Equal(t, resumed.Tip.Height, int32(101))
+}
+
+func newTestStaleMonitor(tips TipProvider, notifier Notifier) *StaleMonitor {
+	cfg := config.GetDefaultAppConfig().StaleTip
+	log := zerolog.Nop()
+	return NewStaleMonitor(cfg, tips, notifier, &log)
+}
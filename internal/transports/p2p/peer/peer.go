@@ -62,6 +62,8 @@ type Peer struct {
 	// sendHeadersMode is specifying whether we already sent the sendheaders message
 	// to the peer and we're expecting to get just headers and no inv msg
 	sendHeadersMode bool
+	// syncMutex serializes handling of received headers with restarting the sync
+	syncMutex sync.Mutex
 
 	// wg is a WaitGroup used to properly handle peer disconnection
 	wg sync.WaitGroup
@@ -156,6 +158,19 @@ func (p *Peer) StartHeadersSync() error {
 	return nil
 }
 
+// RestartHeadersSync requests headers again from the current tip, which is lower after headers above it were deleted.
+func (p *Peer) RestartHeadersSync() error {
+	p.syncMutex.Lock()
+	defer p.syncMutex.Unlock()
+
+	currentTipHeight := p.headersService.GetTipHeight()
+	p.log.Info().Msgf("restarting headers sync with peer %s from height %d", p, currentTipHeight)
+	p.checkpoint = newCheckpoint(p.chainParams.Checkpoints, currentTipHeight, p.log)
+	p.sendHeadersMode = false
+
+	return p.requestHeaders()
+}
+
 func (p *Peer) updatePeerAddr() error {
 	remoteAddr, addrIsTCP := p.conn.RemoteAddr().(*net.TCPAddr)
 
@@ -261,7 +276,9 @@ func (p *Peer) readMsgHandler() {
 			case *wire.MsgPong:
 				p.log.Info().Msgf("received pong from peer %s with nonce: %d", p, msg.Nonce)
 			case *wire.MsgHeaders:
+				p.syncMutex.Lock()
 				p.handleHeadersMsg(msg)
+				p.syncMutex.Unlock()
 			case *wire.MsgInv:
 				p.handleInvMsg(msg)
 			case *wire.MsgGetHeaders:
@@ -488,6 +505,16 @@ func (p *Peer) handleHeadersMsg(msg *wire.MsgHeaders) {
 				continue
 			}
 
+			if service.WritesPaused.Is(err) {
+				p.log.Info().Msgf("maintenance mode is enabled, skipping headers from peer %s", p)
+				return
+			}
+
+			if service.SyncPaused.Is(err) {
+				p.log.Info().Msgf("p2p sync is paused, skipping headers from peer %s", p)
+				return
+			}
+
 			if service.BlockRejected.Is(err) {
 				// TODO: ban peer
 				p.log.Error().Msgf("received rejected header %v from peer %s", h, p)
@@ -495,6 +522,11 @@ func (p *Peer) handleHeadersMsg(msg *wire.MsgHeaders) {
 				return
 			}
 
+			if service.BlockQuarantined.Is(err) {
+				p.log.Warn().Msgf("header %v from peer %s quarantined, skipping the rest of headers: %v", header.BlockHash(), p, err)
+				return
+			}
+
 			if service.HeaderSaveFail.Is(err) {
 				p.log.Error().Msgf("couldn't save header %v in database, because of %+v", h, err)
 				continue
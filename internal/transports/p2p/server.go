@@ -60,6 +60,15 @@ func (s *server) Shutdown() error {
 	return nil
 }
 
+// RestartSync makes every connected peer sync again from the current tip.
+func (s *server) RestartSync() {
+	for _, p := range s.peers {
+		if err := p.RestartHeadersSync(); err != nil {
+			s.log.Error().Msgf("cannot restart sync with peer %s, reason: %v", p, err)
+		}
+	}
+}
+
 func (s *server) seedAndConnect() error {
 	seeds := seedFromDNS(s.chainParams.DNSSeeds, s.log)
 	if len(seeds) == 0 {
@@ -0,0 +1,196 @@
+// Package zmq provides ZeroMQ publishers compatible with ZMQ notifications of the node.
+//
+// Only the PUB side of ZMTP 3.0 over TCP with the NULL security mechanism is implemented,
+// which is what subscribers written against the node notifications use.
+package zmq
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const handshakeTimeout = 5 * time.Second
+
+// Publisher is a ZeroMQ PUB socket bound to a TCP address.
+//
+// Messages are multipart like the node notifications: the topic, the body and a little endian
+// sequence number of the message within the topic. A subscriber which doesn't keep up gets
+// at most highWaterMark messages queued, further messages are dropped for it.
+type Publisher struct {
+	listener      net.Listener
+	highWaterMark int
+	log           *zerolog.Logger
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	sequences   map[string]uint32
+
+	wg sync.WaitGroup
+}
+
+// Listen binds Publisher to the address in form of tcp://host:port and starts accepting subscribers.
+func Listen(address string, highWaterMark int, log *zerolog.Logger) (*Publisher, error) {
+	hostPort, ok := strings.CutPrefix(address, "tcp://")
+	if !ok {
+		return nil, fmt.Errorf("zmq: unsupported address %s, only tcp:// is supported", address)
+	}
+
+	listener, err := net.Listen("tcp", hostPort)
+	if err != nil {
+		return nil, fmt.Errorf("zmq: cannot listen on %s: %w", address, err)
+	}
+
+	publisherLogger := log.With().Str("subservice", "zmq").Str("address", address).Logger()
+	p := &Publisher{
+		listener:      listener,
+		highWaterMark: highWaterMark,
+		log:           &publisherLogger,
+		subscribers:   make(map[*subscriber]struct{}),
+		sequences:     make(map[string]uint32),
+	}
+
+	p.wg.Add(1)
+	go p.accept()
+	return p, nil
+}
+
+// Addr returns the address the publisher listens on.
+func (p *Publisher) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+// Publish sends the message to all subscribers of the topic.
+func (p *Publisher) Publish(topic string, body []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seq := p.sequences[topic]
+	p.sequences[topic] = seq + 1
+
+	var msg []byte
+	for s := range p.subscribers {
+		if !s.isSubscribed(topic) {
+			continue
+		}
+		if msg == nil {
+			msg = appendMessage(nil, [][]byte{[]byte(topic), body, binary.LittleEndian.AppendUint32(nil, seq)})
+		}
+		select {
+		case s.queue <- msg:
+		default:
+			p.log.Warn().Msgf("Dropping %s message for subscriber %s above the high water mark", topic, s.conn.RemoteAddr())
+		}
+	}
+}
+
+// Close stops accepting subscribers and disconnects the connected ones.
+func (p *Publisher) Close() {
+	_ = p.listener.Close()
+
+	p.mu.Lock()
+	for s := range p.subscribers {
+		s.close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *Publisher) accept() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.serve(conn)
+		}()
+	}
+}
+
+func (p *Publisher) serve(conn net.Conn) {
+	defer conn.Close() //nolint:errcheck
+
+	if err := handshake(conn); err != nil {
+		p.log.Debug().Msgf("Rejected subscriber %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	s := newSubscriber(conn, p.highWaterMark)
+	p.mu.Lock()
+	p.subscribers[s] = struct{}{}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.subscribers, s)
+		p.mu.Unlock()
+		s.close()
+	}()
+
+	go s.write()
+	s.read()
+}
+
+// handshake exchanges greetings and READY commands, the peer has to be a SUB or XSUB socket.
+func handshake(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(greeting()); err != nil {
+		return err
+	}
+	peerGreeting := make([]byte, greetingSize)
+	if _, err := io.ReadFull(conn, peerGreeting); err != nil {
+		return err
+	}
+	if err := checkGreeting(peerGreeting); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(appendFrame(nil, flagCommand, readyCommand("PUB"))); err != nil {
+		return err
+	}
+	f, err := readFrame(conn)
+	if err != nil {
+		return err
+	}
+	name, data, err := parseCommand(f.body)
+	if err != nil {
+		return err
+	}
+	if !f.isCommand() || name != "READY" {
+		return fmt.Errorf("%w: expected READY command", errUnsupportedPeer)
+	}
+	props, err := parseProperties(data)
+	if err != nil {
+		return err
+	}
+	if socketType := property(props, socketTypeProperty); socketType != "SUB" && socketType != "XSUB" {
+		return fmt.Errorf("%w: socket type %s", errUnsupportedPeer, socketType)
+	}
+
+	return conn.SetDeadline(time.Time{})
+}
+
+// property returns the value of the property, their names are case-insensitive.
+func property(props map[string]string, name string) string {
+	for k, v := range props {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package zmq
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestPublisherSendsSubscribedTopics(t *testing.T) {
+	// given
+	log := zerolog.Nop()
+	p, err := Listen("tcp://127.0.0.1:0", 10, &log)
+	assert.NoError(t, err)
+	defer p.Close()
+
+	conn := dialSubscriber(t, p, "hashblock")
+	defer conn.Close()
+
+	// when
+	parts := receiveNext(t, p, conn, "hashblock", []byte{0xab})
+
+	// then
+	assert.Equal(t, len(parts), 3)
+	assert.Equal(t, string(parts[0]), "hashblock")
+	assert.EqualBytes(t, parts[1], []byte{0xab})
+
+	// and when
+	p.Publish("rawblockheader", []byte{0x01})
+	p.Publish("hashblock", []byte{0xcd})
+	next := readMessage(t, conn)
+
+	// then
+	assert.Equal(t, string(next[0]), "hashblock")
+	assert.EqualBytes(t, next[1], []byte{0xcd})
+	assert.Equal(t, binary.LittleEndian.Uint32(next[2]), binary.LittleEndian.Uint32(parts[2])+1)
+}
+
+func TestPublisherRejectsNonSubscriber(t *testing.T) {
+	// given
+	log := zerolog.Nop()
+	p, err := Listen("tcp://127.0.0.1:0", 10, &log)
+	assert.NoError(t, err)
+	defer p.Close()
+
+	conn, err := net.Dial("tcp", p.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// when
+	_, err = conn.Write(greeting())
+	assert.NoError(t, err)
+	_, err = conn.Write(appendFrame(nil, flagCommand, readyCommand("PUSH")))
+	assert.NoError(t, err)
+
+	// then
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadAll(conn)
+	assert.NoError(t, err)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	assert.Equal(t, len(p.subscribers), 0)
+}
+
+func dialSubscriber(t *testing.T, p *Publisher, topic string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", p.Addr().String())
+	assert.NoError(t, err)
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = conn.Write(greeting())
+	assert.NoError(t, err)
+	peerGreeting := make([]byte, greetingSize)
+	_, err = io.ReadFull(conn, peerGreeting)
+	assert.NoError(t, err)
+	assert.NoError(t, checkGreeting(peerGreeting))
+
+	_, err = conn.Write(appendFrame(nil, flagCommand, readyCommand("SUB")))
+	assert.NoError(t, err)
+	ready, err := readFrame(conn)
+	assert.NoError(t, err)
+	name, _, err := parseCommand(ready.body)
+	assert.NoError(t, err)
+	assert.Equal(t, name, "READY")
+
+	_, err = conn.Write(appendFrame(nil, 0, append([]byte{1}, topic...)))
+	assert.NoError(t, err)
+	return conn
+}
+
+// receiveNext publishes the message until the subscriber gets it, the subscription is processed asynchronously.
+func receiveNext(t *testing.T, p *Publisher, conn net.Conn, topic string, body []byte) [][]byte {
+	t.Helper()
+	for i := 0; i < 50; i++ {
+		p.Publish(topic, body)
+
+		p.mu.Lock()
+		subscribed := false
+		for s := range p.subscribers {
+			subscribed = subscribed || s.isSubscribed(topic)
+		}
+		p.mu.Unlock()
+
+		if subscribed {
+			return readMessage(t, conn)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("subscriber didn't receive any message")
+	return nil
+}
+
+func readMessage(t *testing.T, conn net.Conn) [][]byte {
+	t.Helper()
+	var parts [][]byte
+	for {
+		f, err := readFrame(conn)
+		assert.NoError(t, err)
+		parts = append(parts, f.body)
+		if f.flags&flagMore == 0 {
+			return parts
+		}
+	}
+}
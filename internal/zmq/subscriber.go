@@ -0,0 +1,114 @@
+package zmq
+
+import (
+	"net"
+	"strings"
+	"sync"
+)
+
+type subscriber struct {
+	conn  net.Conn
+	queue chan []byte
+
+	mu       sync.Mutex
+	prefixes map[string]int
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newSubscriber(conn net.Conn, highWaterMark int) *subscriber {
+	return &subscriber{
+		conn:     conn,
+		queue:    make(chan []byte, highWaterMark),
+		prefixes: make(map[string]int),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *subscriber) isSubscribed(topic string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for prefix := range s.prefixes {
+		if strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// read processes subscriptions until the connection is closed. ZMTP 3.0 peers send them
+// as messages starting with 1 or 0, ZMTP 3.1 peers as SUBSCRIBE and CANCEL commands.
+func (s *subscriber) read() {
+	// multipart is set while parts of a message other than a subscription are skipped.
+	multipart := false
+	for {
+		f, err := readFrame(s.conn)
+		if err != nil {
+			return
+		}
+
+		if f.isCommand() {
+			name, data, err := parseCommand(f.body)
+			if err != nil {
+				return
+			}
+			switch name {
+			case "SUBSCRIBE":
+				s.subscribe(string(data))
+			case "CANCEL":
+				s.cancel(string(data))
+			}
+			continue
+		}
+
+		skip := multipart || f.flags&flagMore != 0
+		multipart = f.flags&flagMore != 0
+		if skip || len(f.body) == 0 {
+			continue
+		}
+		switch f.body[0] {
+		case 1:
+			s.subscribe(string(f.body[1:]))
+		case 0:
+			s.cancel(string(f.body[1:]))
+		}
+	}
+}
+
+func (s *subscriber) subscribe(prefix string) {
+	s.mu.Lock()
+	s.prefixes[prefix]++
+	s.mu.Unlock()
+}
+
+func (s *subscriber) cancel(prefix string) {
+	s.mu.Lock()
+	if s.prefixes[prefix] <= 1 {
+		delete(s.prefixes, prefix)
+	} else {
+		s.prefixes[prefix]--
+	}
+	s.mu.Unlock()
+}
+
+func (s *subscriber) write() {
+	for {
+		select {
+		case msg := <-s.queue:
+			if _, err := s.conn.Write(msg); err != nil {
+				s.close()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *subscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		_ = s.conn.Close()
+	})
+}
@@ -0,0 +1,151 @@
+package zmq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ZMTP 3.0 framing, see https://rfc.zeromq.org/spec/23/.
+const (
+	greetingSize = 64
+
+	flagMore    byte = 0x01
+	flagLong    byte = 0x02
+	flagCommand byte = 0x04
+
+	// maxIncomingFrame bounds frames read from subscribers, which only send commands and subscriptions.
+	maxIncomingFrame = 1 << 16
+
+	socketTypeProperty = "Socket-Type"
+)
+
+var errUnsupportedPeer = errors.New("zmq: unsupported peer")
+
+// greeting returns the ZMTP 3.0 greeting using the NULL security mechanism.
+func greeting() []byte {
+	g := make([]byte, greetingSize)
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = 3 // major version
+	g[11] = 0 // minor version
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// checkGreeting validates the greeting of the peer, it has to speak ZMTP 3 and use the NULL mechanism.
+func checkGreeting(g []byte) error {
+	if g[0] != 0xff || g[9]&0x01 != 0x01 || g[10] < 3 {
+		return errUnsupportedPeer
+	}
+	if mechanism := bytes.TrimRight(g[12:32], "\x00"); string(mechanism) != "NULL" {
+		return fmt.Errorf("%w: security mechanism %s", errUnsupportedPeer, mechanism)
+	}
+	return nil
+}
+
+type frame struct {
+	flags byte
+	body  []byte
+}
+
+func (f frame) isCommand() bool {
+	return f.flags&flagCommand != 0
+}
+
+func readFrame(r io.Reader) (frame, error) {
+	var header [9]byte
+	if _, err := io.ReadFull(r, header[:1]); err != nil {
+		return frame{}, err
+	}
+	flags := header[0]
+
+	var size uint64
+	if flags&flagLong != 0 {
+		if _, err := io.ReadFull(r, header[1:9]); err != nil {
+			return frame{}, err
+		}
+		size = binary.BigEndian.Uint64(header[1:9])
+	} else {
+		if _, err := io.ReadFull(r, header[1:2]); err != nil {
+			return frame{}, err
+		}
+		size = uint64(header[1])
+	}
+	if size > maxIncomingFrame {
+		return frame{}, fmt.Errorf("zmq: frame of %d bytes is too large", size)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return frame{}, err
+	}
+	return frame{flags: flags, body: body}, nil
+}
+
+func appendFrame(buf []byte, flags byte, body []byte) []byte {
+	if len(body) > 255 {
+		buf = append(buf, flags|flagLong)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(body)))
+	} else {
+		buf = append(buf, flags, byte(len(body)))
+	}
+	return append(buf, body...)
+}
+
+// appendMessage appends all parts of a multipart message.
+func appendMessage(buf []byte, parts [][]byte) []byte {
+	for i, p := range parts {
+		var flags byte
+		if i < len(parts)-1 {
+			flags = flagMore
+		}
+		buf = appendFrame(buf, flags, p)
+	}
+	return buf
+}
+
+// readyCommand returns the body of READY command announcing the socket type.
+func readyCommand(socketType string) []byte {
+	body := appendShortString(nil, "READY")
+	body = appendShortString(body, socketTypeProperty)
+	body = binary.BigEndian.AppendUint32(body, uint32(len(socketType)))
+	return append(body, socketType...)
+}
+
+func appendShortString(buf []byte, s string) []byte {
+	buf = append(buf, byte(len(s)))
+	return append(buf, s...)
+}
+
+// parseCommand splits the body of a command into its name and data.
+func parseCommand(body []byte) (name string, data []byte, err error) {
+	if len(body) == 0 || len(body) < 1+int(body[0]) {
+		return "", nil, errors.New("zmq: malformed command")
+	}
+	return string(body[1 : 1+body[0]]), body[1+body[0]:], nil
+}
+
+// parseProperties parses metadata of READY command.
+func parseProperties(data []byte) (map[string]string, error) {
+	props := make(map[string]string)
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+4 {
+			return nil, errors.New("zmq: malformed property")
+		}
+		name := string(data[1 : 1+nameLen])
+		data = data[1+nameLen:]
+
+		valueLen := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) < uint64(valueLen) {
+			return nil, errors.New("zmq: malformed property")
+		}
+		props[name] = string(data[:valueLen])
+		data = data[valueLen:]
+	}
+	return props, nil
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type diskMetrics struct {
+	databaseBytes *prometheus.GaugeVec
+	logsBytes     *prometheus.GaugeVec
+	freeBytes     *prometheus.GaugeVec
+	lowSpace      *prometheus.GaugeVec
+}
+
+func registerDiskMetrics(reg prometheus.Registerer) *diskMetrics {
+	return &diskMetrics{
+		databaseBytes: registerGaugeVec(reg, diskDatabaseBytesName, nil),
+		logsBytes:     registerGaugeVec(reg, diskLogsBytesName, nil),
+		freeBytes:     registerGaugeVec(reg, diskFreeBytesName, nil),
+		lowSpace:      registerGaugeVec(reg, diskLowSpaceName, nil),
+	}
+}
+
+// SetDiskUsage sets the sizes of the database and the logs, the free disk space and whether writes are paused because of it.
+func SetDiskUsage(usage *domains.DiskUsage) {
+	if metrics, enabled := Get(); enabled {
+		metrics.disk.databaseBytes.WithLabelValues().Set(float64(usage.DatabaseBytes))
+		metrics.disk.logsBytes.WithLabelValues().Set(float64(usage.LogsBytes))
+		metrics.disk.freeBytes.WithLabelValues().Set(float64(usage.FreeBytes))
+		value := 0.0
+		if usage.LowSpace {
+			value = 1
+		}
+		metrics.disk.lowSpace.WithLabelValues().Set(value)
+	}
+}
@@ -10,6 +10,11 @@ type Metrics struct {
 	registerer   prometheus.Registerer
 	httpRequests *RequestMetrics
 	latestBlock  *latestBlockMetrics
+	writeQueue   *writeQueueMetrics
+	tipMonitor   *tipMonitorMetrics
+	pruning      *pruningMetrics
+	staleTip     *staleTipMetrics
+	disk         *diskMetrics
 }
 
 func newMetrics() *Metrics {
@@ -22,6 +27,11 @@ func newMetrics() *Metrics {
 		registerer:   registererWithLabels,
 		httpRequests: registerRequestMetrics(registererWithLabels),
 		latestBlock:  registerLatestBlockMetrics(registererWithLabels),
+		writeQueue:   registerWriteQueueMetrics(registererWithLabels),
+		tipMonitor:   registerTipMonitorMetrics(registererWithLabels),
+		pruning:      registerPruningMetrics(registererWithLabels),
+		staleTip:     registerStaleTipMetrics(registererWithLabels),
+		disk:         registerDiskMetrics(registererWithLabels),
 	}
 
 	return m
@@ -11,3 +11,25 @@ const domainPrefix = "bsv_"
 const latestBlockBaseName = domainPrefix + "latest_block"
 const latestBlockHeightName = latestBlockBaseName + "_height"
 const latestBlockTimestampName = latestBlockBaseName + "_timestamp"
+
+const writeQueueBaseName = domainPrefix + "write_queue"
+const writeQueueLengthName = writeQueueBaseName + "_length"
+const writeQueueFlushDurationSecName = writeQueueBaseName + "_flush_duration_seconds"
+const writeQueueWrittenName = writeQueueBaseName + "_written_total"
+
+const tipDivergenceBaseName = domainPrefix + "tip_divergence"
+const tipDivergenceBlocksName = tipDivergenceBaseName + "_blocks"
+const tipDivergenceTimeLagSecName = tipDivergenceBaseName + "_time_lag_seconds"
+const tipDivergedName = domainPrefix + "tip_diverged"
+
+const prunedHeadersName = domainPrefix + "pruned_headers_total"
+const archivedHeadersName = domainPrefix + "archived_headers_total"
+
+const secondsSinceLastHeaderName = domainPrefix + "seconds_since_last_header"
+const chainStaleName = domainPrefix + "chain_stale"
+
+const diskBaseName = domainPrefix + "disk"
+const diskDatabaseBytesName = diskBaseName + "_database_bytes"
+const diskLogsBytesName = diskBaseName + "_logs_bytes"
+const diskFreeBytesName = diskBaseName + "_free_bytes"
+const diskLowSpaceName = diskBaseName + "_low_space"
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pruningMetrics struct {
+	pruned   *prometheus.CounterVec
+	archived *prometheus.CounterVec
+}
+
+func registerPruningMetrics(reg prometheus.Registerer) *pruningMetrics {
+	return &pruningMetrics{
+		pruned:   registerCounterVec(reg, prunedHeadersName, []string{"state"}),
+		archived: registerCounterVec(reg, archivedHeadersName, []string{"state"}),
+	}
+}
+
+// AddPrunedHeaders counts headers in given state deleted by pruning of old branches.
+func AddPrunedHeaders(state string, count int) {
+	if metrics, enabled := Get(); enabled {
+		metrics.pruning.pruned.WithLabelValues(state).Add(float64(count))
+	}
+}
+
+// AddArchivedHeaders counts headers in given state moved to the archive with old branches.
+func AddArchivedHeaders(state string, count int) {
+	if metrics, enabled := Get(); enabled {
+		metrics.pruning.archived.WithLabelValues(state).Add(float64(count))
+	}
+}
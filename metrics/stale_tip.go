@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type staleTipMetrics struct {
+	sinceLastHeader *prometheus.GaugeVec
+	stale           *prometheus.GaugeVec
+}
+
+func registerStaleTipMetrics(reg prometheus.Registerer) *staleTipMetrics {
+	return &staleTipMetrics{
+		sinceLastHeader: registerGaugeVec(reg, secondsSinceLastHeaderName, nil),
+		stale:           registerGaugeVec(reg, chainStaleName, nil),
+	}
+}
+
+// SetSinceLastHeader sets the time elapsed since the tip of the longest chain and whether the chain is considered stale.
+func SetSinceLastHeader(elapsed time.Duration, stale bool) {
+	if metrics, enabled := Get(); enabled {
+		metrics.staleTip.sinceLastHeader.WithLabelValues().Set(elapsed.Seconds())
+		value := 0.0
+		if stale {
+			value = 1
+		}
+		metrics.staleTip.stale.WithLabelValues().Set(value)
+	}
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type tipMonitorMetrics struct {
+	blocks   *prometheus.GaugeVec
+	timeLag  *prometheus.GaugeVec
+	diverged *prometheus.GaugeVec
+}
+
+func registerTipMonitorMetrics(reg prometheus.Registerer) *tipMonitorMetrics {
+	return &tipMonitorMetrics{
+		blocks:   registerGaugeVec(reg, tipDivergenceBlocksName, []string{"source"}),
+		timeLag:  registerGaugeVec(reg, tipDivergenceTimeLagSecName, []string{"source"}),
+		diverged: registerGaugeVec(reg, tipDivergedName, []string{"source"}),
+	}
+}
+
+// SetTipDivergence sets how far the tip of the source is ahead of ours and whether it is considered a divergence.
+func SetTipDivergence(source string, blocks int32, timeLag time.Duration, diverged bool) {
+	if metrics, enabled := Get(); enabled {
+		metrics.tipMonitor.blocks.WithLabelValues(source).Set(float64(blocks))
+		metrics.tipMonitor.timeLag.WithLabelValues(source).Set(timeLag.Seconds())
+		value := 0.0
+		if diverged {
+			value = 1
+		}
+		metrics.tipMonitor.diverged.WithLabelValues(source).Set(value)
+	}
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type writeQueueMetrics struct {
+	length        *prometheus.GaugeVec
+	flushDuration *prometheus.HistogramVec
+	written       *prometheus.CounterVec
+}
+
+func registerWriteQueueMetrics(reg prometheus.Registerer) *writeQueueMetrics {
+	return &writeQueueMetrics{
+		length:        registerGaugeVec(reg, writeQueueLengthName, []string{}),
+		flushDuration: registerDurationHistogram(reg, writeQueueFlushDurationSecName, []string{}),
+		written:       registerCounterVec(reg, writeQueueWrittenName, []string{}),
+	}
+}
+
+// SetWriteQueueLength sets the number of headers waiting in the write queue.
+func SetWriteQueueLength(length int) {
+	if metrics, enabled := Get(); enabled {
+		metrics.writeQueue.length.WithLabelValues().Set(float64(length))
+	}
+}
+
+// ObserveWriteQueueFlush records a batch of headers written from the write queue to the database.
+func ObserveWriteQueueFlush(size int, duration time.Duration) {
+	if metrics, enabled := Get(); enabled {
+		metrics.writeQueue.flushDuration.WithLabelValues().Observe(duration.Seconds())
+		metrics.writeQueue.written.WithLabelValues().Add(float64(size))
+	}
+}
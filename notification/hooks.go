@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
+// Hooks are Go callbacks called on header lifecycle events, they let code compiled
+// together with the service attach custom logic. Any of callbacks can be nil.
+// Callbacks can be called concurrently, a panic in a callback is logged and doesn't stop the service.
+type Hooks struct {
+	// OnHeaderAdded is called with every header added to any chain.
+	OnHeaderAdded func(*domains.HeaderEvent)
+	// OnReorg is called when the longest chain is replaced by another chain.
+	OnReorg func(*domains.ReorgEvent)
+	// OnConfirmationChanged is called with the header of the longest chain
+	// which reached Confirmations confirmations with a new tip.
+	OnConfirmationChanged func(*domains.HeaderEvent)
+	// Confirmations is the number of confirmations OnConfirmationChanged waits for.
+	Confirmations int
+}
+
+var (
+	hooksMu    sync.Mutex
+	registered []Hooks
+)
+
+// RegisterHooks registers hooks called by every Notifier created with service.NewServices.
+// It is meant to be called from init functions, before the service starts.
+func RegisterHooks(h Hooks) error {
+	if h.OnConfirmationChanged != nil && h.Confirmations < 1 {
+		return errors.New("hooks with OnConfirmationChanged callback require at least 1 confirmation")
+	}
+
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	registered = append(registered, h)
+	return nil
+}
+
+// RegisteredHooks returns hooks registered with RegisterHooks.
+func RegisteredHooks() []Hooks {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	return append([]Hooks(nil), registered...)
+}
+
+type hooksChan struct {
+	hooks   []Hooks
+	headers LongestChainHeaders
+	log     *zerolog.Logger
+}
+
+// NewHooksChannel create Channel implementation calling given hooks.
+func NewHooksChannel(log *zerolog.Logger, headers LongestChainHeaders, hooks []Hooks) Channel {
+	channelLogger := log.With().Str("subservice", "hooks-channel").Logger()
+	return &hooksChan{
+		hooks:   hooks,
+		headers: headers,
+		log:     &channelLogger,
+	}
+}
+
+func (c *hooksChan) Notify(event Event) {
+	for _, h := range c.hooks {
+		switch e := event.(type) {
+		case *domains.HeaderEvent:
+			if h.OnHeaderAdded != nil {
+				c.call(func() { h.OnHeaderAdded(e) })
+			}
+			if h.OnConfirmationChanged != nil && e.Header.State == domains.LongestChain {
+				c.confirmed(h, e.Header.Height)
+			}
+		case *domains.ReorgEvent:
+			if h.OnReorg != nil {
+				c.call(func() { h.OnReorg(e) })
+			}
+		}
+	}
+}
+
+// confirmed calls OnConfirmationChanged with the header which reached the number of confirmations with the tip at tipHeight.
+func (c *hooksChan) confirmed(h Hooks, tipHeight int32) {
+	event, err := headerConfirmed(c.headers, tipHeight, h.Confirmations)
+	if err != nil {
+		c.log.Error().Msg(err.Error())
+		return
+	}
+	if event != nil {
+		c.call(func() { h.OnConfirmationChanged(event) })
+	}
+}
+
+func (c *hooksChan) call(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.log.Error().Msgf("Hook panicked: %v", r)
+		}
+	}()
+	fn()
+}
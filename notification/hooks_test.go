@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/rs/zerolog"
+)
+
+func TestHooksChannelCallsHooks(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	var added, confirmed []*domains.HeaderEvent
+	var reorgs []*domains.ReorgEvent
+	hooks := Hooks{
+		OnHeaderAdded:         func(e *domains.HeaderEvent) { added = append(added, e) },
+		OnReorg:               func(e *domains.ReorgEvent) { reorgs = append(reorgs, e) },
+		OnConfirmationChanged: func(e *domains.HeaderEvent) { confirmed = append(confirmed, e) },
+		Confirmations:         2,
+	}
+	log := zerolog.Nop()
+	ch := NewHooksChannel(&log, chainHeaders(chain), []Hooks{hooks})
+
+	// when
+	ch.Notify(domains.HeaderAdded(tip))
+	ch.Notify(domains.Reorg(&chain[tip.Height-1], tip, tip.Height-2))
+
+	// then
+	assert.Equal(t, len(added), 1)
+	assert.Equal(t, added[0].Header.Hash, tip.Hash.String())
+
+	assert.Equal(t, len(confirmed), 1)
+	assert.Equal(t, confirmed[0].Operation, domains.EventHeaderConfirmed)
+	assert.Equal(t, confirmed[0].Confirmations, 2)
+	assert.Equal(t, confirmed[0].Header.Height, tip.Height-1)
+
+	assert.Equal(t, len(reorgs), 1)
+	assert.Equal(t, reorgs[0].NewTip.Hash, tip.Hash.String())
+}
+
+func TestHooksChannelRecoversFromPanic(t *testing.T) {
+	// given
+	_, tip := fixtures.StaleChain()
+	called := false
+	hooks := []Hooks{
+		{OnHeaderAdded: func(*domains.HeaderEvent) { panic("broken hook") }},
+		{OnHeaderAdded: func(*domains.HeaderEvent) { called = true }},
+	}
+	log := zerolog.Nop()
+	ch := NewHooksChannel(&log, chainHeaders(nil), hooks)
+
+	// when
+	ch.Notify(domains.HeaderAdded(tip))
+
+	// then
+	assert.Equal(t, called, true)
+}
+
+func TestRegisterHooksRequiresConfirmations(t *testing.T) {
+	// when
+	err := RegisterHooks(Hooks{OnConfirmationChanged: func(*domains.HeaderEvent) {}})
+
+	// then
+	assert.IsError(t, err, "hooks with OnConfirmationChanged callback require at least 1 confirmation")
+	assert.Equal(t, len(RegisteredHooks()), 0)
+}
@@ -1,8 +1,47 @@
 package notification
 
+import (
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
 // Event represents event to notify with.
 type Event any
 
+// isHeaderEvent checks if the event is about a header.
+func isHeaderEvent(event Event) bool {
+	_, ok := event.(*domains.HeaderEvent)
+	return ok
+}
+
+// isWebhookEvent checks if the event is delivered to webhooks, which are header events, tip and disk space alerts.
+func isWebhookEvent(event Event) bool {
+	switch event.(type) {
+	case *domains.TipDivergenceEvent, *domains.StaleTipEvent, *domains.DiskSpaceEvent:
+		return true
+	}
+	return isHeaderEvent(event)
+}
+
+// isTransientEvent checks if the event is sent right away without storing it, which are disk space alerts,
+// since they're sent while the writes are paused.
+func isTransientEvent(event Event) bool {
+	_, ok := event.(*domains.DiskSpaceEvent)
+	return ok
+}
+
+// Maintenance tells whether the maintenance mode is enabled, in which background jobs don't write to the storage,
+// so it can be backed up consistently.
+type Maintenance interface {
+	ModeEnabled() bool
+}
+
+// paused reports whether writes are paused by the maintenance mode, they never are without the maintenance.
+func paused(m Maintenance) bool {
+	return m != nil && m.ModeEnabled()
+}
+
 // Channel is a component representing channel of communication ex. http request to webhook, websocket etc.
 type Channel interface {
 	// Notify send event notification.
@@ -12,6 +51,10 @@ type Channel interface {
 // Notifier is representing component that can be used to notify clients about important events.
 type Notifier struct {
 	channels []Channel
+
+	mu         sync.Mutex
+	latest     Event
+	delivering bool
 }
 
 // NewNotifier create Notifier.
@@ -32,3 +75,47 @@ func (n *Notifier) Notify(event any) {
 		go ch.Notify(event)
 	}
 }
+
+// NotifyAndWait send event notification via registered channels and waits until all of them handle it.
+func (n *Notifier) NotifyAndWait(event any) {
+	var wg sync.WaitGroup
+	for _, ch := range n.channels {
+		wg.Add(1)
+		go func(ch Channel) {
+			defer wg.Done()
+			ch.Notify(event)
+		}(ch)
+	}
+	wg.Wait()
+}
+
+// NotifyLatest send event notification via registered channels, unless it is replaced by another
+// event before the previous delivery finishes. Only the latest event out of a burst is delivered,
+// so the number of pending notifications doesn't grow with the number of events.
+func (n *Notifier) NotifyLatest(event any) {
+	n.mu.Lock()
+	n.latest = event
+	delivering := n.delivering
+	n.delivering = true
+	n.mu.Unlock()
+
+	if !delivering {
+		go n.deliverLatest()
+	}
+}
+
+func (n *Notifier) deliverLatest() {
+	for {
+		n.mu.Lock()
+		event := n.latest
+		n.latest = nil
+		if event == nil {
+			n.delivering = false
+			n.mu.Unlock()
+			return
+		}
+		n.mu.Unlock()
+
+		n.NotifyAndWait(event)
+	}
+}
@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
+// OutboxEvent represents an event about a header stored in the same transaction as the header,
+// so it's dispatched only for the headers which were stored and isn't lost when the service crashes in between.
+type OutboxEvent struct {
+	// ID orders the events in which they were stored, it's assigned by the outbox when the event is stored.
+	ID int64
+	// HeaderHash is the hash of the header the event is stored with, it isn't stored when the header already exists.
+	HeaderHash string
+	// Event is a *domains.HeaderEvent or a *domains.ReorgEvent.
+	Event Event
+	// LatestOnly marks events of headers received during the initial sync, out of a run of them only the latest is delivered.
+	LatestOnly bool
+	CreatedAt  time.Time
+}
+
+// NewOutboxEvent creates OutboxEvent about the header with given hash.
+func NewOutboxEvent(headerHash string, event Event, latestOnly bool) *OutboxEvent {
+	return &OutboxEvent{
+		HeaderHash: headerHash,
+		Event:      event,
+		LatestOnly: latestOnly,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// OutboxDispatcher delivers events from the outbox to the notifier channels and deletes them once delivered.
+// Events are claimed for the claim timeout before they're delivered, so instances sharing the database don't
+// deliver them concurrently. The delivery is at-least-once: events are delivered again when the dispatcher
+// crashes or its claim expires before deleting them, so every event carries its ID for receivers to drop duplicates.
+// With HA enabled only the leader runs the dispatcher, so the local channels of followers don't receive the events.
+type OutboxDispatcher struct {
+	outbox      Outbox
+	notifier    *Notifier
+	maintenance Maintenance
+	cfg         *config.OutboxConfig
+	log         zerolog.Logger
+
+	dispatching sync.Mutex
+	wake        chan struct{}
+	quit        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewOutboxDispatcher creates and returns OutboxDispatcher delivering events through the notifier.
+func NewOutboxDispatcher(outbox Outbox, notifier *Notifier, maintenance Maintenance, cfg *config.OutboxConfig, log *zerolog.Logger) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outbox:      outbox,
+		notifier:    notifier,
+		maintenance: maintenance,
+		cfg:         cfg,
+		log:         log.With().Str("service", "outbox").Logger(),
+		wake:        make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+	}
+}
+
+// Wake makes the running dispatcher deliver events right away, instead of on the next poll.
+func (d *OutboxDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start delivers events left from before the restart and then the new ones in the background.
+func (d *OutboxDispatcher) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := d.Dispatch(); err != nil {
+				d.log.Error().Msgf("Cannot dispatch events from the outbox. %v", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-d.wake:
+			case <-d.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops dispatching and waits for the running dispatch to finish.
+func (d *OutboxDispatcher) Stop() {
+	close(d.quit)
+	d.wg.Wait()
+}
+
+// Dispatch delivers all unclaimed events in the order they were created, returning the number of delivered events.
+// Events wait in the outbox while the maintenance mode is enabled, as claiming and deleting them writes to the storage.
+func (d *OutboxDispatcher) Dispatch() (int, error) {
+	d.dispatching.Lock()
+	defer d.dispatching.Unlock()
+
+	if paused(d.maintenance) {
+		return 0, nil
+	}
+
+	delivered := 0
+	for {
+		now := time.Now()
+		events, err := d.outbox.ClaimOutboxEvents(now, now.Add(d.cfg.ClaimTimeout), d.cfg.BatchSize)
+		if err != nil {
+			return delivered, err
+		}
+
+		ids := make([]int64, 0, len(events))
+		for i, e := range events {
+			ids = append(ids, e.ID)
+			// The next event replaces this one, as it would replace it in NotifyLatest.
+			if e.LatestOnly && i+1 < len(events) && events[i+1].LatestOnly {
+				continue
+			}
+			d.notifier.NotifyAndWait(withEventID(e.Event, e.ID))
+			delivered++
+		}
+
+		if err := d.outbox.DeleteOutboxEvents(ids); err != nil {
+			return delivered, err
+		}
+
+		if len(events) < d.cfg.BatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// withEventID sets the ID of the outbox event on the delivered event.
+func withEventID(event Event, id int64) Event {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		e.EventID = id
+	case *domains.ReorgEvent:
+		e.EventID = id
+	}
+	return event
+}
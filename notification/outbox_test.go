@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/rs/zerolog"
+)
+
+func TestOutboxDispatcherDeliversEventsInOrder(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChain()
+	outbox := &memoryOutbox{}
+	outbox.add(
+		NewOutboxEvent(chain[1].Hash.String(), domains.HeaderAdded(&chain[1]), true),
+		NewOutboxEvent(chain[2].Hash.String(), domains.HeaderAdded(&chain[2]), true),
+		NewOutboxEvent(chain[3].Hash.String(), domains.Reorg(&chain[2], &chain[3], 1), false),
+		NewOutboxEvent(chain[3].Hash.String(), domains.HeaderAdded(&chain[3]), false),
+	)
+	d, delivered := newTestOutboxDispatcher(outbox, 2, nil)
+
+	// when
+	count, err := d.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, 3)
+	assert.Equal(t, len(outbox.events), 0)
+	assert.Equal(t, len(delivered.events), 3)
+	assert.Equal(t, delivered.events[0].(*domains.HeaderEvent).Header.Hash, chain[2].Hash.String())
+	assert.Equal(t, delivered.events[1].(*domains.ReorgEvent).Operation, domains.EventReorg)
+	assert.Equal(t, delivered.events[2].(*domains.HeaderEvent).Header.Hash, chain[3].Hash.String())
+	assert.Equal(t, delivered.events[0].(*domains.HeaderEvent).EventID, int64(2))
+	assert.Equal(t, delivered.events[1].(*domains.ReorgEvent).EventID, int64(3))
+	assert.Equal(t, delivered.events[2].(*domains.HeaderEvent).EventID, int64(4))
+}
+
+func TestOutboxDispatcherSkipsClaimedEvents(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChain()
+	outbox := &memoryOutbox{}
+	outbox.add(NewOutboxEvent(chain[1].Hash.String(), domains.HeaderAdded(&chain[1]), false))
+	_, err := outbox.ClaimOutboxEvents(time.Now(), time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	d, delivered := newTestOutboxDispatcher(outbox, 10, nil)
+
+	// when
+	count, err := d.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, 0)
+	assert.Equal(t, len(delivered.events), 0)
+	assert.Equal(t, len(outbox.events), 1)
+}
+
+func TestOutboxDispatcherWaitsInMaintenanceMode(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChain()
+	outbox := &memoryOutbox{}
+	outbox.add(NewOutboxEvent(chain[1].Hash.String(), domains.HeaderAdded(&chain[1]), false))
+	mode := maintenanceMode(true)
+	d, delivered := newTestOutboxDispatcher(outbox, 10, &mode)
+
+	// when
+	count, err := d.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, 0)
+	assert.Equal(t, len(delivered.events), 0)
+	assert.Equal(t, len(outbox.events), 1)
+
+	// when
+	mode = false
+	count, err = d.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, 1)
+	assert.Equal(t, len(outbox.events), 0)
+}
+
+type memoryOutbox struct {
+	mu      sync.Mutex
+	events  []*OutboxEvent
+	claimed map[int64]time.Time
+}
+
+func (o *memoryOutbox) add(events ...*OutboxEvent) {
+	for _, e := range events {
+		e.ID = int64(len(o.events) + 1)
+		o.events = append(o.events, e)
+	}
+}
+
+func (o *memoryOutbox) ClaimOutboxEvents(now time.Time, until time.Time, limit int) ([]*OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.claimed == nil {
+		o.claimed = make(map[int64]time.Time)
+	}
+
+	var claimed []*OutboxEvent
+	for _, e := range o.events {
+		if len(claimed) == limit {
+			break
+		}
+		if o.claimed[e.ID].After(now) {
+			continue
+		}
+		o.claimed[e.ID] = until
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) DeleteOutboxEvents(ids []int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = slices.DeleteFunc(o.events, func(e *OutboxEvent) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	return nil
+}
+
+type recordingChannel struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (c *recordingChannel) Notify(event Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+}
+
+type maintenanceMode bool
+
+func (m *maintenanceMode) ModeEnabled() bool {
+	return bool(*m)
+}
+
+func newTestOutboxDispatcher(outbox Outbox, batchSize int, maintenance Maintenance) (*OutboxDispatcher, *recordingChannel) {
+	log := zerolog.Nop()
+	notifier := NewNotifier()
+	delivered := &recordingChannel{}
+	notifier.AddChannel(delivered)
+	cfg := &config.OutboxConfig{Enabled: true, PollInterval: time.Second, BatchSize: batchSize, ClaimTimeout: time.Minute}
+	return NewOutboxDispatcher(outbox, notifier, maintenance, cfg, &log), delivered
+}
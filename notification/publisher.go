@@ -0,0 +1,107 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
+const publishTimeout = 10 * time.Second
+
+// EventPublisher represents a message broker header events are published to.
+type EventPublisher interface {
+	Publish(ctx context.Context, topic, key string, data []byte) error
+}
+
+// LongestChainHeaders represents the source of headers from the longest chain.
+type LongestChainHeaders interface {
+	GetHeaderByHeight(height int32) (*domains.BlockHeader, error)
+}
+
+// headerConfirmed makes event about the header of the longest chain which reached the number of confirmations
+// with the tip at tipHeight, it returns nil when the chain is shorter than the number of confirmations.
+func headerConfirmed(headers LongestChainHeaders, tipHeight int32, confirmations int) (*domains.HeaderEvent, error) {
+	height := tipHeight - int32(confirmations) + 1
+	if height < 0 {
+		return nil, nil
+	}
+
+	h, err := headers.GetHeaderByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting header confirmed on height %d: %w", height, err)
+	}
+	return domains.HeaderConfirmed(h, confirmations), nil
+}
+
+type publisherChan struct {
+	publisher     EventPublisher
+	headers       LongestChainHeaders
+	topics        config.PublisherTopics
+	confirmations int
+	log           *zerolog.Logger
+}
+
+// NewPublisherChannel create Channel implementation publishing header, tip, reorg and confirmation events to a message broker.
+// Confirmation events are published for the header which reached the configured number of confirmations with a new tip.
+func NewPublisherChannel(log *zerolog.Logger, publisher EventPublisher, headers LongestChainHeaders, cfg *config.PublisherConfig) Channel {
+	channelLogger := log.With().Str("subservice", "publisher-channel").Logger()
+	return &publisherChan{
+		publisher:     publisher,
+		headers:       headers,
+		topics:        cfg.Topics,
+		confirmations: cfg.Confirmations,
+		log:           &channelLogger,
+	}
+}
+
+func (p *publisherChan) Notify(event Event) {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		p.publish(p.topics.Header, e.Header.Hash, e)
+		if e.Header.State == domains.LongestChain {
+			p.publishTip(e)
+			p.publishConfirmed(e.Header.Height)
+		}
+	case *domains.ReorgEvent:
+		p.publish(p.topics.Reorg, e.NewTip.Hash, e)
+	}
+}
+
+// publishTip publishes the header as the new tip, headers are added to the longest chain only on its top.
+func (p *publisherChan) publishTip(e *domains.HeaderEvent) {
+	if p.topics.Tip == "" {
+		return
+	}
+	p.publish(p.topics.Tip, e.Header.Hash, &domains.HeaderEvent{Operation: domains.EventTip, Header: e.Header})
+}
+
+func (p *publisherChan) publishConfirmed(tipHeight int32) {
+	event, err := headerConfirmed(p.headers, tipHeight, p.confirmations)
+	if err != nil {
+		p.log.Error().Msg(err.Error())
+		return
+	}
+	if event != nil {
+		p.publish(p.topics.Confirmation, event.Header.Hash, event)
+	}
+}
+
+func (p *publisherChan) publish(topic, key string, event Event) {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		p.log.Error().Msgf("Error when creating json from event %v: %v", event, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := p.publisher.Publish(ctx, topic, key, bytes); err != nil {
+		p.log.Error().Msgf("Error when publishing event to %s: %v", topic, err)
+	}
+}
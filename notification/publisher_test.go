@@ -0,0 +1,175 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/rs/zerolog"
+)
+
+type published struct {
+	topic string
+	key   string
+	data  []byte
+}
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []published
+}
+
+func (r *recordingPublisher) Publish(_ context.Context, topic, key string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, published{topic: topic, key: key, data: data})
+	return nil
+}
+
+type chainHeaders []domains.BlockHeader
+
+func (c chainHeaders) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
+	return &c[height], nil
+}
+
+func TestPublisherChannelPublishesConfirmations(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	publisher := &recordingPublisher{}
+	log := zerolog.Nop()
+	cfg := &config.PublisherConfig{
+		Confirmations: 3,
+		Topics:        config.PublisherTopics{Header: "added", Reorg: "reorg", Confirmation: "confirmed"},
+	}
+	ch := NewPublisherChannel(&log, publisher, chainHeaders(chain), cfg)
+
+	// when
+	ch.Notify(domains.HeaderAdded(tip))
+
+	// then
+	assert.Equal(t, len(publisher.messages), 2)
+	assert.Equal(t, publisher.messages[0].topic, "added")
+	assert.Equal(t, publisher.messages[0].key, tip.Hash.String())
+
+	confirmed := chain[tip.Height-2]
+	assert.Equal(t, publisher.messages[1].topic, "confirmed")
+	assert.Equal(t, publisher.messages[1].key, confirmed.Hash.String())
+
+	var event domains.HeaderEvent
+	assert.NoError(t, json.Unmarshal(publisher.messages[1].data, &event))
+	assert.Equal(t, event.Operation, domains.EventHeaderConfirmed)
+	assert.Equal(t, event.Confirmations, 3)
+	assert.Equal(t, event.Header.Height, confirmed.Height)
+}
+
+func TestPublisherChannelPublishesReorg(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	_, staleTip := fixtures.StaleChain()
+	publisher := &recordingPublisher{}
+	log := zerolog.Nop()
+	cfg := &config.PublisherConfig{
+		Confirmations: 6,
+		Topics:        config.PublisherTopics{Header: "added", Reorg: "reorg", Confirmation: "confirmed"},
+	}
+	ch := NewPublisherChannel(&log, publisher, chainHeaders(chain), cfg)
+
+	// when
+	ch.Notify(domains.Reorg(tip, staleTip, 0))
+
+	// then
+	assert.Equal(t, len(publisher.messages), 1)
+	assert.Equal(t, publisher.messages[0].topic, "reorg")
+	assert.Equal(t, publisher.messages[0].key, staleTip.Hash.String())
+}
+
+func TestPublisherChannelPublishesTip(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	publisher := &recordingPublisher{}
+	log := zerolog.Nop()
+	cfg := &config.PublisherConfig{
+		Confirmations: 6,
+		Topics:        config.PublisherTopics{Header: "added", Reorg: "reorg", Confirmation: "confirmed", Tip: "tip"},
+	}
+	ch := NewPublisherChannel(&log, publisher, chainHeaders(chain), cfg)
+
+	// when
+	ch.Notify(domains.HeaderAdded(tip))
+
+	// then
+	assert.Equal(t, len(publisher.messages), 2)
+	assert.Equal(t, publisher.messages[1].topic, "tip")
+
+	var event domains.HeaderEvent
+	assert.NoError(t, json.Unmarshal(publisher.messages[1].data, &event))
+	assert.Equal(t, event.Operation, domains.EventTip)
+	assert.Equal(t, event.Header.Hash, tip.Hash.String())
+}
+
+type failingHeaders struct{}
+
+func (failingHeaders) GetHeaderByHeight(int32) (*domains.BlockHeader, error) {
+	return nil, errors.New("storage failure")
+}
+
+func TestHeaderConfirmed(t *testing.T) {
+	chain, tip := fixtures.LongestChain()
+
+	testCases := map[string]struct {
+		headers        LongestChainHeaders
+		confirmations  int
+		expectedHeight int32
+		expectedNil    bool
+		expectedErr    string
+	}{
+		"tip with one confirmation": {
+			headers:        chainHeaders(chain),
+			confirmations:  1,
+			expectedHeight: tip.Height,
+		},
+		"header below the tip": {
+			headers:        chainHeaders(chain),
+			confirmations:  3,
+			expectedHeight: tip.Height - 2,
+		},
+		"chain shorter than confirmations": {
+			headers:       chainHeaders(chain),
+			confirmations: int(tip.Height) + 2,
+			expectedNil:   true,
+		},
+		"failing headers": {
+			headers:       failingHeaders{},
+			confirmations: 1,
+			expectedNil:   true,
+			expectedErr:   fmt.Sprintf("error when getting header confirmed on height %d: storage failure", tip.Height),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			event, err := headerConfirmed(tc.headers, tip.Height, tc.confirmations)
+
+			// then
+			if tc.expectedErr != "" {
+				assert.IsError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, event == nil, tc.expectedNil)
+			if !tc.expectedNil {
+				assert.Equal(t, event.Operation, domains.EventHeaderConfirmed)
+				assert.Equal(t, event.Confirmations, tc.confirmations)
+				assert.Equal(t, event.Header.Height, tc.expectedHeight)
+			}
+		})
+	}
+}
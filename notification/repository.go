@@ -1,5 +1,7 @@
 package notification
 
+import "time"
+
 // Webhooks is an interface which represents methods performed on registered_webhooks table in defined storage.
 type Webhooks interface {
 	AddWebhookToDatabase(token *Webhook) error
@@ -7,4 +9,15 @@ type Webhooks interface {
 	GetWebhookByURL(url string) (*Webhook, error)
 	GetAllWebhooks() ([]*Webhook, error)
 	UpdateWebhook(w *Webhook) error
+	AddDeliveries(deliveries []*WebhookDelivery) error
+	GetDueDeliveries(until time.Time, limit int) ([]*WebhookDelivery, error)
+	ClaimDelivery(d *WebhookDelivery, next time.Time) (bool, error)
+	DeleteDelivery(id string) error
+}
+
+// Outbox is an interface which represents methods performed on notification_outbox table in defined storage.
+// Events are added to it together with headers by the headers repository.
+type Outbox interface {
+	ClaimOutboxEvents(now time.Time, until time.Time, limit int) ([]*OutboxEvent, error)
+	DeleteOutboxEvents(ids []int64) error
 }
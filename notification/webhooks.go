@@ -1,6 +1,8 @@
 package notification
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,6 +22,17 @@ type Webhook struct {
 	MaxTries          int       `json:"-"`
 }
 
+// WebhookDelivery represents an event waiting to be delivered to webhook.
+type WebhookDelivery struct {
+	ID  string
+	URL string
+	// Event is the JSON of the delivered event, it's sent as it is.
+	Event         []byte
+	Attempts      int
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
 // WebhookTargetClient is the interface for the webhooks http calls.
 type WebhookTargetClient interface {
 	Call(headers map[string]string, method string, url string, body any) (*http.Response, error)
@@ -54,6 +67,9 @@ func (w *Webhook) Notify(event Event, client WebhookTargetClient) error {
 	strBody := string(body)
 	w.updateWebhookAfterNotification(res.StatusCode, strBody, err)
 
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook %s responded with status %d", w.URL, res.StatusCode)
+	}
 	return nil
 }
 
@@ -92,3 +108,18 @@ func CreateWebhook(url, tokenHeader, token string, maxTries int) *Webhook {
 		MaxTries:    maxTries,
 	}
 }
+
+// newWebhookDelivery creates delivery of the event to webhook with given url, which should be attempted right away.
+func newWebhookDelivery(url string, event []byte, now time.Time) (*WebhookDelivery, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &WebhookDelivery{
+		ID:            hex.EncodeToString(id),
+		URL:           url,
+		Event:         event,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
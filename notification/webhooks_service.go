@@ -1,29 +1,44 @@
 package notification
 
 import (
+	"encoding/json"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/rs/zerolog"
 )
 
+// deliveriesBatchSize is the number of pending deliveries read from the outbox at once.
+const deliveriesBatchSize = 100
+
 // WebhooksService represents Webhooks service and provide access to repositories.
+// Events are stored as pending deliveries before they are sent, so they survive a restart until they're delivered.
+// In the maintenance mode pending deliveries wait and new events are sent once without storing them.
 type WebhooksService struct {
-	webhooks Webhooks
-	client   WebhookTargetClient
-	log      *zerolog.Logger
-	cfg      *config.WebhookConfig
+	webhooks    Webhooks
+	client      WebhookTargetClient
+	maintenance Maintenance
+	log         *zerolog.Logger
+	cfg         *config.WebhookConfig
+
+	delivering sync.Mutex
+	quit       chan struct{}
+	wg         sync.WaitGroup
 }
 
 // NewWebhooksService creates and returns WebhooksService instance.
-func NewWebhooksService(repo Webhooks, client WebhookTargetClient, log *zerolog.Logger, cfg *config.WebhookConfig) *WebhooksService {
+func NewWebhooksService(repo Webhooks, client WebhookTargetClient, maintenance Maintenance, log *zerolog.Logger, cfg *config.WebhookConfig) *WebhooksService {
 	webhhoksLogger := log.With().Str("service", "webhooks").Logger()
 	return &WebhooksService{
-		webhooks: repo,
-		client:   client,
-		log:      &webhhoksLogger,
-		cfg:      cfg,
+		webhooks:    repo,
+		client:      client,
+		maintenance: maintenance,
+		log:         &webhhoksLogger,
+		cfg:         cfg,
+		quit:        make(chan struct{}),
 	}
 }
 
@@ -58,8 +73,13 @@ func (s *WebhooksService) DeleteWebhook(value string) error {
 	return err
 }
 
-// Notify notifies all active webhooks.
+// Notify stores delivery of the event for every active webhook and delivers them.
+// Transient events and events in the maintenance mode are sent once without storing them, so they aren't retried.
 func (s *WebhooksService) Notify(event Event) {
+	if !isWebhookEvent(event) {
+		return
+	}
+
 	webhooks, err := s.webhooks.GetAllWebhooks()
 
 	if err != nil {
@@ -67,25 +87,157 @@ func (s *WebhooksService) Notify(event Event) {
 		return
 	}
 
-	// Notify all active webhooks
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error().Msgf("Cannot encode event for webhooks. %v", err)
+		return
+	}
+
+	if isTransientEvent(event) || paused(s.maintenance) {
+		s.send(webhooks, body)
+		return
+	}
+
+	now := time.Now()
+	deliveries := make([]*WebhookDelivery, 0, len(webhooks))
 	for _, webhook := range webhooks {
 		if webhook.Active {
-			if err := webhook.Notify(event, s.client); err != nil {
-				s.log.Warn().Msgf("Error during notification of the webhook: %v", err)
+			d, err := newWebhookDelivery(webhook.URL, body, now)
+			if err != nil {
+				s.log.Error().Msgf("Cannot create delivery to webhook %s. %v", webhook.URL, err)
+				return
 			}
+			deliveries = append(deliveries, d)
+		}
+	}
 
-			if err := s.webhooks.UpdateWebhook(webhook); err != nil {
-				s.log.Error().Msgf("Error has happened during updating webhook state: %v", err)
+	if err := s.webhooks.AddDeliveries(deliveries); err != nil {
+		s.log.Error().Msgf("Cannot store deliveries to webhooks. %v", err)
+		return
+	}
+
+	s.deliverPending()
+}
+
+// send notifies active webhooks about the event once, without touching the storage.
+func (s *WebhooksService) send(webhooks []*Webhook, body []byte) {
+	for _, webhook := range webhooks {
+		if !webhook.Active {
+			continue
+		}
+		// A copy is notified, the emit status of the webhook isn't stored.
+		w := *webhook
+		if err := w.Notify(json.RawMessage(body), s.client); err != nil {
+			s.log.Warn().Msgf("Error during notification of the webhook %s, it isn't retried: %v", webhook.URL, err)
+		}
+	}
+}
+
+// Start delivers deliveries left from before the restart and retries failed ones in the background.
+func (s *WebhooksService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.RetryInterval)
+		defer ticker.Stop()
+
+		for {
+			s.deliverPending()
+
+			select {
+			case <-ticker.C:
+			case <-s.quit:
+				return
 			}
 		}
+	}()
+}
+
+// Stop stops retrying deliveries and waits for the running ones to finish.
+func (s *WebhooksService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// deliverPending attempts all deliveries which are due, one at a time, unless the maintenance mode is enabled.
+func (s *WebhooksService) deliverPending() {
+	s.delivering.Lock()
+	defer s.delivering.Unlock()
+
+	if paused(s.maintenance) {
+		return
+	}
+
+	for {
+		deliveries, err := s.webhooks.GetDueDeliveries(time.Now(), deliveriesBatchSize)
+		if err != nil {
+			s.log.Error().Msgf("Cannot load pending webhook deliveries. %v", err)
+			return
+		}
+
+		for _, d := range deliveries {
+			if err := s.deliver(d); err != nil {
+				s.log.Error().Msgf("Error has happened during delivery to webhook %s: %v", d.URL, err)
+				return
+			}
+		}
+
+		if len(deliveries) < deliveriesBatchSize {
+			return
+		}
 	}
 }
 
+// deliver sends the delivery and removes it once it's delivered, the webhook is gone or it runs out of tries.
+// The attempt is counted and the next one scheduled before sending, so a crash during it can't lose the delivery
+// and other instances sharing the database don't send it concurrently. It returns only errors of the storage.
+func (s *WebhooksService) deliver(d *WebhookDelivery) error {
+	claimed, err := s.webhooks.ClaimDelivery(d, time.Now().Add(s.retryDelay(d.Attempts+1)))
+	if err != nil || !claimed {
+		return err
+	}
+	d.Attempts++
+
+	webhook, err := s.webhooks.GetWebhookByURL(d.URL)
+	if err != nil || !webhook.Active {
+		s.log.Warn().Msgf("Dropping delivery to webhook %s which was revoked or deactivated", d.URL)
+		return s.webhooks.DeleteDelivery(d.ID)
+	}
+	webhook.MaxTries = s.cfg.MaxTries
+
+	notifyErr := webhook.Notify(json.RawMessage(d.Event), s.client)
+	if err := s.webhooks.UpdateWebhook(webhook); err != nil {
+		s.log.Error().Msgf("Error has happened during updating webhook state: %v", err)
+	}
+
+	switch {
+	case notifyErr == nil:
+		return s.webhooks.DeleteDelivery(d.ID)
+	case d.Attempts >= s.cfg.MaxTries:
+		s.log.Warn().Msgf("Dropping delivery to webhook %s after %d tries: %v", d.URL, d.Attempts, notifyErr)
+		return s.webhooks.DeleteDelivery(d.ID)
+	default:
+		s.log.Warn().Msgf("Error during notification of the webhook, retrying in %s: %v", s.retryDelay(d.Attempts), notifyErr)
+		return nil
+	}
+}
+
+// retryDelay is the time between the given attempt and the next one, which grows linearly with the attempts.
+func (s *WebhooksService) retryDelay(attempt int) time.Duration {
+	return time.Duration(attempt) * s.cfg.RetryInterval
+}
+
 // GetWebhookByURL returns webhook by url.
 func (s *WebhooksService) GetWebhookByURL(url string) (*Webhook, error) {
 	return s.webhooks.GetWebhookByURL(url)
 }
 
+// GetWebhooks returns all registered webhooks.
+func (s *WebhooksService) GetWebhooks() ([]*Webhook, error) {
+	return s.webhooks.GetAllWebhooks()
+}
+
 // refreshWebhook refresh webhook by resetting ErrorsCount and Active fields.
 func (s *WebhooksService) refreshWebhook(url string) (*Webhook, error) {
 	w, err := s.webhooks.GetWebhookByURL(url)
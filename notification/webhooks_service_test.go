@@ -0,0 +1,193 @@
+package notification_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/rs/zerolog"
+)
+
+const targetURL = "http://localhost:8080/webhook"
+
+type webhookTarget struct {
+	mu     sync.Mutex
+	status int
+	calls  int
+}
+
+func (t *webhookTarget) Call(_ map[string]string, _ string, _ string, _ any) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls++
+	return &http.Response{StatusCode: t.status, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (t *webhookTarget) respond(status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = status
+}
+
+func (t *webhookTarget) called() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls
+}
+
+func TestNotifyDeliversToWebhook(t *testing.T) {
+	// given
+	repo, target := givenWebhook(http.StatusOK)
+	s := newWebhooksService(repo, target)
+
+	// when
+	s.Notify(headerAdded())
+
+	// then
+	assert.Equal(t, target.called(), 1)
+	assert.Equal(t, len(repo.Deliveries()), 0)
+}
+
+func TestFailedDeliveryIsRetriedAfterRestart(t *testing.T) {
+	// given
+	repo, target := givenWebhook(http.StatusServiceUnavailable)
+	s := newWebhooksService(repo, target)
+	s.Notify(headerAdded())
+	assert.Equal(t, len(repo.Deliveries()), 1)
+
+	// when
+	target.respond(http.StatusOK)
+	restarted := newWebhooksService(repo, target)
+	restarted.Start()
+	waitUntil(t, func() bool { return len(repo.Deliveries()) == 0 })
+	restarted.Stop()
+
+	// then
+	assert.Equal(t, target.called(), 2)
+	w, err := repo.GetWebhookByURL(targetURL)
+	assert.NoError(t, err)
+	assert.Equal(t, w.ErrorsCount, 0)
+}
+
+func TestDeliveryIsDroppedAfterMaxTries(t *testing.T) {
+	// given
+	repo, target := givenWebhook(http.StatusServiceUnavailable)
+	s := newWebhooksService(repo, target)
+
+	// when
+	s.Notify(headerAdded())
+	s.Start()
+	waitUntil(t, func() bool { return len(repo.Deliveries()) == 0 })
+	s.Stop()
+
+	// then
+	assert.Equal(t, target.called(), 3)
+	w, err := repo.GetWebhookByURL(targetURL)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Active, false)
+}
+
+func TestNotifySkipsInactiveWebhooks(t *testing.T) {
+	// given
+	repo, target := givenWebhook(http.StatusOK)
+	w, err := repo.GetWebhookByURL(targetURL)
+	assert.NoError(t, err)
+	w.Active = false
+	s := newWebhooksService(repo, target)
+
+	// when
+	s.Notify(headerAdded())
+
+	// then
+	assert.Equal(t, target.called(), 0)
+	assert.Equal(t, len(repo.Deliveries()), 0)
+}
+
+func TestNotifySendsDiskSpaceAlertWithoutStoringIt(t *testing.T) {
+	// given
+	repo, target := givenWebhook(http.StatusServiceUnavailable)
+	s := newWebhooksService(repo, target)
+
+	// when
+	s.Notify(&domains.DiskSpaceEvent{Operation: domains.EventDiskSpaceLow})
+
+	// then
+	assert.Equal(t, target.called(), 1)
+	assert.Equal(t, len(repo.Deliveries()), 0)
+	w, err := repo.GetWebhookByURL(targetURL)
+	assert.NoError(t, err)
+	assert.Equal(t, w.ErrorsCount, 0)
+}
+
+func TestDeliveriesWaitInMaintenanceMode(t *testing.T) {
+	// given
+	repo, target := givenWebhook(http.StatusServiceUnavailable)
+	s := newWebhooksService(repo, target)
+	s.Notify(headerAdded())
+	target.respond(http.StatusOK)
+	mode := &maintenanceMode{}
+	mode.enabled.Store(true)
+	log := zerolog.Nop()
+	paused := notification.NewWebhooksService(repo, target, mode, &log, &config.WebhookConfig{MaxTries: 3, RetryInterval: time.Millisecond})
+
+	// when
+	paused.Notify(headerAdded())
+	paused.Start()
+	time.Sleep(10 * time.Millisecond)
+
+	// then
+	assert.Equal(t, target.called(), 2)
+	assert.Equal(t, len(repo.Deliveries()), 1)
+
+	// when
+	mode.enabled.Store(false)
+	waitUntil(t, func() bool { return len(repo.Deliveries()) == 0 })
+	paused.Stop()
+
+	// then
+	assert.Equal(t, target.called(), 3)
+}
+
+type maintenanceMode struct {
+	enabled atomic.Bool
+}
+
+func (m *maintenanceMode) ModeEnabled() bool {
+	return m.enabled.Load()
+}
+
+func givenWebhook(status int) (*testrepository.WebhooksTestRepository, *webhookTarget) {
+	repo := testrepository.NewWebhooksTestRepository(&[]notification.Webhook{})
+	_ = repo.AddWebhookToDatabase(notification.CreateWebhook(targetURL, "Authorization", "Bearer token", 3))
+	return repo, &webhookTarget{status: status}
+}
+
+func newWebhooksService(repo notification.Webhooks, target notification.WebhookTargetClient) *notification.WebhooksService {
+	log := zerolog.Nop()
+	return notification.NewWebhooksService(repo, target, nil, &log, &config.WebhookConfig{MaxTries: 3, RetryInterval: time.Millisecond})
+}
+
+func headerAdded() *domains.HeaderEvent {
+	_, tip := fixtures.LongestChain()
+	return domains.HeaderAdded(tip)
+}
+
+func waitUntil(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
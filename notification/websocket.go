@@ -2,9 +2,9 @@ package notification
 
 import (
 	"encoding/json"
-	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/centrifugal/centrifuge"
 	"github.com/rs/zerolog"
 )
@@ -15,38 +15,63 @@ type WebsocketPublisher interface {
 }
 
 type wsChan struct {
-	publisher      WebsocketPublisher
-	log            *zerolog.Logger
-	historySize    int
-	historySeconds int
+	publisher WebsocketPublisher
+	headers   LongestChainHeaders
+	log       *zerolog.Logger
+	cfg       *config.WebsocketConfig
 }
 
 // NewWebsocketChannel create Channel implementation communicating via websocket.
-func NewWebsocketChannel(log *zerolog.Logger, publisher WebsocketPublisher, cfg *config.WebsocketConfig) Channel {
+// Header events are published to the headers channel, reorg events to the reorgs channel and confirmation events,
+// for the header which reached the configured number of confirmations with a new tip, to the confirmations channel.
+func NewWebsocketChannel(log *zerolog.Logger, publisher WebsocketPublisher, headers LongestChainHeaders, cfg *config.WebsocketConfig) Channel {
 	channelLogger := log.With().Str("subservice", "ws-channel").Logger()
 	return &wsChan{
-		publisher:      publisher,
-		log:            &channelLogger,
-		historySize:    cfg.HistoryMax,
-		historySeconds: cfg.HistoryTTL,
+		publisher: publisher,
+		headers:   headers,
+		log:       &channelLogger,
+		cfg:       cfg,
 	}
 }
 
 func (w *wsChan) Notify(event Event) {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		w.publishEvent(config.WebsocketHeadersChannel, e)
+		if e.Header.State == domains.LongestChain {
+			w.publishConfirmed(e.Header.Height)
+		}
+	case *domains.ReorgEvent:
+		w.publishEvent(config.WebsocketReorgsChannel, e)
+	}
+}
+
+func (w *wsChan) publishConfirmed(tipHeight int32) {
+	event, err := headerConfirmed(w.headers, tipHeight, w.cfg.Confirmations)
+	if err != nil {
+		w.log.Error().Msg(err.Error())
+		return
+	}
+	if event != nil {
+		w.publishEvent(config.WebsocketConfirmationsChannel, event)
+	}
+}
+
+func (w *wsChan) publishEvent(channel string, event Event) {
 	bytes, err := json.Marshal(event)
 	if err != nil {
 		w.log.Error().Msgf("Error when creating json from event %v: %v", event, err)
 		return
 	}
 
-	if err := w.publishToHeadersChannel(bytes); err != nil {
-		w.log.Error().Msgf("Error when sending event %v to channel: %v", event, err)
-		return
+	if err := w.publish(channel, bytes); err != nil {
+		w.log.Error().Msgf("Error when sending event %v to channel %s: %v", event, channel, err)
 	}
 }
 
-func (w *wsChan) publishToHeadersChannel(bytes []byte) error {
-	_, err := w.publisher.Publish("headers", bytes,
-		centrifuge.WithHistory(w.historySize, time.Duration(w.historySeconds)*time.Minute))
+// publish publishes data to the channel keeping it in the history configured for the channel.
+func (w *wsChan) publish(channel string, data []byte) error {
+	size, ttl := w.cfg.History(channel)
+	_, err := w.publisher.Publish(channel, data, centrifuge.WithHistory(size, ttl))
 	return err
 }
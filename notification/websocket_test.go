@@ -0,0 +1,123 @@
+package notification
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/centrifugal/centrifuge"
+	"github.com/rs/zerolog"
+)
+
+type recordingWebsocketPublisher struct {
+	channels []string
+	data     [][]byte
+	options  []centrifuge.PublishOptions
+}
+
+func (r *recordingWebsocketPublisher) Publish(channel string, data []byte, opts ...centrifuge.PublishOption) (centrifuge.PublishResult, error) {
+	var o centrifuge.PublishOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r.channels = append(r.channels, channel)
+	r.data = append(r.data, data)
+	r.options = append(r.options, o)
+	return centrifuge.PublishResult{}, nil
+}
+
+func TestWebsocketChannelHistory(t *testing.T) {
+	testCases := map[string]struct {
+		channels    map[string]*config.WebsocketChannelConfig
+		historySize int
+		historyTTL  time.Duration
+	}{
+		"global history": {
+			historySize: 300,
+			historyTTL:  10 * time.Minute,
+		},
+		"history of the channel": {
+			channels: map[string]*config.WebsocketChannelConfig{
+				config.WebsocketHeadersChannel: {HistoryMax: 10, HistoryTTL: 1440},
+			},
+			historySize: 10,
+			historyTTL:  24 * time.Hour,
+		},
+		"partial history of the channel": {
+			channels: map[string]*config.WebsocketChannelConfig{
+				config.WebsocketHeadersChannel: {HistoryTTL: 1},
+			},
+			historySize: 300,
+			historyTTL:  time.Minute,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			chain, tip := fixtures.LongestChain()
+			publisher := &recordingWebsocketPublisher{}
+			log := zerolog.Nop()
+			cfg := &config.WebsocketConfig{HistoryMax: 300, HistoryTTL: 10, Confirmations: 3, Channels: tc.channels}
+			ch := NewWebsocketChannel(&log, publisher, chainHeaders(chain), cfg)
+
+			// when
+			ch.Notify(domains.HeaderAdded(tip))
+
+			// then
+			assert.Equal(t, len(publisher.channels), 2)
+			assert.Equal(t, publisher.channels[0], config.WebsocketHeadersChannel)
+			assert.Equal(t, publisher.options[0].HistorySize, tc.historySize)
+			assert.Equal(t, publisher.options[0].HistoryTTL, tc.historyTTL)
+		})
+	}
+}
+
+func TestWebsocketChannelPublishesConfirmations(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	publisher := &recordingWebsocketPublisher{}
+	log := zerolog.Nop()
+	cfg := &config.WebsocketConfig{HistoryMax: 300, HistoryTTL: 10, Confirmations: 3}
+	ch := NewWebsocketChannel(&log, publisher, chainHeaders(chain), cfg)
+
+	// when
+	ch.Notify(domains.HeaderAdded(tip))
+
+	// then
+	assert.Equal(t, len(publisher.channels), 2)
+	assert.Equal(t, publisher.channels[1], config.WebsocketConfirmationsChannel)
+
+	confirmed := chain[tip.Height-2]
+	var event domains.HeaderEvent
+	assert.NoError(t, json.Unmarshal(publisher.data[1], &event))
+	assert.Equal(t, event.Operation, domains.EventHeaderConfirmed)
+	assert.Equal(t, event.Confirmations, 3)
+	assert.Equal(t, event.Header.Hash, confirmed.Hash.String())
+}
+
+func TestWebsocketChannelPublishesReorg(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	_, staleTip := fixtures.StaleChain()
+	publisher := &recordingWebsocketPublisher{}
+	log := zerolog.Nop()
+	cfg := &config.WebsocketConfig{HistoryMax: 300, HistoryTTL: 10, Confirmations: 3}
+	ch := NewWebsocketChannel(&log, publisher, chainHeaders(chain), cfg)
+
+	// when
+	ch.Notify(domains.Reorg(tip, staleTip, 0))
+
+	// then
+	assert.Equal(t, len(publisher.channels), 1)
+	assert.Equal(t, publisher.channels[0], config.WebsocketReorgsChannel)
+
+	var event domains.ReorgEvent
+	assert.NoError(t, json.Unmarshal(publisher.data[0], &event))
+	assert.Equal(t, event.Operation, domains.EventReorg)
+	assert.Equal(t, event.NewTip.Hash, staleTip.Hash.String())
+}
@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/rs/zerolog"
+)
+
+const (
+	zmqTopicHashBlock      = "hashblock"
+	zmqTopicRawBlockHeader = "rawblockheader"
+)
+
+// ZMQPublisher represents ZeroMQ socket publishing messages to subscribers of their topics.
+type ZMQPublisher interface {
+	Publish(topic string, body []byte)
+}
+
+// HeadersByHash represents the source of headers looked up by their hash.
+type HeadersByHash interface {
+	GetHeaderByHash(hash string) (*domains.BlockHeader, error)
+}
+
+type zmqChan struct {
+	hashBlock      ZMQPublisher
+	rawBlockHeader ZMQPublisher
+	headers        HeadersByHash
+	log            *zerolog.Logger
+}
+
+// NewZMQChannel create Channel implementation publishing new tips of the longest chain the same way
+// as ZMQ notifications of the node. Either of publishers can be nil, when the notification is disabled.
+func NewZMQChannel(log *zerolog.Logger, hashBlock, rawBlockHeader ZMQPublisher, headers HeadersByHash) Channel {
+	channelLogger := log.With().Str("subservice", "zmq-channel").Logger()
+	return &zmqChan{
+		hashBlock:      hashBlock,
+		rawBlockHeader: rawBlockHeader,
+		headers:        headers,
+		log:            &channelLogger,
+	}
+}
+
+func (z *zmqChan) Notify(event Event) {
+	e, ok := event.(*domains.HeaderEvent)
+	if !ok || e.Header.State != domains.LongestChain {
+		return
+	}
+
+	if z.hashBlock != nil {
+		// The node publishes the hash in the same byte order as its hex representation.
+		hash, err := hex.DecodeString(e.Header.Hash)
+		if err != nil {
+			z.log.Error().Msgf("Error when decoding hash %s: %v", e.Header.Hash, err)
+			return
+		}
+		z.hashBlock.Publish(zmqTopicHashBlock, hash)
+	}
+
+	if z.rawBlockHeader != nil {
+		raw, err := z.serializedHeader(e.Header.Hash)
+		if err != nil {
+			z.log.Error().Msgf("Error when serializing header %s: %v", e.Header.Hash, err)
+			return
+		}
+		z.rawBlockHeader.Publish(zmqTopicRawBlockHeader, raw)
+	}
+}
+
+func (z *zmqChan) serializedHeader(hash string) ([]byte, error) {
+	h, err := z.headers.GetHeaderByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	header := wire.BlockHeader{
+		Version:    h.Version,
+		PrevBlock:  h.PreviousBlock,
+		MerkleRoot: h.MerkleRoot,
+		Timestamp:  h.Timestamp,
+		Bits:       h.Bits,
+		Nonce:      h.Nonce,
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, wire.MaxBlockHeaderPayload))
+	if err := header.Serialize(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
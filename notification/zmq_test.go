@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/rs/zerolog"
+)
+
+type recordingZMQPublisher struct {
+	topics []string
+	bodies [][]byte
+}
+
+func (r *recordingZMQPublisher) Publish(topic string, body []byte) {
+	r.topics = append(r.topics, topic)
+	r.bodies = append(r.bodies, body)
+}
+
+type headersByHash map[string]*domains.BlockHeader
+
+func (h headersByHash) GetHeaderByHash(hash string) (*domains.BlockHeader, error) {
+	return h[hash], nil
+}
+
+func TestZMQChannelPublishesTip(t *testing.T) {
+	// given
+	_, tip := fixtures.LongestChain()
+	hashBlock := &recordingZMQPublisher{}
+	rawBlockHeader := &recordingZMQPublisher{}
+	log := zerolog.Nop()
+	ch := NewZMQChannel(&log, hashBlock, rawBlockHeader, headersByHash{tip.Hash.String(): tip})
+
+	// when
+	ch.Notify(domains.HeaderAdded(tip))
+
+	// then
+	assert.Equal(t, len(hashBlock.topics), 1)
+	assert.Equal(t, hashBlock.topics[0], "hashblock")
+	for i, b := range hashBlock.bodies[0] {
+		assert.Equal(t, b, tip.Hash[31-i])
+	}
+
+	assert.Equal(t, len(rawBlockHeader.topics), 1)
+	assert.Equal(t, rawBlockHeader.topics[0], "rawblockheader")
+
+	var header wire.BlockHeader
+	assert.NoError(t, header.Deserialize(bytes.NewReader(rawBlockHeader.bodies[0])))
+	assert.Equal(t, header.BlockHash(), tip.Hash)
+}
+
+func TestZMQChannelIgnoresStaleHeaders(t *testing.T) {
+	// given
+	_, staleTip := fixtures.StaleChain()
+	hashBlock := &recordingZMQPublisher{}
+	log := zerolog.Nop()
+	ch := NewZMQChannel(&log, hashBlock, nil, headersByHash{})
+
+	// when
+	ch.Notify(domains.HeaderAdded(staleTip))
+
+	// then
+	assert.Equal(t, len(hashBlock.topics), 0)
+}
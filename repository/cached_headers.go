@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/cache"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+)
+
+// CachedHeaders is a Headers decorator which keeps recently used headers in memory,
+// looked up by hash and by height in the longest chain, before reaching the storage.
+type CachedHeaders struct {
+	Headers
+	byHash   *cache.LRU[string, domains.BlockHeader]
+	byHeight *cache.LRU[int32, domains.BlockHeader]
+}
+
+// NewCachedHeaders creates and returns CachedHeaders instance keeping up to size headers in each index.
+func NewCachedHeaders(headers Headers, size int) *CachedHeaders {
+	return &CachedHeaders{
+		Headers:  headers,
+		byHash:   cache.NewLRU[string, domains.BlockHeader](size),
+		byHeight: cache.NewLRU[int32, domains.BlockHeader](size),
+	}
+}
+
+// GetHeaderByHash returns header with given hash.
+func (r *CachedHeaders) GetHeaderByHash(hash string) (*domains.BlockHeader, error) {
+	if h, ok := r.byHash.Get(hash); ok {
+		return &h, nil
+	}
+
+	h, err := r.Headers.GetHeaderByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	r.byHash.Put(hash, *h)
+	return h, nil
+}
+
+// GetHeaderByHeight returns header from the longest chain on given height.
+func (r *CachedHeaders) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
+	if h, ok := r.byHeight.Get(height); ok {
+		return &h, nil
+	}
+
+	h, err := r.Headers.GetHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	r.byHeight.Put(height, *h)
+	return h, nil
+}
+
+// AddHeaderToDatabase adds new header to the storage.
+func (r *CachedHeaders) AddHeaderToDatabase(header domains.BlockHeader) error {
+	if err := r.Headers.AddHeaderToDatabase(header); err != nil {
+		return err
+	}
+	r.byHeight.Remove(header.Height)
+	return nil
+}
+
+// AddMultipleHeadersToDatabase adds multiple new headers to the storage.
+func (r *CachedHeaders) AddMultipleHeadersToDatabase(headers []domains.BlockHeader) error {
+	if err := r.Headers.AddMultipleHeadersToDatabase(headers); err != nil {
+		return err
+	}
+	r.byHeight.Purge()
+	return nil
+}
+
+// AddHeadersWithEvents adds new headers to the storage together with state changes and events about them.
+func (r *CachedHeaders) AddHeadersWithEvents(headers []domains.BlockHeader, changes []domains.StateChange, events []*notification.OutboxEvent) error {
+	// Invalidate also on failure, like UpdateState does.
+	defer func() {
+		for _, c := range changes {
+			for _, h := range c.Hashes {
+				r.byHash.Remove(h.String())
+			}
+		}
+		r.byHeight.Purge()
+	}()
+	return r.Headers.AddHeadersWithEvents(headers, changes, events)
+}
+
+// UpdateState changes state of headers with given hashes.
+func (r *CachedHeaders) UpdateState(hashes []chainhash.Hash, state domains.HeaderState) error {
+	// Invalidate also on failure, the update could have been partially applied.
+	defer func() {
+		for _, h := range hashes {
+			r.byHash.Remove(h.String())
+		}
+		// State change means reorganization, so any height can now point to a different header.
+		r.byHeight.Purge()
+	}()
+
+	return r.Headers.UpdateState(hashes, state)
+}
+
+// DeleteHeaders deletes headers with given hashes.
+func (r *CachedHeaders) DeleteHeaders(hashes []chainhash.Hash) (int, error) {
+	// Invalidate also on failure, the headers could have been partially deleted.
+	defer func() {
+		for _, h := range hashes {
+			r.byHash.Remove(h.String())
+		}
+	}()
+
+	return r.Headers.DeleteHeaders(hashes)
+}
+
+// ArchiveBranches moves headers of the branches to the archive.
+func (r *CachedHeaders) ArchiveBranches(branches []*domains.ArchivedBranch) (int, error) {
+	// Invalidate also on failure, like when the headers are deleted.
+	defer func() {
+		for _, b := range branches {
+			for _, h := range b.Headers {
+				r.byHash.Remove(h.Hash.String())
+			}
+		}
+	}()
+
+	return r.Headers.ArchiveBranches(branches)
+}
+
+// DeleteHeadersAboveHeight deletes headers higher than given height.
+func (r *CachedHeaders) DeleteHeadersAboveHeight(height int32) (int, error) {
+	defer func() {
+		r.byHash.Purge()
+		r.byHeight.Purge()
+	}()
+
+	return r.Headers.DeleteHeadersAboveHeight(height)
+}
+
+// ReplaceHeaders replaces all stored headers with the swap function.
+func (r *CachedHeaders) ReplaceHeaders(swap func() error) error {
+	defer func() {
+		r.byHash.Purge()
+		r.byHeight.Purge()
+	}()
+
+	return r.Headers.ReplaceHeaders(swap)
+}
@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+)
+
+func TestCachedHeadersServesFromCache(t *testing.T) {
+	// given
+	db, tip := fixtures.LongestChain()
+	var array []domains.BlockHeader = db
+	cached := repository.NewCachedHeaders(testrepository.NewHeadersTestRepository(&array), 10)
+	_, err := cached.GetHeaderByHash(tip.Hash.String())
+	assert.NoError(t, err)
+
+	// when
+	array = array[:0]
+	h, err := cached.GetHeaderByHash(tip.Hash.String())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, h.Hash, tip.Hash)
+}
+
+func TestCachedHeadersInvalidatesOnStateUpdate(t *testing.T) {
+	// given
+	db, tip := fixtures.LongestChain()
+	var array []domains.BlockHeader = db
+	cached := repository.NewCachedHeaders(testrepository.NewHeadersTestRepository(&array), 10)
+	_, err := cached.GetHeaderByHash(tip.Hash.String())
+	assert.NoError(t, err)
+
+	// when
+	err = cached.UpdateState([]chainhash.Hash{tip.Hash}, domains.Stale)
+	assert.NoError(t, err)
+	h, err := cached.GetHeaderByHash(tip.Hash.String())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, h.State, domains.Stale)
+}
@@ -0,0 +1,211 @@
+package dto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/pkg/errors"
+)
+
+// DbArchivedBranch represent branch moved to the archive saved in db, with its headers and events compressed.
+type DbArchivedBranch struct {
+	TipHash      DbHash `db:"tip_hash"`
+	TipHeight    int32  `db:"tip_height"`
+	Length       int    `db:"length"`
+	State        string `db:"header_state"`
+	TipTimestamp int64  `db:"tip_timestamp"`
+	ArchivedAt   int64  `db:"archived_at"`
+	// Headers is the gzip of the JSON array of the archived headers, nil when only the branches are listed.
+	Headers []byte `db:"headers"`
+	// Events is the gzip of the JSON array of the events moved from the outbox with the headers, nil when there were none.
+	Events []byte `db:"events"`
+	// Hashes are hashes of the archived headers, stored separately, so the branch can be found by any of them.
+	Hashes []DbHash `db:"-"`
+}
+
+// archivedHeader is the archived representation of a header, with all the fields stored in the headers table.
+type archivedHeader struct {
+	Hash          string    `json:"hash"`
+	Height        int32     `json:"height"`
+	Version       int32     `json:"version"`
+	MerkleRoot    string    `json:"merkleroot"`
+	Timestamp     time.Time `json:"timestamp"`
+	Bits          uint32    `json:"bits"`
+	Nonce         uint32    `json:"nonce"`
+	State         string    `json:"state"`
+	Chainwork     string    `json:"chainwork"`
+	CumulatedWork string    `json:"cumulatedWork"`
+	PreviousBlock string    `json:"previousBlock"`
+}
+
+// ToArchivedBranch converts DbArchivedBranch to ArchivedBranch, decompressing its headers if they were read.
+func (dba *DbArchivedBranch) ToArchivedBranch() (*domains.ArchivedBranch, error) {
+	branch := &domains.ArchivedBranch{
+		TipHash:      dba.TipHash.String(),
+		TipHeight:    dba.TipHeight,
+		Length:       dba.Length,
+		State:        domains.HeaderState(dba.State),
+		TipTimestamp: time.UnixMilli(dba.TipTimestamp),
+		ArchivedAt:   time.UnixMilli(dba.ArchivedAt),
+	}
+	if dba.Headers == nil {
+		return branch, nil
+	}
+
+	var headers []archivedHeader
+	if err := decompress(dba.Headers, &headers); err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress archived branch %s", dba.TipHash)
+	}
+	branch.Headers = make([]*domains.BlockHeader, 0, len(headers))
+	for _, h := range headers {
+		bh, err := h.toBlockHeader()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid header %s of archived branch %s", h.Hash, dba.TipHash)
+		}
+		branch.Headers = append(branch.Headers, bh)
+	}
+
+	if dba.Events == nil {
+		return branch, nil
+	}
+	var events []archivedEvent
+	if err := decompress(dba.Events, &events); err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress events of archived branch %s", dba.TipHash)
+	}
+	branch.Events = make([]*domains.ArchivedEvent, 0, len(events))
+	for _, e := range events {
+		branch.Events = append(branch.Events, &domains.ArchivedEvent{
+			HeaderHash: e.HeaderHash,
+			Operation:  e.Operation,
+			Event:      e.Event,
+			CreatedAt:  time.UnixMilli(e.CreatedAt),
+		})
+	}
+	return branch, nil
+}
+
+// archivedEvent is the archived representation of an event from the outbox.
+type archivedEvent struct {
+	HeaderHash string          `json:"headerHash"`
+	Operation  string          `json:"operation"`
+	Event      json.RawMessage `json:"event"`
+	CreatedAt  int64           `json:"createdAt"`
+}
+
+// CompressArchivedEvents compresses events moved from the outbox to the archive, nil when there are none.
+func CompressArchivedEvents(events []*DbOutboxEvent) ([]byte, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	archived := make([]archivedEvent, 0, len(events))
+	for _, e := range events {
+		archived = append(archived, archivedEvent{
+			HeaderHash: e.HeaderHash,
+			Operation:  e.Operation,
+			Event:      json.RawMessage(e.Event),
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return compress(archived)
+}
+
+// ToDbArchivedBranch converts ArchivedBranch to DbArchivedBranch, compressing its headers.
+func ToDbArchivedBranch(b *domains.ArchivedBranch) (*DbArchivedBranch, error) {
+	headers := make([]archivedHeader, 0, len(b.Headers))
+	hashes := make([]DbHash, 0, len(b.Headers))
+	for _, h := range b.Headers {
+		headers = append(headers, toArchivedHeader(h))
+		hashes = append(hashes, NewDbHash(h.Hash))
+	}
+	compressed, err := compress(headers)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compress archived branch %s", b.TipHash)
+	}
+
+	return &DbArchivedBranch{
+		TipHash:      ParseDbHash(b.TipHash),
+		TipHeight:    b.TipHeight,
+		Length:       b.Length,
+		State:        b.State.String(),
+		TipTimestamp: b.TipTimestamp.UnixMilli(),
+		ArchivedAt:   b.ArchivedAt.UnixMilli(),
+		Headers:      compressed,
+		Hashes:       hashes,
+	}, nil
+}
+
+func toArchivedHeader(h *domains.BlockHeader) archivedHeader {
+	return archivedHeader{
+		Hash:          h.Hash.String(),
+		Height:        h.Height,
+		Version:       h.Version,
+		MerkleRoot:    h.MerkleRoot.String(),
+		Timestamp:     h.Timestamp,
+		Bits:          h.Bits,
+		Nonce:         h.Nonce,
+		State:         h.State.String(),
+		Chainwork:     h.Chainwork.String(),
+		CumulatedWork: h.CumulatedWork.String(),
+		PreviousBlock: h.PreviousBlock.String(),
+	}
+}
+
+func (h archivedHeader) toBlockHeader() (*domains.BlockHeader, error) {
+	hash, err := chainhash.NewHashFromStr(h.Hash)
+	if err != nil {
+		return nil, err
+	}
+	merkleRoot, err := chainhash.NewHashFromStr(h.MerkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := chainhash.NewHashFromStr(h.PreviousBlock)
+	if err != nil {
+		return nil, err
+	}
+	chainwork, _ := new(big.Int).SetString(h.Chainwork, 10)
+	cumulatedWork, _ := new(big.Int).SetString(h.CumulatedWork, 10)
+
+	return &domains.BlockHeader{
+		Height:        h.Height,
+		Hash:          *hash,
+		Version:       h.Version,
+		MerkleRoot:    *merkleRoot,
+		Timestamp:     h.Timestamp,
+		Bits:          h.Bits,
+		Nonce:         h.Nonce,
+		State:         domains.HeaderState(h.State),
+		Chainwork:     chainwork,
+		CumulatedWork: cumulatedWork,
+		PreviousBlock: *previous,
+	}, nil
+}
+
+func compress(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(compressed []byte, v any) error {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+
+	return json.NewDecoder(zr).Decode(v)
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+const (
+	// decimalChunk is the number of decimal digits which always fit into a single big.Word.
+	decimalChunk = bits.UintSize*3/10 - 1
+
+	// workWords is the number of words reserved for each work, 128 bits cover the cumulated work of the whole chain.
+	workWords = 128 / bits.UintSize
+)
+
+// blockWork keeps both works of a header together with their digits, so parsing them from
+// a database row takes a single allocation as long as the values fit into workWords.
+type blockWork struct {
+	chainwork     big.Int
+	cumulatedWork big.Int
+	words         [2 * workWords]big.Word
+}
+
+// parseWork sets z to the value of decimal string s using buf as its storage,
+// an invalid or empty string results in zero.
+func parseWork(z *big.Int, s string, buf []big.Word) {
+	words := buf[:0]
+	for start := 0; start < len(s); {
+		end := min(start+decimalChunk, len(s))
+
+		chunk, pow := big.Word(0), big.Word(1)
+		for i := start; i < end; i++ {
+			d := s[i] - '0'
+			if d > 9 {
+				z.SetBits(buf[:0])
+				return
+			}
+			chunk = chunk*10 + big.Word(d)
+			pow *= 10
+		}
+		words = mulAddWord(words, pow, chunk)
+		start = end
+	}
+	z.SetBits(words)
+}
+
+// mulAddWord computes words*m + a in place, words being little endian digits of a natural number.
+func mulAddWord(words []big.Word, m, a big.Word) []big.Word {
+	carry := uint(a)
+	for i, w := range words {
+		hi, lo := bits.Mul(uint(w), uint(m))
+		lo, c := bits.Add(lo, carry, 0)
+		words[i] = big.Word(lo)
+		carry = hi + c
+	}
+	if carry != 0 {
+		words = append(words, big.Word(carry))
+	}
+	return words
+}
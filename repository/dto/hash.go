@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+)
+
+// DbHash is a hash stored in the database as 32 bytes. The bytes are kept in the order of the
+// hex string representation of the hash, so stored values sort and read the same as the strings.
+type DbHash []byte
+
+// NewDbHash converts the hash to DbHash.
+func NewDbHash(h chainhash.Hash) DbHash {
+	dbh := make(DbHash, chainhash.HashSize)
+	for i, b := range h {
+		dbh[chainhash.HashSize-1-i] = b
+	}
+	return dbh
+}
+
+// ParseDbHash converts the hex string representation of a hash to DbHash.
+// A string which is not a valid hex results in a value not matching any stored hash.
+func ParseDbHash(s string) DbHash {
+	dbh, err := hex.DecodeString(s)
+	if err != nil {
+		return DbHash(s)
+	}
+	return dbh
+}
+
+// ParseDbHashes converts hex string representations of hashes to DbHashes.
+func ParseDbHashes(s []string) []DbHash {
+	hashes := make([]DbHash, len(s))
+	for i, h := range s {
+		hashes[i] = ParseDbHash(h)
+	}
+	return hashes
+}
+
+// String returns the hex string representation of the hash.
+func (h DbHash) String() string {
+	return hex.EncodeToString(h)
+}
+
+// Value implements driver.Valuer, so the hash is stored as binary.
+func (h DbHash) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return []byte(h), nil
+}
+
+// Scan implements sql.Scanner, NULL results in nil hash.
+func (h *DbHash) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*h = nil
+	case []byte:
+		// The driver may reuse the buffer for the next row.
+		*h = bytes.Clone(v)
+	default:
+		return fmt.Errorf("cannot scan %T into DbHash", src)
+	}
+	return nil
+}
+
+// decode writes the hash into dst, a value of invalid length results in zero hash.
+func (h DbHash) decode(dst *chainhash.Hash) {
+	if len(h) != chainhash.HashSize {
+		*dst = chainhash.Hash{}
+		return
+	}
+	for i, b := range h {
+		dst[chainhash.HashSize-1-i] = b
+	}
+}
@@ -1,78 +1,83 @@
 package dto
 
 import (
-	"database/sql"
-	"math/big"
 	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/domains"
-	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 )
 
 // DbMerkleRoot is a database representation of a Merkle Root and it's height
 type DbMerkleRoot struct {
-	MerkleRoot string `db:"merkleroot"`
+	MerkleRoot DbHash `db:"merkleroot"`
 	Height     int32  `db:"height"`
 }
 
 // DbBlockHeader represent header saved in db.
 type DbBlockHeader struct {
 	Height        int32     `db:"height"`
-	Hash          string    `db:"hash"`
+	Hash          DbHash    `db:"hash"`
 	Version       int32     `db:"version"`
-	MerkleRoot    string    `db:"merkleroot"`
+	MerkleRoot    DbHash    `db:"merkleroot"`
 	Timestamp     time.Time `db:"timestamp"`
 	Bits          uint32    `db:"bits"`
 	Nonce         uint32    `db:"nonce"`
 	State         string    `db:"header_state"`
 	Chainwork     string    `db:"chainwork"`
 	CumulatedWork string    `db:"cumulated_work"`
-	PreviousBlock string    `db:"previous_block"`
+	PreviousBlock DbHash    `db:"previous_block"`
 }
 
-// ToBlockHeader converts work from string to big.Int and return BlockHeader.
-func (dbh *DbBlockHeader) ToBlockHeader() *domains.BlockHeader {
-	if dbh.CumulatedWork == "" {
-		dbh.CumulatedWork = "0"
-	}
-	cumulatedWork, ok := new(big.Int).SetString(dbh.CumulatedWork, 10)
-	if !ok {
-		cumulatedWork = big.NewInt(0)
-	}
+// DbStateChange represent change of state of the headers saved in db.
+type DbStateChange struct {
+	Hashes []string
+	State  string
+}
 
-	chainWork, ok := new(big.Int).SetString(dbh.Chainwork, 10)
-	if !ok {
-		chainWork = big.NewInt(0)
+// ToDbStateChange converts StateChange to DbStateChange.
+func ToDbStateChange(c domains.StateChange) DbStateChange {
+	hashes := make([]string, 0, len(c.Hashes))
+	for _, h := range c.Hashes {
+		hashes = append(hashes, h.String())
 	}
+	return DbStateChange{Hashes: hashes, State: c.State.String()}
+}
 
-	hash, _ := chainhash.NewHashFromStr(dbh.Hash)
-	merkleTree, _ := chainhash.NewHashFromStr(dbh.MerkleRoot)
-	prevBlock, _ := chainhash.NewHashFromStr(dbh.PreviousBlock)
-
-	return &domains.BlockHeader{
-		Height:        dbh.Height,
-		Hash:          *hash,
-		Version:       dbh.Version,
-		MerkleRoot:    *merkleTree,
-		Timestamp:     dbh.Timestamp,
-		Bits:          dbh.Bits,
-		Nonce:         dbh.Nonce,
-		Chainwork:     chainWork,
-		CumulatedWork: cumulatedWork,
-		State:         domains.HeaderState(dbh.State),
-		PreviousBlock: *prevBlock,
-	}
+// ToBlockHeader converts work from string to big.Int and return BlockHeader.
+func (dbh *DbBlockHeader) ToBlockHeader() *domains.BlockHeader {
+	bh := new(domains.BlockHeader)
+	dbh.toBlockHeader(bh)
+	return bh
+}
+
+func (dbh *DbBlockHeader) toBlockHeader(bh *domains.BlockHeader) {
+	work := new(blockWork)
+	parseWork(&work.chainwork, dbh.Chainwork, work.words[:workWords:workWords])
+	parseWork(&work.cumulatedWork, dbh.CumulatedWork, work.words[workWords:])
+
+	bh.Height = dbh.Height
+	dbh.Hash.decode(&bh.Hash)
+	bh.Version = dbh.Version
+	dbh.MerkleRoot.decode(&bh.MerkleRoot)
+	bh.Timestamp = dbh.Timestamp
+	bh.Bits = dbh.Bits
+	bh.Nonce = dbh.Nonce
+	bh.Chainwork = &work.chainwork
+	bh.CumulatedWork = &work.cumulatedWork
+	bh.State = domains.HeaderState(dbh.State)
+	dbh.PreviousBlock.decode(&bh.PreviousBlock)
 }
 
 // ConvertToBlockHeader converts one or whole slice of DbBlockHeaders to BlockHeaders
 // used after getting records from db.
 func ConvertToBlockHeader(dbBlockHeaders []*DbBlockHeader) []*domains.BlockHeader {
 	if dbBlockHeaders != nil {
-		var blockHeaders []*domains.BlockHeader
+		// Headers share one backing array instead of being allocated one by one.
+		headers := make([]domains.BlockHeader, len(dbBlockHeaders))
+		blockHeaders := make([]*domains.BlockHeader, len(dbBlockHeaders))
 
-		for _, header := range dbBlockHeaders {
-			h := header.ToBlockHeader()
-			blockHeaders = append(blockHeaders, h)
+		for i, header := range dbBlockHeaders {
+			header.toBlockHeader(&headers[i])
+			blockHeaders[i] = &headers[i]
 		}
 		return blockHeaders
 	}
@@ -84,26 +89,29 @@ func ConvertToBlockHeader(dbBlockHeaders []*DbBlockHeader) []*domains.BlockHeade
 func ToDbBlockHeader(bh domains.BlockHeader) DbBlockHeader {
 	return DbBlockHeader{
 		Height:        bh.Height,
-		Hash:          bh.Hash.String(),
+		Hash:          NewDbHash(bh.Hash),
 		Version:       bh.Version,
-		MerkleRoot:    bh.MerkleRoot.String(),
+		MerkleRoot:    NewDbHash(bh.MerkleRoot),
 		Timestamp:     bh.Timestamp,
 		Bits:          bh.Bits,
 		Nonce:         bh.Nonce,
 		State:         bh.State.String(),
 		Chainwork:     bh.Chainwork.String(),
 		CumulatedWork: bh.CumulatedWork.String(),
-		PreviousBlock: bh.PreviousBlock.String(),
+		PreviousBlock: NewDbHash(bh.PreviousBlock),
 	}
 }
 
 // DbMerkleRootConfirmation is a database representation of a Confirmation
 // of Merkle Root inclusion in the longest chain.
 type DbMerkleRootConfirmation struct {
-	MerkleRoot  string         `db:"merkleroot"`
-	BlockHeight int32          `db:"blockheight"`
-	Hash        sql.NullString `db:"hash"`
-	TipHeight   int32          `db:"tipheight"`
+	MerkleRoot  DbHash `db:"merkleroot"`
+	BlockHeight int32  `db:"blockheight"`
+	// Hash is nil when the merkle root is not found in the longest chain.
+	Hash      DbHash `db:"hash"`
+	TipHeight int32  `db:"tipheight"`
+	// Unverified is set when the merkle root couldn't be looked up, because the query of its batch failed.
+	Unverified bool `db:"-"`
 }
 
 // ToMerkleRootConfirmation converts DbMerkleRootConfirmation to domain's
@@ -113,7 +121,9 @@ func (dbMerkleConfm *DbMerkleRootConfirmation) ToMerkleRootConfirmation(
 ) *domains.MerkleRootConfirmation {
 	var confmState domains.MerkleRootConfirmationState
 
-	if dbMerkleConfm.Hash.Valid {
+	if dbMerkleConfm.Unverified {
+		confmState = domains.UnableToVerify
+	} else if dbMerkleConfm.Hash != nil {
 		confmState = domains.Confirmed
 	} else if dbMerkleConfm.BlockHeight > dbMerkleConfm.TipHeight &&
 		dbMerkleConfm.BlockHeight-dbMerkleConfm.TipHeight <= int32(maxBlockHeightExcess) {
@@ -123,9 +133,9 @@ func (dbMerkleConfm *DbMerkleRootConfirmation) ToMerkleRootConfirmation(
 	}
 
 	c := &domains.MerkleRootConfirmation{
-		MerkleRoot:   dbMerkleConfm.MerkleRoot,
+		MerkleRoot:   dbMerkleConfm.MerkleRoot.String(),
 		BlockHeight:  dbMerkleConfm.BlockHeight,
-		Hash:         dbMerkleConfm.Hash.String,
+		Hash:         dbMerkleConfm.Hash.String(),
 		Confirmation: confmState,
 	}
 
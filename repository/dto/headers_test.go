@@ -0,0 +1,131 @@
+package dto_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/repository/dto"
+)
+
+func TestToBlockHeader(t *testing.T) {
+	// given
+	_, tip := fixtures.LongestChain()
+	dbHeader := dto.ToDbBlockHeader(*tip)
+
+	// when
+	h := dbHeader.ToBlockHeader()
+
+	// then
+	assert.Equal(t, h.Hash, tip.Hash)
+	assert.Equal(t, h.PreviousBlock, tip.PreviousBlock)
+	assert.Equal(t, h.MerkleRoot, tip.MerkleRoot)
+	assert.Equal(t, h.Chainwork.Cmp(tip.Chainwork), 0)
+	assert.Equal(t, h.CumulatedWork.Cmp(tip.CumulatedWork), 0)
+	assert.Equal(t, h.State, tip.State)
+}
+
+func TestToBlockHeaderParsesWork(t *testing.T) {
+	for _, work := range []string{
+		"0",
+		"4295032833",
+		"18446744073709551616",
+		"1445197339467186930496722452125486521053208734",
+		"340282366920938463463374607431768211457123456789012345678901234567890",
+	} {
+		t.Run(work, func(t *testing.T) {
+			// given
+			_, tip := fixtures.LongestChain()
+			dbHeader := dto.ToDbBlockHeader(*tip)
+			dbHeader.Chainwork = work
+			dbHeader.CumulatedWork = work
+			expected, _ := new(big.Int).SetString(work, 10)
+
+			// when
+			h := dbHeader.ToBlockHeader()
+
+			// then
+			assert.Equal(t, h.Chainwork.String(), expected.String())
+			assert.Equal(t, h.CumulatedWork.String(), expected.String())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		// given
+		_, tip := fixtures.LongestChain()
+		dbHeader := dto.ToDbBlockHeader(*tip)
+		dbHeader.Chainwork = "12a"
+		dbHeader.CumulatedWork = ""
+
+		// when
+		h := dbHeader.ToBlockHeader()
+
+		// then
+		assert.Equal(t, h.Chainwork.Sign(), 0)
+		assert.Equal(t, h.CumulatedWork.Sign(), 0)
+	})
+}
+
+func TestToMerkleRootConfirmation(t *testing.T) {
+	hash := dto.ParseDbHash("0000000000000000000000000000000000000000000000000000000000000001")
+
+	testCases := map[string]struct {
+		confirmation dto.DbMerkleRootConfirmation
+		expected     domains.MerkleRootConfirmationState
+	}{
+		"found in the longest chain": {
+			confirmation: dto.DbMerkleRootConfirmation{BlockHeight: 10, Hash: hash, TipHeight: 20},
+			expected:     domains.Confirmed,
+		},
+		"not found in the longest chain": {
+			confirmation: dto.DbMerkleRootConfirmation{BlockHeight: 10, TipHeight: 20},
+			expected:     domains.Invalid,
+		},
+		"slightly above the tip": {
+			confirmation: dto.DbMerkleRootConfirmation{BlockHeight: 22, TipHeight: 20},
+			expected:     domains.UnableToVerify,
+		},
+		"in a failed batch": {
+			confirmation: dto.DbMerkleRootConfirmation{BlockHeight: 10, Unverified: true},
+			expected:     domains.UnableToVerify,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			c := tc.confirmation.ToMerkleRootConfirmation(5)
+
+			// then
+			assert.Equal(t, c.Confirmation, tc.expected)
+		})
+	}
+}
+
+func BenchmarkToBlockHeader(b *testing.B) {
+	_, tip := fixtures.LongestChain()
+	dbHeader := dto.ToDbBlockHeader(*tip)
+	dbHeader.CumulatedWork = "1445197339467186930496722452125486521053208734"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = dbHeader.ToBlockHeader()
+	}
+}
+
+func BenchmarkConvertToBlockHeader(b *testing.B) {
+	_, tip := fixtures.LongestChain()
+	dbHeaders := make([]*dto.DbBlockHeader, 2000)
+	for i := range dbHeaders {
+		h := dto.ToDbBlockHeader(*tip)
+		h.CumulatedWork = "1445197339467186930496722452125486521053208734"
+		dbHeaders[i] = &h
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = dto.ConvertToBlockHeader(dbHeaders)
+	}
+}
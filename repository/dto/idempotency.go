@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// DbIdempotencyKey represent idempotency key with the stored response saved in db.
+type DbIdempotencyKey struct {
+	Key         string `db:"idempotency_key"`
+	RequestHash string `db:"request_hash"`
+	StatusCode  int    `db:"status_code"`
+	ContentType string `db:"content_type"`
+	Body        string `db:"body"`
+	CreatedAt   int64  `db:"created_at"`
+	ExpiresAt   int64  `db:"expires_at"`
+}
+
+// ToIdempotencyKey converts DbIdempotencyKey to IdempotencyKey.
+func (dbk *DbIdempotencyKey) ToIdempotencyKey() *domains.IdempotencyKey {
+	return &domains.IdempotencyKey{
+		Key:         dbk.Key,
+		RequestHash: dbk.RequestHash,
+		StatusCode:  dbk.StatusCode,
+		ContentType: dbk.ContentType,
+		Body:        []byte(dbk.Body),
+		CreatedAt:   time.UnixMilli(dbk.CreatedAt),
+		ExpiresAt:   time.UnixMilli(dbk.ExpiresAt),
+	}
+}
+
+// ToDbIdempotencyKey converts IdempotencyKey to DbIdempotencyKey.
+func ToDbIdempotencyKey(k *domains.IdempotencyKey) *DbIdempotencyKey {
+	return &DbIdempotencyKey{
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		StatusCode:  k.StatusCode,
+		ContentType: k.ContentType,
+		Body:        string(k.Body),
+		CreatedAt:   k.CreatedAt.UnixMilli(),
+		ExpiresAt:   k.ExpiresAt.UnixMilli(),
+	}
+}
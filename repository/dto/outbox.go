@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/pkg/errors"
+)
+
+// DbOutboxEvent represent event about a header saved in db together with the header.
+type DbOutboxEvent struct {
+	ID           int64  `db:"id"`
+	HeaderHash   string `db:"header_hash"`
+	Operation    string `db:"operation"`
+	Event        string `db:"event"`
+	LatestOnly   bool   `db:"latest_only"`
+	ClaimedUntil int64  `db:"claimed_until"`
+	CreatedAt    int64  `db:"created_at"`
+}
+
+// ToOutboxEvent converts DbOutboxEvent to OutboxEvent, decoding the event by its operation.
+func (dbe *DbOutboxEvent) ToOutboxEvent() (*notification.OutboxEvent, error) {
+	var event notification.Event
+	if domains.HeaderEventType(dbe.Operation) == domains.EventReorg {
+		event = &domains.ReorgEvent{}
+	} else {
+		event = &domains.HeaderEvent{}
+	}
+	if err := json.Unmarshal([]byte(dbe.Event), event); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode outbox event %d", dbe.ID)
+	}
+
+	return &notification.OutboxEvent{
+		ID:         dbe.ID,
+		HeaderHash: dbe.HeaderHash,
+		Event:      event,
+		LatestOnly: dbe.LatestOnly,
+		CreatedAt:  time.UnixMilli(dbe.CreatedAt),
+	}, nil
+}
+
+// ToDbOutboxEvent converts OutboxEvent to DbOutboxEvent.
+func ToDbOutboxEvent(e *notification.OutboxEvent) (*DbOutboxEvent, error) {
+	event, err := json.Marshal(e.Event)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode outbox event of header %s", e.HeaderHash)
+	}
+
+	return &DbOutboxEvent{
+		ID:         e.ID,
+		HeaderHash: e.HeaderHash,
+		Operation:  string(outboxOperation(e.Event)),
+		Event:      string(event),
+		LatestOnly: e.LatestOnly,
+		CreatedAt:  e.CreatedAt.UnixMilli(),
+	}, nil
+}
+
+func outboxOperation(event notification.Event) domains.HeaderEventType {
+	switch e := event.(type) {
+	case *domains.HeaderEvent:
+		return e.Operation
+	case *domains.ReorgEvent:
+		return e.Operation
+	default:
+		return ""
+	}
+}
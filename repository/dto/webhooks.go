@@ -41,3 +41,37 @@ func ToDbWebhook(t *notification.Webhook) *DbWebhook {
 		Active:      t.Active,
 	}
 }
+
+// DbWebhookDelivery represent pending delivery of an event to webhook saved in db.
+type DbWebhookDelivery struct {
+	ID            string `db:"id"`
+	URL           string `db:"url"`
+	Event         string `db:"event"`
+	Attempts      int    `db:"attempts"`
+	NextAttemptAt int64  `db:"next_attempt_at"`
+	CreatedAt     int64  `db:"created_at"`
+}
+
+// ToWebhookDelivery converts DbWebhookDelivery to WebhookDelivery.
+func (dbd *DbWebhookDelivery) ToWebhookDelivery() *notification.WebhookDelivery {
+	return &notification.WebhookDelivery{
+		ID:            dbd.ID,
+		URL:           dbd.URL,
+		Event:         []byte(dbd.Event),
+		Attempts:      dbd.Attempts,
+		NextAttemptAt: time.UnixMilli(dbd.NextAttemptAt),
+		CreatedAt:     time.UnixMilli(dbd.CreatedAt),
+	}
+}
+
+// ToDbWebhookDelivery converts WebhookDelivery to DbWebhookDelivery.
+func ToDbWebhookDelivery(d *notification.WebhookDelivery) *DbWebhookDelivery {
+	return &DbWebhookDelivery{
+		ID:            d.ID,
+		URL:           d.URL,
+		Event:         string(d.Event),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt.UnixMilli(),
+		CreatedAt:     d.CreatedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// QueuedHeaders is a Headers decorator which puts new headers on a bounded queue and writes them
+// to the storage in batches from a dedicated goroutine, so a slow disk doesn't stall the caller.
+//
+// Headers waiting in the queue are visible to lookups by hash and height. Every other operation
+// waits until the queue is written, so it always sees the same data as the underlying storage.
+//
+// A batch which fails to be written is retried. When it can't be written at all, the queue stops
+// accepting headers and every following write returns the error, as the headers which were
+// already accepted and announced are lost and their descendants would be stored without them.
+type QueuedHeaders struct {
+	Headers
+	queue     chan domains.BlockHeader
+	batchSize int
+	log       *zerolog.Logger
+
+	mu       sync.Mutex
+	written  *sync.Cond
+	byHash   map[string]domains.BlockHeader
+	byHeight map[int32]domains.BlockHeader
+	inFlight int
+	// err is the error of the batch which couldn't be written.
+	err error
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+const (
+	// writeAttempts is the number of times a batch is written before the queue gives up.
+	writeAttempts = 5
+	// writeRetryDelay is the delay before the first retry of a failed batch, doubled with every next one.
+	writeRetryDelay = 50 * time.Millisecond
+)
+
+// NewQueuedHeaders creates QueuedHeaders holding up to size headers in the queue
+// and starts the writer, which stores up to batchSize headers at once.
+func NewQueuedHeaders(headers Headers, size, batchSize int, log *zerolog.Logger) *QueuedHeaders {
+	queueLogger := log.With().Str("subservice", "write-queue").Logger()
+	q := &QueuedHeaders{
+		Headers:   headers,
+		queue:     make(chan domains.BlockHeader, size),
+		batchSize: batchSize,
+		log:       &queueLogger,
+		byHash:    make(map[string]domains.BlockHeader),
+		byHeight:  make(map[int32]domains.BlockHeader),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	q.written = sync.NewCond(&q.mu)
+
+	go q.writer()
+	return q
+}
+
+// Close writes the remaining queued headers and stops the writer.
+func (q *QueuedHeaders) Close() {
+	close(q.quit)
+	<-q.done
+}
+
+// AddHeaderToDatabase puts the header on the queue, blocking while the queue is full.
+// It returns the write error once a queued batch couldn't be written.
+func (q *QueuedHeaders) AddHeaderToDatabase(header domains.BlockHeader) error {
+	q.mu.Lock()
+	if q.err != nil {
+		q.mu.Unlock()
+		return q.err
+	}
+	q.byHash[header.Hash.String()] = header
+	if header.IsLongestChain() {
+		q.byHeight[header.Height] = header
+	}
+	q.inFlight++
+	q.mu.Unlock()
+
+	q.queue <- header
+	metrics.SetWriteQueueLength(len(q.queue))
+	return nil
+}
+
+// GetHeaderByHash returns header with given hash.
+func (q *QueuedHeaders) GetHeaderByHash(hash string) (*domains.BlockHeader, error) {
+	q.mu.Lock()
+	h, ok := q.byHash[hash]
+	q.mu.Unlock()
+	if ok {
+		return &h, nil
+	}
+	return q.Headers.GetHeaderByHash(hash)
+}
+
+// GetHeaderByHeight returns header from the longest chain on given height.
+func (q *QueuedHeaders) GetHeaderByHeight(height int32) (*domains.BlockHeader, error) {
+	q.mu.Lock()
+	h, ok := q.byHeight[height]
+	q.mu.Unlock()
+	if ok {
+		return &h, nil
+	}
+	return q.Headers.GetHeaderByHeight(height)
+}
+
+// AddMultipleHeadersToDatabase adds multiple new headers to the storage.
+func (q *QueuedHeaders) AddMultipleHeadersToDatabase(headers []domains.BlockHeader) error {
+	if err := q.flushed(); err != nil {
+		return err
+	}
+	return q.Headers.AddMultipleHeadersToDatabase(headers)
+}
+
+// AddHeadersWithEvents adds new headers to the storage together with state changes and events about them, after writing the pending ones.
+// It doesn't go through the queue, the events are dispatched only once the headers are stored.
+func (q *QueuedHeaders) AddHeadersWithEvents(headers []domains.BlockHeader, changes []domains.StateChange, events []*notification.OutboxEvent) error {
+	if err := q.flushed(); err != nil {
+		return err
+	}
+	return q.Headers.AddHeadersWithEvents(headers, changes, events)
+}
+
+// UpdateState changes state of headers with given hashes.
+func (q *QueuedHeaders) UpdateState(hashes []chainhash.Hash, state domains.HeaderState) error {
+	if err := q.flushed(); err != nil {
+		return err
+	}
+	return q.Headers.UpdateState(hashes, state)
+}
+
+// DeleteHeaders deletes headers with given hashes, after writing the pending ones.
+func (q *QueuedHeaders) DeleteHeaders(hashes []chainhash.Hash) (int, error) {
+	if err := q.flushed(); err != nil {
+		return 0, err
+	}
+	return q.Headers.DeleteHeaders(hashes)
+}
+
+// DeleteHeadersAboveHeight deletes headers higher than given height, after writing the pending ones.
+func (q *QueuedHeaders) DeleteHeadersAboveHeight(height int32) (int, error) {
+	if err := q.flushed(); err != nil {
+		return 0, err
+	}
+	return q.Headers.DeleteHeadersAboveHeight(height)
+}
+
+// ArchiveBranches moves headers of the branches to the archive, after writing the pending ones.
+func (q *QueuedHeaders) ArchiveBranches(branches []*domains.ArchivedBranch) (int, error) {
+	if err := q.flushed(); err != nil {
+		return 0, err
+	}
+	return q.Headers.ArchiveBranches(branches)
+}
+
+// ReplaceHeaders replaces all stored headers with the swap function, after writing the pending ones.
+// Headers which couldn't be written don't matter anymore, so a successful swap makes the queue accept headers again.
+func (q *QueuedHeaders) ReplaceHeaders(swap func() error) error {
+	q.flush()
+	if err := q.Headers.ReplaceHeaders(swap); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.err = nil
+	q.mu.Unlock()
+	return nil
+}
+
+// GetHeaderByHeightRange returns headers from given height range which satisfy the query.
+func (q *QueuedHeaders) GetHeaderByHeightRange(from int, to int, query domains.HeadersQuery) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetHeaderByHeightRange(from, to, query)
+}
+
+// StreamHeadersByHeightRange passes headers from given height range which satisfy the query one by one to fn.
+func (q *QueuedHeaders) StreamHeadersByHeightRange(from int, to int, query domains.HeadersQuery, fn func(*domains.BlockHeader) error) error {
+	q.flush()
+	return q.Headers.StreamHeadersByHeightRange(from, to, query, fn)
+}
+
+// GetLongestChainHeadersFromHeight returns headers from the longest chain starting from given height.
+func (q *QueuedHeaders) GetLongestChainHeadersFromHeight(height int32) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetLongestChainHeadersFromHeight(height)
+}
+
+// GetStaleChainHeadersBackFrom returns stale headers going back from the header with given hash.
+func (q *QueuedHeaders) GetStaleChainHeadersBackFrom(hash string) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetStaleChainHeadersBackFrom(hash)
+}
+
+// GetCurrentHeight returns the highest height of stored headers.
+func (q *QueuedHeaders) GetCurrentHeight() (int, error) {
+	q.flush()
+	return q.Headers.GetCurrentHeight()
+}
+
+// GetHeadersCount returns the number of stored headers.
+func (q *QueuedHeaders) GetHeadersCount() (int, error) {
+	q.flush()
+	return q.Headers.GetHeadersCount()
+}
+
+// GetMerkleRootsConfirmations returns confirmations of given merkle roots.
+func (q *QueuedHeaders) GetMerkleRootsConfirmations(
+	request []domains.MerkleRootConfirmationRequestItem,
+	maxBlockHeightExcess int,
+) ([]*domains.MerkleRootConfirmation, error) {
+	q.flush()
+	return q.Headers.GetMerkleRootsConfirmations(request, maxBlockHeightExcess)
+}
+
+// GetMerkleRoots returns a page of merkle roots from the longest chain.
+func (q *QueuedHeaders) GetMerkleRoots(batchSize int, lastEvaluatedKey string) (*domains.MerkleRootsESKPagedResponse, error) {
+	q.flush()
+	return q.Headers.GetMerkleRoots(batchSize, lastEvaluatedKey)
+}
+
+// GenesisExists checks if the genesis header is stored.
+func (q *QueuedHeaders) GenesisExists() bool {
+	q.flush()
+	return q.Headers.GenesisExists()
+}
+
+// GetPreviousHeader returns the previous header of the header with given hash.
+func (q *QueuedHeaders) GetPreviousHeader(hash string) (*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetPreviousHeader(hash)
+}
+
+// GetTip returns the tip of the longest chain.
+func (q *QueuedHeaders) GetTip() (*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetTip()
+}
+
+// GetAllTips returns tips of all chains.
+func (q *QueuedHeaders) GetAllTips() ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetAllTips()
+}
+
+// GetAncestorOnHeight returns the ancestor of the header with given hash on given height.
+func (q *QueuedHeaders) GetAncestorOnHeight(hash string, height int32) (*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetAncestorOnHeight(hash, height)
+}
+
+// GetChainBetweenTwoHashes returns the chain of headers between two given hashes.
+func (q *QueuedHeaders) GetChainBetweenTwoHashes(low string, high string) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetChainBetweenTwoHashes(low, high)
+}
+
+// GetHeadersStartHeight returns the height of the first known header from given hashes.
+func (q *QueuedHeaders) GetHeadersStartHeight(hashtable []string) (int, error) {
+	q.flush()
+	return q.Headers.GetHeadersStartHeight(hashtable)
+}
+
+// GetHeadersByHeightRange returns headers from the longest chain in given height range.
+func (q *QueuedHeaders) GetHeadersByHeightRange(from int, to int) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetHeadersByHeightRange(from, to)
+}
+
+// GetSampledHeadersByHeightRange returns every interval-th header of the longest chain in given height range.
+func (q *QueuedHeaders) GetSampledHeadersByHeightRange(from int, to int, interval int) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetSampledHeadersByHeightRange(from, to, interval)
+}
+
+// GetHeadersByHashes returns the headers with given hashes, unknown hashes are skipped.
+func (q *QueuedHeaders) GetHeadersByHashes(hashes []string) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetHeadersByHashes(hashes)
+}
+
+// GetLongestChainHeadersByHeights returns the headers of the longest chain at given heights, heights above the tip are skipped.
+func (q *QueuedHeaders) GetLongestChainHeadersByHeights(heights []int32) ([]*domains.BlockHeader, error) {
+	q.flush()
+	return q.Headers.GetLongestChainHeadersByHeights(heights)
+}
+
+// GetHeadersCountByState returns the number of headers in each state from given height.
+func (q *QueuedHeaders) GetHeadersCountByState(fromHeight int32) (map[domains.HeaderState]int, error) {
+	q.flush()
+	return q.Headers.GetHeadersCountByState(fromHeight)
+}
+
+// GetHeadersStopHeight returns the height of the header with given hash.
+func (q *QueuedHeaders) GetHeadersStopHeight(hashStop string) (int, error) {
+	q.flush()
+	return q.Headers.GetHeadersStopHeight(hashStop)
+}
+
+// flush waits until all queued headers are written to the storage.
+func (q *QueuedHeaders) flush() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.inFlight > 0 {
+		q.written.Wait()
+	}
+}
+
+// flushed waits until all queued headers are written and returns the error of a batch which couldn't be.
+func (q *QueuedHeaders) flushed() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.inFlight > 0 {
+		q.written.Wait()
+	}
+	return q.err
+}
+
+func (q *QueuedHeaders) writer() {
+	defer close(q.done)
+
+	batch := make([]domains.BlockHeader, 0, q.batchSize)
+	for {
+		select {
+		case h := <-q.queue:
+			batch = append(batch[:0], h)
+		case <-q.quit:
+			// Headers can't be enqueued anymore at this point, so whatever is left is the last batch.
+			for len(q.queue) > 0 {
+				q.write(q.take(batch[:0]))
+			}
+			return
+		}
+		q.write(q.take(batch))
+	}
+}
+
+// take fills the batch with headers already waiting in the queue, without blocking.
+func (q *QueuedHeaders) take(batch []domains.BlockHeader) []domains.BlockHeader {
+	for len(batch) < q.batchSize {
+		select {
+		case h := <-q.queue:
+			batch = append(batch, h)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (q *QueuedHeaders) write(batch []domains.BlockHeader) {
+	start := time.Now()
+	q.mu.Lock()
+	err := q.err
+	q.mu.Unlock()
+	if err == nil {
+		err = q.store(batch)
+	}
+	metrics.ObserveWriteQueueFlush(len(batch), time.Since(start))
+	metrics.SetWriteQueueLength(len(q.queue))
+
+	q.mu.Lock()
+	if err != nil {
+		q.log.Error().Msgf("dropped %d queued headers starting at height %d, headers aren't accepted anymore: %v", len(batch), batch[0].Height, err)
+		if q.err == nil {
+			q.err = errors.Wrapf(err, "failed to write queued headers starting at height %d", batch[0].Height)
+		}
+	}
+	for _, h := range batch {
+		delete(q.byHash, h.Hash.String())
+		if p, ok := q.byHeight[h.Height]; ok && p.Hash == h.Hash {
+			delete(q.byHeight, h.Height)
+		}
+	}
+	q.inFlight -= len(batch)
+	q.written.Broadcast()
+	q.mu.Unlock()
+}
+
+// store writes the batch, retrying it with a growing delay when it fails.
+func (q *QueuedHeaders) store(batch []domains.BlockHeader) error {
+	delay := writeRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := q.Headers.AddMultipleHeadersToDatabase(batch)
+		if err == nil || attempt == writeAttempts {
+			return err
+		}
+		q.log.Warn().Msgf("failed to write %d queued headers starting at height %d, retrying in %s: %v", len(batch), batch[0].Height, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,112 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+func TestQueuedHeadersReadsAfterWrite(t *testing.T) {
+	// given
+	chain, tip := fixtures.LongestChain()
+	array := []domains.BlockHeader{chain[0]}
+	log := zerolog.Nop()
+	queued := repository.NewQueuedHeaders(testrepository.NewHeadersTestRepository(&array), 2, 3, &log)
+	defer queued.Close()
+
+	// when
+	for _, h := range chain[1:] {
+		err := queued.AddHeaderToDatabase(h)
+		assert.NoError(t, err)
+	}
+	actualTip, err := queued.GetTip()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, actualTip.Hash, tip.Hash)
+}
+
+func TestQueuedHeadersWritesQueueOnClose(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChain()
+	array := []domains.BlockHeader{chain[0]}
+	log := zerolog.Nop()
+	queued := repository.NewQueuedHeaders(testrepository.NewHeadersTestRepository(&array), 10, 2, &log)
+
+	// when
+	for _, h := range chain[1:] {
+		err := queued.AddHeaderToDatabase(h)
+		assert.NoError(t, err)
+	}
+	queued.Close()
+
+	// then
+	assert.Equal(t, len(array), len(chain))
+}
+
+func TestQueuedHeadersRetriesFailedWrite(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChain()
+	array := []domains.BlockHeader{chain[0]}
+	log := zerolog.Nop()
+	failing := &failingHeaders{Headers: testrepository.NewHeadersTestRepository(&array), failures: 2}
+	queued := repository.NewQueuedHeaders(failing, 10, 10, &log)
+	defer queued.Close()
+
+	// when
+	for _, h := range chain[1:] {
+		err := queued.AddHeaderToDatabase(h)
+		assert.NoError(t, err)
+	}
+	count, err := queued.GetHeadersCount()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, count, len(chain))
+}
+
+func TestQueuedHeadersStopsAcceptingAfterFailedWrite(t *testing.T) {
+	// given
+	chain, _ := fixtures.LongestChain()
+	array := []domains.BlockHeader{chain[0]}
+	log := zerolog.Nop()
+	failing := &failingHeaders{Headers: testrepository.NewHeadersTestRepository(&array), failures: 100}
+	queued := repository.NewQueuedHeaders(failing, 10, 10, &log)
+	defer queued.Close()
+
+	// when
+	err := queued.AddHeaderToDatabase(chain[1])
+	assert.NoError(t, err)
+	updateErr := queued.UpdateState(nil, domains.LongestChain)
+	addErr := queued.AddHeaderToDatabase(chain[2])
+
+	// then
+	assert.Equal(t, errors.Is(updateErr, errFailingWrite), true)
+	assert.Equal(t, errors.Is(addErr, errFailingWrite), true)
+	_, err = queued.GetHeaderByHash(chain[1].Hash.String())
+	assert.Equal(t, errors.Is(err, bhserrors.ErrHeaderNotFound), true)
+	assert.Equal(t, len(array), 1)
+}
+
+var errFailingWrite = errors.New("disk I/O error")
+
+// failingHeaders fails to write headers the given number of times.
+type failingHeaders struct {
+	repository.Headers
+	failures int
+}
+
+func (f *failingHeaders) AddMultipleHeadersToDatabase(headers []domains.BlockHeader) error {
+	if f.failures > 0 {
+		f.failures--
+		return errFailingWrite
+	}
+	return f.Headers.AddMultipleHeadersToDatabase(headers)
+}
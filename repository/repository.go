@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -10,14 +12,23 @@ import (
 type Headers interface {
 	AddHeaderToDatabase(domains.BlockHeader) error
 	AddMultipleHeadersToDatabase([]domains.BlockHeader) error
+	AddHeadersWithEvents([]domains.BlockHeader, []domains.StateChange, []*notification.OutboxEvent) error
 	UpdateState([]chainhash.Hash, domains.HeaderState) error
+	DeleteHeaders([]chainhash.Hash) (int, error)
+	DeleteHeadersAboveHeight(height int32) (int, error)
+	ArchiveBranches([]*domains.ArchivedBranch) (int, error)
+	ReplaceHeaders(swap func() error) error
 	GetHeaderByHeight(height int32) (*domains.BlockHeader, error)
-	GetHeaderByHeightRange(from int, to int) ([]*domains.BlockHeader, error)
+	GetHeaderByHeightRange(from int, to int, query domains.HeadersQuery) ([]*domains.BlockHeader, error)
+	StreamHeadersByHeightRange(from int, to int, query domains.HeadersQuery, fn func(*domains.BlockHeader) error) error
 	GetLongestChainHeadersFromHeight(height int32) ([]*domains.BlockHeader, error)
 	GetStaleChainHeadersBackFrom(hash string) ([]*domains.BlockHeader, error)
 	GetCurrentHeight() (int, error)
 	GetHeadersCount() (int, error)
+	GetHeadersCountByState(fromHeight int32) (map[domains.HeaderState]int, error)
 	GetHeaderByHash(hash string) (*domains.BlockHeader, error)
+	GetHeadersByHashes(hashes []string) ([]*domains.BlockHeader, error)
+	GetLongestChainHeadersByHeights(heights []int32) ([]*domains.BlockHeader, error)
 	GetMerkleRootsConfirmations(request []domains.MerkleRootConfirmationRequestItem, maxBlockHeightExcess int) ([]*domains.MerkleRootConfirmation, error)
 	GetMerkleRoots(batchSize int, lastEvaluatedKey string) (*domains.MerkleRootsESKPagedResponse, error)
 	GenesisExists() bool
@@ -28,6 +39,7 @@ type Headers interface {
 	GetChainBetweenTwoHashes(low string, high string) ([]*domains.BlockHeader, error)
 	GetHeadersStartHeight(hashtable []string) (int, error)
 	GetHeadersByHeightRange(from int, to int) ([]*domains.BlockHeader, error)
+	GetSampledHeadersByHeightRange(from int, to int, interval int) ([]*domains.BlockHeader, error)
 	GetHeadersStopHeight(hashStop string) (int, error)
 }
 
@@ -38,9 +50,51 @@ type Tokens interface {
 	DeleteToken(token string) error
 }
 
+// Leases is a interface which represents methods performed on leader_lease table in defined storage.
+type Leases interface {
+	TryAcquireLease(name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(name, holder string) error
+}
+
+// IdempotencyKeys is a interface which represents methods performed on idempotency_keys table in defined storage.
+type IdempotencyKeys interface {
+	ClaimIdempotencyKey(key *domains.IdempotencyKey, now time.Time) (*domains.IdempotencyKey, error)
+	CompleteIdempotencyKey(key *domains.IdempotencyKey) error
+	DeleteIdempotencyKey(key string) error
+	DeleteExpiredIdempotencyKeys(now time.Time) (int, error)
+}
+
+// Archive is a interface which represents methods performed on archived_branches table in defined storage.
+// Branches are moved to it from the header table by Headers.ArchiveBranches.
+type Archive interface {
+	GetArchivedBranches(limit int) ([]*domains.ArchivedBranch, error)
+	GetArchivedBranchOf(hash string) (*domains.ArchivedBranch, error)
+}
+
+// Maintenance is a interface which represents maintenance of the storage.
+type Maintenance interface {
+	Maintain() error
+}
+
+// Snapshots is a interface which represents rebuilding the header table from a prepared database file.
+type Snapshots interface {
+	ImportShadow(path string) (int, error)
+	SwapShadow() error
+}
+
 // Repositories represents all repositories in app and provide access to them.
 type Repositories struct {
-	Headers  Headers
-	Tokens   Tokens
-	Webhooks notification.Webhooks
+	Headers     Headers
+	Tokens      Tokens
+	Webhooks    notification.Webhooks
+	Leases      Leases
+	Maintenance Maintenance
+	// IdempotencyKeys is nil when responses of requests with the Idempotency-Key header aren't stored.
+	IdempotencyKeys IdempotencyKeys
+	// Archive is nil when the storage doesn't keep archived branches.
+	Archive Archive
+	// Outbox is nil when the storage doesn't keep the events of added headers.
+	Outbox notification.Outbox
+	// Snapshots is nil when the storage can't be rebuilt in place.
+	Snapshots Snapshots
 }
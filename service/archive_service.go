@@ -0,0 +1,188 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/cron"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// ArchiveService moves stale and orphan branches whose tips are older than the configured age
+// from the headers to the archive, where they take less space and don't slow down queries of the chain.
+type ArchiveService struct {
+	headers     repository.Headers
+	archive     repository.Archive
+	maintenance Maintenance
+	// writes is held by adding headers, so no header is added to a branch while it is moved to the archive.
+	writes  *sync.RWMutex
+	cfg     *config.ArchiveConfig
+	log     zerolog.Logger
+	now     func() time.Time
+	running sync.Mutex
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewArchiveService creates and returns ArchiveService instance, without a config archiving is never scheduled.
+func NewArchiveService(repos *repository.Repositories, maintenance Maintenance, writes *sync.RWMutex, cfg *config.ArchiveConfig, log *zerolog.Logger) *ArchiveService {
+	if cfg == nil {
+		cfg = &config.ArchiveConfig{}
+	}
+	return &ArchiveService{
+		headers:     repos.Headers,
+		archive:     repos.Archive,
+		maintenance: maintenance,
+		writes:      writes,
+		cfg:         cfg,
+		log:         log.With().Str("service", "archive").Logger(),
+		now:         time.Now,
+		quit:        make(chan struct{}),
+	}
+}
+
+// Run archives branches whose tips were mined more than the configured age ago and returns the number of archived headers.
+// Headers shared with a younger branch are kept.
+func (s *ArchiveService) Run() (int, error) {
+	if s.maintenance.ModeEnabled() {
+		return 0, bhserrors.ErrMaintenanceMode
+	}
+	if s.archive == nil {
+		return 0, nil
+	}
+	s.running.Lock()
+	defer s.running.Unlock()
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	tips, err := s.headers.GetAllTips()
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	olderThan := now.Add(-s.cfg.MinAge)
+	kept := make(map[chainhash.Hash]bool)
+	var old [][]*domains.BlockHeader
+	for _, t := range tips {
+		if !prunable(t) {
+			continue
+		}
+		branch, err := branchOf(s.headers, t)
+		if err != nil {
+			return 0, err
+		}
+		if t.Timestamp.After(olderThan) {
+			for _, h := range branch {
+				kept[h.Hash] = true
+			}
+			continue
+		}
+		old = append(old, branch)
+	}
+
+	seen := make(map[chainhash.Hash]bool)
+	archived := make(map[domains.HeaderState]int)
+	branches := make([]*domains.ArchivedBranch, 0, len(old))
+	for _, branch := range old {
+		var headers []*domains.BlockHeader
+		for _, h := range branch {
+			if !kept[h.Hash] && !seen[h.Hash] {
+				seen[h.Hash] = true
+				headers = append(headers, h)
+				archived[h.State]++
+			}
+		}
+		if len(headers) == 0 {
+			continue
+		}
+
+		tip := branch[0]
+		branches = append(branches, &domains.ArchivedBranch{
+			TipHash:      tip.Hash.String(),
+			TipHeight:    tip.Height,
+			Length:       len(headers),
+			State:        tip.State,
+			TipTimestamp: tip.Timestamp,
+			ArchivedAt:   now,
+			Headers:      headers,
+		})
+	}
+
+	total, err := s.headers.ArchiveBranches(branches)
+	if err != nil {
+		return 0, err
+	}
+	for state, count := range archived {
+		metrics.AddArchivedHeaders(state.String(), count)
+	}
+
+	s.log.Info().Msgf("Archived %d headers of %d branches ending before %s", total, len(branches), olderThan.Format(time.RFC3339))
+	return total, nil
+}
+
+// GetArchivedBranches returns at most limit archived branches from the highest tip, without their headers.
+func (s *ArchiveService) GetArchivedBranches(limit int) ([]*domains.ArchivedBranch, error) {
+	if s.archive == nil {
+		return []*domains.ArchivedBranch{}, nil
+	}
+	return s.archive.GetArchivedBranches(limit)
+}
+
+// GetArchivedBranchOf returns the archived branch with the header of given hash, together with its headers.
+func (s *ArchiveService) GetArchivedBranchOf(hash string) (*domains.ArchivedBranch, error) {
+	if s.archive == nil {
+		return nil, bhserrors.ErrArchivedHeaderNotFound
+	}
+	return s.archive.GetArchivedBranchOf(hash)
+}
+
+// Start runs the archiving in the background on the configured schedule, if any.
+func (s *ArchiveService) Start() {
+	if s.cfg.Schedule == "" || s.archive == nil {
+		return
+	}
+	schedule, err := cron.Parse(s.cfg.Schedule)
+	if err != nil {
+		s.log.Error().Msgf("scheduled archiving disabled: %v", err)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				s.log.Warn().Msgf("schedule %q has no next run, scheduled archiving stopped", s.cfg.Schedule)
+				return
+			}
+
+			select {
+			case <-time.After(time.Until(next)):
+				if s.maintenance.ModeEnabled() {
+					s.log.Info().Msg("scheduled archiving skipped in the maintenance mode")
+					continue
+				}
+				if _, err := s.Run(); err != nil {
+					s.log.Error().Msgf("scheduled archiving failed: %v", err)
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled archiving and waits for a running one to finish.
+func (s *ArchiveService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+func TestArchivingOldBranches(t *testing.T) {
+	// given
+	now := time.Now()
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	old := givenChain(&db[3], "old", 2, domains.Stale)
+	orphans := givenChain(&domains.BlockHeader{Height: 1, Hash: chainhash.DoubleHashH([]byte("unknown"))}, "orphan", 1, domains.Orphan)
+	young := givenChain(&db[7], "young", 2, domains.Stale)
+	young[1].Timestamp = now
+	db = append(append(append(db, old...), orphans...), young...)
+	s := newTestArchiveService(&db, time.Hour)
+
+	// when
+	archived, err := s.Run()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, archived, 3)
+	assert.Equal(t, len(db), 12)
+	assertNotStored(t, db, old...)
+	assertNotStored(t, db, orphans...)
+
+	branch, err := s.GetArchivedBranchOf(old[0].Hash.String())
+	assert.NoError(t, err)
+	assert.Equal(t, branch.TipHash, old[1].Hash.String())
+	assert.Equal(t, branch.Length, 2)
+	assert.Equal(t, branch.State, domains.Stale)
+	assert.Equal(t, len(branch.Headers), 2)
+
+	branches, err := s.GetArchivedBranches(10)
+	assert.NoError(t, err)
+	assert.Equal(t, len(branches), 2)
+	assert.Equal(t, branches[0].TipHash, old[1].Hash.String())
+	assert.Equal(t, branches[1].State, domains.Orphan)
+}
+
+func TestArchivingKeepsHeadersSharedWithYoungBranch(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	shared := givenChain(&db[2], "shared", 2, domains.Stale)
+	old := givenChain(&shared[1], "old", 1, domains.Stale)
+	young := givenChain(&shared[1], "young", 1, domains.Stale)
+	young[0].Timestamp = time.Now()
+	db = append(append(append(db, shared...), old...), young...)
+	s := newTestArchiveService(&db, time.Hour)
+
+	// when
+	archived, err := s.Run()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, archived, 1)
+	assertNotStored(t, db, old...)
+	_, err = s.GetArchivedBranchOf(shared[0].Hash.String())
+	assert.Equal(t, errors.Is(err, bhserrors.ErrArchivedHeaderNotFound), true)
+}
+
+func TestArchivingInMaintenanceMode(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	db = append(db, givenChain(&db[1], "old", 1, domains.Stale)...)
+	s := newTestArchiveService(&db, time.Hour)
+	s.maintenance.SetMode(true)
+
+	// when
+	_, err := s.Run()
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrMaintenanceMode), true)
+	assert.Equal(t, len(db), 11)
+}
+
+func newTestArchiveService(db *[]domains.BlockHeader, minAge time.Duration) *ArchiveService {
+	log := zerolog.Nop()
+	headers := testrepository.NewHeadersTestRepository(db)
+	repos := &repository.Repositories{Headers: headers, Archive: headers.Archive}
+	maintenance := NewMaintenanceService(testrepository.NewMaintenanceTestRepository(), &config.MaintenanceConfig{}, &log)
+	return NewArchiveService(repos, maintenance, &sync.RWMutex{}, &config.ArchiveConfig{MinAge: minAge}, &log)
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"strings"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/repository"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -23,6 +26,14 @@ type BlockHasher interface {
 type Notification interface {
 	// Notify notifies about new header stored.
 	Notify(any)
+	// NotifyLatest notifies about new header stored, coalescing notifications which weren't delivered yet.
+	NotifyLatest(any)
+}
+
+// Outbox is "port" through which chain service lets the dispatcher know about events stored together with headers.
+type Outbox interface {
+	// Wake makes the dispatcher deliver the stored events right away.
+	Wake()
 }
 
 type chainService struct {
@@ -30,6 +41,16 @@ type chainService struct {
 	chainParams  *chaincfg.Params
 	log          *zerolog.Logger
 	notification Notification
+	// outbox is nil unless events are stored together with headers, instead of being sent after the header is stored.
+	outbox Outbox
+	// syncedThreshold is the age of a header above which it is considered part of the initial sync.
+	syncedThreshold time.Duration
+	// validateDifficulty enables rejecting headers which bits don't follow the difficulty adjustment.
+	validateDifficulty bool
+	// timestampValidation defines rules for timestamps, headers breaking them are quarantined.
+	timestampValidation config.TimestampValidationConfig
+	timeSource          config.MedianTimeSource
+	quarantine          *quarantine
 	BlockHasher
 }
 
@@ -49,14 +70,31 @@ func NewChainsService(
 	log *zerolog.Logger,
 	hasher BlockHasher,
 	notification Notification,
+	outbox Outbox,
+	syncedThreshold time.Duration,
+	validateDifficulty bool,
+	timestampValidation *config.TimestampValidationConfig,
+	timeSource config.MedianTimeSource,
 ) Chains {
 	serviceLogger := log.With().Str("service", "chain").Logger()
+	var tv config.TimestampValidationConfig
+	if timestampValidation != nil {
+		tv = *timestampValidation
+	}
 	return &chainService{
 		Repositories: repos,
 		chainParams:  params,
 		log:          &serviceLogger,
 		BlockHasher:  hasher,
 		notification: notification,
+		outbox:       outbox,
+
+		syncedThreshold:    syncedThreshold,
+		validateDifficulty: validateDifficulty,
+
+		timestampValidation: tv,
+		timeSource:          timeSource,
+		quarantine:          newQuarantine(tv.QuarantineSize),
 	}
 }
 
@@ -73,10 +111,37 @@ func (cs *chainService) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader,
 		return domains.NewRejectedBlockHeader(hash), BlockRejected.error()
 	}
 
-	h, err := cs.createHeader(&hash, &bs)
+	ph, err := cs.previousHeader(&bs)
 	if err != nil {
 		return nil, HeaderCreationFail.causedBy(&err)
 	}
+	bh := domains.CreateHeader(&hash, &bs, ph)
+	h := &bh
+
+	if cs.validateDifficulty && !h.IsOrphan() {
+		reason, err := cs.checkDifficulty(h, ph)
+		if err != nil {
+			return nil, HeaderCreationFail.causedBy(&err)
+		}
+		if reason != "" {
+			cs.log.Warn().Msgf("Message rejected - %s", reason)
+			err = errors.New(reason)
+			return domains.NewRejectedBlockHeader(hash), BlockRejected.causedBy(&err)
+		}
+	}
+
+	if !h.IsOrphan() {
+		reason, err := cs.checkTimestamp(h, ph)
+		if err != nil {
+			return nil, HeaderCreationFail.causedBy(&err)
+		}
+		if reason != "" {
+			cs.log.Warn().Msgf("Header %s quarantined - %s", h.Hash, reason)
+			cs.quarantine.add(h, reason)
+			err = errors.New(reason)
+			return h, BlockQuarantined.causedBy(&err)
+		}
+	}
 
 	isConcurrentChain := cs.hasConcurrentHeaderFromLongestChain(h)
 
@@ -93,23 +158,77 @@ func (cs *chainService) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader,
 		}
 	}
 
+	var changes []domains.StateChange
+	var reorg *domains.ReorgEvent
 	if isConcurrentChain && h.IsLongestChain() {
-		err := cs.switchChainsStates(h)
+		changes, reorg, err = cs.switchChainsStates(h)
 		if err != nil {
 			return h, err
 		}
 	}
 
+	if cs.outbox != nil {
+		return cs.insertWithEvents(h, changes, reorg)
+	}
+
+	for _, c := range changes {
+		if err := cs.Headers.UpdateState(c.Hashes, c.State); err != nil {
+			return h, ChainUpdateFail.causedBy(&err)
+		}
+	}
+
 	h, err = cs.insert(h)
 	if err != nil {
 		return nil, err
 	}
 
 	metrics.SetLatestBlock(h.Height, h.Timestamp, h.State.String())
-	cs.notification.Notify(domains.HeaderAdded(h))
+	if reorg != nil {
+		cs.notification.Notify(reorg)
+	}
+	cs.notify(h)
 	return h, err
 }
 
+// insertWithEvents stores the header together with the state changes of the reorg it causes and the events about it,
+// which are delivered by the outbox dispatcher, so they're sent only when the header was stored.
+func (cs *chainService) insertWithEvents(h *domains.BlockHeader, changes []domains.StateChange, reorg *domains.ReorgEvent) (*domains.BlockHeader, error) {
+	hash := h.Hash.String()
+	events := make([]*notification.OutboxEvent, 0, 2)
+	if reorg != nil {
+		events = append(events, notification.NewOutboxEvent(hash, reorg, false))
+	}
+	events = append(events, notification.NewOutboxEvent(hash, domains.HeaderAdded(h), cs.initialSync(h)))
+
+	if err := cs.Repositories.Headers.AddHeadersWithEvents([]domains.BlockHeader{*h}, changes, events); err != nil {
+		return nil, HeaderSaveFail.causedBy(&err)
+	}
+
+	metrics.SetLatestBlock(h.Height, h.Timestamp, h.State.String())
+	cs.outbox.Wake()
+	return h, nil
+}
+
+// Quarantined returns the latest headers which weren't added because of their timestamps, the oldest first.
+func (cs *chainService) Quarantined() []*domains.QuarantinedHeader {
+	return cs.quarantine.list()
+}
+
+// notify sends a notification about the added header. During the initial sync only the
+// latest header is delivered, since clients can't keep up with every header anyway.
+func (cs *chainService) notify(h *domains.BlockHeader) {
+	if cs.initialSync(h) {
+		cs.notification.NotifyLatest(domains.HeaderAdded(h))
+		return
+	}
+	cs.notification.Notify(domains.HeaderAdded(h))
+}
+
+// initialSync reports whether the header is old enough to be considered a part of the initial sync.
+func (cs *chainService) initialSync(h *domains.BlockHeader) bool {
+	return cs.syncedThreshold > 0 && time.Since(h.Timestamp) > cs.syncedThreshold
+}
+
 func (cs *chainService) hasConcurrentHeaderFromLongestChain(h *domains.BlockHeader) bool {
 	if h.IsOrphan() {
 		return false
@@ -121,32 +240,33 @@ func (cs *chainService) hasConcurrentHeaderFromLongestChain(h *domains.BlockHead
 	return true
 }
 
-// switchChainsStates marking chain connected to given block as longest chain
-// and concurrent part of (currently) "longest chain" as STALE.
-func (cs *chainService) switchChainsStates(h *domains.BlockHeader) error {
+// switchChainsStates returns the state changes marking chain connected to given block as longest chain
+// and concurrent part of (currently) "longest chain" as STALE, which are stored together with the header.
+// It returns also the event describing the reorg, nil when no header left the longest chain.
+func (cs *chainService) switchChainsStates(h *domains.BlockHeader) ([]domains.StateChange, *domains.ReorgEvent, error) {
 	cs.log.Warn().Msgf("Promoting currently stale chain to be LONGEST chain ending on header %s", h.Hash)
 	headerStaleChain, err := cs.stalePartOfChainOf(h)
 	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+		return nil, nil, ChainUpdateFail.causedBy(&err)
 	}
 
 	lh := lowestHeightOf(&headerStaleChain, h)
 
 	concurrentChain, err := cs.longestChainFromHeight(lh)
 	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+		return nil, nil, ChainUpdateFail.causedBy(&err)
 	}
 
-	err = cs.Headers.UpdateState(concurrentChain.hashes(), domains.Stale)
-	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+	changes := []domains.StateChange{
+		{Hashes: concurrentChain.hashes(), State: domains.Stale},
+		{Hashes: headerStaleChain.hashes(), State: domains.LongestChain},
 	}
 
-	err = cs.Headers.UpdateState(headerStaleChain.hashes(), domains.LongestChain)
-	if err != nil {
-		return ChainUpdateFail.causedBy(&err)
+	oldTip := concurrentChain.last()
+	if oldTip == nil {
+		return changes, nil, nil
 	}
-	return nil
+	return changes, domains.Reorg(oldTip, h, lh-1), nil
 }
 
 func (cs *chainService) longestChainFromHeight(smallestHeight int32) (chain, error) {
@@ -176,15 +296,6 @@ func (cs *chainService) ignoreBlockHash(blockHash *domains.BlockHash) bool {
 	return false
 }
 
-func (cs *chainService) createHeader(hash *domains.BlockHash, bs *domains.BlockHeaderSource) (*domains.BlockHeader, error) {
-	ph, err := cs.previousHeader(bs)
-	if err != nil {
-		return nil, err
-	}
-	bh := domains.CreateHeader(hash, bs, ph)
-	return &bh, nil
-}
-
 func (cs *chainService) previousHeader(bs *domains.BlockHeaderSource) (*domains.BlockHeader, error) {
 	h, err := cs.Repositories.Headers.GetHeaderByHash(bs.PrevBlock.String())
 	if h == nil && err != nil && errors.Is(err, bhserrors.ErrHeaderNotFound) {
@@ -226,6 +337,16 @@ func (c *chain) first() *domains.BlockHeader {
 	return f
 }
 
+func (c *chain) last() *domains.BlockHeader {
+	var l *domains.BlockHeader
+	for _, ch := range *c {
+		if l == nil || ch.Height > l.Height {
+			l = ch
+		}
+	}
+	return l
+}
+
 func (c *chain) hashes() []chainhash.Hash {
 	hs := make([]chainhash.Hash, len(*c))
 	for i, ch := range *c {
@@ -247,6 +368,9 @@ const (
 	// BlockRejected error code representing situation when block is on the blacklist.
 	BlockRejected AddBlockErrorCode = "BlockRejected"
 
+	// BlockQuarantined error code representing situation when block timestamp breaks the timestamp validation rules.
+	BlockQuarantined AddBlockErrorCode = "BlockQuarantined"
+
 	// HeaderCreationFail error code representing situation when block cannot be created from source.
 	HeaderCreationFail AddBlockErrorCode = "HeaderCreationFail"
 
@@ -258,6 +382,12 @@ const (
 
 	// HeaderAlreadyExists error code representing situation when header received from peers already exists in db.
 	HeaderAlreadyExists AddBlockErrorCode = "HeaderAlreadyExists"
+
+	// WritesPaused error code representing situation when header is not added because the maintenance mode is enabled.
+	WritesPaused AddBlockErrorCode = "WritesPaused"
+
+	// SyncPaused error code representing situation when header received from peers is not added because the p2p sync is paused.
+	SyncPaused AddBlockErrorCode = "SyncPaused"
 )
 
 func (e *AddBlockError) Error() string {
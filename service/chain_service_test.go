@@ -1,15 +1,18 @@
 package service
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/repository"
 	"github.com/rs/zerolog"
 )
@@ -233,6 +236,35 @@ func TestAddConcurrentChainBlock(t *testing.T) {
 	}
 }
 
+func TestAddHeaderSwitchingChainsNotifiesAboutReorg(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	givenStaleChainInRepository(&r)
+
+	prev, _ := r.Headers.GetHeaderByHash(fixtures.StaleHashHeight4.String())
+	h := givenHeaderToAddNextTo(prev)
+	h.Bits = 0x180f0dc7
+	notification := newRecordingNotification()
+
+	cs := createChainsService(serviceSetup{Repositories: &r, Notification: notification})
+
+	// when
+	header, err := cs.Add(h)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(notification.Events), 2)
+
+	reorg, ok := notification.Events[0].(*domains.ReorgEvent)
+	if !ok {
+		t.Fatalf("Expect the first event to be a reorg but it is %T", notification.Events[0])
+	}
+	assert.Equal(t, reorg.ForkHeight, int32(0))
+	assert.Equal(t, reorg.Depth, 4)
+	assert.Equal(t, reorg.OldTip.Hash, longestChainTip.Hash.String())
+	assert.Equal(t, reorg.NewTip.Hash, header.Hash.String())
+}
+
 func givenStaleChainInRepository(r *repository.Repositories) {
 	sc, _ := fixtures.StaleChain()
 	for _, h := range sc {
@@ -307,6 +339,119 @@ func givenChainWithOnlyGenesisBlockInRepository() (repository.Repositories, *dom
 	return testrepository.NewTestRepositories(&db), tip
 }
 
+func TestAddHeaderDuringInitialSync(t *testing.T) {
+	testCases := map[string]struct {
+		syncedThreshold   time.Duration
+		expectedEvents    int
+		expectedCoalesced int
+	}{
+		"notify about header when synced": {
+			syncedThreshold: 0,
+			expectedEvents:  1,
+		},
+		"coalesce notification about header older than synced threshold": {
+			syncedThreshold:   24 * time.Hour,
+			expectedCoalesced: 1,
+		},
+	}
+
+	for name, params := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			r, longestChainTip := givenChainWithOnlyGenesisBlockInRepository()
+			h := givenHeaderToAddNextTo(longestChainTip)
+			notification := newRecordingNotification()
+
+			cs := createChainsService(serviceSetup{
+				Repositories:    &r,
+				Notification:    notification,
+				SyncedThreshold: params.syncedThreshold,
+			})
+
+			// when
+			_, err := cs.Add(h)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, len(notification.Events), params.expectedEvents)
+			assert.Equal(t, len(notification.CoalescedEvents), params.expectedCoalesced)
+		})
+	}
+}
+
+func TestAddHeaderStoresEventsInOutbox(t *testing.T) {
+	// given
+	r, longestChainTip := givenChainWithOnlyGenesisBlockInRepository()
+	h := givenHeaderToAddNextTo(longestChainTip)
+	notification := newRecordingNotification()
+	outbox := &wakeCounter{}
+
+	cs := createChainsService(serviceSetup{
+		Repositories:    &r,
+		Notification:    notification,
+		Outbox:          outbox,
+		SyncedThreshold: 24 * time.Hour,
+	})
+
+	// when
+	header, err := cs.Add(h)
+
+	// then
+	assert.NoError(t, err)
+	assertHeaderInDb(t, r, header)
+	assert.Equal(t, len(notification.Events), 0)
+	assert.Equal(t, len(notification.CoalescedEvents), 0)
+	assert.Equal(t, outbox.wakes, 1)
+
+	events := r.Headers.(*testrepository.HeaderTestRepository).Outbox.Events()
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].HeaderHash, header.Hash.String())
+	assert.Equal(t, events[0].LatestOnly, true)
+	assert.Equal(t, events[0].Event.(*domains.HeaderEvent).Operation, domains.EventHeaderAdded)
+}
+
+func TestAddHeaderSwitchingChainsWithOutboxKeepsStatesWhenInsertFails(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	givenStaleChainInRepository(&r)
+	r.Headers = &failingInsertHeaders{Headers: r.Headers}
+
+	prev, _ := r.Headers.GetHeaderByHash(fixtures.StaleHashHeight4.String())
+	h := givenHeaderToAddNextTo(prev)
+	h.Bits = 0x180f0dc7
+
+	cs := createChainsService(serviceSetup{Repositories: &r, Outbox: &wakeCounter{}})
+
+	// when
+	_, err := cs.Add(h)
+
+	// then
+	assert.Equal(t, err != nil, true)
+	tip, err := r.Headers.GetTip()
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Hash, longestChainTip.Hash)
+	stale, err := r.Headers.GetHeaderByHash(fixtures.StaleHashHeight4.String())
+	assert.NoError(t, err)
+	assert.Equal(t, stale.State, domains.Stale)
+}
+
+// failingInsertHeaders fails to store headers with events, before applying any of the changes.
+type failingInsertHeaders struct {
+	repository.Headers
+}
+
+func (f *failingInsertHeaders) AddHeadersWithEvents([]domains.BlockHeader, []domains.StateChange, []*notification.OutboxEvent) error {
+	return errors.New("database is locked")
+}
+
+type wakeCounter struct {
+	wakes int
+}
+
+func (w *wakeCounter) Wake() {
+	w.wakes++
+}
+
 func givenLongestChainInRepository() (repository.Repositories, *domains.BlockHeader) {
 	db, tip := fixtures.LongestChain()
 
@@ -347,35 +492,55 @@ func givenOrphanedHeaderToAdd() domains.BlockHeaderSource {
 
 func createChainsService(s serviceSetup) Chains {
 	log := zerolog.Nop()
+	notification := s.Notification
+	if notification == nil {
+		notification = newRecordingNotification()
+	}
 	return NewChainsService(
 		s.Repositories,
 		s.Params(),
 		&log,
 		DefaultBlockHasher(),
-		newRecordingNotification(),
+		notification,
+		s.Outbox,
+		s.SyncedThreshold,
+		s.ValidateDifficulty,
+		&s.TimestampValidation,
+		config.NewMedianTime(&log),
 	)
 }
 
 type serviceSetup struct {
 	*repository.Repositories
-	IgnoredHash domains.BlockHash
+	IgnoredHash         domains.BlockHash
+	Notification        *recordingNotification
+	Outbox              Outbox
+	SyncedThreshold     time.Duration
+	ChainParams         *chaincfg.Params
+	ValidateDifficulty  bool
+	TimestampValidation config.TimestampValidationConfig
 }
 
 func (s *serviceSetup) Params() *chaincfg.Params {
 	ign := chainhash.Hash(s.IgnoredHash)
 
-	return &chaincfg.Params{
-		HeadersToIgnore: []*chainhash.Hash{&ign},
+	var params chaincfg.Params
+	if s.ChainParams != nil {
+		params = *s.ChainParams
 	}
+	params.HeadersToIgnore = []*chainhash.Hash{&ign}
+	return &params
 }
 
 type recordingNotification struct {
-	Events []interface{}
+	Events          []interface{}
+	CoalescedEvents []interface{}
 }
 
 func newRecordingNotification() *recordingNotification {
 	return &recordingNotification{
-		Events: make([]interface{}, 0),
+		Events:          make([]interface{}, 0),
+		CoalescedEvents: make([]interface{}, 0),
 	}
 }
 
@@ -383,6 +548,10 @@ func (r *recordingNotification) Notify(event any) {
 	r.Events = append(r.Events, event)
 }
 
+func (r *recordingNotification) NotifyLatest(event any) {
+	r.CoalescedEvents = append(r.CoalescedEvents, event)
+}
+
 func (r *recordingNotification) Clear() {
 	r.Events = make([]interface{}, 0)
 }
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+const (
+	// daaWindow is the number of blocks which work and time the difficulty adjustment algorithm averages.
+	daaWindow = 144
+	// edaWindow is the number of blocks which took over edaTimespan to produce trigger the emergency difficulty adjustment.
+	edaWindow = 6
+	// edaTimespan is the time after which the emergency difficulty adjustment lowers the difficulty.
+	edaTimespan = 12 * time.Hour
+)
+
+var oneLsh256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// checkDifficulty returns the reason to reject the header if its bits differ from the bits required
+// by the difficulty adjustment of the chain, empty when they don't.
+func (cs *chainService) checkDifficulty(h *domains.BlockHeader, prev *domains.BlockHeader) (string, error) {
+	bits, ok, err := cs.requiredBits(prev, h.Timestamp)
+	if err != nil {
+		return "", err
+	}
+	if ok && bits != h.Bits {
+		return fmt.Sprintf("header %s has bits %08x, but %08x are required at height %d", h.Hash, h.Bits, bits, h.Height), nil
+	}
+	return "", nil
+}
+
+// RequiredBits returns bits required by the difficulty adjustment of the header extending the stored header
+// of given hash created at the given time, or bits of that header when there are not enough headers to determine them.
+func (cs *chainService) RequiredBits(prevHash string, timestamp time.Time) (uint32, error) {
+	prev, err := cs.Headers.GetHeaderByHash(prevHash)
+	if err != nil {
+		return 0, err
+	}
+	bits, ok, err := cs.requiredBits(prev, timestamp)
+	if err != nil || !ok {
+		return prev.Bits, err
+	}
+	return bits, nil
+}
+
+// requiredBits returns bits of the header extending prev created at the given time.
+// It returns false when there are not enough headers to determine them.
+func (cs *chainService) requiredBits(prev *domains.BlockHeader, timestamp time.Time) (uint32, bool, error) {
+	switch {
+	case cs.chainParams.NoDifficultyAdjustment:
+		return prev.Bits, true, nil
+	case prev.Height >= cs.chainParams.DaaForkHeight:
+		return cs.daaRequiredBits(prev, timestamp)
+	default:
+		bits, err := cs.legacyRequiredBits(prev, timestamp)
+		return bits, err == nil, err
+	}
+}
+
+// daaRequiredBits computes bits with the difficulty adjustment algorithm active since November 2017,
+// which targets the block time from work done by the last 144 blocks.
+func (cs *chainService) daaRequiredBits(prev *domains.BlockHeader, timestamp time.Time) (uint32, bool, error) {
+	p := cs.chainParams
+	if p.ReduceMinDifficulty && timestamp.After(prev.Timestamp.Add(p.MinDiffReductionTime)) {
+		return p.PowLimitBits, true, nil
+	}
+	if prev.Height < daaWindow+2 {
+		return 0, false, nil
+	}
+
+	last, err := cs.suitableHeader(prev)
+	if err != nil {
+		return 0, false, err
+	}
+	firstAncestor, err := cs.ancestor(prev, prev.Height-daaWindow)
+	if err != nil {
+		return 0, false, err
+	}
+	first, err := cs.suitableHeader(firstAncestor)
+	if err != nil {
+		return 0, false, err
+	}
+
+	spacing := int64(p.TargetTimePerBlock / time.Second)
+	work := new(big.Int).Sub(last.CumulatedWork, first.CumulatedWork)
+	work.Mul(work, big.NewInt(spacing))
+
+	timespan := last.Timestamp.Unix() - first.Timestamp.Unix()
+	timespan = min(max(timespan, daaWindow/2*spacing), daaWindow*2*spacing)
+	work.Div(work, big.NewInt(timespan))
+
+	// The target is (2^256 - work) / work, the same as the chain work of a header is 2^256 / (target + 1).
+	target := new(big.Int).Sub(oneLsh256, work)
+	target.Div(target, work)
+	return cs.compactTarget(target), true, nil
+}
+
+// suitableHeader returns the header with the median timestamp of the header and its two parents,
+// so a single header with a skewed timestamp doesn't distort the difficulty.
+func (cs *chainService) suitableHeader(h *domains.BlockHeader) (*domains.BlockHeader, error) {
+	headers, err := cs.ancestors(h, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sorted the same way as nodes do, since headers with equal timestamps have different work.
+	if headers[0].Timestamp.After(headers[2].Timestamp) {
+		headers[0], headers[2] = headers[2], headers[0]
+	}
+	if headers[0].Timestamp.After(headers[1].Timestamp) {
+		headers[0], headers[1] = headers[1], headers[0]
+	}
+	if headers[1].Timestamp.After(headers[2].Timestamp) {
+		headers[1], headers[2] = headers[2], headers[1]
+	}
+	return headers[1], nil
+}
+
+// legacyRequiredBits computes bits with the difficulty retarget every 2016 blocks, together with the emergency
+// difficulty adjustment active since the August 2017 hard fork until the difficulty adjustment algorithm.
+func (cs *chainService) legacyRequiredBits(prev *domains.BlockHeader, timestamp time.Time) (uint32, error) {
+	p := cs.chainParams
+	interval := int32(p.TargetTimespan / p.TargetTimePerBlock)
+	height := prev.Height + 1
+
+	if height%interval == 0 {
+		first, err := cs.ancestor(prev, height-interval)
+		if err != nil {
+			return 0, err
+		}
+		return cs.retarget(prev, first), nil
+	}
+
+	if p.ReduceMinDifficulty {
+		if timestamp.After(prev.Timestamp.Add(p.MinDiffReductionTime)) {
+			return p.PowLimitBits, nil
+		}
+		// Blocks with the lowest difficulty are skipped, the difficulty returns to the last one before them.
+		h := prev
+		for h.Height > 0 && h.Height%interval != 0 && h.Bits == p.PowLimitBits {
+			var err error
+			if h, err = cs.ancestor(h, h.Height-1); err != nil {
+				return 0, err
+			}
+		}
+		return h.Bits, nil
+	}
+
+	if prev.Bits == p.PowLimitBits || prev.Height < p.UahfForkHeight || prev.Height < edaWindow+1 {
+		return prev.Bits, nil
+	}
+	return cs.emergencyBits(prev)
+}
+
+// retarget adjusts the target by the time producing the last interval of blocks took compared to the target timespan.
+func (cs *chainService) retarget(prev *domains.BlockHeader, first *domains.BlockHeader) uint32 {
+	p := cs.chainParams
+	targetTimespan := int64(p.TargetTimespan / time.Second)
+	timespan := prev.Timestamp.Unix() - first.Timestamp.Unix()
+	timespan = min(max(timespan, targetTimespan/p.RetargetAdjustmentFactor), targetTimespan*p.RetargetAdjustmentFactor)
+
+	target := domains.CompactToBig(prev.Bits)
+	target.Mul(target, big.NewInt(timespan))
+	target.Div(target, big.NewInt(targetTimespan))
+	return cs.compactTarget(target)
+}
+
+// emergencyBits raises the target by a quarter when the median time past of the last 6 blocks grew by 12 hours or more.
+func (cs *chainService) emergencyBits(prev *domains.BlockHeader) (uint32, error) {
+	prevMTP, err := cs.medianTimePast(prev)
+	if err != nil {
+		return 0, err
+	}
+	before, err := cs.ancestor(prev, prev.Height-edaWindow)
+	if err != nil {
+		return 0, err
+	}
+	beforeMTP, err := cs.medianTimePast(before)
+	if err != nil {
+		return 0, err
+	}
+
+	if prevMTP.Sub(beforeMTP) < edaTimespan {
+		return prev.Bits, nil
+	}
+	target := domains.CompactToBig(prev.Bits)
+	target.Add(target, new(big.Int).Rsh(target, 2))
+	return cs.compactTarget(target), nil
+}
+
+func (cs *chainService) medianTimePast(h *domains.BlockHeader) (time.Time, error) {
+	headers, err := cs.ancestors(h, domains.MedianTimeBlocks)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return domains.MedianTimePast(headers), nil
+}
+
+// compactTarget returns the target in the compact form, capped by the proof of work limit.
+func (cs *chainService) compactTarget(target *big.Int) uint32 {
+	if target.Cmp(cs.chainParams.PowLimit) > 0 {
+		return cs.chainParams.PowLimitBits
+	}
+	return domains.BigToCompact(target)
+}
+
+// ancestors returns up to count headers ending with h, ordered by height.
+func (cs *chainService) ancestors(h *domains.BlockHeader, count int32) ([]*domains.BlockHeader, error) {
+	from := max(0, h.Height-count+1)
+	headers := make([]*domains.BlockHeader, 0, h.Height-from+1)
+	for height := from; height < h.Height; height++ {
+		a, err := cs.ancestor(h, height)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, a)
+	}
+	return append(headers, h), nil
+}
+
+// ancestor returns the header on given height of the chain ending with h.
+func (cs *chainService) ancestor(h *domains.BlockHeader, height int32) (*domains.BlockHeader, error) {
+	if h.Height == height {
+		return h, nil
+	}
+	if h.IsLongestChain() {
+		return cs.Headers.GetHeaderByHeight(height)
+	}
+	return cs.Headers.GetAncestorOnHeight(h.Hash.String(), height)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+)
+
+func TestAddHeaderWithInvalidDifficulty(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	h := givenHeaderToAddNextTo(longestChainTip)
+	h.Bits = 0x1d00fffe
+
+	cs := createChainsService(serviceSetup{
+		Repositories:       &r,
+		ChainParams:        &chaincfg.MainNetParams,
+		ValidateDifficulty: true,
+	})
+
+	// when
+	header, err := cs.Add(h)
+
+	// then
+	assert.Equal(t, BlockRejected.Is(err), true)
+	assertHeaderExist(t, header)
+	assert.Equal(t, header.State, domains.Rejected)
+	_, err = r.Headers.GetHeaderByHash(header.Hash.String())
+	assert.IsError(t, err, "header not found")
+}
+
+func TestAddHeaderWithValidDifficulty(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	h := givenHeaderToAddNextTo(longestChainTip)
+
+	cs := createChainsService(serviceSetup{
+		Repositories:       &r,
+		ChainParams:        &chaincfg.MainNetParams,
+		ValidateDifficulty: true,
+	})
+
+	// when
+	header, err := cs.Add(h)
+
+	// then
+	assert.NoError(t, err)
+	assertHeaderInState(t, header, domains.LongestChain)
+}
+
+func TestRequiredBits(t *testing.T) {
+	const bits = 0x1c100000
+
+	testCases := map[string]struct {
+		daaForkHeight int32
+		chainLength   int
+		spacing       time.Duration
+		expectedBits  uint32
+	}{
+		"DAA keeps difficulty when blocks are on time": {
+			chainLength:  150,
+			spacing:      10 * time.Minute,
+			expectedBits: bits,
+		},
+		"DAA raises difficulty when blocks are too fast": {
+			chainLength:  150,
+			spacing:      5 * time.Minute,
+			expectedBits: 0x1c080000,
+		},
+		"DAA lowers difficulty when blocks are too slow": {
+			chainLength:  150,
+			spacing:      20 * time.Minute,
+			expectedBits: 0x1c200000,
+		},
+		"DAA limits difficulty change": {
+			chainLength:  150,
+			spacing:      time.Minute,
+			expectedBits: 0x1c080000,
+		},
+		"legacy keeps difficulty between retargets": {
+			daaForkHeight: 1000,
+			chainLength:   5,
+			spacing:       time.Minute,
+			expectedBits:  bits,
+		},
+		"legacy retarget raises difficulty when blocks are too fast": {
+			daaForkHeight: 1000,
+			chainLength:   10,
+			spacing:       10 * time.Minute,
+			expectedBits:  0x1c0e6666,
+		},
+		"legacy retarget lowers difficulty when blocks are too slow": {
+			daaForkHeight: 1000,
+			chainLength:   10,
+			spacing:       20 * time.Minute,
+			expectedBits:  0x1c1ccccc,
+		},
+		"legacy retarget limits difficulty change": {
+			daaForkHeight: 1000,
+			chainLength:   10,
+			spacing:       time.Minute,
+			expectedBits:  0x1c040000,
+		},
+	}
+
+	for name, params := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			r, tip := givenChainWithSpacing(bits, params.spacing, params.chainLength)
+			cs := createChainsService(serviceSetup{
+				Repositories: &r,
+				ChainParams: &chaincfg.Params{
+					PowLimit:                 domains.CompactToBig(0x1d00ffff),
+					PowLimitBits:             0x1d00ffff,
+					DaaForkHeight:            params.daaForkHeight,
+					TargetTimePerBlock:       10 * time.Minute,
+					TargetTimespan:           100 * time.Minute,
+					RetargetAdjustmentFactor: 4,
+				},
+				ValidateDifficulty: true,
+			}).(*chainService)
+
+			// when
+			required, ok, err := cs.requiredBits(tip, tip.Timestamp.Add(params.spacing))
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, required, params.expectedBits)
+		})
+	}
+}
+
+func TestRequiredBitsWithoutEnoughHeaders(t *testing.T) {
+	// given
+	r, tip := givenChainWithSpacing(0x1d00ffff, 10*time.Minute, 100)
+	cs := createChainsService(serviceSetup{
+		Repositories:       &r,
+		ChainParams:        &chaincfg.RegressionNetParams,
+		ValidateDifficulty: true,
+	}).(*chainService)
+	cs.chainParams.NoDifficultyAdjustment = false
+
+	// when
+	_, ok, err := cs.requiredBits(tip, tip.Timestamp.Add(10*time.Minute))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, ok, false)
+}
+
+// givenChainWithSpacing creates a longest chain of given length with headers created in equal intervals.
+func givenChainWithSpacing(bits uint32, spacing time.Duration, length int) (r repository.Repositories, tip *domains.BlockHeader) {
+	work := domains.CalculateWork(bits).BigInt()
+	tip = &domains.BlockHeader{
+		Height:        0,
+		Timestamp:     time.Unix(1500000000, 0),
+		Bits:          bits,
+		State:         domains.LongestChain,
+		Chainwork:     work,
+		CumulatedWork: work,
+	}
+	db := []domains.BlockHeader{*tip}
+	hasher := DefaultBlockHasher()
+
+	for i := 1; i < length; i++ {
+		bs := domains.BlockHeaderSource{
+			Version:   1,
+			PrevBlock: tip.Hash,
+			Timestamp: tip.Timestamp.Add(spacing),
+			Bits:      bits,
+			Nonce:     uint32(i),
+		}
+		hash := hasher.BlockHash(&bs)
+		h := domains.CreateHeader(&hash, &bs, tip)
+		db = append(db, h)
+		tip = &h
+	}
+	return testrepository.NewTestRepositories(&db), tip
+}
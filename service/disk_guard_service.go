@@ -0,0 +1,139 @@
+package service
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/diskusage"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/rs/zerolog"
+)
+
+const bytesInMB = 1024 * 1024
+
+// DiskGuardService measures sizes of the database and the logs and the free disk space.
+// While the free space is below the threshold it keeps the service in the maintenance mode,
+// so a write failing midway doesn't leave the SQLite file corrupted.
+type DiskGuardService struct {
+	cfg         *config.DiskGuardConfig
+	maintenance Maintenance
+	notifier    *notification.Notifier
+	log         zerolog.Logger
+
+	// dbFiles are the SQLite database files, there are none with PostgreSQL, which isn't guarded.
+	dbFiles  []string
+	freePath string
+	free     func(path string) (uint64, error)
+	checking sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDiskGuardService creates and returns DiskGuardService of the configured database, without a config it's disabled.
+func NewDiskGuardService(cfg *config.DiskGuardConfig, db *config.DbConfig, maintenance Maintenance, notifier *notification.Notifier, log *zerolog.Logger) *DiskGuardService {
+	if cfg == nil {
+		cfg = &config.DiskGuardConfig{}
+	}
+	s := &DiskGuardService{
+		cfg:         cfg,
+		maintenance: maintenance,
+		notifier:    notifier,
+		log:         log.With().Str("service", "disk-guard").Logger(),
+		freePath:    ".",
+		free:        diskusage.Free,
+		quit:        make(chan struct{}),
+	}
+	if db != nil && db.Engine == config.DBSQLite {
+		path := db.SQLite.FilePath
+		s.dbFiles = []string{path, path + "-wal", path + "-shm", path + "-journal"}
+		s.freePath = filepath.Dir(path)
+	} else if cfg.LogDir != "" {
+		s.freePath = cfg.LogDir
+	}
+	return s
+}
+
+// Check measures the disk usage. With the guard enabled it also pauses or resumes writes
+// of the SQLite database when the free space crosses the threshold, alerting webhooks about it.
+func (s *DiskGuardService) Check() (*domains.DiskUsage, error) {
+	s.checking.Lock()
+	defer s.checking.Unlock()
+
+	usage := &domains.DiskUsage{MinFreeBytes: s.cfg.MinFreeMB * bytesInMB}
+	var err error
+	if usage.DatabaseBytes, err = diskusage.Size(s.dbFiles...); err != nil {
+		return nil, bhserrors.ErrDiskUsage.Wrap(err)
+	}
+	if s.cfg.LogDir != "" {
+		if usage.LogsBytes, err = diskusage.Size(s.cfg.LogDir); err != nil {
+			return nil, bhserrors.ErrDiskUsage.Wrap(err)
+		}
+	}
+	if usage.FreeBytes, err = s.free(s.freePath); err != nil {
+		return nil, bhserrors.ErrDiskUsage.Wrap(err)
+	}
+
+	if s.cfg.Enabled && s.dbFiles != nil {
+		s.guard(usage)
+	}
+	usage.LowSpace = s.maintenance.LowDiskSpace()
+	metrics.SetDiskUsage(usage)
+	return usage, nil
+}
+
+func (s *DiskGuardService) guard(usage *domains.DiskUsage) {
+	low := usage.FreeBytes < usage.MinFreeBytes
+	if low == s.maintenance.LowDiskSpace() {
+		return
+	}
+	s.maintenance.SetLowDiskSpace(low)
+
+	operation := domains.EventDiskSpaceRecovered
+	if low {
+		operation = domains.EventDiskSpaceLow
+		s.log.Warn().Msgf("Only %d MB of disk space is free, the minimum is %d MB", usage.FreeBytes/bytesInMB, s.cfg.MinFreeMB)
+	}
+	s.notifier.Notify(&domains.DiskSpaceEvent{
+		Operation:    operation,
+		FreeBytes:    usage.FreeBytes,
+		MinFreeBytes: usage.MinFreeBytes,
+	})
+}
+
+// Start checks the disk usage right away and then in the background on the configured interval, if enabled.
+func (s *DiskGuardService) Start() {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.Check(); err != nil {
+				s.log.Error().Msgf("disk usage check failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the disk usage and waits for a running check to finish.
+func (s *DiskGuardService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
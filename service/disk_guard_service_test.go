@@ -0,0 +1,93 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/rs/zerolog"
+)
+
+func TestDiskGuardPausesWritesWhileSpaceIsLow(t *testing.T) {
+	// given
+	events := make(eventsChannel, 2)
+	s, maintenance := newTestDiskGuardService(t, config.DBSQLite, events)
+	s.free = func(string) (uint64, error) { return 10 * bytesInMB, nil }
+
+	// when
+	usage, err := s.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, usage.LowSpace, true)
+	assert.Equal(t, maintenance.ModeEnabled(), true)
+	low := (<-events).(*domains.DiskSpaceEvent)
+	assert.Equal(t, low.Operation, domains.EventDiskSpaceLow)
+	assert.Equal(t, low.FreeBytes, uint64(10*bytesInMB))
+	assert.Equal(t, low.MinFreeBytes, uint64(256*bytesInMB))
+
+	// when
+	s.free = func(string) (uint64, error) { return 300 * bytesInMB, nil }
+	usage, err = s.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, usage.LowSpace, false)
+	assert.Equal(t, maintenance.ModeEnabled(), false)
+	recovered := (<-events).(*domains.DiskSpaceEvent)
+	assert.Equal(t, recovered.Operation, domains.EventDiskSpaceRecovered)
+}
+
+func TestDiskGuardKeepsMaintenanceModeOfAdmin(t *testing.T) {
+	// given
+	s, maintenance := newTestDiskGuardService(t, config.DBSQLite, make(eventsChannel, 2))
+	maintenance.SetMode(true)
+	s.free = func(string) (uint64, error) { return 0, nil }
+	_, err := s.Check()
+	assert.NoError(t, err)
+
+	// when
+	s.free = func(string) (uint64, error) { return 300 * bytesInMB, nil }
+	_, err = s.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, maintenance.LowDiskSpace(), false)
+	assert.Equal(t, maintenance.ModeEnabled(), true)
+}
+
+func TestDiskGuardDoesNotGuardPostgres(t *testing.T) {
+	// given
+	s, maintenance := newTestDiskGuardService(t, config.DBPostgreSQL, make(eventsChannel, 2))
+	s.free = func(string) (uint64, error) { return 0, nil }
+
+	// when
+	usage, err := s.Check()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, usage.DatabaseBytes, int64(0))
+	assert.Equal(t, usage.LowSpace, false)
+	assert.Equal(t, maintenance.ModeEnabled(), false)
+}
+
+type eventsChannel chan notification.Event
+
+func (c eventsChannel) Notify(event notification.Event) {
+	c <- event
+}
+
+func newTestDiskGuardService(t *testing.T, engine config.DbEngine, events eventsChannel) (*DiskGuardService, *MaintenanceService) {
+	log := zerolog.Nop()
+	db := config.GetDefaultAppConfig().Db
+	db.Engine = engine
+	db.SQLite.FilePath = filepath.Join(t.TempDir(), "blockheaders.db")
+	notifier := notification.NewNotifier()
+	notifier.AddChannel(events)
+	maintenance := newTestMaintenanceService(testrepository.NewMaintenanceTestRepository())
+	return NewDiskGuardService(config.GetDefaultAppConfig().DiskGuard, db, maintenance, notifier, &log), maintenance
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+)
+
+// Generate mines count headers with the minimal difficulty on top of the header with given hash, or the tip when it's empty,
+// and adds them the same way as headers received from peers. It's allowed only on the regtest network.
+// Generated headers are deterministic, a header which is already stored is skipped by mining the next nonce,
+// so generating on an older header creates a fork.
+func (cs *chainService) Generate(count int, from string) ([]*domains.BlockHeader, error) {
+	if cs.chainParams.Name != chaincfg.RegressionNetParams.Name {
+		return nil, bhserrors.ErrGenerateNotRegtest
+	}
+
+	parent, err := cs.generateParent(from)
+	if err != nil {
+		return nil, err
+	}
+
+	generated := make([]*domains.BlockHeader, 0, count)
+	for range count {
+		bs, err := cs.mineHeader(parent)
+		if err != nil {
+			return generated, err
+		}
+		h, err := cs.Add(*bs)
+		if err != nil {
+			return generated, fmt.Errorf("cannot add generated header at height %d: %w", parent.Height+1, err)
+		}
+		generated = append(generated, h)
+		parent = h
+	}
+	return generated, nil
+}
+
+func (cs *chainService) generateParent(from string) (*domains.BlockHeader, error) {
+	if from == "" {
+		return cs.Headers.GetTip()
+	}
+	parent, err := cs.Headers.GetHeaderByHash(from)
+	if err != nil {
+		return nil, bhserrors.ErrHeaderNotFound
+	}
+	return parent, nil
+}
+
+// mineHeader finds a header extending parent with a valid proof of work, which is not stored yet.
+// Its timestamp follows the median time past, so generated headers don't run ahead of the network time.
+func (cs *chainService) mineHeader(parent *domains.BlockHeader) (*domains.BlockHeaderSource, error) {
+	mtp, err := cs.medianTimePast(parent)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := parent.Timestamp
+	if mtp.After(timestamp) {
+		timestamp = mtp
+	}
+	timestamp = timestamp.Add(time.Second)
+
+	bits, ok, err := cs.requiredBits(parent, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		bits = parent.Bits
+	}
+
+	// The merkle root commits to the height, so headers on different heights never collide.
+	var root [chainhash.HashSize]byte
+	binary.LittleEndian.PutUint32(root[:], uint32(parent.Height+1))
+
+	bs := &domains.BlockHeaderSource{
+		Version:    parent.Version,
+		PrevBlock:  parent.Hash,
+		MerkleRoot: chainhash.DoubleHashH(root[:]),
+		Timestamp:  timestamp,
+		Bits:       bits,
+	}
+	target := domains.CompactToBig(bits)
+	for {
+		hash := chainhash.Hash(cs.BlockHasher.BlockHash(bs))
+		if domains.HashToBig(&hash).Cmp(target) <= 0 {
+			if existing, _ := cs.Headers.GetHeaderByHash(hash.String()); existing == nil {
+				return bs, nil
+			}
+		}
+		if bs.Nonce == ^uint32(0) {
+			return nil, fmt.Errorf("cannot mine header at height %d with bits %08x", parent.Height+1, bits)
+		}
+		bs.Nonce++
+	}
+}
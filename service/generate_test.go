@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+)
+
+func TestGenerateOnTip(t *testing.T) {
+	// given
+	r, tip := givenChainWithSpacing(chaincfg.RegressionNetParams.PowLimitBits, 10*time.Minute, 3)
+	cs := createRegtestChainsService(&r)
+
+	// when
+	generated, err := cs.Generate(3, "")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(generated), 3)
+	prev := tip
+	for _, h := range generated {
+		assert.Equal(t, h.PreviousBlock, prev.Hash)
+		assert.Equal(t, h.Height, prev.Height+1)
+		assertHeaderInState(t, h, domains.LongestChain)
+		prev = h
+	}
+
+	newTip, err := r.Headers.GetTip()
+	assert.NoError(t, err)
+	assert.Equal(t, newTip.Hash, prev.Hash)
+}
+
+func TestGenerateFork(t *testing.T) {
+	// given
+	r, tip := givenChainWithSpacing(chaincfg.RegressionNetParams.PowLimitBits, 10*time.Minute, 3)
+	cs := createRegtestChainsService(&r)
+	forkPoint, err := r.Headers.GetHeaderByHeight(tip.Height - 1)
+	assert.NoError(t, err)
+
+	// when
+	generated, err := cs.Generate(2, forkPoint.Hash.String())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(generated), 2)
+	assert.Equal(t, generated[0].PreviousBlock, forkPoint.Hash)
+	assert.Equal(t, generated[0].Height, tip.Height)
+	assertHeaderInState(t, generated[1], domains.LongestChain)
+	formerTip, err := r.Headers.GetHeaderByHash(tip.Hash.String())
+	assert.NoError(t, err)
+	assertHeaderInState(t, formerTip, domains.Stale)
+}
+
+func TestGenerateSkipsStoredHeaders(t *testing.T) {
+	// given
+	r, tip := givenChainWithSpacing(chaincfg.RegressionNetParams.PowLimitBits, 10*time.Minute, 3)
+	cs := createRegtestChainsService(&r)
+	first, err := cs.Generate(1, tip.Hash.String())
+	assert.NoError(t, err)
+
+	// when
+	second, err := cs.Generate(1, tip.Hash.String())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, second[0].PreviousBlock, tip.Hash)
+	if second[0].Hash == first[0].Hash {
+		t.Fatalf("expected a new header, got the stored %s", first[0].Hash)
+	}
+}
+
+func TestGenerateOutsideOfRegtest(t *testing.T) {
+	// given
+	r, _ := givenLongestChainInRepository()
+	cs := createChainsService(serviceSetup{Repositories: &r})
+
+	// when
+	generated, err := cs.Generate(1, "")
+
+	// then
+	assert.IsError(t, err, bhserrors.ErrGenerateNotRegtest.Error())
+	assert.Equal(t, len(generated), 0)
+}
+
+func createRegtestChainsService(r *repository.Repositories) Chains {
+	return createChainsService(serviceSetup{
+		Repositories:       r,
+		ChainParams:        &chaincfg.RegressionNetParams,
+		ValidateDifficulty: true,
+		TimestampValidation: config.TimestampValidationConfig{
+			MaxFutureDrift: 2 * time.Hour,
+			MedianTimePast: true,
+		},
+	})
+}
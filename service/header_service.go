@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
@@ -20,16 +24,19 @@ import (
 type HeaderService struct {
 	repo        *repository.Repositories
 	checkpoints []chaincfg.Checkpoint
+	powLimit    *big.Int
 	timeSource  config.MedianTimeSource
 	log         *zerolog.Logger
 }
 
 // NewHeaderService creates and returns HeaderService instance.
-func NewHeaderService(repo *repository.Repositories, _ *config.P2PConfig, log *zerolog.Logger) *HeaderService {
+func NewHeaderService(repo *repository.Repositories, p2pCfg *config.P2PConfig, log *zerolog.Logger) *HeaderService {
 	headerLogger := log.With().Str("service", "header").Logger()
+	params := p2pCfg.GetNetParams()
 	return &HeaderService{
 		repo:        repo,
-		checkpoints: config.Checkpoints,
+		checkpoints: params.Checkpoints,
+		powLimit:    params.PowLimit,
 		timeSource:  config.TimeSource,
 		log:         &headerLogger,
 	}
@@ -64,12 +71,11 @@ func (hs *HeaderService) IsCurrent() bool {
 	// Not current if the latest main (best) chain height is before the
 	// latest known good checkpoint (when checkpoints are enabled).
 	checkpoints := hs.checkpoints
-	checkpoint := &checkpoints[len(checkpoints)-1]
 	tip := hs.GetTip()
 	if tip == nil {
 		return true
 	}
-	if checkpoint != nil && tip.Height < checkpoint.Height {
+	if len(checkpoints) > 0 && tip.Height < checkpoints[len(checkpoints)-1].Height {
 		return false
 	}
 
@@ -110,10 +116,55 @@ func (hs *HeaderService) GetHeaderByHash(hash string) (*domains.BlockHeader, err
 	return header, nil
 }
 
-// GetHeadersByHeight returns the specified number of headers starting from given height.
-func (hs *HeaderService) GetHeadersByHeight(height int, count int) ([]*domains.BlockHeader, error) {
+// MapHeaders finds headers with given hashes and headers of the longest chain at given heights,
+// each list is looked up with a single query.
+func (hs *HeaderService) MapHeaders(hashes []string, heights []int32) (*domains.HeadersMapping, error) {
+	byHash, err := hs.repo.Headers.GetHeadersByHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+	byHeight, err := hs.repo.Headers.GetLongestChainHeadersByHeights(heights)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := &domains.HeadersMapping{
+		ByHash:         make([]*domains.BlockHeader, 0, len(byHash)),
+		ByHeight:       make([]*domains.BlockHeader, 0, len(byHeight)),
+		UnknownHashes:  make([]string, 0),
+		UnknownHeights: make([]int32, 0),
+	}
+
+	found := make(map[string]*domains.BlockHeader, len(byHash))
+	for _, h := range byHash {
+		found[h.Hash.String()] = h
+	}
+	for _, hash := range hashes {
+		if h, ok := found[strings.ToLower(hash)]; ok {
+			mapping.ByHash = append(mapping.ByHash, h)
+		} else {
+			mapping.UnknownHashes = append(mapping.UnknownHashes, hash)
+		}
+	}
+
+	atHeight := make(map[int32]*domains.BlockHeader, len(byHeight))
+	for _, h := range byHeight {
+		atHeight[h.Height] = h
+	}
+	for _, height := range heights {
+		if h, ok := atHeight[height]; ok {
+			mapping.ByHeight = append(mapping.ByHeight, h)
+		} else {
+			mapping.UnknownHeights = append(mapping.UnknownHeights, height)
+		}
+	}
+	return mapping, nil
+}
+
+// GetHeadersByHeight returns headers satisfying the query from the specified number of heights starting from given height.
+func (hs *HeaderService) GetHeadersByHeight(height int, count int, query domains.HeadersQuery) ([]*domains.BlockHeader, error) {
 	headersRange := height + count - 1
-	headers, err := hs.repo.Headers.GetHeaderByHeightRange(height, headersRange)
+	headers, err := hs.repo.Headers.GetHeaderByHeightRange(height, headersRange, query)
 
 	if err == nil {
 		return headers, nil
@@ -121,6 +172,31 @@ func (hs *HeaderService) GetHeadersByHeight(height int, count int) ([]*domains.B
 	return nil, err
 }
 
+// errEnoughHeaders stops reading headers once the limit is reached.
+var errEnoughHeaders = errors.New("enough headers read")
+
+// GetHeadersFromHeight returns at most limit headers satisfying the query from given height up to the tip,
+// so the limit caps the number of returned headers regardless of how many of them share a height.
+func (hs *HeaderService) GetHeadersFromHeight(height int, limit int, query domains.HeadersQuery) ([]*domains.BlockHeader, error) {
+	headers := make([]*domains.BlockHeader, 0)
+	err := hs.repo.Headers.StreamHeadersByHeightRange(height, math.MaxInt32, query, func(h *domains.BlockHeader) error {
+		headers = append(headers, h)
+		if len(headers) == limit {
+			return errEnoughHeaders
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnoughHeaders) {
+		return nil, err
+	}
+	return headers, nil
+}
+
+// StreamHeadersByHeight passes headers from given height one by one to fn, without loading them all into memory.
+func (hs *HeaderService) StreamHeadersByHeight(height int, count int, query domains.HeadersQuery, fn func(*domains.BlockHeader) error) error {
+	return hs.repo.Headers.StreamHeadersByHeightRange(height, height+count-1, query, fn)
+}
+
 // GetHeaderAncestorsByHash returns first ancestor for two headers specified by hash.
 func (hs *HeaderService) GetHeaderAncestorsByHash(hash string, ancestorHash string) ([]*domains.BlockHeader, error) {
 	// Get headers by hash
@@ -154,6 +230,58 @@ func (hs *HeaderService) GetHeaderAncestorsByHash(hash string, ancestorHash stri
 	return nil, err
 }
 
+// GetChainSegment returns headers from the header with ancestorHash up to the header with descendantHash, both included,
+// ordered by height. The headers have to be on the same branch and, unless limit is 0, the segment can't be longer than limit.
+func (hs *HeaderService) GetChainSegment(ancestorHash string, descendantHash string, limit int) ([]*domains.BlockHeader, error) {
+	ancestor, err := hs.repo.Headers.GetHeaderByHash(ancestorHash)
+	if err != nil {
+		return nil, bhserrors.ErrHeaderWithGivenHashes.Wrap(err)
+	}
+	descendant, err := hs.repo.Headers.GetHeaderByHash(descendantHash)
+	if err != nil {
+		return nil, bhserrors.ErrHeaderWithGivenHashes.Wrap(err)
+	}
+
+	if ancestor.Height > descendant.Height {
+		return nil, bhserrors.ErrAncestorHashHigher
+	}
+	length := int(descendant.Height-ancestor.Height) + 1
+	if limit > 0 && length > limit {
+		return nil, bhserrors.ErrChainSegmentTooLong
+	}
+	if ancestor.Hash == descendant.Hash {
+		return []*domains.BlockHeader{descendant}, nil
+	}
+
+	// Checked upfront, so the chain isn't read back to genesis when the headers are on different branches.
+	a, err := hs.repo.Headers.GetAncestorOnHeight(descendantHash, ancestor.Height)
+	if err != nil {
+		return nil, bhserrors.ErrHeadersNotPartOfTheSameChain.Wrap(err)
+	}
+	if a.Hash != ancestor.Hash {
+		return nil, bhserrors.ErrHeadersNotPartOfTheSameChain
+	}
+
+	headers, err := hs.repo.Headers.GetChainBetweenTwoHashes(ancestorHash, descendantHash)
+	if err != nil {
+		return nil, err
+	}
+	byHash := make(map[chainhash.Hash]*domains.BlockHeader, len(headers))
+	for _, h := range headers {
+		byHash[h.Hash] = h
+	}
+
+	segment := make([]*domains.BlockHeader, length)
+	segment[length-1] = descendant
+	for i := length - 2; i >= 0; i-- {
+		segment[i] = byHash[segment[i+1].PreviousBlock]
+		if segment[i] == nil {
+			return nil, bhserrors.ErrHeadersNotPartOfTheSameChain
+		}
+	}
+	return segment, nil
+}
+
 // GetCommonAncestor returns first ancestor for given slice of hashes.
 func (hs *HeaderService) GetCommonAncestor(hashes []string) (*domains.BlockHeader, error) {
 	headers := make([]*domains.BlockHeader, 0, len(hashes)+1)
@@ -201,6 +329,173 @@ func (hs *HeaderService) GetCommonAncestor(hashes []string) (*domains.BlockHeade
 	return nil, nil
 }
 
+// GetBranchDiff returns headers of both branches leading to the headers with given hashes which lie above their fork point.
+// Unless limit is 0, both branches together can't have more headers than limit.
+func (hs *HeaderService) GetBranchDiff(hash string, otherHash string, limit int) (*domains.BranchDiff, error) {
+	header, err := hs.repo.Headers.GetHeaderByHash(hash)
+	if err != nil {
+		return nil, bhserrors.ErrHeaderWithGivenHashes.Wrap(err)
+	}
+	other, err := hs.repo.Headers.GetHeaderByHash(otherHash)
+	if err != nil {
+		return nil, bhserrors.ErrHeaderWithGivenHashes.Wrap(err)
+	}
+
+	diff := &domains.BranchDiff{
+		Branch:      make([]*domains.BlockHeader, 0),
+		OtherBranch: make([]*domains.BlockHeader, 0),
+	}
+	for header.Hash != other.Hash {
+		if limit > 0 && len(diff.Branch)+len(diff.OtherBranch) >= limit {
+			return nil, bhserrors.ErrBranchDiffTooLong
+		}
+
+		if header.Height >= other.Height {
+			diff.Branch = append(diff.Branch, header)
+			header, err = hs.repo.Headers.GetHeaderByHash(header.PreviousBlock.String())
+		} else {
+			diff.OtherBranch = append(diff.OtherBranch, other)
+			other, err = hs.repo.Headers.GetHeaderByHash(other.PreviousBlock.String())
+		}
+		if err != nil {
+			return nil, bhserrors.ErrHeadersNotPartOfTheSameChain.Wrap(err)
+		}
+	}
+
+	diff.ForkPoint = header
+	slices.Reverse(diff.Branch)
+	slices.Reverse(diff.OtherBranch)
+	return diff, nil
+}
+
+// GetHeaderAtTime returns the highest header of the longest chain whose median time past is not after t.
+// Since the median time past never decreases, the header is found with binary search over heights.
+func (hs *HeaderService) GetHeaderAtTime(t time.Time) (*domains.HeaderAtTime, error) {
+	tip, err := hs.repo.Headers.GetTip()
+	if err != nil {
+		return nil, err
+	}
+
+	var searchErr error
+	height := sort.Search(int(tip.Height)+1, func(h int) bool {
+		if searchErr != nil {
+			return true
+		}
+		mtp, err := hs.medianTimePast(h)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return mtp.After(t)
+	}) - 1
+	if searchErr != nil {
+		return nil, searchErr
+	}
+	if height < 0 {
+		return nil, bhserrors.ErrNoHeaderAtTime
+	}
+
+	headers, err := hs.medianTimeHeaders(height)
+	if err != nil {
+		return nil, err
+	}
+	return &domains.HeaderAtTime{
+		Header:         headers[len(headers)-1],
+		MedianTimePast: domains.MedianTimePast(headers),
+	}, nil
+}
+
+func (hs *HeaderService) medianTimePast(height int) (time.Time, error) {
+	headers, err := hs.medianTimeHeaders(height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return domains.MedianTimePast(headers), nil
+}
+
+// medianTimeHeaders returns headers of the longest chain making the median time past of the header on given height, ordered by height.
+func (hs *HeaderService) medianTimeHeaders(height int) ([]*domains.BlockHeader, error) {
+	headers, err := hs.repo.Headers.GetHeadersByHeightRange(max(0, height-domains.MedianTimeBlocks+1), height)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) == 0 {
+		return nil, bhserrors.ErrHeaderNotFound
+	}
+	slices.SortFunc(headers, func(a, b *domains.BlockHeader) int { return int(a.Height - b.Height) })
+	return headers, nil
+}
+
+// GetDifficultyHistory returns every interval-th header of the longest chain between given heights, starting with from,
+// so the difficulty can be charted without reading all headers.
+func (hs *HeaderService) GetDifficultyHistory(from int, to int, interval int) ([]*domains.BlockHeader, error) {
+	return hs.repo.Headers.GetSampledHeadersByHeightRange(from, to, interval)
+}
+
+// GetHashrate estimates the network hashrate from work done by the last blocks headers of the longest chain,
+// the window starts at genesis when the chain is shorter.
+func (hs *HeaderService) GetHashrate(blocks int) (*domains.Hashrate, error) {
+	tip, err := hs.repo.Headers.GetTip()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := hs.repo.Headers.GetHeaderByHeight(max(0, tip.Height-int32(blocks)))
+	if err != nil {
+		return nil, err
+	}
+	return domains.EstimateHashrate(from, tip), nil
+}
+
+// GetHeadersCounts counts stored headers in each state, in total and within each of the windows before now.
+// Windows are bounded by heights of the longest chain, so stale headers on lower heights are not counted in them.
+func (hs *HeaderService) GetHeadersCounts(windows []time.Duration) (*domains.HeadersCounts, error) {
+	total, err := hs.repo.Headers.GetHeadersCountByState(0)
+	if err != nil {
+		return nil, err
+	}
+	counts := &domains.HeadersCounts{Total: total, Windows: make([]domains.HeadersCountWindow, 0, len(windows))}
+	if len(windows) == 0 {
+		return counts, nil
+	}
+
+	tip, err := hs.repo.Headers.GetTip()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, w := range windows {
+		from, err := hs.firstHeightSince(tip, now.Add(-w))
+		if err != nil {
+			return nil, err
+		}
+		windowCounts, err := hs.repo.Headers.GetHeadersCountByState(from)
+		if err != nil {
+			return nil, err
+		}
+		counts.Windows = append(counts.Windows, domains.HeadersCountWindow{Window: w, FromHeight: from, Counts: windowCounts})
+	}
+	return counts, nil
+}
+
+// firstHeightSince finds the lowest height of the longest chain which header was created at the given time or later,
+// it's the height above the tip when there is no such header.
+func (hs *HeaderService) firstHeightSince(tip *domains.BlockHeader, since time.Time) (int32, error) {
+	var searchErr error
+	height := sort.Search(int(tip.Height)+1, func(h int) bool {
+		if searchErr != nil {
+			return true
+		}
+		header, err := hs.repo.Headers.GetHeaderByHeight(int32(h))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return !header.Timestamp.Before(since)
+	})
+	return int32(height), searchErr
+}
+
 // GetHeadersState returns state of the header with given hash.
 func (hs *HeaderService) GetHeadersState(hash string) (*domains.BlockHeaderState, error) {
 	header, err := hs.repo.Headers.GetHeaderByHash(hash)
@@ -418,6 +713,57 @@ func (hs *HeaderService) GetTips() ([]*domains.BlockHeader, error) {
 	return hs.repo.Headers.GetAllTips()
 }
 
+// GetForks returns stale chains known to the service with the point where they branch off the longest chain.
+func (hs *HeaderService) GetForks() ([]*domains.Fork, error) {
+	tips, err := hs.repo.Headers.GetAllTips()
+	if err != nil {
+		return nil, err
+	}
+
+	mainTip, err := hs.repo.Headers.GetTip()
+	if err != nil {
+		return nil, err
+	}
+
+	forks := make([]*domains.Fork, 0)
+	for _, tip := range tips {
+		if tip.State != domains.Stale {
+			continue
+		}
+
+		fork, err := hs.forkOf(tip, mainTip)
+		if err != nil {
+			return nil, err
+		}
+		forks = append(forks, fork)
+	}
+	return forks, nil
+}
+
+// forkOf walks back from the stale tip until it reaches the longest chain.
+// A fork with a missing header is reported as an orphan without the fork point.
+func (hs *HeaderService) forkOf(tip, mainTip *domains.BlockHeader) (*domains.Fork, error) {
+	fork := &domains.Fork{Tip: tip, WorkDeficit: new(big.Int).Sub(mainTip.CumulatedWork, tip.CumulatedWork)}
+
+	header := tip
+	for header.State != domains.LongestChain {
+		prev, err := hs.repo.Headers.GetHeaderByHash(header.PreviousBlock.String())
+		if errors.Is(err, bhserrors.ErrHeaderNotFound) {
+			hs.log.Warn().Msgf("Fork with tip %s misses header %s below height %d", tip.Hash, header.PreviousBlock, header.Height)
+			fork.Length++
+			fork.Orphan = true
+			return fork, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fork.Length++
+		header = prev
+	}
+	fork.ForkPoint = header
+	return fork, nil
+}
+
 func areAllElementsEqual(slice []*domains.BlockHeader) bool {
 	for _, val := range slice {
 		if val.Hash != slice[0].Hash {
@@ -3,6 +3,7 @@ package service
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
@@ -56,13 +57,40 @@ func TestGetHeadersByHeight(t *testing.T) {
 	}
 
 	for _, tt := range testCases {
-		headers, err := tData.hs.Headers.GetHeadersByHeight(tt.height, tt.count)
+		headers, err := tData.hs.Headers.GetHeadersByHeight(tt.height, tt.count, domains.HeadersQuery{})
 
 		assert.Equal(t, err != nil, tt.expectedError)
 		assert.Equal(t, len(headers), tt.expectedCount)
 	}
 }
 
+func TestGetHeadersByHeightInStates(t *testing.T) {
+	// given
+	db, _ := fixtures.LongestChainWithFork()
+	var array []domains.BlockHeader = db
+	repo := &repository.Repositories{
+		Headers: testrepository.NewHeadersTestRepository(&array),
+	}
+	log := zerolog.Nop()
+	hs := NewHeaderService(repo, config.GetDefaultAppConfig().P2P, &log)
+
+	// when
+	stale, err := hs.GetHeadersByHeight(0, 10, domains.HeadersQuery{States: []domains.HeaderState{domains.Stale}})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(stale), 2)
+	assert.Equal(t, stale[0].Hash, *fixtures.StaleHashHeight3)
+	assert.Equal(t, stale[1].Hash, *fixtures.StaleHashHeight4)
+
+	// when
+	all, err := hs.GetHeadersByHeight(3, 1, domains.HeadersQuery{States: []domains.HeaderState{domains.Stale, domains.LongestChain}})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(all), 2)
+}
+
 func TestGetHeadersByHash(t *testing.T) {
 	tData := setUpServices()
 
@@ -172,6 +200,247 @@ func TestGetHeaderAncestorsByHash(t *testing.T) {
 	}
 }
 
+func TestGetChainSegment(t *testing.T) {
+	tData := setUpServices()
+
+	testCases := map[string]struct {
+		ancestorHash   string
+		descendantHash string
+		limit          int
+		expectedHashes []*chainhash.Hash
+		expectedError  string
+	}{
+		"segment ordered by height": {
+			ancestorHash:   fixtures.HashHeight1.String(),
+			descendantHash: fixtures.HashHeight4.String(),
+			expectedHashes: []*chainhash.Hash{fixtures.HashHeight1, fixtures.HashHeight2, fixtures.HashHeight3, fixtures.HashHeight4},
+		},
+		"single header": {
+			ancestorHash:   fixtures.HashHeight2.String(),
+			descendantHash: fixtures.HashHeight2.String(),
+			expectedHashes: []*chainhash.Hash{fixtures.HashHeight2},
+		},
+		"ancestor higher than descendant": {
+			ancestorHash:   fixtures.HashHeight4.String(),
+			descendantHash: fixtures.HashHeight1.String(),
+			expectedError:  "ancestor header height can not be higher than requested header height",
+		},
+		"segment longer than limit": {
+			ancestorHash:   fixtures.HashHeight1.String(),
+			descendantHash: fixtures.HashHeight4.String(),
+			limit:          3,
+			expectedError:  "chain segment is longer than the bulk headers limit",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			segment, err := tData.hs.Headers.GetChainSegment(tc.ancestorHash, tc.descendantHash, tc.limit)
+
+			// then
+			if tc.expectedError != "" {
+				assert.IsError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, len(segment), len(tc.expectedHashes))
+			for i, h := range segment {
+				assert.Equal(t, h.Hash, *tc.expectedHashes[i])
+			}
+		})
+	}
+}
+
+func TestGetBranchDiff(t *testing.T) {
+	// given
+	tData := setUpServices()
+	*tData.db = append(*tData.db,
+		createForkHeader(3, *fixtures.HashHeight5, *fixtures.HashHeight2),
+		createForkHeader(4, *fixtures.HashHeight6, *fixtures.HashHeight5),
+	)
+
+	testCases := map[string]struct {
+		hash                string
+		otherHash           string
+		limit               int
+		expectedForkPoint   *chainhash.Hash
+		expectedBranch      []*chainhash.Hash
+		expectedOtherBranch []*chainhash.Hash
+		expectedError       string
+	}{
+		"diverging branches": {
+			hash:                fixtures.HashHeight4.String(),
+			otherHash:           fixtures.HashHeight6.String(),
+			expectedForkPoint:   fixtures.HashHeight2,
+			expectedBranch:      []*chainhash.Hash{fixtures.HashHeight3, fixtures.HashHeight4},
+			expectedOtherBranch: []*chainhash.Hash{fixtures.HashHeight5, fixtures.HashHeight6},
+		},
+		"header on the other branch": {
+			hash:                fixtures.HashHeight1.String(),
+			otherHash:           fixtures.HashHeight3.String(),
+			expectedForkPoint:   fixtures.HashHeight1,
+			expectedOtherBranch: []*chainhash.Hash{fixtures.HashHeight2, fixtures.HashHeight3},
+		},
+		"branches longer than limit": {
+			hash:          fixtures.HashHeight4.String(),
+			otherHash:     fixtures.HashHeight6.String(),
+			limit:         3,
+			expectedError: "diverging branches have more headers than the bulk headers limit",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			diff, err := tData.hs.Headers.GetBranchDiff(tc.hash, tc.otherHash, tc.limit)
+
+			// then
+			if tc.expectedError != "" {
+				assert.IsError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, diff.ForkPoint.Hash, *tc.expectedForkPoint)
+			assert.Equal(t, len(diff.Branch), len(tc.expectedBranch))
+			for i, h := range diff.Branch {
+				assert.Equal(t, h.Hash, *tc.expectedBranch[i])
+			}
+			assert.Equal(t, len(diff.OtherBranch), len(tc.expectedOtherBranch))
+			for i, h := range diff.OtherBranch {
+				assert.Equal(t, h.Hash, *tc.expectedOtherBranch[i])
+			}
+		})
+	}
+}
+
+func TestGetHeaderAtTime(t *testing.T) {
+	tData := setUpServices()
+
+	testCases := map[string]struct {
+		time           time.Time
+		expectedHeight int32
+		expectedMTP    time.Time
+		expectedError  string
+	}{
+		"between median times past": {
+			time:           time.Date(2009, 1, 9, 2, 55, 0, 0, time.UTC),
+			expectedHeight: 2,
+			expectedMTP:    fixtures.HeaderSourceHeight1.Timestamp,
+		},
+		"equal to median time past": {
+			time:           fixtures.HeaderSourceHeight2.Timestamp,
+			expectedHeight: 4,
+			expectedMTP:    fixtures.HeaderSourceHeight2.Timestamp,
+		},
+		"after the tip": {
+			time:           time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedHeight: 4,
+			expectedMTP:    fixtures.HeaderSourceHeight2.Timestamp,
+		},
+		"before genesis": {
+			time:          time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedError: "no header of the longest chain was active at given time",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			header, err := tData.hs.Headers.GetHeaderAtTime(tc.time)
+
+			// then
+			if tc.expectedError != "" {
+				assert.IsError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, header.Header.Height, tc.expectedHeight)
+			assert.Equal(t, header.MedianTimePast.Equal(tc.expectedMTP), true)
+		})
+	}
+}
+
+func TestGetDifficultyHistory(t *testing.T) {
+	// given
+	tData := setUpServices()
+
+	// when
+	headers, err := tData.hs.Headers.GetDifficultyHistory(0, 4, 2)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(headers), 3)
+	for i, h := range headers {
+		assert.Equal(t, h.Height, int32(2*i))
+	}
+}
+
+func TestGetHashrate(t *testing.T) {
+	// given
+	tData := setUpServices()
+	elapsed := fixtures.HeaderSourceHeight4.Timestamp.Sub(fixtures.HeaderSourceHeight2.Timestamp)
+
+	// when
+	hashrate, err := tData.hs.Headers.GetHashrate(2)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, hashrate.From.Height, 2)
+	assert.Equal(t, hashrate.To.Height, 4)
+	assert.Equal(t, hashrate.Work.Int64(), 2*fixtures.DefaultChainWork)
+	assert.Equal(t, hashrate.HashesPerSecond.Int64(), 2*fixtures.DefaultChainWork/int64(elapsed.Seconds()))
+}
+
+func TestMapHeaders(t *testing.T) {
+	// given
+	tData := setUpServices()
+	*tData.db = append(*tData.db, createForkHeader(4, *fixtures.HashHeight5, *fixtures.HashHeight3))
+	unknownHash := chainhash.Hash{}.String()
+
+	// when
+	mapping, err := tData.hs.Headers.MapHeaders(
+		[]string{fixtures.HashHeight5.String(), unknownHash, fixtures.HashHeight1.String()},
+		[]int32{4, 9},
+	)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(mapping.ByHash), 2)
+	assert.Equal(t, mapping.ByHash[0].Hash, *fixtures.HashHeight5)
+	assert.Equal(t, mapping.ByHash[0].State, domains.Stale)
+	assert.Equal(t, mapping.ByHash[1].Height, 1)
+	assert.Equal(t, len(mapping.ByHeight), 1)
+	assert.Equal(t, mapping.ByHeight[0].Hash, *fixtures.HashHeight4)
+	assert.Equal(t, len(mapping.UnknownHashes), 1)
+	assert.Equal(t, mapping.UnknownHashes[0], unknownHash)
+	assert.Equal(t, len(mapping.UnknownHeights), 1)
+	assert.Equal(t, mapping.UnknownHeights[0], 9)
+}
+
+func TestGetHeadersCounts(t *testing.T) {
+	// given
+	tData := setUpServices()
+	*tData.db = append(*tData.db, createForkHeader(4, *fixtures.HashHeight5, *fixtures.HashHeight3))
+	window := time.Since(fixtures.HeaderSourceHeight4.Timestamp) + time.Minute
+
+	// when
+	counts, err := tData.hs.Headers.GetHeadersCounts([]time.Duration{window, time.Hour})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, counts.Total[domains.LongestChain], 5)
+	assert.Equal(t, counts.Total[domains.Stale], 1)
+	assert.Equal(t, len(counts.Windows), 2)
+
+	assert.Equal(t, counts.Windows[0].FromHeight, 4)
+	assert.Equal(t, counts.Windows[0].Counts[domains.LongestChain], 1)
+	assert.Equal(t, counts.Windows[0].Counts[domains.Stale], 1)
+
+	assert.Equal(t, counts.Windows[1].FromHeight, 5)
+	assert.Equal(t, len(counts.Windows[1].Counts), 0)
+}
+
 func TestGetCommonAncestor(t *testing.T) {
 	tData := setUpServices()
 
@@ -224,7 +493,7 @@ func TestLatestHeaderLocator(t *testing.T) {
 	tData := setUpServices()
 
 	locator := tData.hs.Headers.LatestHeaderLocator()
-	headers, _ := tData.hs.Headers.GetHeadersByHeight(0, 5)
+	headers, _ := tData.hs.Headers.GetHeadersByHeight(0, 5, domains.HeadersQuery{})
 
 	for _, header := range headers {
 		check := false
@@ -251,6 +520,57 @@ func TestGetAllTips(t *testing.T) {
 	assert.Equal(t, len(tips), 2)
 }
 
+func TestGetForks(t *testing.T) {
+	// given
+	tData := setUpServices()
+	stale := []domains.BlockHeader{
+		createForkHeader(3, *fixtures.HashHeight5, *fixtures.HashHeight2),
+		createForkHeader(4, *fixtures.HashHeight6, *fixtures.HashHeight5),
+	}
+	stale[1].CumulatedWork = big.NewInt(fixtures.DefaultChainWork*4 - 10)
+	*tData.db = append(*tData.db, stale...)
+
+	// when
+	forks, err := tData.hs.Headers.GetForks()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(forks), 1)
+	assert.Equal(t, forks[0].Tip.Hash, *fixtures.HashHeight6)
+	assert.Equal(t, forks[0].ForkPoint.Hash, *fixtures.HashHeight2)
+	assert.Equal(t, forks[0].Length, 2)
+	assert.Equal(t, forks[0].WorkDeficit.Int64(), 10)
+}
+
+func TestGetForksReportsOrphanFork(t *testing.T) {
+	// given
+	tData := setUpServices()
+	missing := fixtures.HashOf("00000000000000000000000000000000000000000000000000000000000000aa")
+	stale := []domains.BlockHeader{
+		createForkHeader(3, *fixtures.HashHeight5, *fixtures.HashHeight2),
+		createForkHeader(4, *fixtures.HashHeight6, *fixtures.HashHeight5),
+		createForkHeader(4, *fixtures.HashOf("00000000000000000000000000000000000000000000000000000000000000bb"), *missing),
+	}
+	*tData.db = append(*tData.db, stale...)
+
+	// when
+	forks, err := tData.hs.Headers.GetForks()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(forks), 2)
+	for _, fork := range forks {
+		if fork.Tip.Hash == *fixtures.HashHeight6 {
+			assert.Equal(t, fork.Orphan, false)
+			assert.Equal(t, fork.ForkPoint.Hash, *fixtures.HashHeight2)
+		} else {
+			assert.Equal(t, fork.Orphan, true)
+			assert.Equal(t, fork.ForkPoint == nil, true)
+			assert.Equal(t, fork.Length, 1)
+		}
+	}
+}
+
 func TestLocateHeadersGetHeadersHappyPath(t *testing.T) {
 	tData := setUpServices()
 
@@ -452,3 +772,10 @@ func createHeader(height int32, hash chainhash.Hash, prevBlock chainhash.Hash) d
 		Chainwork:     big.NewInt(4295032833),
 	}
 }
+
+func createForkHeader(height int32, hash chainhash.Hash, prevBlock chainhash.Hash) domains.BlockHeader {
+	header := createHeader(height, hash, prevBlock)
+	header.State = domains.Stale
+	header.CumulatedWork = big.NewInt(fixtures.DefaultChainWork * int64(height))
+	return header
+}
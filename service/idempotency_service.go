@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// IdempotencyService stores responses of mutating requests sent with an idempotency key,
+// so a request retried with the same key gets the stored response instead of being processed again.
+type IdempotencyService struct {
+	repo        repository.IdempotencyKeys
+	maintenance Maintenance
+	cfg         *config.IdempotencyConfig
+	log         zerolog.Logger
+	now         func() time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewIdempotencyService creates and returns IdempotencyService, without a config or a repository it's disabled.
+func NewIdempotencyService(repo repository.IdempotencyKeys, maintenance Maintenance, cfg *config.IdempotencyConfig, log *zerolog.Logger) *IdempotencyService {
+	if cfg == nil {
+		cfg = &config.IdempotencyConfig{}
+	}
+	return &IdempotencyService{
+		repo:        repo,
+		maintenance: maintenance,
+		cfg:         cfg,
+		log:         log.With().Str("service", "idempotency").Logger(),
+		now:         time.Now,
+		quit:        make(chan struct{}),
+	}
+}
+
+// Enabled reports whether responses of requests with an idempotency key are stored.
+func (s *IdempotencyService) Enabled() bool {
+	return s.cfg.Enabled && s.repo != nil
+}
+
+// Begin claims the key for the request with given hash. It returns nil when the request should be processed now,
+// or the stored response when it was already processed. It fails with ErrIdempotencyKeyInProgress while the request
+// is still processed and with ErrIdempotencyKeyMismatch when the key was used with another request.
+func (s *IdempotencyService) Begin(key, requestHash string) (*domains.IdempotencyKey, error) {
+	now := s.now()
+	stored, err := s.repo.ClaimIdempotencyKey(&domains.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.cfg.TTL),
+	}, now)
+	if err != nil {
+		return nil, bhserrors.ErrIdempotencyKey.Wrap(err)
+	}
+
+	switch {
+	case stored == nil:
+		return nil, nil
+	case stored.RequestHash != requestHash:
+		return nil, bhserrors.ErrIdempotencyKeyMismatch
+	case !stored.Completed():
+		return nil, bhserrors.ErrIdempotencyKeyInProgress
+	default:
+		return stored, nil
+	}
+}
+
+// Complete stores the response of the request made with the key.
+func (s *IdempotencyService) Complete(key *domains.IdempotencyKey) error {
+	if err := s.repo.CompleteIdempotencyKey(key); err != nil {
+		return bhserrors.ErrIdempotencyKey.Wrap(err)
+	}
+	return nil
+}
+
+// Release frees the key of a request which failed, so it can be retried with the same key.
+func (s *IdempotencyService) Release(key string) error {
+	if err := s.repo.DeleteIdempotencyKey(key); err != nil {
+		return bhserrors.ErrIdempotencyKey.Wrap(err)
+	}
+	return nil
+}
+
+// Start deletes expired keys in the background on the configured interval, if enabled.
+// Expired keys are kept while the maintenance mode is enabled.
+func (s *IdempotencyService) Start() {
+	if !s.Enabled() {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if s.maintenance.ModeEnabled() {
+					continue
+				}
+				deleted, err := s.repo.DeleteExpiredIdempotencyKeys(s.now())
+				if err != nil {
+					s.log.Error().Msgf("failed to delete expired idempotency keys: %v", err)
+				} else if deleted > 0 {
+					s.log.Debug().Msgf("Deleted %d expired idempotency keys", deleted)
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops deleting expired keys.
+func (s *IdempotencyService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
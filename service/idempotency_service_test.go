@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestIdempotencyKeyReplaysCompletedRequest(t *testing.T) {
+	// given
+	s := newTestIdempotencyService()
+	first, err := s.Begin("key", "request")
+	assert.NoError(t, err)
+
+	// when
+	_, inProgressErr := s.Begin("key", "request")
+	assert.NoError(t, s.Complete(&domains.IdempotencyKey{Key: "key", RequestHash: "request", StatusCode: 200, Body: []byte("{}")}))
+	replayed, err := s.Begin("key", "request")
+	_, mismatchErr := s.Begin("key", "other request")
+
+	// then
+	assert.Equal(t, first == nil, true)
+	assert.Equal(t, errors.Is(inProgressErr, bhserrors.ErrIdempotencyKeyInProgress), true)
+	assert.NoError(t, err)
+	assert.Equal(t, replayed.StatusCode, 200)
+	assert.Equal(t, string(replayed.Body), "{}")
+	assert.Equal(t, errors.Is(mismatchErr, bhserrors.ErrIdempotencyKeyMismatch), true)
+}
+
+func TestIdempotencyKeyCanBeReusedWhenReleasedOrExpired(t *testing.T) {
+	// given
+	s := newTestIdempotencyService()
+	_, err := s.Begin("released", "request")
+	assert.NoError(t, err)
+	_, err = s.Begin("expired", "request")
+	assert.NoError(t, err)
+	assert.NoError(t, s.Complete(&domains.IdempotencyKey{Key: "expired", RequestHash: "request", StatusCode: 200}))
+
+	// when
+	assert.NoError(t, s.Release("released"))
+	released, releasedErr := s.Begin("released", "request")
+	s.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	expired, expiredErr := s.Begin("expired", "other request")
+
+	// then
+	assert.NoError(t, releasedErr)
+	assert.Equal(t, released == nil, true)
+	assert.NoError(t, expiredErr)
+	assert.Equal(t, expired == nil, true)
+}
+
+func newTestIdempotencyService() *IdempotencyService {
+	log := zerolog.Nop()
+	return NewIdempotencyService(testrepository.NewIdempotencyKeysTestRepository(), newTestMaintenanceService(testrepository.NewMaintenanceTestRepository()), config.GetDefaultAppConfig().Idempotency, &log)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// LeaderLeaseName is the name of the lease which instances compete for to become the syncing leader.
+const LeaderLeaseName = "p2p_sync"
+
+// LeaderElector elects a single instance, out of instances sharing the same database,
+// which is allowed to sync headers over p2p and write them into the database.
+type LeaderElector struct {
+	repo     repository.Leases
+	cfg      *config.HAConfig
+	holder   string
+	log      zerolog.Logger
+	isLeader atomic.Bool
+	renewed  time.Time
+	quit     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewLeaderElector creates and returns LeaderElector instance.
+func NewLeaderElector(repo repository.Leases, cfg *config.HAConfig, log *zerolog.Logger) *LeaderElector {
+	holder := cfg.InstanceID
+	if holder == "" {
+		hostname, _ := os.Hostname()
+		holder = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return &LeaderElector{
+		repo:   repo,
+		cfg:    cfg,
+		holder: holder,
+		log:    log.With().Str("service", "leader-election").Str("instance", holder).Logger(),
+		quit:   make(chan struct{}),
+	}
+}
+
+// IsLeader returns true if this instance currently holds the leader lease.
+func (e *LeaderElector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+// Start runs the election loop in the background. The onElected callback is invoked when this
+// instance becomes the leader and the onDemoted callback when it loses a lease it was holding.
+func (e *LeaderElector) Start(onElected, onDemoted func()) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.cfg.RenewInterval)
+		defer ticker.Stop()
+
+		for {
+			e.campaign(onElected, onDemoted)
+
+			select {
+			case <-ticker.C:
+			case <-e.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the election loop and releases the lease if this instance is the leader.
+// It can be called multiple times, only the first call stops the loop.
+func (e *LeaderElector) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.quit)
+		e.wg.Wait()
+
+		if e.isLeader.Swap(false) {
+			if err := e.repo.ReleaseLease(LeaderLeaseName, e.holder); err != nil {
+				e.log.Error().Msgf("failed to release leader lease: %v", err)
+				return
+			}
+			e.log.Info().Msg("leader lease released")
+		}
+	})
+}
+
+func (e *LeaderElector) campaign(onElected, onDemoted func()) {
+	acquired, err := e.repo.TryAcquireLease(LeaderLeaseName, e.holder, e.cfg.LeaseTTL)
+	if err != nil {
+		e.log.Error().Msgf("failed to acquire leader lease: %v", err)
+		// Lease held by this instance stays valid until it expires, so a single failed renewal is not a demotion.
+		acquired = e.IsLeader() && time.Since(e.renewed) < e.cfg.LeaseTTL
+	} else if acquired {
+		e.renewed = time.Now()
+	}
+
+	wasLeader := e.isLeader.Swap(acquired)
+	switch {
+	case acquired && !wasLeader:
+		e.log.Info().Msg("elected as leader")
+		onElected()
+	case !acquired && wasLeader:
+		e.log.Warn().Msg("leader lease lost")
+		onDemoted()
+	}
+}
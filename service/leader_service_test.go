@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestOnlyOneInstanceIsElected(t *testing.T) {
+	// given
+	repo := testrepository.NewLeaseTestRepository()
+	first := newTestElector(repo, "first")
+	second := newTestElector(repo, "second")
+	firstEvents, secondEvents := 0, 0
+
+	// when
+	first.campaign(func() { firstEvents++ }, func() { firstEvents-- })
+	second.campaign(func() { secondEvents++ }, func() { secondEvents-- })
+
+	// then
+	assert.Equal(t, first.IsLeader(), true)
+	assert.Equal(t, second.IsLeader(), false)
+	assert.Equal(t, firstEvents, 1)
+	assert.Equal(t, secondEvents, 0)
+}
+
+func TestLeaderKeepsLeadershipOnTransientError(t *testing.T) {
+	// given
+	repo := testrepository.NewLeaseTestRepository()
+	elector := newTestElector(repo, "leader")
+	demoted := false
+	elector.campaign(func() {}, func() { demoted = true })
+
+	// when
+	repo.Err = errors.New("connection refused")
+	elector.campaign(func() {}, func() { demoted = true })
+
+	// then
+	assert.Equal(t, elector.IsLeader(), true)
+	assert.Equal(t, demoted, false)
+}
+
+func TestLeaseIsTakenOverAfterRelease(t *testing.T) {
+	// given
+	repo := testrepository.NewLeaseTestRepository()
+	first := newTestElector(repo, "first")
+	second := newTestElector(repo, "second")
+	first.campaign(func() {}, func() {})
+
+	// when
+	first.Stop()
+	second.campaign(func() {}, func() {})
+
+	// then
+	assert.Equal(t, first.IsLeader(), false)
+	assert.Equal(t, second.IsLeader(), true)
+}
+
+func newTestElector(repo *testrepository.LeaseTestRepository, instanceID string) *LeaderElector {
+	log := zerolog.Nop()
+	return NewLeaderElector(repo, &config.HAConfig{
+		Enabled:       true,
+		InstanceID:    instanceID,
+		LeaseTTL:      time.Minute,
+		RenewInterval: time.Second,
+	}, &log)
+}
+
+func TestLeaderElectorStopsOnce(t *testing.T) {
+	// given
+	repo := testrepository.NewLeaseTestRepository()
+	elector := newTestElector(repo, "leader")
+	elector.Start(func() {}, func() {})
+
+	// when
+	elector.Stop()
+	elector.Stop()
+
+	// then
+	assert.Equal(t, elector.IsLeader(), false)
+}
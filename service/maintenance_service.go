@@ -0,0 +1,166 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// MaintenanceService runs database maintenance on demand and on the configured schedule.
+// It also holds the maintenance mode, in which syncing and write operations are paused.
+// The mode is entered by the admin or, to protect the database, while the free disk space is low.
+type MaintenanceService struct {
+	repo         repository.Maintenance
+	cfg          *config.MaintenanceConfig
+	log          zerolog.Logger
+	running      sync.Mutex
+	mode         atomic.Bool
+	lowDiskSpace atomic.Bool
+	quit         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewMaintenanceService creates and returns MaintenanceService instance.
+func NewMaintenanceService(repo repository.Maintenance, cfg *config.MaintenanceConfig, log *zerolog.Logger) *MaintenanceService {
+	s := &MaintenanceService{
+		repo: repo,
+		cfg:  cfg,
+		log:  log.With().Str("service", "maintenance").Logger(),
+		quit: make(chan struct{}),
+	}
+	if cfg != nil {
+		s.mode.Store(cfg.ModeEnabled)
+	}
+	return s
+}
+
+// Run vacuums the database and refreshes its statistics, returning how long it took.
+// Only one maintenance runs at a time, a concurrent call fails with ErrMaintenanceInProgress.
+// It fails with ErrMaintenanceMode in the maintenance mode, since the database may be being backed up.
+func (s *MaintenanceService) Run() (time.Duration, error) {
+	if s.ModeEnabled() {
+		return 0, bhserrors.ErrMaintenanceMode
+	}
+	if !s.running.TryLock() {
+		return 0, bhserrors.ErrMaintenanceInProgress
+	}
+	defer s.running.Unlock()
+
+	start := time.Now()
+	if err := s.repo.Maintain(); err != nil {
+		return 0, bhserrors.ErrMaintenance.Wrap(err)
+	}
+	took := time.Since(start)
+
+	s.log.Info().Msgf("Database maintenance finished in %s", took)
+	return took, nil
+}
+
+// Start runs the maintenance in the background on the configured interval, if any.
+func (s *MaintenanceService) Start() {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if s.ModeEnabled() {
+					s.log.Info().Msg("scheduled database maintenance skipped in the maintenance mode")
+					continue
+				}
+				if _, err := s.Run(); err != nil {
+					s.log.Error().Msgf("scheduled database maintenance failed: %v", err)
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled maintenance and waits for a running one to finish.
+func (s *MaintenanceService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// SetMode enables or disables the maintenance mode.
+func (s *MaintenanceService) SetMode(enabled bool) {
+	if s.mode.Swap(enabled) == enabled {
+		return
+	}
+	switch {
+	case enabled:
+		s.log.Warn().Msg("Maintenance mode enabled, syncing and write operations are paused")
+	case s.LowDiskSpace():
+		s.log.Warn().Msg("Maintenance mode disabled, syncing and write operations stay paused until free disk space is recovered")
+	default:
+		s.log.Info().Msg("Maintenance mode disabled, syncing and write operations are resumed")
+	}
+}
+
+// ModeEnabled returns true if the maintenance mode is enabled, either by the admin or because of low disk space.
+func (s *MaintenanceService) ModeEnabled() bool {
+	return s.mode.Load() || s.lowDiskSpace.Load()
+}
+
+// SetLowDiskSpace enters or leaves the maintenance mode because of low free disk space,
+// independently of the mode set by the admin.
+func (s *MaintenanceService) SetLowDiskSpace(low bool) {
+	if s.lowDiskSpace.Swap(low) == low {
+		return
+	}
+	switch {
+	case low:
+		s.log.Warn().Msg("Free disk space is low, syncing and write operations are paused")
+	case s.mode.Load():
+		s.log.Info().Msg("Free disk space is recovered, syncing and write operations stay paused in the maintenance mode")
+	default:
+		s.log.Info().Msg("Free disk space is recovered, syncing and write operations are resumed")
+	}
+}
+
+// LowDiskSpace returns true if syncing and write operations are paused because of low free disk space.
+func (s *MaintenanceService) LowDiskSpace() bool {
+	return s.lowDiskSpace.Load()
+}
+
+// pausableChains doesn't add headers while the maintenance mode is enabled, whichever source they come from.
+// Adding holds writes for reading, so it waits while headers are deleted for a re-sync.
+type pausableChains struct {
+	Chains
+	maintenance Maintenance
+	writes      *sync.RWMutex
+}
+
+func (c *pausableChains) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader, error) {
+	if c.maintenance.ModeEnabled() {
+		return nil, WritesPaused.error()
+	}
+	c.writes.RLock()
+	defer c.writes.RUnlock()
+	return c.Chains.Add(bs)
+}
+
+func (c *pausableChains) Generate(count int, from string) ([]*domains.BlockHeader, error) {
+	if c.maintenance.ModeEnabled() {
+		return nil, bhserrors.ErrMaintenanceMode
+	}
+	c.writes.RLock()
+	defer c.writes.RUnlock()
+	return c.Chains.Generate(count, from)
+}
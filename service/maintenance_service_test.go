@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestMaintenanceRunIsExclusive(t *testing.T) {
+	// given
+	repo := testrepository.NewMaintenanceTestRepository()
+	s := newTestMaintenanceService(repo)
+	s.running.Lock()
+
+	// when
+	_, err := s.Run()
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrMaintenanceInProgress), true)
+	assert.Equal(t, repo.Runs, 0)
+}
+
+func TestMaintenanceRunFailure(t *testing.T) {
+	// given
+	repo := testrepository.NewMaintenanceTestRepository()
+	repo.Err = errors.New("database is locked")
+	s := newTestMaintenanceService(repo)
+
+	// when
+	_, err := s.Run()
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrMaintenance), true)
+
+	// and the next run is not blocked
+	repo.Err = nil
+	_, err = s.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Runs, 1)
+}
+
+func TestMaintenanceRunInMaintenanceMode(t *testing.T) {
+	// given
+	repo := testrepository.NewMaintenanceTestRepository()
+	s := newTestMaintenanceService(repo)
+	s.SetMode(true)
+
+	// when
+	_, err := s.Run()
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrMaintenanceMode), true)
+	assert.Equal(t, repo.Runs, 0)
+}
+
+func newTestMaintenanceService(repo *testrepository.MaintenanceTestRepository) *MaintenanceService {
+	log := zerolog.Nop()
+	return NewMaintenanceService(repo, &config.MaintenanceConfig{}, &log)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/cron"
+	"github.com/bitcoin-sv/block-headers-service/metrics"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// PruningService deletes stale and orphan branches which ended deep below the tip of the longest chain.
+type PruningService struct {
+	headers     repository.Headers
+	maintenance Maintenance
+	// writes is held by adding headers, so none is added to a branch while it is deleted.
+	writes  *sync.RWMutex
+	cfg     *config.PruningConfig
+	log     zerolog.Logger
+	running sync.Mutex
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewPruningService creates and returns PruningService instance.
+func NewPruningService(headers repository.Headers, maintenance Maintenance, writes *sync.RWMutex, cfg *config.PruningConfig, log *zerolog.Logger) *PruningService {
+	return &PruningService{
+		headers:     headers,
+		maintenance: maintenance,
+		writes:      writes,
+		cfg:         cfg,
+		log:         log.With().Str("service", "pruning").Logger(),
+		quit:        make(chan struct{}),
+	}
+}
+
+// Run deletes branches whose tips are more than the configured depth below the tip of the longest chain
+// and returns the number of deleted headers. Headers shared with a younger branch are kept.
+func (s *PruningService) Run() (int, error) {
+	if s.maintenance.ModeEnabled() {
+		return 0, bhserrors.ErrMaintenanceMode
+	}
+	s.running.Lock()
+	defer s.running.Unlock()
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	tip, err := s.headers.GetTip()
+	if err != nil {
+		return 0, err
+	}
+	tips, err := s.headers.GetAllTips()
+	if err != nil {
+		return 0, err
+	}
+
+	belowHeight := tip.Height - int32(s.cfg.Depth)
+	kept := make(map[chainhash.Hash]bool)
+	pruned := make(map[domains.HeaderState][]chainhash.Hash)
+	var old [][]*domains.BlockHeader
+	for _, t := range tips {
+		if !prunable(t) {
+			continue
+		}
+		branch, err := branchOf(s.headers, t)
+		if err != nil {
+			return 0, err
+		}
+		if t.Height > belowHeight {
+			for _, h := range branch {
+				kept[h.Hash] = true
+			}
+			continue
+		}
+		old = append(old, branch)
+	}
+
+	seen := make(map[chainhash.Hash]bool)
+	for _, branch := range old {
+		for _, h := range branch {
+			if !kept[h.Hash] && !seen[h.Hash] {
+				seen[h.Hash] = true
+				pruned[h.State] = append(pruned[h.State], h.Hash)
+			}
+		}
+	}
+
+	total := 0
+	for state, hashes := range pruned {
+		deleted, err := s.headers.DeleteHeaders(hashes)
+		total += deleted
+		metrics.AddPrunedHeaders(state.String(), deleted)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	s.log.Info().Msgf("Pruned %d headers of %d branches ending below height %d", total, len(old), belowHeight)
+	return total, nil
+}
+
+// Start runs the pruning in the background on the configured schedule, if any.
+func (s *PruningService) Start() {
+	if s.cfg == nil || s.cfg.Schedule == "" {
+		return
+	}
+	schedule, err := cron.Parse(s.cfg.Schedule)
+	if err != nil {
+		s.log.Error().Msgf("scheduled pruning disabled: %v", err)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				s.log.Warn().Msgf("schedule %q has no next run, scheduled pruning stopped", s.cfg.Schedule)
+				return
+			}
+
+			select {
+			case <-time.After(time.Until(next)):
+				if s.maintenance.ModeEnabled() {
+					s.log.Info().Msg("scheduled pruning skipped in the maintenance mode")
+					continue
+				}
+				if _, err := s.Run(); err != nil {
+					s.log.Error().Msgf("scheduled pruning failed: %v", err)
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled pruning and waits for a running one to finish.
+func (s *PruningService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// branchOf returns headers from the tip back to the longest chain, or to the first header of an orphan branch.
+func branchOf(headers repository.Headers, tip *domains.BlockHeader) ([]*domains.BlockHeader, error) {
+	branch := []*domains.BlockHeader{tip}
+	for h := tip; ; {
+		prev, err := headers.GetHeaderByHash(h.PreviousBlock.String())
+		if err != nil && h.State == domains.Orphan {
+			// Parent of the first orphan is not known.
+			return branch, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !prunable(prev) {
+			return branch, nil
+		}
+		branch = append(branch, prev)
+		h = prev
+	}
+}
+
+func prunable(h *domains.BlockHeader) bool {
+	return h.State == domains.Stale || h.State == domains.Orphan
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+func TestPruningOldBranches(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	old := givenChain(&db[3], "old", 2, domains.Stale)
+	orphans := givenChain(&domains.BlockHeader{Height: 1, Hash: chainhash.DoubleHashH([]byte("unknown"))}, "orphan", 1, domains.Orphan)
+	young := givenChain(&db[7], "young", 2, domains.Stale)
+	db = append(append(append(db, old...), orphans...), young...)
+	s := newTestPruningService(&db, 4)
+
+	// when
+	pruned, err := s.Run()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pruned, 3)
+	assert.Equal(t, len(db), 12)
+	assertNotStored(t, db, old...)
+	assertNotStored(t, db, orphans...)
+}
+
+func TestPruningKeepsSharedHeaders(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	shared := givenChain(&db[2], "shared", 2, domains.Stale)
+	old := givenChain(&shared[1], "old", 1, domains.Stale)
+	young := givenChain(&shared[1], "young", 5, domains.Stale)
+	db = append(append(append(db, shared...), old...), young...)
+	s := newTestPruningService(&db, 3)
+
+	// when
+	pruned, err := s.Run()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pruned, 1)
+	assertNotStored(t, db, old...)
+}
+
+func TestPruningInMaintenanceMode(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	db = append(db, givenChain(&db[1], "old", 1, domains.Stale)...)
+	s := newTestPruningService(&db, 1)
+	s.maintenance.SetMode(true)
+
+	// when
+	_, err := s.Run()
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrMaintenanceMode), true)
+	assert.Equal(t, len(db), 11)
+}
+
+func TestPruningWaitsForAddedHeaders(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	db = append(db, givenChain(&db[1], "old", 1, domains.Stale)...)
+	s := newTestPruningService(&db, 1)
+	s.writes.RLock()
+	done := make(chan struct{})
+
+	// when
+	go func() {
+		defer close(done)
+		_, _ = s.Run()
+	}()
+
+	// then
+	select {
+	case <-done:
+		t.Fatal("pruning didn't wait for the added headers")
+	case <-time.After(50 * time.Millisecond):
+	}
+	s.writes.RUnlock()
+	<-done
+	assert.Equal(t, len(db), 10)
+}
+
+func newTestPruningService(db *[]domains.BlockHeader, depth int) *PruningService {
+	log := zerolog.Nop()
+	maintenance := NewMaintenanceService(testrepository.NewMaintenanceTestRepository(), &config.MaintenanceConfig{}, &log)
+	return NewPruningService(testrepository.NewHeadersTestRepository(db), maintenance, &sync.RWMutex{}, &config.PruningConfig{Depth: depth}, &log)
+}
+
+// givenChain returns n headers with the given state following the parent, or starting from genesis if it's nil.
+func givenChain(parent *domains.BlockHeader, name string, n int, state domains.HeaderState) []domains.BlockHeader {
+	chain := make([]domains.BlockHeader, 0, n)
+	for i := 0; i < n; i++ {
+		h := domains.BlockHeader{
+			Hash:  chainhash.DoubleHashH([]byte(fmt.Sprintf("%s-%d", name, i))),
+			State: state,
+		}
+		if parent != nil {
+			h.Height = parent.Height + 1
+			h.PreviousBlock = parent.Hash
+		}
+		chain = append(chain, h)
+		parent = &chain[i]
+	}
+	return chain
+}
+
+func assertNotStored(t *testing.T, db []domains.BlockHeader, headers ...domains.BlockHeader) {
+	t.Helper()
+	for _, h := range headers {
+		for _, stored := range db {
+			if stored.Hash == h.Hash {
+				t.Errorf("header %s should be pruned", h.Hash)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// checkTimestamp returns the reason to quarantine the header if its timestamp breaks the configured rules, empty when it doesn't.
+func (cs *chainService) checkTimestamp(h *domains.BlockHeader, prev *domains.BlockHeader) (string, error) {
+	cfg := cs.timestampValidation
+	if cfg.MaxFutureDrift > 0 {
+		maxTime := cs.timeSource.AdjustedTime().Add(cfg.MaxFutureDrift)
+		if h.Timestamp.After(maxTime) {
+			return fmt.Sprintf("timestamp %s is more than %s ahead of the network time", h.Timestamp.UTC().Format(time.RFC3339), cfg.MaxFutureDrift), nil
+		}
+	}
+
+	if cfg.MedianTimePast {
+		mtp, err := cs.medianTimePast(prev)
+		if err != nil {
+			return "", err
+		}
+		if !h.Timestamp.After(mtp) {
+			return fmt.Sprintf("timestamp %s is not after the median time past %s", h.Timestamp.UTC().Format(time.RFC3339), mtp.UTC().Format(time.RFC3339)), nil
+		}
+	}
+	return "", nil
+}
+
+// quarantine keeps the latest quarantined headers, so operators can inspect what peers sent.
+type quarantine struct {
+	mu      sync.Mutex
+	size    int
+	headers []*domains.QuarantinedHeader
+}
+
+func newQuarantine(size int) *quarantine {
+	return &quarantine{size: size}
+}
+
+// add stores the header, replacing its previous entry and dropping the oldest one when the quarantine is full.
+func (q *quarantine) add(h *domains.BlockHeader, reason string) {
+	if q.size <= 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.headers = slices.DeleteFunc(q.headers, func(qh *domains.QuarantinedHeader) bool {
+		return qh.Header.Hash == h.Hash
+	})
+	if len(q.headers) >= q.size {
+		q.headers = q.headers[len(q.headers)-q.size+1:]
+	}
+	q.headers = append(q.headers, &domains.QuarantinedHeader{Header: h, Reason: reason, QuarantinedAt: time.Now()})
+}
+
+// list returns quarantined headers, the oldest first.
+func (q *quarantine) list() []*domains.QuarantinedHeader {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.headers)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+)
+
+func TestAddHeaderWithInvalidTimestamp(t *testing.T) {
+	testCases := map[string]struct {
+		timestamp      time.Time
+		expectedReason string
+	}{
+		"quarantine header from the future": {
+			timestamp:      time.Now().Add(3 * time.Hour).Truncate(time.Second),
+			expectedReason: "is more than 2h0m0s ahead of the network time",
+		},
+		"quarantine header not after median time past": {
+			timestamp:      fixtures.HeaderSourceHeight2.Timestamp,
+			expectedReason: "is not after the median time past 2009-01-09T02:55:44Z",
+		},
+	}
+
+	for name, params := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			r, longestChainTip := givenLongestChainInRepository()
+			h := givenHeaderToAddNextTo(longestChainTip)
+			h.Timestamp = params.timestamp
+
+			cs := createChainsService(serviceSetup{
+				Repositories: &r,
+				TimestampValidation: config.TimestampValidationConfig{
+					MaxFutureDrift: 2 * time.Hour,
+					MedianTimePast: true,
+					QuarantineSize: 10,
+				},
+			})
+
+			// when
+			header, err := cs.Add(h)
+
+			// then
+			assert.Equal(t, BlockQuarantined.Is(err), true)
+			assert.Equal(t, header.Height, longestChainTip.Height+1)
+			_, err = r.Headers.GetHeaderByHash(header.Hash.String())
+			assert.IsError(t, err, "header not found")
+
+			quarantined := cs.Quarantined()
+			assert.Equal(t, len(quarantined), 1)
+			assert.Equal(t, quarantined[0].Header.Hash, header.Hash)
+			assert.Equal(t, strings.Contains(quarantined[0].Reason, params.expectedReason), true)
+		})
+	}
+}
+
+func TestAddHeaderWithValidTimestamp(t *testing.T) {
+	// given
+	r, longestChainTip := givenLongestChainInRepository()
+	h := givenHeaderToAddNextTo(longestChainTip)
+	h.Timestamp = longestChainTip.Timestamp.Add(10 * time.Minute)
+
+	cs := createChainsService(serviceSetup{
+		Repositories: &r,
+		TimestampValidation: config.TimestampValidationConfig{
+			MaxFutureDrift: 2 * time.Hour,
+			MedianTimePast: true,
+			QuarantineSize: 10,
+		},
+	})
+
+	// when
+	header, err := cs.Add(h)
+
+	// then
+	assert.NoError(t, err)
+	assertHeaderInState(t, header, domains.LongestChain)
+	assert.Equal(t, len(cs.Quarantined()), 0)
+}
+
+func TestQuarantineKeepsLatestHeaders(t *testing.T) {
+	// given
+	q := newQuarantine(2)
+	first := &domains.BlockHeader{Hash: *fixtures.HashHeight1}
+	second := &domains.BlockHeader{Hash: *fixtures.HashHeight2}
+	third := &domains.BlockHeader{Hash: *fixtures.HashHeight3}
+
+	// when
+	q.add(first, "first")
+	q.add(second, "second")
+	q.add(first, "first again")
+	q.add(third, "third")
+
+	// then
+	quarantined := q.list()
+	assert.Equal(t, len(quarantined), 2)
+	assert.Equal(t, quarantined[0].Reason, "first again")
+	assert.Equal(t, quarantined[1].Reason, "third")
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/rs/zerolog"
+)
+
+// Syncer is a source of headers which can be told to sync again from the current tip.
+type Syncer interface {
+	RestartSync()
+}
+
+// ResyncService deletes headers above a given height or replaces them with a snapshot
+// and makes the syncers fetch the following ones again.
+type ResyncService struct {
+	headers     repository.Headers
+	snapshots   repository.Snapshots
+	maintenance Maintenance
+	// writes is held by adding headers, so none is added while the headers are deleted.
+	writes *sync.RWMutex
+	log    zerolog.Logger
+
+	mu      sync.Mutex
+	syncers []Syncer
+
+	restoring sync.Mutex
+}
+
+// NewResyncService creates and returns ResyncService instance.
+func NewResyncService(headers repository.Headers, snapshots repository.Snapshots, maintenance Maintenance, writes *sync.RWMutex, log *zerolog.Logger) *ResyncService {
+	return &ResyncService{
+		headers:     headers,
+		snapshots:   snapshots,
+		maintenance: maintenance,
+		writes:      writes,
+		log:         log.With().Str("service", "resync").Logger(),
+	}
+}
+
+// AddSyncer registers the syncer restarted after every re-sync, the returned function unregisters it.
+func (s *ResyncService) AddSyncer(syncer Syncer) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncers = append(s.syncers, syncer)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.syncers = slices.DeleteFunc(s.syncers, func(x Syncer) bool { return x == syncer })
+	}
+}
+
+// Resync deletes headers in all states above the height, so the header on it becomes the tip,
+// restarts syncing from there and returns the number of deleted headers.
+func (s *ResyncService) Resync(height int32) (int, error) {
+	if s.maintenance.ModeEnabled() {
+		return 0, bhserrors.ErrMaintenanceMode
+	}
+
+	tip, err := s.headers.GetTip()
+	if err != nil {
+		return 0, bhserrors.ErrResync.Wrap(err)
+	}
+	if height < 0 || height >= tip.Height {
+		return 0, bhserrors.ErrInvalidResyncHeight
+	}
+
+	s.writes.Lock()
+	deleted, err := s.headers.DeleteHeadersAboveHeight(height)
+	s.writes.Unlock()
+	if err != nil {
+		return deleted, bhserrors.ErrResync.Wrap(err)
+	}
+	s.log.Warn().Msgf("Deleted %d headers above height %d, tip was %s at %d", deleted, height, tip.Hash, tip.Height)
+
+	s.restartSyncers()
+	return deleted, nil
+}
+
+// RestoreSnapshot imports the prepared database file next to the stored headers and swaps them, so the writes
+// are paused only for the swap. Syncing restarts from the tip of the snapshot, which is returned with the number of imported headers.
+func (s *ResyncService) RestoreSnapshot(path string) (int, *domains.BlockHeader, error) {
+	if s.maintenance.ModeEnabled() {
+		return 0, nil, bhserrors.ErrMaintenanceMode
+	}
+	if s.snapshots == nil {
+		return 0, nil, bhserrors.ErrSnapshotRestore.Wrap(errors.New("database doesn't support restoring snapshots"))
+	}
+	if !s.restoring.TryLock() {
+		return 0, nil, bhserrors.ErrSnapshotRestoreInProgress
+	}
+	defer s.restoring.Unlock()
+
+	s.log.Info().Msgf("Importing snapshot %s", path)
+	imported, err := s.snapshots.ImportShadow(path)
+	if err != nil {
+		return imported, nil, bhserrors.ErrSnapshotRestore.Wrap(err)
+	}
+
+	s.writes.Lock()
+	err = s.headers.ReplaceHeaders(s.snapshots.SwapShadow)
+	s.writes.Unlock()
+	if err != nil {
+		return imported, nil, bhserrors.ErrSnapshotRestore.Wrap(err)
+	}
+
+	tip, err := s.headers.GetTip()
+	if err != nil {
+		return imported, nil, bhserrors.ErrSnapshotRestore.Wrap(err)
+	}
+	s.log.Warn().Msgf("Replaced headers with %d headers of snapshot %s, tip is %s at %d", imported, path, tip.Hash, tip.Height)
+
+	s.restartSyncers()
+	return imported, tip, nil
+}
+
+func (s *ResyncService) restartSyncers() {
+	s.mu.Lock()
+	syncers := slices.Clone(s.syncers)
+	s.mu.Unlock()
+	for _, syncer := range syncers {
+		syncer.RestartSync()
+	}
+}
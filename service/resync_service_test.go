@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/rs/zerolog"
+)
+
+type countingSyncer struct {
+	restarts int
+}
+
+func (s *countingSyncer) RestartSync() {
+	s.restarts++
+}
+
+func TestResyncRestartsSyncers(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	db = append(db, givenChain(&db[6], "stale", 2, domains.Stale)...)
+	s := newTestResyncService(&db)
+	syncer, removed := &countingSyncer{}, &countingSyncer{}
+	s.AddSyncer(syncer)
+	remove := s.AddSyncer(removed)
+	remove()
+
+	// when
+	deleted, err := s.Resync(5)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, deleted, 6)
+	assert.Equal(t, len(db), 6)
+	assert.Equal(t, syncer.restarts, 1)
+	assert.Equal(t, removed.restarts, 0)
+}
+
+func TestResyncInvalidHeight(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	s := newTestResyncService(&db)
+
+	for _, height := range []int32{-1, 9, 10} {
+		// when
+		_, err := s.Resync(height)
+
+		// then
+		assert.Equal(t, errors.Is(err, bhserrors.ErrInvalidResyncHeight), true)
+		assert.Equal(t, len(db), 10)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	snapshot := givenChain(nil, "snapshot", 20, domains.LongestChain)
+	s := newTestResyncService(&db)
+	s.snapshots = &fakeSnapshots{db: &db, snapshot: snapshot}
+	syncer := &countingSyncer{}
+	s.AddSyncer(syncer)
+
+	// when
+	imported, tip, err := s.RestoreSnapshot("snapshot.bin")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, imported, 20)
+	assert.Equal(t, tip.Hash, snapshot[19].Hash)
+	assert.Equal(t, len(db), 20)
+	assert.Equal(t, syncer.restarts, 1)
+}
+
+func TestRestoreSnapshotImportFailure(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 10, domains.LongestChain)
+	s := newTestResyncService(&db)
+	s.snapshots = &fakeSnapshots{db: &db, importErr: errors.New("checkpoint block is not present")}
+	syncer := &countingSyncer{}
+	s.AddSyncer(syncer)
+
+	// when
+	_, _, err := s.RestoreSnapshot("snapshot.bin")
+
+	// then
+	assert.Equal(t, errors.Is(err, bhserrors.ErrSnapshotRestore), true)
+	assert.Equal(t, len(db), 10)
+	assert.Equal(t, syncer.restarts, 0)
+}
+
+func newTestResyncService(db *[]domains.BlockHeader) *ResyncService {
+	log := zerolog.Nop()
+	maintenance := NewMaintenanceService(testrepository.NewMaintenanceTestRepository(), &config.MaintenanceConfig{}, &log)
+	return NewResyncService(testrepository.NewHeadersTestRepository(db), nil, maintenance, &sync.RWMutex{}, &log)
+}
+
+// fakeSnapshots replaces the test database with the snapshot headers on swap.
+type fakeSnapshots struct {
+	db        *[]domains.BlockHeader
+	snapshot  []domains.BlockHeader
+	importErr error
+}
+
+func (f *fakeSnapshots) ImportShadow(_ string) (int, error) {
+	return len(f.snapshot), f.importErr
+}
+
+func (f *fakeSnapshots) SwapShadow() error {
+	*f.db = f.snapshot
+	return nil
+}
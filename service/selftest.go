@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+)
+
+// VerifyChainTail re-verifies hashes, proof of work and linkage of the latest count headers of the longest chain,
+// so corrupted storage is detected before its data is served.
+func (hs *HeaderService) VerifyChainTail(count int) error {
+	tip, err := hs.repo.Headers.GetTip()
+	if err != nil {
+		return fmt.Errorf("cannot get the tip: %w", err)
+	}
+
+	from := max(0, tip.Height-int32(count)+1)
+	headers, err := hs.repo.Headers.GetLongestChainHeadersFromHeight(from)
+	if err != nil {
+		return fmt.Errorf("cannot get headers from height %d: %w", from, err)
+	}
+	slices.SortFunc(headers, func(a, b *domains.BlockHeader) int {
+		return int(a.Height - b.Height)
+	})
+
+	if len(headers) != int(tip.Height-from+1) || headers[len(headers)-1].Hash != tip.Hash {
+		return fmt.Errorf("expected %d headers of the longest chain from height %d to the tip %s, found %d", tip.Height-from+1, from, tip.Hash, len(headers))
+	}
+
+	// The first verified header is linked to its stored parent too, unless it's the genesis.
+	prev := headers[0]
+	if first := headers[0]; first.Height > 0 {
+		if prev, err = hs.repo.Headers.GetHeaderByHash(first.PreviousBlock.String()); err != nil {
+			return fmt.Errorf("header %s at height %d has missing previous header: %w", first.Hash, first.Height, err)
+		}
+	}
+
+	for i, h := range headers {
+		if err := hs.verifyStoredHeader(h); err != nil {
+			return err
+		}
+		if i > 0 || h.Height > 0 {
+			if err := verifyLink(prev, h); err != nil {
+				return err
+			}
+		}
+		prev = h
+	}
+
+	hs.log.Info().Msgf("Verified %d headers of the longest chain from height %d to the tip %s", len(headers), from, tip.Hash)
+	return nil
+}
+
+// verifyStoredHeader checks the header hashes to its stored hash with enough proof of work.
+func (hs *HeaderService) verifyStoredHeader(h *domains.BlockHeader) error {
+	hash := chainhash.Hash(DefaultBlockHasher().BlockHash(&domains.BlockHeaderSource{
+		Version:    h.Version,
+		PrevBlock:  h.PreviousBlock,
+		MerkleRoot: h.MerkleRoot,
+		Timestamp:  h.Timestamp,
+		Bits:       h.Bits,
+		Nonce:      h.Nonce,
+	}))
+	if hash != h.Hash {
+		return fmt.Errorf("header at height %d is stored as %s, but hashes to %s", h.Height, h.Hash, hash)
+	}
+
+	target := domains.CompactToBig(h.Bits)
+	if target.Sign() <= 0 || target.Cmp(hs.powLimit) > 0 {
+		return fmt.Errorf("header %s at height %d has target out of range", h.Hash, h.Height)
+	}
+	if domains.HashToBig(&hash).Cmp(target) > 0 {
+		return fmt.Errorf("header %s at height %d has insufficient proof of work", h.Hash, h.Height)
+	}
+	return nil
+}
+
+// verifyLink checks the header extends prev and its height and cumulated work follow from it.
+func verifyLink(prev *domains.BlockHeader, h *domains.BlockHeader) error {
+	if h.PreviousBlock != prev.Hash {
+		return fmt.Errorf("header %s at height %d doesn't extend header %s", h.Hash, h.Height, prev.Hash)
+	}
+	if h.Height != prev.Height+1 {
+		return fmt.Errorf("header %s is stored at height %d, but its previous header is at height %d", h.Hash, h.Height, prev.Height)
+	}
+
+	work := new(big.Int).Add(prev.CumulatedWork, domains.CalculateWork(h.Bits).BigInt())
+	if h.CumulatedWork.Cmp(work) != 0 {
+		return fmt.Errorf("header %s at height %d has cumulated work %s, but %s is expected", h.Hash, h.Height, h.CumulatedWork, work)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestVerifyChainTail(t *testing.T) {
+	testCases := map[string]struct {
+		count         int
+		corrupt       func(h *domains.BlockHeader)
+		expectedError string
+	}{
+		"intact chain from genesis": {
+			count: 10,
+		},
+		"intact tail of the chain": {
+			count: 2,
+		},
+		"header with changed content": {
+			count:         10,
+			corrupt:       func(h *domains.BlockHeader) { h.Nonce++ },
+			expectedError: "header at height 3 is stored as",
+		},
+		"header with changed cumulated work": {
+			count:         10,
+			corrupt:       func(h *domains.BlockHeader) { h.CumulatedWork = new(big.Int).Add(h.CumulatedWork, big.NewInt(1)) },
+			expectedError: "at height 3 has cumulated work",
+		},
+		"header with changed height": {
+			count:         2,
+			corrupt:       func(h *domains.BlockHeader) { h.Height = 1 },
+			expectedError: "expected 2 headers of the longest chain from height 3",
+		},
+	}
+
+	for name, params := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			testData := setUpServices()
+			givenConsistentCumulatedWork(*testData.db)
+			if params.corrupt != nil {
+				params.corrupt(&(*testData.db)[3])
+			}
+
+			// when
+			err := testData.hs.Headers.VerifyChainTail(params.count)
+
+			// then
+			if params.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), params.expectedError) {
+				t.Fatalf("expected error containing %q, got %v", params.expectedError, err)
+			}
+		})
+	}
+}
+
+// givenConsistentCumulatedWork recomputes cumulated work of the chain the way it's computed for added headers,
+// fixtures only approximate it.
+func givenConsistentCumulatedWork(chain []domains.BlockHeader) {
+	for i := 1; i < len(chain); i++ {
+		chain[i].CumulatedWork = new(big.Int).Add(chain[i-1].CumulatedWork, domains.CalculateWork(chain[i].Bits).BigInt())
+	}
+}
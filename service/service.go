@@ -1,8 +1,12 @@
 package service
 
 import (
+	"sync"
+	"time"
+
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/cache"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 	"github.com/bitcoin-sv/block-headers-service/notification"
@@ -29,11 +33,22 @@ type Headers interface {
 	GetTipHeight() int32
 	CountHeaders() int
 	GetHeaderByHash(hash string) (*domains.BlockHeader, error)
-	GetHeadersByHeight(height int, count int) ([]*domains.BlockHeader, error)
+	GetHeadersByHeight(height int, count int, query domains.HeadersQuery) ([]*domains.BlockHeader, error)
+	GetHeadersFromHeight(height int, limit int, query domains.HeadersQuery) ([]*domains.BlockHeader, error)
+	MapHeaders(hashes []string, heights []int32) (*domains.HeadersMapping, error)
+	StreamHeadersByHeight(height int, count int, query domains.HeadersQuery, fn func(*domains.BlockHeader) error) error
 	GetHeaderAncestorsByHash(hash string, ancestorHash string) ([]*domains.BlockHeader, error)
+	GetChainSegment(ancestorHash string, descendantHash string, limit int) ([]*domains.BlockHeader, error)
 	GetCommonAncestor(hashes []string) (*domains.BlockHeader, error)
+	GetBranchDiff(hash string, otherHash string, limit int) (*domains.BranchDiff, error)
 	GetHeadersState(hash string) (*domains.BlockHeaderState, error)
+	GetHeaderAtTime(t time.Time) (*domains.HeaderAtTime, error)
+	GetDifficultyHistory(from int, to int, interval int) ([]*domains.BlockHeader, error)
+	GetHashrate(blocks int) (*domains.Hashrate, error)
 	GetTips() ([]*domains.BlockHeader, error)
+	GetForks() ([]*domains.Fork, error)
+	VerifyChainTail(count int) error
+	GetHeadersCounts(windows []time.Duration) (*domains.HeadersCounts, error)
 	LocateHeadersGetHeaders(locators []*chainhash.Hash, hashstop *chainhash.Hash) ([]*wire.BlockHeader, error)
 }
 
@@ -46,6 +61,9 @@ type Merkleroots interface {
 // Chains is an interface which represents methods exposed by Chains Service.
 type Chains interface {
 	Add(domains.BlockHeaderSource) (*domains.BlockHeader, error)
+	Quarantined() []*domains.QuarantinedHeader
+	Generate(count int, from string) ([]*domains.BlockHeader, error)
+	RequiredBits(prevHash string, timestamp time.Time) (uint32, error)
 }
 
 // Tokens is an interface which represents methods required for Tokens service.
@@ -55,15 +73,86 @@ type Tokens interface {
 	DeleteToken(token string) error
 }
 
+// Maintenance is an interface which represents methods required for Maintenance service.
+type Maintenance interface {
+	Run() (time.Duration, error)
+	Start()
+	Stop()
+	SetMode(enabled bool)
+	ModeEnabled() bool
+	SetLowDiskSpace(low bool)
+	LowDiskSpace() bool
+}
+
+// DiskGuard is an interface which represents methods required for DiskGuard service.
+type DiskGuard interface {
+	Check() (*domains.DiskUsage, error)
+	Start()
+	Stop()
+}
+
+// Idempotency is an interface which represents methods required for Idempotency service.
+type Idempotency interface {
+	Enabled() bool
+	Begin(key, requestHash string) (*domains.IdempotencyKey, error)
+	Complete(key *domains.IdempotencyKey) error
+	Release(key string) error
+	Start()
+	Stop()
+}
+
+// Pruning is an interface which represents methods required for Pruning service.
+type Pruning interface {
+	Run() (int, error)
+	Start()
+	Stop()
+}
+
+// Archive is an interface which represents methods required for Archive service.
+type Archive interface {
+	Run() (int, error)
+	GetArchivedBranches(limit int) ([]*domains.ArchivedBranch, error)
+	GetArchivedBranchOf(hash string) (*domains.ArchivedBranch, error)
+	Start()
+	Stop()
+}
+
+// Resync is an interface which represents methods required for Resync service.
+type Resync interface {
+	Resync(height int32) (int, error)
+	RestoreSnapshot(path string) (int, *domains.BlockHeader, error)
+	AddSyncer(syncer Syncer) func()
+}
+
+// SyncPause is an interface which represents methods required for SyncPause service.
+type SyncPause interface {
+	SetPaused(paused bool)
+	Paused() bool
+}
+
 // Services represents all services in app and provide access to them.
 type Services struct {
 	Network     Network
 	Headers     Headers
 	Merkleroots Merkleroots
 	Chains      Chains
+	// P2PChains are Chains used by the p2p servers, they don't add headers while the p2p sync is paused.
+	P2PChains   Chains
 	Tokens      Tokens
+	Maintenance Maintenance
+	DiskGuard   DiskGuard
+	Idempotency Idempotency
+	Pruning     Pruning
+	Archive     Archive
+	Resync      Resync
+	SyncPause   SyncPause
 	Notifier    *notification.Notifier
 	Webhooks    *notification.WebhooksService
+	// Outbox is nil unless events are stored together with headers.
+	Outbox *notification.OutboxDispatcher
+	// Leader is nil unless high availability is enabled, write requests are accepted only while it holds the leader lease.
+	Leader      *LeaderElector
+	SharedCache cache.Shared
 	Logger      *zerolog.Logger
 }
 
@@ -74,43 +163,83 @@ type Dept struct {
 	AdminToken   string
 	Logger       *zerolog.Logger
 	Config       *config.AppConfig
+	// SharedCache is the cache shared between instances, nil when there is none.
+	SharedCache cache.Shared
 }
 
 // NewServices creates and returns Services instance.
 func NewServices(d Dept) *Services {
-	notifier := newNotifier()
+	notifier := newNotifier(d)
+	maintenance := NewMaintenanceService(d.Repositories.Maintenance, d.Config.Maintenance, d.Logger)
+	outbox := newOutbox(d, notifier, maintenance)
+	writes := &sync.RWMutex{}
+	chains := &pausableChains{Chains: newChainService(d, notifier, outbox), maintenance: maintenance, writes: writes}
+	resync := NewResyncService(d.Repositories.Headers, d.Repositories.Snapshots, maintenance, writes, d.Logger)
+	syncPause := NewSyncPauseService(resync, d.Logger)
 
 	return &Services{
 		Network:     NewNetworkService(d.Peers),
 		Headers:     NewHeaderService(d.Repositories, d.Config.P2P, d.Logger),
 		Merkleroots: NewMerklerootsService(d.Repositories, d.Config.MerkleRoot, d.Logger),
 		Notifier:    notifier,
-		Chains:      newChainService(d, notifier),
+		Chains:      chains,
+		P2PChains:   &syncPausableChains{Chains: chains, pause: syncPause},
 		Tokens:      NewTokenService(d.Repositories, d.AdminToken),
-		Webhooks:    newWebhooks(d),
+		Maintenance: maintenance,
+		DiskGuard:   NewDiskGuardService(d.Config.DiskGuard, d.Config.Db, maintenance, notifier, d.Logger),
+		Idempotency: NewIdempotencyService(d.Repositories.IdempotencyKeys, maintenance, d.Config.Idempotency, d.Logger),
+		Pruning:     NewPruningService(d.Repositories.Headers, maintenance, writes, d.Config.Pruning, d.Logger),
+		Archive:     NewArchiveService(d.Repositories, maintenance, writes, d.Config.Archive, d.Logger),
+		Resync:      resync,
+		SyncPause:   syncPause,
+		Webhooks:    newWebhooks(d, maintenance),
+		Outbox:      outbox,
+		SharedCache: d.SharedCache,
 		Logger:      d.Logger,
 	}
 }
 
-func newChainService(d Dept, notifier *notification.Notifier) Chains {
+func newChainService(d Dept, notifier *notification.Notifier, outbox *notification.OutboxDispatcher) Chains {
+	var chainOutbox Outbox
+	if outbox != nil {
+		chainOutbox = outbox
+	}
 	return NewChainsService(
 		d.Repositories,
 		d.Config.P2P.GetNetParams(),
 		d.Logger,
 		DefaultBlockHasher(),
 		notifier,
+		chainOutbox,
+		d.Config.P2P.SyncedThreshold,
+		!d.Config.P2P.DisableDifficultyValidation,
+		d.Config.P2P.TimestampValidation,
+		config.TimeSource,
 	)
 }
 
-func newWebhooks(d Dept) *notification.WebhooksService {
+// newOutbox returns the dispatcher of events stored together with headers, nil when it's disabled or not supported by the storage.
+func newOutbox(d Dept, notifier *notification.Notifier, maintenance Maintenance) *notification.OutboxDispatcher {
+	if d.Config.Outbox == nil || !d.Config.Outbox.Enabled || d.Repositories.Outbox == nil {
+		return nil
+	}
+	return notification.NewOutboxDispatcher(d.Repositories.Outbox, notifier, maintenance, d.Config.Outbox, d.Logger)
+}
+
+func newWebhooks(d Dept, maintenance Maintenance) *notification.WebhooksService {
 	return notification.NewWebhooksService(
 		d.Repositories.Webhooks,
 		client.NewWebhookTargetClient(),
+		maintenance,
 		d.Logger,
 		d.Config.Webhook,
 	)
 }
 
-func newNotifier() *notification.Notifier {
-	return notification.NewNotifier()
+func newNotifier(d Dept) *notification.Notifier {
+	notifier := notification.NewNotifier()
+	if hooks := notification.RegisteredHooks(); len(hooks) > 0 {
+		notifier.AddChannel(notification.NewHooksChannel(d.Logger, d.Repositories.Headers, hooks))
+	}
+	return notifier
 }
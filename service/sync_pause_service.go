@@ -0,0 +1,56 @@
+package service
+
+import (
+	"sync/atomic"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/rs/zerolog"
+)
+
+// SyncPauseService pauses adding headers received from p2p peers, which stay connected meanwhile,
+// so the state can be frozen for investigation while the API keeps serving it.
+type SyncPauseService struct {
+	resync *ResyncService
+	log    zerolog.Logger
+	paused atomic.Bool
+}
+
+// NewSyncPauseService creates and returns SyncPauseService instance, the resync syncers are restarted on resume.
+func NewSyncPauseService(resync *ResyncService, log *zerolog.Logger) *SyncPauseService {
+	return &SyncPauseService{
+		resync: resync,
+		log:    log.With().Str("service", "sync-pause").Logger(),
+	}
+}
+
+// SetPaused pauses or resumes the p2p sync. On resume the syncers are restarted,
+// so the headers announced in the meantime are fetched right away.
+func (s *SyncPauseService) SetPaused(paused bool) {
+	if s.paused.Swap(paused) == paused {
+		return
+	}
+	if paused {
+		s.log.Warn().Msg("P2P sync paused, headers received from peers are ignored")
+		return
+	}
+	s.log.Info().Msg("P2P sync resumed")
+	s.resync.restartSyncers()
+}
+
+// Paused returns true if the p2p sync is paused.
+func (s *SyncPauseService) Paused() bool {
+	return s.paused.Load()
+}
+
+// syncPausableChains doesn't add headers while the p2p sync is paused, it's used by the p2p servers only.
+type syncPausableChains struct {
+	Chains
+	pause SyncPause
+}
+
+func (c *syncPausableChains) Add(bs domains.BlockHeaderSource) (*domains.BlockHeader, error) {
+	if c.pause.Paused() {
+		return nil, SyncPaused.error()
+	}
+	return c.Chains.Add(bs)
+}
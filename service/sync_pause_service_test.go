@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestSyncPauseRestartsSyncersOnResume(t *testing.T) {
+	// given
+	db := givenChain(nil, "longest", 3, domains.LongestChain)
+	resync := newTestResyncService(&db)
+	syncer := &countingSyncer{}
+	resync.AddSyncer(syncer)
+	log := zerolog.Nop()
+	s := NewSyncPauseService(resync, &log)
+
+	// when
+	s.SetPaused(true)
+	s.SetPaused(true)
+
+	// then
+	assert.Equal(t, s.Paused(), true)
+	assert.Equal(t, syncer.restarts, 0)
+
+	// when
+	s.SetPaused(false)
+	s.SetPaused(false)
+
+	// then
+	assert.Equal(t, s.Paused(), false)
+	assert.Equal(t, syncer.restarts, 1)
+}
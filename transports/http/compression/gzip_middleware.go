@@ -0,0 +1,154 @@
+// Package compression provides compression of HTTP responses negotiated with Accept-Encoding.
+package compression
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	acceptEncodingHeader  = "Accept-Encoding"
+	contentEncodingHeader = "Content-Encoding"
+	contentLengthHeader   = "Content-Length"
+	varyHeader            = "Vary"
+	gzipEncoding          = "gzip"
+)
+
+// GzipMiddleware compresses responses of configured routes with gzip when the client accepts it.
+type GzipMiddleware struct {
+	routes []string
+	pool   sync.Pool
+}
+
+// NewMiddleware creates GzipMiddleware compressing responses of routes listed in the config.
+func NewMiddleware(cfg *config.CompressionConfig) *GzipMiddleware {
+	level := cfg.Level
+	return &GzipMiddleware{
+		routes: cfg.Routes,
+		pool: sync.Pool{
+			New: func() any {
+				gz, err := gzip.NewWriterLevel(nil, level)
+				if err != nil {
+					gz = gzip.NewWriter(nil)
+				}
+				return gz
+			},
+		},
+	}
+}
+
+// ApplyToAPI is a middleware which wraps the response writer with gzip compression.
+func (m *GzipMiddleware) ApplyToAPI(c *gin.Context) {
+	if !m.routeEnabled(c.FullPath()) || !acceptsGzip(c.GetHeader(acceptEncodingHeader)) {
+		return
+	}
+
+	w := &gzipWriter{ResponseWriter: c.Writer, pool: &m.pool}
+	c.Writer = w
+	defer func() {
+		w.close()
+		c.Writer = w.ResponseWriter
+	}()
+
+	c.Next()
+}
+
+func (m *GzipMiddleware) routeEnabled(fullPath string) bool {
+	for _, route := range m.routes {
+		if strings.HasSuffix(fullPath, route) {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsGzip(acceptEncoding string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.TrimSpace(name) != gzipEncoding {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0"
+	}
+	return false
+}
+
+// gzipWriter compresses the body of successful responses, others are written as they are.
+type gzipWriter struct {
+	gin.ResponseWriter
+	pool     *sync.Pool
+	gz       *gzip.Writer
+	decided  bool
+	compress bool
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	w.decide(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	w.decide(w.Status())
+	if !w.compress {
+		return w.ResponseWriter.Write(data)
+	}
+
+	if w.gz == nil {
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	return w.gz.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap returns the underlying writer, so http.ResponseController can reach it.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipWriter) decide(code int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	if code < http.StatusOK || code >= http.StatusMultipleChoices || code == http.StatusNoContent {
+		return
+	}
+
+	w.compress = true
+	header := w.Header()
+	header.Set(contentEncodingHeader, gzipEncoding)
+	header.Add(varyHeader, acceptEncodingHeader)
+	header.Del(contentLengthHeader)
+}
+
+func (w *gzipWriter) close() {
+	if w.gz == nil {
+		// Nothing was written, so there is no body which could be announced as compressed.
+		if w.compress && !w.Written() {
+			w.Header().Del(contentEncodingHeader)
+		}
+		return
+	}
+	_ = w.gz.Close()
+	w.gz.Reset(nil)
+	w.pool.Put(w.gz)
+	w.gz = nil
+}
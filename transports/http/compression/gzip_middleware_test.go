@@ -0,0 +1,77 @@
+package compression_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/compression"
+	"github.com/gin-gonic/gin"
+)
+
+var body = strings.Repeat(`{"hash":"000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"}`, 100)
+
+func TestGzipMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := compression.NewMiddleware(&config.CompressionConfig{
+		Enabled: true,
+		Level:   gzip.DefaultCompression,
+		Routes:  []string{"/headers"},
+	})
+	engine := gin.New()
+	api := engine.Group("/api/v1", m.ApplyToAPI)
+	api.GET("/headers", func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+	api.GET("/other", func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+
+	t.Run("compresses configured route when client accepts gzip", func(t *testing.T) {
+		// when
+		res := call(engine, "/api/v1/headers", "gzip, deflate")
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		assert.Equal(t, res.Header().Get("Content-Encoding"), "gzip")
+
+		gz, err := gzip.NewReader(res.Body)
+		assert.NoError(t, err)
+		decompressed, err := io.ReadAll(gz)
+		assert.NoError(t, err)
+		assert.Equal(t, string(decompressed), body)
+	})
+
+	t.Run("doesn't compress when client doesn't accept gzip", func(t *testing.T) {
+		// when
+		res := call(engine, "/api/v1/headers", "")
+
+		// then
+		assert.Equal(t, res.Header().Get("Content-Encoding"), "")
+		assert.Equal(t, res.Body.String(), body)
+	})
+
+	t.Run("doesn't compress route which is not configured", func(t *testing.T) {
+		// when
+		res := call(engine, "/api/v1/other", "gzip")
+
+		// then
+		assert.Equal(t, res.Header().Get("Content-Encoding"), "")
+		assert.Equal(t, res.Body.String(), body)
+	})
+}
+
+func call(engine *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+	return res
+}
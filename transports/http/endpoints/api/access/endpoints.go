@@ -8,18 +8,20 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
 
 type handler struct {
-	service service.Tokens
-	log     *zerolog.Logger
+	service     service.Tokens
+	idempotency service.Idempotency
+	log         *zerolog.Logger
 }
 
 // NewHandler creates new endpoint handler.
 func NewHandler(s *service.Services) router.APIEndpoints {
-	return &handler{service: s.Tokens, log: s.Logger}
+	return &handler{service: s.Tokens, idempotency: s.Idempotency, log: s.Logger}
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
@@ -27,7 +29,7 @@ func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTP
 	tokens := router.Group("/access")
 	{
 		tokens.GET("", h.getToken)
-		tokens.POST("", auth.RequireAdmin(h.createToken, cfg.UseAuth))
+		tokens.POST("", auth.RequireAdmin(idempotency.Handler(h.idempotency, h.createToken, idempotency.DefaultMaxBody, h.log), cfg.UseAuth))
 		tokens.DELETE("/:token", auth.RequireAdmin(h.revokeToken, cfg.UseAuth))
 	}
 }
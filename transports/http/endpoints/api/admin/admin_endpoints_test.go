@@ -0,0 +1,503 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/admin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMaintenance(t *testing.T) {
+	t.Run("success with admin token", func(t *testing.T) {
+		// given
+		var maintenance *testrepository.MaintenanceTestRepository
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.RepoOpt(func(r *testrepository.TestRepositories) {
+			maintenance = r.Maintenance
+		}))
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(runMaintenance(config.DefaultAppToken))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		assert.Equal(t, maintenance.Runs, 1)
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(runMaintenance("wrong_token"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestGetQuarantined(t *testing.T) {
+	t.Run("success - no quarantined headers", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getQuarantined(config.DefaultAppToken))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, "[]", res.Body.String())
+	})
+
+	t.Run("success - header from the future", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+		future := wire.BlockHeader(*fixtures.HeaderSourceHeight5)
+		future.Timestamp = time.Now().Add(3 * time.Hour).Truncate(time.Second)
+		var body bytes.Buffer
+		require.NoError(t, future.Serialize(&body))
+		bhs.API().Call(submitHeaders(config.DefaultAppToken, body.Bytes()))
+
+		// when
+		res := bhs.API().Call(getQuarantined(config.DefaultAppToken))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var quarantined []admin.QuarantinedHeaderResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&quarantined))
+		require.Len(t, quarantined, 1)
+		assert.Equal(t, quarantined[0].Hash, future.BlockHash().String())
+		assert.Equal(t, quarantined[0].PreviousBlock, fixtures.HashHeight4.String())
+		assert.Equal(t, quarantined[0].Height, int32(5))
+		assert.Equal(t, quarantined[0].CreationTimestamp, uint32(future.Timestamp.Unix()))
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getQuarantined("wrong_token"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestGenerateHeaders(t *testing.T) {
+	t.Run("failure outside of regtest", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(generateHeaders(config.DefaultAppToken, admin.GenerateHeadersRequest{Count: 1}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, `{"code":"ErrGenerateNotRegtest","message":"headers can be generated only on the regtest network"}`, res.Body.String())
+	})
+
+	t.Run("failure - invalid count", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithBulkHeadersLimit(10))
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(generateHeaders(config.DefaultAppToken, admin.GenerateHeadersRequest{Count: 11}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, `{"code":"ErrInvalidGenerateCount","message":"count must be a positive integer not greater than the bulk headers limit"}`, res.Body.String())
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(generateHeaders("wrong_token", admin.GenerateHeadersRequest{Count: 1}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestFollowerRejectsWrites(t *testing.T) {
+	// given
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithFollower())
+	defer cleanup()
+	future := wire.BlockHeader(*fixtures.HeaderSourceHeight5)
+	var body bytes.Buffer
+	require.NoError(t, future.Serialize(&body))
+
+	// when
+	read := bhs.API().Call(getLongestTip(config.DefaultAppToken))
+	write := bhs.API().Call(submitHeaders(config.DefaultAppToken, body.Bytes()))
+	mode := bhs.API().Call(setMaintenanceMode(config.DefaultAppToken, true))
+	pause := bhs.API().Call(setSyncPause(config.DefaultAppToken, true))
+
+	// then
+	assert.Equal(t, read.Code, http.StatusOK)
+	assert.Equal(t, write.Code, http.StatusServiceUnavailable)
+	require.JSONEq(t, `{"code":"ErrNotLeader","message":"instance is not the leader, write operations are accepted by the leader only"}`, write.Body.String())
+	assert.Equal(t, mode.Code, http.StatusOK)
+	assert.Equal(t, pause.Code, http.StatusOK)
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	t.Run("reads are served and writes are rejected", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+		future := wire.BlockHeader(*fixtures.HeaderSourceHeight5)
+		var body bytes.Buffer
+		require.NoError(t, future.Serialize(&body))
+
+		// when
+		res := bhs.API().Call(setMaintenanceMode(config.DefaultAppToken, true))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{"enabled":true}`, res.Body.String())
+
+		// when
+		read := bhs.API().Call(getLongestTip(config.DefaultAppToken))
+		write := bhs.API().Call(submitHeaders(config.DefaultAppToken, body.Bytes()))
+
+		// then
+		assert.Equal(t, read.Code, http.StatusOK)
+		assert.Equal(t, read.Header().Get("X-Maintenance-Mode"), "true")
+		assert.Equal(t, write.Code, http.StatusServiceUnavailable)
+		require.JSONEq(t, `{"code":"ErrMaintenanceMode","message":"service is in maintenance mode, write operations are paused"}`, write.Body.String())
+
+		// when
+		res = bhs.API().Call(setMaintenanceMode(config.DefaultAppToken, false))
+		read = bhs.API().Call(getLongestTip(config.DefaultAppToken))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{"enabled":false}`, res.Body.String())
+		assert.Equal(t, read.Header().Get("X-Maintenance-Mode"), "")
+	})
+
+	t.Run("headers are not added", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+		bhs.API().Call(setMaintenanceMode(config.DefaultAppToken, true))
+
+		// when
+		err := bhs.When().NewHeaderReceived(*fixtures.HeaderSourceHeight5)
+
+		// then
+		assert.IsError(t, err, "WritesPaused")
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(setMaintenanceMode("wrong_token", true))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestSyncPause(t *testing.T) {
+	t.Run("headers from peers are ignored until resumed", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(setSyncPause(config.DefaultAppToken, true))
+		err := bhs.When().NewHeaderReceivedFromPeer(*fixtures.HeaderSourceHeight5)
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{"paused":true}`, res.Body.String())
+		assert.IsError(t, err, "SyncPaused")
+
+		// when
+		res = bhs.API().Call(setSyncPause(config.DefaultAppToken, false))
+		err = bhs.When().NewHeaderReceivedFromPeer(*fixtures.HeaderSourceHeight5)
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{"paused":false}`, res.Body.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("can be switched in the maintenance mode", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+		bhs.API().Call(setMaintenanceMode(config.DefaultAppToken, true))
+
+		// when
+		res := bhs.API().Call(setSyncPause(config.DefaultAppToken, true))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{"paused":true}`, res.Body.String())
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(setSyncPause("wrong_token", true))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestStats(t *testing.T) {
+	t.Run("reports disk usage", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithDiskGuard(dir, 1))
+		defer cleanup()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "blockheaders.db"), make([]byte, 4096), 0o600))
+
+		// when
+		res := bhs.API().Call(getStats(config.DefaultAppToken))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		var stats admin.StatsResponse
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &stats))
+		assert.Equal(t, stats.Disk.DatabaseBytes, int64(4096))
+		assert.Equal(t, stats.Disk.MinFreeBytes, uint64(1024*1024))
+		assert.Equal(t, stats.Disk.FreeBytes > 0, true)
+		assert.Equal(t, stats.Disk.LowSpace, false)
+	})
+
+	t.Run("writes are paused when free disk space is low", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithDiskGuard(t.TempDir(), 1<<40))
+		defer cleanup()
+		future := wire.BlockHeader(*fixtures.HeaderSourceHeight5)
+		var body bytes.Buffer
+		require.NoError(t, future.Serialize(&body))
+
+		// when
+		res := bhs.API().Call(getStats(config.DefaultAppToken))
+		mode := bhs.API().Call(setMaintenanceMode(config.DefaultAppToken, false))
+		write := bhs.API().Call(submitHeaders(config.DefaultAppToken, body.Bytes()))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		var stats admin.StatsResponse
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &stats))
+		assert.Equal(t, stats.Disk.LowSpace, true)
+		require.JSONEq(t, `{"enabled":true,"lowDiskSpace":true}`, mode.Body.String())
+		assert.Equal(t, write.Code, http.StatusInsufficientStorage)
+		require.JSONEq(t, `{"code":"ErrLowDiskSpace","message":"free disk space is low, write operations are paused"}`, write.Body.String())
+	})
+}
+
+func TestResync(t *testing.T) {
+	t.Run("success - headers above the height are deleted", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(resync(config.DefaultAppToken, 2))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{"height":2,"deleted":2}`, res.Body.String())
+
+		// when
+		tip := bhs.API().Call(getLongestTip(config.DefaultAppToken))
+
+		// then
+		assert.Equal(t, tip.Code, http.StatusOK)
+		var body struct {
+			Height int32 `json:"height"`
+		}
+		require.NoError(t, json.Unmarshal(tip.Body.Bytes(), &body))
+		assert.Equal(t, body.Height, int32(2))
+	})
+
+	t.Run("failure - height not below the tip", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(resync(config.DefaultAppToken, 4))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, `{"code":"ErrInvalidResyncHeight","message":"height must be a non-negative integer lower than the tip height"}`, res.Body.String())
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(resync("wrong_token", 0))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	t.Run("failure - missing path", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(restoreSnapshot(config.DefaultAppToken, ""))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, `{"code":"ErrMissingSnapshotPath","message":"path of the snapshot file is required"}`, res.Body.String())
+	})
+
+	t.Run("failure without admin token", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t)
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(restoreSnapshot("wrong_token", "snapshot.bin"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+	})
+}
+
+func runMaintenance(headerToken string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/maintenance", nil)
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func getQuarantined(headerToken string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/admin/quarantine", nil)
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func submitHeaders(headerToken string, body []byte) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/chain/header", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	return
+}
+
+func generateHeaders(headerToken string, request admin.GenerateHeadersRequest) (req *http.Request, err error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/generate", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func setMaintenanceMode(headerToken string, enabled bool) (req *http.Request, err error) {
+	body, err := json.Marshal(admin.MaintenanceModeRequest{Enabled: enabled})
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPut, "/api/v1/admin/maintenance/mode", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func setSyncPause(headerToken string, paused bool) (req *http.Request, err error) {
+	body, err := json.Marshal(admin.SyncPauseRequest{Paused: paused})
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPut, "/api/v1/admin/sync/pause", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func getStats(headerToken string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/admin/stats", nil)
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func getLongestTip(headerToken string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/chain/tip/longest", nil)
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func resync(headerToken string, height int32) (req *http.Request, err error) {
+	body, err := json.Marshal(admin.ResyncRequest{Height: height})
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/resync", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
+
+func restoreSnapshot(headerToken string, path string) (req *http.Request, err error) {
+	body, err := json.Marshal(admin.RestoreSnapshotRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/snapshot/restore", bytes.NewReader(body))
+	if err == nil {
+		req.Header.Add("Authorization", "Bearer "+headerToken)
+	}
+	return
+}
@@ -0,0 +1,276 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	maintenance service.Maintenance
+	chains      service.Chains
+	resync      service.Resync
+	syncPause   service.SyncPause
+	diskGuard   service.DiskGuard
+	idempotency service.Idempotency
+	log         *zerolog.Logger
+	bulkLimit   int
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{maintenance: s.Maintenance, chains: s.Chains, resync: s.Resync, syncPause: s.SyncPause, diskGuard: s.DiskGuard, idempotency: s.Idempotency, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	h.bulkLimit = cfg.BulkHeadersLimit
+
+	admin := router.Group("/admin")
+	{
+		admin.POST("/maintenance", auth.RequireAdmin(h.idempotent(h.runMaintenance), cfg.UseAuth))
+		admin.GET("/maintenance/mode", auth.RequireAdmin(h.getMaintenanceMode, cfg.UseAuth))
+		admin.PUT("/maintenance/mode", auth.RequireAdmin(h.setMaintenanceMode, cfg.UseAuth))
+		admin.GET("/stats", auth.RequireAdmin(h.getStats, cfg.UseAuth))
+		admin.GET("/sync/pause", auth.RequireAdmin(h.getSyncPause, cfg.UseAuth))
+		admin.PUT("/sync/pause", auth.RequireAdmin(h.setSyncPause, cfg.UseAuth))
+		admin.GET("/quarantine", auth.RequireAdmin(h.getQuarantined, cfg.UseAuth))
+		admin.POST("/generate", auth.RequireAdmin(h.idempotent(h.generateHeaders), cfg.UseAuth))
+		admin.POST("/resync", auth.RequireAdmin(h.idempotent(h.resyncFromHeight), cfg.UseAuth))
+		admin.POST("/snapshot/restore", auth.RequireAdmin(h.idempotent(h.restoreSnapshot), cfg.UseAuth))
+	}
+}
+
+// idempotent makes retries of the operation with the same Idempotency-Key header return the first response instead of running it again.
+func (h *handler) idempotent(handler gin.HandlerFunc) gin.HandlerFunc {
+	return idempotency.Handler(h.idempotency, handler, idempotency.DefaultMaxBody, h.log)
+}
+
+// runMaintenance godoc.
+//
+//		@Summary Runs database maintenance
+//		@Description Vacuums the database and refreshes its statistics, which is otherwise done on maintenance.interval
+//		@Tags admin
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} MaintenanceResponse
+//		@Router /admin/maintenance [post]
+//	 @Security Bearer
+func (h *handler) runMaintenance(c *gin.Context) {
+	took, err := h.maintenance.Run()
+
+	if err == nil {
+		c.JSON(http.StatusOK, MaintenanceResponse{DurationMs: took.Milliseconds()})
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// getMaintenanceMode godoc.
+//
+//		@Summary Gets maintenance mode
+//		@Description Returns whether the maintenance mode, in which syncing and write operations are paused, is enabled
+//		@Tags admin
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} MaintenanceModeResponse
+//		@Router /admin/maintenance/mode [get]
+//	 @Security Bearer
+func (h *handler) getMaintenanceMode(c *gin.Context) {
+	c.JSON(http.StatusOK, h.maintenanceModeResponse())
+}
+
+// setMaintenanceMode godoc.
+//
+//		@Summary Switches maintenance mode
+//		@Description Enables or disables the maintenance mode. While it's enabled, headers aren't synced and write requests fail with 503,
+//		@Description read queries are still served with the X-Maintenance-Mode header, so the database can be backed up or migrated.
+//		@Description The mode stays enabled while the free disk space is low, then write requests fail with 507.
+//		@Tags admin
+//		@Accept json
+//		@Produce json
+//		@Success 200 {object} MaintenanceModeResponse
+//		@Router /admin/maintenance/mode [put]
+//		@Param request body MaintenanceModeRequest true "JSON"
+//	 @Security Bearer
+func (h *handler) setMaintenanceMode(c *gin.Context) {
+	var body MaintenanceModeRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+
+	h.maintenance.SetMode(body.Enabled)
+	c.JSON(http.StatusOK, h.maintenanceModeResponse())
+}
+
+func (h *handler) maintenanceModeResponse() MaintenanceModeResponse {
+	return MaintenanceModeResponse{Enabled: h.maintenance.ModeEnabled(), LowDiskSpace: h.maintenance.LowDiskSpace()}
+}
+
+// getStats godoc.
+//
+//		@Summary Gets statistics of the service
+//		@Description Measures sizes of the database files and the configured log directory and the free disk space.
+//		@Description Writes are paused while the free space is below minFreeBytes, if disk_guard is enabled.
+//		@Tags admin
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} StatsResponse
+//		@Router /admin/stats [get]
+//	 @Security Bearer
+func (h *handler) getStats(c *gin.Context) {
+	disk, err := h.diskGuard.Check()
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, mapToStatsResponse(disk))
+}
+
+// getSyncPause godoc.
+//
+//		@Summary Gets p2p sync pause
+//		@Description Returns whether the p2p sync, in which headers received from peers are added, is paused
+//		@Tags admin
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} SyncPauseResponse
+//		@Router /admin/sync/pause [get]
+//	 @Security Bearer
+func (h *handler) getSyncPause(c *gin.Context) {
+	c.JSON(http.StatusOK, SyncPauseResponse{Paused: h.syncPause.Paused()})
+}
+
+// setSyncPause godoc.
+//
+//		@Summary Pauses or resumes p2p sync
+//		@Description Pauses or resumes the p2p sync. While it's paused, peers stay connected but headers received from them are ignored,
+//		@Description so the state can be investigated. On resume the sync restarts from the tip.
+//		@Tags admin
+//		@Accept json
+//		@Produce json
+//		@Success 200 {object} SyncPauseResponse
+//		@Router /admin/sync/pause [put]
+//		@Param request body SyncPauseRequest true "JSON"
+//	 @Security Bearer
+func (h *handler) setSyncPause(c *gin.Context) {
+	var body SyncPauseRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+
+	h.syncPause.SetPaused(body.Paused)
+	c.JSON(http.StatusOK, SyncPauseResponse{Paused: h.syncPause.Paused()})
+}
+
+// getQuarantined godoc.
+//
+//		@Summary Gets quarantined headers
+//		@Description Returns the latest received headers which weren't added because their timestamps broke the timestamp validation rules
+//		@Tags admin
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} []QuarantinedHeaderResponse
+//		@Router /admin/quarantine [get]
+//	 @Security Bearer
+func (h *handler) getQuarantined(c *gin.Context) {
+	c.JSON(http.StatusOK, mapToQuarantinedHeadersResponse(h.chains.Quarantined()))
+}
+
+// generateHeaders godoc.
+//
+//		@Summary Generates headers on regtest
+//		@Description Mines count headers with the minimal difficulty on top of the header with given hash or the tip and adds them to the chain.
+//		@Description It's available only on the regtest network, count can't be greater than http.bulk_headers_limit.
+//		@Tags admin
+//		@Accept json
+//		@Produce json
+//		@Success 200 {object} []GeneratedHeaderResponse
+//		@Router /admin/generate [post]
+//		@Param request body GenerateHeadersRequest true "JSON"
+//	 @Security Bearer
+func (h *handler) generateHeaders(c *gin.Context) {
+	var body GenerateHeadersRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+	if body.Count <= 0 || (h.bulkLimit > 0 && body.Count > h.bulkLimit) {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidGenerateCount, h.log)
+		return
+	}
+
+	generated, err := h.chains.Generate(body.Count, body.From)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, mapToGeneratedHeadersResponse(generated))
+}
+
+// resyncFromHeight godoc.
+//
+//		@Summary Re-syncs headers from a height
+//		@Description Deletes headers in all states above the given height, so the longest chain header on it becomes the tip,
+//		@Description and restarts syncing from there. It's meant for recovering from suspected corruption of the latest headers.
+//		@Tags admin
+//		@Accept json
+//		@Produce json
+//		@Success 200 {object} ResyncResponse
+//		@Router /admin/resync [post]
+//		@Param request body ResyncRequest true "JSON"
+//	 @Security Bearer
+func (h *handler) resyncFromHeight(c *gin.Context) {
+	var body ResyncRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+
+	deleted, err := h.resync.Resync(body.Height)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, ResyncResponse{Height: body.Height, Deleted: deleted})
+}
+
+// restoreSnapshot godoc.
+//
+//		@Summary Rebuilds the database from a snapshot
+//		@Description Imports the prepared database file at the path on the server into a shadow table and swaps it with the headers,
+//		@Description pausing writes only for the swap. Syncing restarts from the tip of the snapshot.
+//		@Tags admin
+//		@Accept json
+//		@Produce json
+//		@Success 200 {object} RestoreSnapshotResponse
+//		@Router /admin/snapshot/restore [post]
+//		@Param request body RestoreSnapshotRequest true "JSON"
+//	 @Security Bearer
+func (h *handler) restoreSnapshot(c *gin.Context) {
+	var body RestoreSnapshotRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+	if body.Path == "" {
+		bhserrors.ErrorResponse(c, bhserrors.ErrMissingSnapshotPath, h.log)
+		return
+	}
+
+	imported, tip, err := h.resync.RestoreSnapshot(body.Path)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, RestoreSnapshotResponse{Imported: imported, Height: tip.Height, Hash: tip.Hash.String()})
+}
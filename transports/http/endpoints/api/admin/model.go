@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// MaintenanceResponse defines a result of database maintenance.
+type MaintenanceResponse struct {
+	DurationMs int64 `json:"durationMs"`
+}
+
+// MaintenanceModeRequest defines whether the maintenance mode should be enabled.
+type MaintenanceModeRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// MaintenanceModeResponse defines whether the maintenance mode is enabled and whether it's because of low free disk space.
+type MaintenanceModeResponse struct {
+	Enabled      bool `json:"enabled"`
+	LowDiskSpace bool `json:"lowDiskSpace,omitempty"`
+}
+
+// SyncPauseRequest defines whether the p2p sync should be paused.
+type SyncPauseRequest struct {
+	Paused bool `json:"paused"`
+}
+
+// SyncPauseResponse defines whether the p2p sync is paused.
+type SyncPauseResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// QuarantinedHeaderResponse defines a header which wasn't added because of its timestamp.
+type QuarantinedHeaderResponse struct {
+	Hash              string    `json:"hash"`
+	PreviousBlock     string    `json:"prevBlockHash"`
+	Height            int32     `json:"height"`
+	CreationTimestamp uint32    `json:"creationTimestamp"`
+	Reason            string    `json:"reason"`
+	QuarantinedAt     time.Time `json:"quarantinedAt"`
+}
+
+func mapToQuarantinedHeadersResponse(headers []*domains.QuarantinedHeader) []QuarantinedHeaderResponse {
+	res := make([]QuarantinedHeaderResponse, 0, len(headers))
+	for _, qh := range headers {
+		res = append(res, QuarantinedHeaderResponse{
+			Hash:              qh.Header.Hash.String(),
+			PreviousBlock:     qh.Header.PreviousBlock.String(),
+			Height:            qh.Header.Height,
+			CreationTimestamp: uint32(qh.Header.Timestamp.Unix()),
+			Reason:            qh.Reason,
+			QuarantinedAt:     qh.QuarantinedAt,
+		})
+	}
+	return res
+}
+
+// GenerateHeadersRequest defines how many headers to generate and the hash of the header to generate them on, the tip by default.
+type GenerateHeadersRequest struct {
+	Count int    `json:"count"`
+	From  string `json:"from,omitempty"`
+}
+
+// GeneratedHeaderResponse defines a generated header with its place in the chain.
+type GeneratedHeaderResponse struct {
+	Hash              string `json:"hash"`
+	PreviousBlock     string `json:"prevBlockHash"`
+	Height            int32  `json:"height"`
+	State             string `json:"state"`
+	CreationTimestamp uint32 `json:"creationTimestamp"`
+}
+
+func mapToGeneratedHeadersResponse(headers []*domains.BlockHeader) []GeneratedHeaderResponse {
+	res := make([]GeneratedHeaderResponse, 0, len(headers))
+	for _, h := range headers {
+		res = append(res, GeneratedHeaderResponse{
+			Hash:              h.Hash.String(),
+			PreviousBlock:     h.PreviousBlock.String(),
+			Height:            h.Height,
+			State:             h.State.String(),
+			CreationTimestamp: uint32(h.Timestamp.Unix()),
+		})
+	}
+	return res
+}
+
+// ResyncRequest defines the height above which headers are deleted and synced again.
+type ResyncRequest struct {
+	Height int32 `json:"height"`
+}
+
+// ResyncResponse defines the height the sync restarted from and the number of deleted headers.
+type ResyncResponse struct {
+	Height  int32 `json:"height"`
+	Deleted int   `json:"deleted"`
+}
+
+// RestoreSnapshotRequest defines the prepared database file on the server the headers are restored from.
+type RestoreSnapshotRequest struct {
+	Path string `json:"path"`
+}
+
+// RestoreSnapshotResponse defines the number of imported headers and the tip syncing restarted from.
+type RestoreSnapshotResponse struct {
+	Imported int    `json:"imported"`
+	Height   int32  `json:"height"`
+	Hash     string `json:"hash"`
+}
+
+// StatsResponse defines statistics of the service.
+type StatsResponse struct {
+	Disk DiskUsageResponse `json:"disk"`
+}
+
+// DiskUsageResponse defines sizes of the database and the logs and the free disk space.
+type DiskUsageResponse struct {
+	DatabaseBytes int64  `json:"databaseBytes"`
+	LogsBytes     int64  `json:"logsBytes"`
+	FreeBytes     uint64 `json:"freeBytes"`
+	MinFreeBytes  uint64 `json:"minFreeBytes"`
+	LowSpace      bool   `json:"lowSpace"`
+}
+
+func mapToStatsResponse(disk *domains.DiskUsage) StatsResponse {
+	return StatsResponse{
+		Disk: DiskUsageResponse{
+			DatabaseBytes: disk.DatabaseBytes,
+			LogsBytes:     disk.LogsBytes,
+			FreeBytes:     disk.FreeBytes,
+			MinFreeBytes:  disk.MinFreeBytes,
+			LowSpace:      disk.LowSpace,
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package archive
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// defaultLimit is the number of archived branches listed when the limit isn't given.
+const defaultLimit = 100
+
+type handler struct {
+	service   service.Archive
+	bulkLimit int
+	log       *zerolog.Logger
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{service: s.Archive, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	h.bulkLimit = cfg.BulkHeadersLimit
+
+	archive := router.Group("/archive")
+	{
+		archive.GET("", h.getArchivedBranches)
+		archive.GET("/:hash", h.getArchivedBranchOf)
+	}
+}
+
+// getArchivedBranches godoc.
+//
+//	@Summary Gets archived branches
+//	@Description Returns stale and orphan branches moved to the archive, from the highest tip, without their headers.
+//	@Description The limit can't be greater than http.bulk_headers_limit
+//	@Tags archive
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} []ArchivedBranchResponse
+//	@Router /archive [get]
+//	@Param limit query int false "Number of branches to return (optional, default 100)"
+//	@Security Bearer
+func (h *handler) getArchivedBranches(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidArchiveLimit, h.log)
+		return
+	}
+	if h.bulkLimit > 0 && limit > h.bulkLimit {
+		limit = h.bulkLimit
+	}
+
+	branches, err := h.service.GetArchivedBranches(limit)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, mapToArchivedBranchResponses(branches))
+}
+
+// getArchivedBranchOf godoc.
+//
+//	@Summary Gets archived branch of a header
+//	@Description Returns the archived branch containing the header of given hash, together with its headers from the tip back
+//	@Tags archive
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} ArchivedBranchResponse
+//	@Router /archive/{hash} [get]
+//	@Param hash path string true "Requested header hash"
+//	@Security Bearer
+func (h *handler) getArchivedBranchOf(c *gin.Context) {
+	branch, err := h.service.GetArchivedBranchOf(c.Param("hash"))
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, newArchivedBranchResponse(branch))
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"encoding/json"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// ArchivedBranchResponse defines a stale or orphan branch moved to the archive.
+type ArchivedBranchResponse struct {
+	TipHash      string                   `json:"tipHash"`
+	TipHeight    int32                    `json:"tipHeight"`
+	Length       int                      `json:"length"`
+	State        string                   `json:"state"`
+	TipTimestamp uint32                   `json:"tipCreationTimestamp"`
+	ArchivedAt   int64                    `json:"archivedAt"`
+	Headers      []ArchivedHeaderResponse `json:"headers,omitempty"`
+	Events       []ArchivedEventResponse  `json:"events,omitempty"`
+}
+
+// ArchivedHeaderResponse defines a single archived header.
+type ArchivedHeaderResponse struct {
+	Hash             string `json:"hash"`
+	Height           int32  `json:"height"`
+	Version          int32  `json:"version"`
+	PreviousBlock    string `json:"prevBlockHash"`
+	MerkleRoot       string `json:"merkleRoot"`
+	Timestamp        uint32 `json:"creationTimestamp"`
+	DifficultyTarget uint32 `json:"difficultyTarget"`
+	Nonce            uint32 `json:"nonce"`
+	ChainWork        string `json:"chainWork"`
+}
+
+// ArchivedEventResponse defines an event about an archived header which was still in the outbox when it was archived.
+type ArchivedEventResponse struct {
+	HeaderHash string          `json:"headerHash"`
+	Operation  string          `json:"operation"`
+	Event      json.RawMessage `json:"event"`
+	CreatedAt  int64           `json:"createdAt"`
+}
+
+// newArchivedBranchResponse maps a domain ArchivedBranch to a transport ArchivedBranchResponse.
+func newArchivedBranchResponse(branch *domains.ArchivedBranch) ArchivedBranchResponse {
+	res := ArchivedBranchResponse{
+		TipHash:      branch.TipHash,
+		TipHeight:    branch.TipHeight,
+		Length:       branch.Length,
+		State:        branch.State.String(),
+		TipTimestamp: uint32(branch.TipTimestamp.Unix()),
+		ArchivedAt:   branch.ArchivedAt.Unix(),
+	}
+	for _, h := range branch.Headers {
+		res.Headers = append(res.Headers, ArchivedHeaderResponse{
+			Hash:             h.Hash.String(),
+			Height:           h.Height,
+			Version:          h.Version,
+			PreviousBlock:    h.PreviousBlock.String(),
+			MerkleRoot:       h.MerkleRoot.String(),
+			Timestamp:        uint32(h.Timestamp.Unix()),
+			DifficultyTarget: h.Bits,
+			Nonce:            h.Nonce,
+			ChainWork:        h.CumulatedWork.String(),
+		})
+	}
+	for _, e := range branch.Events {
+		res.Events = append(res.Events, ArchivedEventResponse{
+			HeaderHash: e.HeaderHash,
+			Operation:  e.Operation,
+			Event:      e.Event,
+			CreatedAt:  e.CreatedAt.Unix(),
+		})
+	}
+	return res
+}
+
+// mapToArchivedBranchResponses maps a slice of domain ArchivedBranch to a slice of transport ArchivedBranchResponse.
+func mapToArchivedBranchResponses(branches []*domains.ArchivedBranch) []ArchivedBranchResponse {
+	res := make([]ArchivedBranchResponse, 0, len(branches))
+	for _, b := range branches {
+		res = append(res, newArchivedBranchResponse(b))
+	}
+	return res
+}
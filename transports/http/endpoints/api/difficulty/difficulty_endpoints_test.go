@@ -0,0 +1,114 @@
+package difficulty_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/difficulty"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDifficultyHistory(t *testing.T) {
+	t.Run("success - every second header up to the tip", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getDifficultyHistory("from=1&interval=2"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var history []difficulty.DifficultyResponse
+		err := json.NewDecoder(res.Body).Decode(&history)
+		assert.NoError(t, err)
+		assert.Equal(t, len(history), 2)
+		assert.Equal(t, history[0], difficulty.DifficultyResponse{
+			Height:           1,
+			Timestamp:        uint32(fixtures.HeaderSourceHeight1.Timestamp.Unix()),
+			DifficultyTarget: fixtures.DefaultBits,
+			Difficulty:       1,
+		})
+		assert.Equal(t, history[1].Height, 3)
+	})
+
+	t.Run("failure - from higher than to", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrInvalidDifficultyRange\",\"message\":\"from and to must be heights with from not higher than to and interval a positive integer\"}",
+		}
+
+		// when
+		res := bhs.API().Call(getDifficultyHistory("from=3&to=1"))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
+}
+
+func TestGetHashrate(t *testing.T) {
+	t.Run("success - window longer than the chain", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHashrate("blocks=100"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var hashrate difficulty.HashrateResponse
+		err := json.NewDecoder(res.Body).Decode(&hashrate)
+		assert.NoError(t, err)
+		assert.Equal(t, hashrate.FromHeight, 0)
+		assert.Equal(t, hashrate.ToHeight, 4)
+		assert.Equal(t, hashrate.Work.Int64(), 3*fixtures.DefaultChainWork)
+		assert.Equal(t, hashrate.Hashrate.Sign(), 1)
+	})
+
+	t.Run("failure - invalid window", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHashrate("blocks=0"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, "{\"code\":\"ErrInvalidHashrateWindow\",\"message\":\"blocks must be a positive integer\"}", res.Body.String())
+	})
+}
+
+func getDifficultyHistory(query string) (req *http.Request, err error) {
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		fmt.Sprintf("/api/v1/chain/difficulty?%s", query),
+		nil,
+	)
+}
+
+func getHashrate(query string) (req *http.Request, err error) {
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		fmt.Sprintf("/api/v1/chain/hashrate?%s", query),
+		nil,
+	)
+}
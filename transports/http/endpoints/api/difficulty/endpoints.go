@@ -0,0 +1,108 @@
+package difficulty
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// defaultHashrateBlocks is the number of blocks expected to be mined in a day.
+const defaultHashrateBlocks = 144
+
+type handler struct {
+	service   service.Headers
+	bulkLimit int
+	log       *zerolog.Logger
+}
+
+// NewHandler creates new endpoint handler.
+func NewHandler(s *service.Services) router.APIEndpoints {
+	return &handler{service: s.Headers, log: s.Logger}
+}
+
+// RegisterAPIEndpoints registers routes that are part of service API.
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	h.bulkLimit = cfg.BulkHeadersLimit
+
+	chain := router.Group("/chain")
+	{
+		chain.GET("/difficulty", h.getDifficultyHistory)
+		chain.GET("/hashrate", h.getHashrate)
+	}
+}
+
+// getDifficultyHistory godoc.
+//
+//	@Summary Gets difficulty history of the longest chain
+//	@Description Returns difficulty of every interval-th header between given heights, starting with from.
+//	@Description The number of returned points can't be greater than http.bulk_headers_limit
+//	@Tags difficulty
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} []DifficultyResponse
+//	@Router /chain/difficulty [get]
+//	@Param from query int true "Height to start from"
+//	@Param to query int false "Height to end with (optional, default tip height)"
+//	@Param interval query int false "Number of heights between returned points (optional, default 1)"
+//	@Security Bearer
+func (h *handler) getDifficultyHistory(c *gin.Context) {
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from < 0 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidDifficultyRange, h.log)
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", strconv.Itoa(int(h.service.GetTipHeight()))))
+	if err != nil || to < from {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidDifficultyRange, h.log)
+		return
+	}
+	interval, err := strconv.Atoi(c.DefaultQuery("interval", "1"))
+	if err != nil || interval < 1 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidDifficultyRange, h.log)
+		return
+	}
+
+	if h.bulkLimit > 0 && (to-from)/interval+1 > h.bulkLimit {
+		bhserrors.ErrorResponse(c, bhserrors.ErrTooManyDifficultyPoints, h.log)
+		return
+	}
+
+	headers, err := h.service.GetDifficultyHistory(from, to, interval)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, mapToDifficultyResponse(headers))
+}
+
+// getHashrate godoc.
+//
+//	@Summary Gets estimated network hashrate
+//	@Description Returns the work done by the trailing window of the longest chain divided by the time it took
+//	@Tags difficulty
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} HashrateResponse
+//	@Router /chain/hashrate [get]
+//	@Param blocks query int false "Number of last blocks to estimate hashrate from (optional, default 144)"
+//	@Security Bearer
+func (h *handler) getHashrate(c *gin.Context) {
+	blocks, err := strconv.Atoi(c.DefaultQuery("blocks", strconv.Itoa(defaultHashrateBlocks)))
+	if err != nil || blocks < 1 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidHashrateWindow, h.log)
+		return
+	}
+
+	hashrate, err := h.service.GetHashrate(blocks)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, newHashrateResponse(hashrate))
+}
@@ -0,0 +1,52 @@
+package difficulty
+
+import (
+	"math/big"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// DifficultyResponse defines the difficulty of the longest chain at a single height.
+type DifficultyResponse struct {
+	Height           int32   `json:"height"`
+	Timestamp        uint32  `json:"creationTimestamp"`
+	DifficultyTarget uint32  `json:"difficultyTarget"`
+	Difficulty       float64 `json:"difficulty"`
+}
+
+// mapToDifficultyResponse maps a slice of domain BlockHeader to a slice of transport DifficultyResponse.
+func mapToDifficultyResponse(headers []*domains.BlockHeader) []DifficultyResponse {
+	difficultyResponse := make([]DifficultyResponse, 0, len(headers))
+
+	for _, header := range headers {
+		difficultyResponse = append(difficultyResponse, DifficultyResponse{
+			Height:           header.Height,
+			Timestamp:        uint32(header.Timestamp.Unix()),
+			DifficultyTarget: header.Bits,
+			Difficulty:       domains.Difficulty(header.Bits),
+		})
+	}
+
+	return difficultyResponse
+}
+
+// HashrateResponse defines the network hashrate estimated from the trailing window of the longest chain.
+type HashrateResponse struct {
+	FromHeight     int32    `json:"fromHeight"`
+	ToHeight       int32    `json:"toHeight"`
+	Work           *big.Int `json:"work" swaggertype:"string"`
+	ElapsedSeconds int64    `json:"elapsedSeconds"`
+	// Hashrate is the number of hashes per second.
+	Hashrate *big.Int `json:"hashrate" swaggertype:"string"`
+}
+
+// newHashrateResponse maps a domain Hashrate to a transport HashrateResponse.
+func newHashrateResponse(h *domains.Hashrate) HashrateResponse {
+	return HashrateResponse{
+		FromHeight:     h.From.Height,
+		ToHeight:       h.To.Height,
+		Work:           h.Work,
+		ElapsedSeconds: int64(h.Elapsed.Seconds()),
+		Hashrate:       h.HashesPerSecond,
+	}
+}
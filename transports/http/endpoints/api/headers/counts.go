@@ -0,0 +1,102 @@
+package headers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCountsWindow = 24 * time.Hour
+	maxCountsWindows    = 10
+)
+
+// HeadersCountsResponse defines numbers of headers in each state.
+type HeadersCountsResponse struct {
+	Total   map[string]int               `json:"total"`
+	Windows []HeadersCountWindowResponse `json:"windows"`
+}
+
+// HeadersCountWindowResponse defines numbers of headers in each state created within the window before now.
+type HeadersCountWindowResponse struct {
+	WindowSeconds int64          `json:"windowSeconds"`
+	FromHeight    int32          `json:"fromHeight"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// getHeadersCounts godoc.
+//
+//		@Summary Gets numbers of headers in each state
+//		@Description Counts all stored headers by state and headers created within each window before now, 24h by default.
+//		@Description Windows are bounded by heights of the longest chain, e.g. stale headers in the window fork off within it.
+//		@Tags headers
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} HeadersCountsResponse
+//		@Router /chain/header/counts [get]
+//		@Param window query []string false "Windows as durations, e.g. 1h or 24h (optional)"
+//	 @Security Bearer
+func (h *handler) getHeadersCounts(c *gin.Context) {
+	windows, err := parseCountsWindows(c.QueryArray("window"))
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
+	counts, err := h.service.GetHeadersCounts(windows)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, mapToHeadersCountsResponse(counts))
+}
+
+func parseCountsWindows(params []string) ([]time.Duration, error) {
+	if len(params) == 0 {
+		return []time.Duration{defaultCountsWindow}, nil
+	}
+	if len(params) > maxCountsWindows {
+		return nil, bhserrors.ErrInvalidCountsWindow
+	}
+
+	windows := make([]time.Duration, 0, len(params))
+	for _, p := range params {
+		w, err := time.ParseDuration(p)
+		if err != nil || w <= 0 {
+			return nil, bhserrors.ErrInvalidCountsWindow
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func mapToHeadersCountsResponse(counts *domains.HeadersCounts) HeadersCountsResponse {
+	res := HeadersCountsResponse{
+		Total:   stateCountsResponse(counts.Total),
+		Windows: make([]HeadersCountWindowResponse, 0, len(counts.Windows)),
+	}
+	for _, w := range counts.Windows {
+		res.Windows = append(res.Windows, HeadersCountWindowResponse{
+			WindowSeconds: int64(w.Window / time.Second),
+			FromHeight:    w.FromHeight,
+			Counts:        stateCountsResponse(w.Counts),
+		})
+	}
+	return res
+}
+
+// stateCountsResponse lists every stored state, so missing states are reported as zero.
+func stateCountsResponse(counts domains.HeaderStateCounts) map[string]int {
+	res := map[string]int{
+		string(domains.LongestChain): 0,
+		string(domains.Stale):        0,
+		string(domains.Orphan):       0,
+	}
+	for state, count := range counts {
+		res[string(state)] = count
+	}
+	return res
+}
@@ -3,34 +3,59 @@ package headers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/etag"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/protobuf"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
 
+// responseOptionsKey is the context key of options shaping header responses.
+const responseOptionsKey = "responseOptions"
+
 type handler struct {
-	service service.Headers
-	log     *zerolog.Logger
+	service         service.Headers
+	chains          service.Chains
+	idempotency     service.Idempotency
+	streamThreshold int
+	bulkLimit       int
+	writeTimeout    time.Duration
+	log             *zerolog.Logger
 }
 
 // NewHandler creates new endpoint handler.
 func NewHandler(s *service.Services) router.APIEndpoints {
-	return &handler{service: s.Headers, log: s.Logger}
+	return &handler{service: s.Headers, chains: s.Chains, idempotency: s.Idempotency, log: s.Logger}
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	h.streamThreshold = cfg.StreamThreshold
+	h.bulkLimit = cfg.BulkHeadersLimit
+	h.writeTimeout = time.Duration(cfg.WriteTimeout) * time.Second
+
 	headers := router.Group("/chain/header")
 	{
-		headers.GET("/:hash", h.getHeaderByHash)
-		headers.GET("/byHeight", h.getHeaderByHeight)
-		headers.GET("/:hash/:ancestorHash/ancestor", h.getHeaderAncestorsByHash)
-		headers.POST("/commonAncestor", h.getCommonAncestor)
-		headers.GET("/state/:hash", h.getHeadersState)
+		headers.POST("", auth.RequireAdmin(idempotency.Handler(h.idempotency, h.submitHeaders, h.maxSubmitBody(), h.log), cfg.UseAuth))
+		headers.GET("/:hash", h.responseOptions, etag.Middleware(), h.getHeaderByHash)
+		headers.GET("/byHeight", h.responseOptions, h.getHeaderByHeight)
+		headers.GET("/byTime", h.responseOptions, h.getHeaderAtTime)
+		headers.GET("/counts", h.getHeadersCounts)
+		headers.GET("/:hash/:ancestorHash/ancestor", h.responseOptions, h.getHeaderAncestorsByHash)
+		headers.GET("/between/:ancestorHash/:descendantHash", h.responseOptions, h.getChainSegment)
+		headers.GET("/diff/:hash/:otherHash", h.responseOptions, h.getBranchDiff)
+		headers.POST("/commonAncestor", h.responseOptions, h.getCommonAncestor)
+		headers.POST("/bulk", h.responseOptions, h.getHeadersBulk)
+		headers.POST("/mapping", h.mapHeaders)
+		headers.GET("/state/:hash", h.responseOptions, etag.Middleware(), h.getHeadersState)
 	}
 }
 
@@ -43,13 +68,15 @@ func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPCo
 //		@Produce json
 //		@Router /chain/header/{hash} [get]
 //		@Param hash path string true "Requested Header Hash"
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
 //	 @Security Bearer
 func (h *handler) getHeaderByHash(c *gin.Context) {
 	hash := c.Param("hash")
 	bh, err := h.service.GetHeaderByHash(hash)
 
 	if err == nil {
-		c.JSON(http.StatusOK, newBlockHeaderResponse(bh))
+		c.JSON(http.StatusOK, headerResponse(bh, optionsOf(c)))
 	} else {
 		bhserrors.ErrorResponse(c, err, h.log)
 	}
@@ -58,15 +85,28 @@ func (h *handler) getHeaderByHash(c *gin.Context) {
 // getHeaderByHeight godoc.
 //
 //		@Summary Gets header by height
+//		@Description Large ranges (above http.stream_threshold) and requests accepting application/x-ndjson are streamed
+//		@Description Requests accepting application/x-protobuf are never streamed
 //		@Tags headers
 //		@Accept */*
-//		@Produce json
+//		@Produce json,x-protobuf
 //		@Success 200 {object} []BlockHeaderResponse
 //		@Router /chain/header/byHeight [get]
 //		@Param height query int true "Height to start from"
 //		@Param count query int false "Headers count (optional)"
+//		@Param state query []string false "Header states (optional)" Enums(LONGEST_CHAIN, STALE, ORPHAN) collectionFormat(csv)
+//		@Param sort query string false "Field to order headers by (optional)" Enums(height, timestamp, chainwork)
+//		@Param order query string false "Order of headers (optional)" Enums(asc, desc)
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
 //	 @Security Bearer
 func (h *handler) getHeaderByHeight(c *gin.Context) {
+	query, err := parseHeadersQuery(c.Request.URL.Query())
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
 	height, _ := c.GetQuery("height")
 	count, _ := c.GetQuery("count")
 	heightInt, err := strconv.Atoi(height)
@@ -76,9 +116,13 @@ func (h *handler) getHeaderByHeight(c *gin.Context) {
 		if err2 != nil {
 			countInt = 1
 		}
-		bh, err := h.service.GetHeadersByHeight(heightInt, countInt)
+		if h.wantsStream(c, countInt) {
+			h.streamHeadersByHeight(c, heightInt, countInt, query)
+			return
+		}
+		bh, err := h.service.GetHeadersByHeight(heightInt, countInt, query)
 		if err == nil {
-			c.JSON(http.StatusOK, mapToBlockHeadersResponses(bh))
+			h.writeHeaders(c, bh)
 		} else {
 			bhserrors.ErrorResponse(c, err, h.log)
 		}
@@ -92,11 +136,13 @@ func (h *handler) getHeaderByHeight(c *gin.Context) {
 //		@Summary Gets header ancestors
 //		@Tags headers
 //		@Accept */*
-//		@Produce json
+//		@Produce json,x-protobuf
 //		@Success 200 {object} []BlockHeaderResponse
 //		@Router /chain/header/{hash}/{ancestorHash}/ancestor [get]
 //		@Param hash path string true "Requested Header Hash"
 //		@Param ancestorHash path string true "Ancestor Header Hash"
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
 //	 @Security Bearer
 func (h *handler) getHeaderAncestorsByHash(c *gin.Context) {
 	hash := c.Param("hash")
@@ -104,12 +150,108 @@ func (h *handler) getHeaderAncestorsByHash(c *gin.Context) {
 	ancestors, err := h.service.GetHeaderAncestorsByHash(hash, ancestorHash)
 
 	if err == nil {
-		c.JSON(http.StatusOK, mapToBlockHeadersResponses(ancestors))
+		h.writeHeaders(c, ancestors)
 	} else {
 		bhserrors.ErrorResponse(c, err, h.log)
 	}
 }
 
+// getChainSegment godoc.
+//
+//		@Summary Gets chain segment between two headers
+//		@Description Returns headers from the ancestor to the descendant ordered by height, both have to be on the same branch
+//		@Description and the segment can't be longer than http.bulk_headers_limit
+//		@Tags headers
+//		@Accept */*
+//		@Produce json,x-protobuf
+//		@Success 200 {object} []BlockHeaderResponse
+//		@Router /chain/header/between/{ancestorHash}/{descendantHash} [get]
+//		@Param ancestorHash path string true "Ancestor Header Hash"
+//		@Param descendantHash path string true "Descendant Header Hash"
+//		@Param includeAncestor query bool false "Include the ancestor (optional, default true)"
+//		@Param includeDescendant query bool false "Include the descendant (optional, default true)"
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
+//	 @Security Bearer
+func (h *handler) getChainSegment(c *gin.Context) {
+	includeAncestor, err := strconv.ParseBool(c.DefaultQuery("includeAncestor", "true"))
+	if err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidSegmentBounds, h.log)
+		return
+	}
+	includeDescendant, err := strconv.ParseBool(c.DefaultQuery("includeDescendant", "true"))
+	if err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidSegmentBounds, h.log)
+		return
+	}
+
+	segment, err := h.service.GetChainSegment(c.Param("ancestorHash"), c.Param("descendantHash"), h.bulkLimit)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
+	if !includeAncestor && len(segment) > 0 {
+		segment = segment[1:]
+	}
+	if !includeDescendant && len(segment) > 0 {
+		segment = segment[:len(segment)-1]
+	}
+	h.writeHeaders(c, segment)
+}
+
+// getHeaderAtTime godoc.
+//
+//		@Summary Gets header of the longest chain active at given time
+//		@Description Returns the highest header whose median time past is not after the given time
+//		@Tags headers
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} HeaderAtTimeResponse
+//		@Router /chain/header/byTime [get]
+//		@Param time query string true "Unix timestamp or RFC 3339 time"
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
+//	 @Security Bearer
+func (h *handler) getHeaderAtTime(c *gin.Context) {
+	t, err := parseTime(c.Query("time"))
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
+	header, err := h.service.GetHeaderAtTime(t)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, newHeaderAtTimeResponse(header, optionsOf(c)))
+}
+
+// getBranchDiff godoc.
+//
+//		@Summary Gets headers of two branches above their fork point
+//		@Description Returns the fork point of two headers and headers of each branch leading from it to the given headers,
+//		@Description both branches together can't have more headers than http.bulk_headers_limit
+//		@Tags headers
+//		@Accept */*
+//		@Produce json
+//		@Success 200 {object} BranchDiffResponse
+//		@Router /chain/header/diff/{hash}/{otherHash} [get]
+//		@Param hash path string true "Header Hash"
+//		@Param otherHash path string true "Other Header Hash"
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
+//	 @Security Bearer
+func (h *handler) getBranchDiff(c *gin.Context) {
+	diff, err := h.service.GetBranchDiff(c.Param("hash"), c.Param("otherHash"), h.bulkLimit)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, newBranchDiffResponse(diff, optionsOf(c)))
+}
+
 // getCommonAncestors godoc.
 //
 //		@Summary Gets common ancestors
@@ -119,6 +261,8 @@ func (h *handler) getHeaderAncestorsByHash(c *gin.Context) {
 //		@Success 200 {object} BlockHeaderResponse
 //		@Router /chain/header/commonAncestor [post]
 //		@Param ancesstors body []string true "JSON"
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
 //	 @Security Bearer
 func (h *handler) getCommonAncestor(c *gin.Context) {
 	var body []string
@@ -128,13 +272,113 @@ func (h *handler) getCommonAncestor(c *gin.Context) {
 		ancestor, err := h.service.GetCommonAncestor(body)
 
 		if err == nil {
-			c.JSON(http.StatusOK, newBlockHeaderResponse(ancestor))
+			c.JSON(http.StatusOK, headerResponse(ancestor, optionsOf(c)))
 		} else {
 			bhserrors.ErrorResponse(c, err, h.log)
 		}
 	}
 }
 
+// getHeadersBulk godoc.
+//
+//		@Summary Gets headers in bulk
+//		@Description Returns up to count headers starting from given height or hash of the longest chain, count is truncated to http.bulk_headers_limit.
+//		@Description Headers of the longest chain ordered by height are returned unless the state and sort are given.
+//		@Tags headers
+//		@Accept json
+//		@Produce json,x-protobuf
+//		@Success 200 {object} []BlockHeaderResponse
+//		@Router /chain/header/bulk [post]
+//		@Param request body BulkHeadersRequest true "JSON"
+//		@Param state query []string false "Header states (optional)" Enums(LONGEST_CHAIN, STALE, ORPHAN) collectionFormat(csv)
+//		@Param sort query string false "Field to order headers by (optional)" Enums(height, timestamp, chainwork)
+//		@Param order query string false "Order of headers (optional)" Enums(asc, desc)
+//		@Param fields query string false "Comma separated fields to return (optional)"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
+//	 @Security Bearer
+func (h *handler) getHeadersBulk(c *gin.Context) {
+	query, err := parseHeadersQuery(c.Request.URL.Query())
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
+	var body BulkHeadersRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+	if (body.Height == nil) == (body.Hash == "") {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidHeadersStart, h.log)
+		return
+	}
+	if body.Count <= 0 {
+		bhserrors.ErrorResponse(c, bhserrors.ErrInvalidHeadersCount, h.log)
+		return
+	}
+
+	var height int
+	if body.Height != nil {
+		height = *body.Height
+	} else {
+		bh, err := h.service.GetHeaderByHash(body.Hash)
+		if err != nil {
+			bhserrors.ErrorResponse(c, err, h.log)
+			return
+		}
+		// Heights above a header of another branch belong to the longest chain, not to the branch of the header.
+		if !bh.IsLongestChain() {
+			bhserrors.ErrorResponse(c, bhserrors.ErrHeadersStartNotInLongestChain, h.log)
+			return
+		}
+		height = int(bh.Height)
+	}
+
+	if len(query.States) == 0 {
+		query.States = []domains.HeaderState{domains.LongestChain}
+	}
+	if query.SortBy == "" {
+		query.SortBy = domains.SortByHeight
+	}
+
+	count := body.Count
+	if h.bulkLimit > 0 && count > h.bulkLimit {
+		count = h.bulkLimit
+	}
+
+	bh, err := h.service.GetHeadersFromHeight(height, count, query)
+	if err == nil {
+		h.writeHeaders(c, bh)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// writeHeaders writes headers as the BlockHeaders protobuf message if the client accepts it, otherwise as JSON.
+func (h *handler) writeHeaders(c *gin.Context, headers []*domains.BlockHeader) {
+	if protobuf.Accepted(c.Request) {
+		c.Data(http.StatusOK, protobuf.ContentType, protobuf.MarshalBlockHeaders(headers))
+		return
+	}
+	c.JSON(http.StatusOK, headersResponse(headers, optionsOf(c)))
+}
+
+// responseOptions validates parameters shaping header responses and keeps them in the context for the response writers.
+func (h *handler) responseOptions(c *gin.Context) {
+	opts, err := parseResponseOptions(c.Request.URL.Query())
+	if err != nil {
+		bhserrors.AbortWithErrorResponse(c, err, h.log)
+		return
+	}
+	c.Set(responseOptionsKey, opts)
+}
+
+func optionsOf(c *gin.Context) responseOptions {
+	opts, _ := c.Get(responseOptionsKey)
+	o, _ := opts.(responseOptions)
+	return o
+}
+
 // getHeadersState godoc.
 //
 //		@Summary Gets header state
@@ -144,13 +388,14 @@ func (h *handler) getCommonAncestor(c *gin.Context) {
 //		@Success 200 {object} BlockHeaderStateResponse
 //		@Router /chain/header/state/{hash} [get]
 //		@Param hash path string true "Requested Header Hash"
+//		@Param includeRaw query bool false "Include hex encoded serialized header (optional)"
 //	 @Security Bearer
 func (h *handler) getHeadersState(c *gin.Context) {
 	hash := c.Param("hash")
 	bh, err := h.service.GetHeaderByHash(hash)
 
 	if err == nil {
-		headerStateResponse := newBlockHeaderStateResponse(bh)
+		headerStateResponse := newBlockHeaderStateResponse(bh, optionsOf(c).includeRaw)
 		c.JSON(http.StatusOK, headerStateResponse)
 	} else {
 		bhserrors.ErrorResponse(c, err, h.log)
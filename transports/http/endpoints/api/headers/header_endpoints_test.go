@@ -3,11 +3,14 @@ package headers_test
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
@@ -15,7 +18,9 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
 	"github.com/stretchr/testify/require"
 )
 
@@ -94,6 +99,62 @@ func TestGetHeaderByHash(t *testing.T) {
 		assert.Equal(t, res.Code, expectedResult.code)
 		require.JSONEq(t, expectedResult.body, res.Body.String())
 	})
+
+	t.Run("success - sparse fields", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedBody := fmt.Sprintf(
+			"{\"hash\":%q,\"height\":1,\"merkleRoot\":%q}",
+			fixtures.HashHeight1.String(), fixtures.HeaderSourceHeight1.MerkleRoot.String(),
+		)
+
+		// when
+		res := bhs.API().Call(getHeaderByHash(fixtures.HashHeight1.String() + "?fields=hash,height,merkleRoot"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("success - raw header included", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expected := expectedObj
+		expected.Raw = hex.EncodeToString(rawHeader(t, fixtures.HeaderSourceHeight1))
+
+		// when
+		res := bhs.API().Call(getHeaderByHash(fixtures.HashHeight1.String() + "?includeRaw=true"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var header headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&header)
+		assert.NoError(t, err)
+		assert.Equal(t, header, expected)
+	})
+
+	t.Run("failure - unknown field", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrInvalidHeaderFields\",\"message\":\"fields must be comma separated names of header fields\"}",
+		}
+
+		// when
+		res := bhs.API().Call(getHeaderByHash(fixtures.HashHeight1.String() + "?fields=hash,transactions"))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
 }
 
 func TestGetHeaderByHeight(t *testing.T) {
@@ -160,6 +221,97 @@ func TestGetHeaderByHeight(t *testing.T) {
 		assert.Equal(t, res.Code, expectedResult.code)
 		require.JSONEq(t, expectedResult.body, res.Body.String())
 	})
+
+	t.Run("success - ndjson stream", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeaderByHeightAccepting(1, 4, "application/x-ndjson"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		assert.Equal(t, res.Header().Get("Content-Type"), "application/x-ndjson")
+
+		lines := bytes.Split(bytes.TrimSpace(res.Body.Bytes()), []byte("\n"))
+		assert.Equal(t, len(lines), 4)
+
+		var header headers.BlockHeaderResponse
+		err := json.Unmarshal(lines[0], &header)
+		assert.NoError(t, err)
+		assert.Equal(t, header, expectedObj)
+	})
+
+	t.Run("success - stale headers only", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChainFork(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeaderByHeightInStates(0, 10, "STALE"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var stale []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&stale)
+		assert.NoError(t, err)
+		assert.Equal(t, len(stale), 2)
+		assert.Equal(t, stale[0].Hash, fixtures.StaleHashHeight3.String())
+		assert.Equal(t, stale[1].Hash, fixtures.StaleHashHeight4.String())
+	})
+
+	t.Run("success - newest first", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeaderByHeightWithQuery(1, 4, "sort=height&order=desc"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var sorted []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&sorted)
+		assert.NoError(t, err)
+		assert.Equal(t, len(sorted), 4)
+		assert.Equal(t, sorted[0].Hash, fixtures.HashHeight4.String())
+		assert.Equal(t, sorted[3].Hash, fixtures.HashHeight1.String())
+	})
+
+	t.Run("failure - invalid sort", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeaderByHeightWithQuery(1, 4, "sort=nonce"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+	})
+
+	t.Run("failure - invalid state", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrInvalidHeaderState\",\"message\":\"state must be one of LONGEST_CHAIN, STALE and ORPHAN\"}",
+		}
+
+		// when
+		res := bhs.API().Call(getHeaderByHeightInStates(1, 1, "STALE,REJECTED"))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
 }
 
 func TestGetHeaderAncestorsByHash(t *testing.T) {
@@ -228,6 +380,175 @@ func TestGetHeaderAncestorsByHash(t *testing.T) {
 	})
 }
 
+func TestGetChainSegment(t *testing.T) {
+	t.Run("success - descendant excluded", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getChainSegment(fixtures.HashHeight1.String(), fixtures.HashHeight4.String(), "includeDescendant=false"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var segment []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&segment)
+		assert.NoError(t, err)
+		assert.Equal(t, len(segment), 3)
+		assert.Equal(t, segment[0], expectedObj)
+		assert.Equal(t, segment[2].Hash, fixtures.HashHeight3.String())
+	})
+
+	t.Run("failure - ancestor higher than descendant", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrAncestorHashHigher\",\"message\":\"ancestor header height can not be higher than requested header height\"}",
+		}
+
+		// when
+		res := bhs.API().Call(getChainSegment(fixtures.HashHeight4.String(), fixtures.HashHeight1.String(), ""))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
+}
+
+func TestGetHeaderAtTime(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeaderAtTime("2009-01-09T02:55:00Z"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var header struct {
+			Height         int32                       `json:"height"`
+			MedianTimePast uint32                      `json:"medianTimePast"`
+			Header         headers.BlockHeaderResponse `json:"header"`
+		}
+		err := json.NewDecoder(res.Body).Decode(&header)
+		assert.NoError(t, err)
+		assert.Equal(t, header.Height, 2)
+		assert.Equal(t, header.MedianTimePast, uint32(fixtures.HeaderSourceHeight1.Timestamp.Unix()))
+		assert.Equal(t, header.Header.Hash, fixtures.HashHeight2.String())
+	})
+
+	t.Run("failure - invalid time", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrInvalidTimestamp\",\"message\":\"time must be either unix timestamp or RFC 3339 time\"}",
+		}
+
+		// when
+		res := bhs.API().Call(getHeaderAtTime("yesterday"))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
+}
+
+func TestGetHeadersCounts(t *testing.T) {
+	t.Run("success - default window", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeadersCounts())
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, `{
+			"total": {"LONGEST_CHAIN": 5, "STALE": 0, "ORPHAN": 0},
+			"windows": [{"windowSeconds": 86400, "fromHeight": 5, "counts": {"LONGEST_CHAIN": 0, "STALE": 0, "ORPHAN": 0}}]
+		}`, res.Body.String())
+	})
+
+	t.Run("success - window covering the whole chain", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		window := (time.Since(fixtures.HeaderSourceHeight3.Timestamp) - time.Minute).Truncate(time.Second).String()
+
+		// when
+		res := bhs.API().Call(getHeadersCounts(window))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		var counts headers.HeadersCountsResponse
+		err := json.NewDecoder(res.Body).Decode(&counts)
+		assert.NoError(t, err)
+		assert.Equal(t, len(counts.Windows), 1)
+		assert.Equal(t, counts.Windows[0].FromHeight, 4)
+		assert.Equal(t, counts.Windows[0].Counts[string(domains.LongestChain)], 1)
+	})
+
+	t.Run("failure - invalid window", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrInvalidCountsWindow\",\"message\":\"window must be a positive duration, e.g. 24h, and at most 10 windows can be requested\"}",
+		}
+
+		// when
+		res := bhs.API().Call(getHeadersCounts("-1h"))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
+}
+
+func TestGetBranchDiff(t *testing.T) {
+	t.Run("success - header on the same branch", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getBranchDiff(fixtures.HashHeight1.String(), fixtures.HashHeight3.String()))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var diff struct {
+			ForkPoint   headers.BlockHeaderResponse   `json:"forkPoint"`
+			Branch      []headers.BlockHeaderResponse `json:"branch"`
+			OtherBranch []headers.BlockHeaderResponse `json:"otherBranch"`
+		}
+		err := json.NewDecoder(res.Body).Decode(&diff)
+		assert.NoError(t, err)
+		assert.Equal(t, diff.ForkPoint, expectedObj)
+		assert.Equal(t, len(diff.Branch), 0)
+		assert.Equal(t, len(diff.OtherBranch), 2)
+		assert.Equal(t, diff.OtherBranch[1].Hash, fixtures.HashHeight3.String())
+	})
+}
+
 func TestGetCommonAncestor(t *testing.T) {
 	t.Run("failure when authorization on and empty auth header", func(t *testing.T) {
 		// given
@@ -305,6 +626,200 @@ func TestGetCommonAncestor(t *testing.T) {
 	})
 }
 
+func TestGetHeadersBulk(t *testing.T) {
+	t.Run("success - from height", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		height := 1
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Height: &height, Count: 4}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var result []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Equal(t, len(result), 4)
+		assert.Equal(t, result[0], expectedObj)
+	})
+
+	t.Run("success - from hash", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Hash: fixtures.HashHeight2.String(), Count: 10}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var result []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Equal(t, len(result), 3)
+		assert.Equal(t, result[0].Hash, fixtures.HashHeight2.String())
+	})
+
+	t.Run("success - count truncated to limit", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t,
+			testapp.WithLongestChain(),
+			testapp.WithAPIAuthorizationDisabled(),
+			testapp.WithBulkHeadersLimit(2),
+		)
+		defer cleanup()
+		height := 1
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Height: &height, Count: 4}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var result []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Equal(t, len(result), 2)
+	})
+
+	t.Run("success - longest chain ordered by height by default", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChainFork(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		height := 2
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Height: &height, Count: 10}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var result []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Equal(t, len(result), 3)
+		assert.Equal(t, result[0].Hash, fixtures.HashHeight2.String())
+		assert.Equal(t, result[1].Hash, fixtures.HashHeight3.String())
+		assert.Equal(t, result[2].Hash, fixtures.HashHeight4.String())
+	})
+
+	t.Run("success - count caps headers of all requested states", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChainFork(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		height := 3
+
+		// when
+		res := bhs.API().Call(getHeadersBulkWithQuery(headers.BulkHeadersRequest{Height: &height, Count: 3}, "state=LONGEST_CHAIN,STALE"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var result []headers.BlockHeaderResponse
+		err := json.NewDecoder(res.Body).Decode(&result)
+		assert.NoError(t, err)
+		assert.Equal(t, len(result), 3)
+	})
+
+	t.Run("failure - hash not in the longest chain", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChainFork(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Hash: fixtures.StaleHashHeight3.String(), Count: 10}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, "{\"code\":\"ErrHeadersStartNotInLongestChain\",\"message\":\"headers can only be requested from a hash of the longest chain\"}", res.Body.String())
+	})
+
+	t.Run("failure - both height and hash", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		height := 1
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Height: &height, Hash: fixtures.HashHeight2.String(), Count: 4}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, "{\"code\":\"ErrInvalidHeadersStart\",\"message\":\"exactly one of height or hash must be provided\"}", res.Body.String())
+	})
+
+	t.Run("failure - invalid count", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		height := 1
+
+		// when
+		res := bhs.API().Call(getHeadersBulk(headers.BulkHeadersRequest{Height: &height}))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, "{\"code\":\"ErrInvalidHeadersCount\",\"message\":\"count must be a positive integer\"}", res.Body.String())
+	})
+}
+
+func TestMapHeaders(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		unknownHash := chainhash.Hash{}.String()
+		request := headers.HeadersMappingRequest{
+			Hashes:  []string{fixtures.HashHeight2.String(), unknownHash},
+			Heights: []int32{4, 7},
+		}
+
+		// when
+		res := bhs.API().Call(mapHeaders(request))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, fmt.Sprintf(`{
+			"byHash": [{"hash": %q, "height": 2, "state": "LONGEST_CHAIN"}],
+			"byHeight": [{"hash": %q, "height": 4, "state": "LONGEST_CHAIN"}],
+			"unknownHashes": [%q],
+			"unknownHeights": [7]
+		}`, fixtures.HashHeight2, fixtures.HashHeight4, unknownHash), res.Body.String())
+	})
+
+	t.Run("failure - more hashes and heights than the bulk limit", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t,
+			testapp.WithLongestChain(),
+			testapp.WithAPIAuthorizationDisabled(),
+			testapp.WithBulkHeadersLimit(2),
+		)
+		defer cleanup()
+		request := headers.HeadersMappingRequest{
+			Hashes:  []string{fixtures.HashHeight2.String()},
+			Heights: []int32{3, 4},
+		}
+		expectedResult := struct {
+			code int
+			body string
+		}{
+			code: http.StatusBadRequest,
+			body: "{\"code\":\"ErrTooManyMappedHeaders\",\"message\":\"too many hashes and heights requested at once\"}",
+		}
+
+		// when
+		res := bhs.API().Call(mapHeaders(request))
+
+		// then
+		assert.Equal(t, res.Code, expectedResult.code)
+		require.JSONEq(t, expectedResult.body, res.Body.String())
+	})
+}
+
 func TestGetHeadersState(t *testing.T) {
 	t.Run("failure when authorization on and empty auth header", func(t *testing.T) {
 		// given
@@ -377,6 +892,134 @@ func TestGetHeadersState(t *testing.T) {
 	})
 }
 
+func TestSubmitHeaders(t *testing.T) {
+	t.Run("success - hex encoded headers", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		hexHeaders := []string{
+			hex.EncodeToString(rawHeader(t, fixtures.HeaderSourceHeight4)),
+			hex.EncodeToString(rawHeader(t, fixtures.HeaderSourceHeight5)),
+		}
+		body, _ := json.Marshal(hexHeaders)
+
+		// when
+		res := bhs.API().Call(submitHeaders(body, "application/json"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var results []headers.SubmittedHeaderResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&results))
+		require.Len(t, results, 2)
+		assert.Equal(t, results[0], headers.SubmittedHeaderResponse{
+			Hash: fixtures.HashHeight4.String(), Status: headers.SubmitStatusExists, State: string(domains.LongestChain), Height: 4,
+		})
+		assert.Equal(t, results[1], headers.SubmittedHeaderResponse{
+			Hash: fixtures.HashHeight5.String(), Status: headers.SubmitStatusAdded, State: string(domains.LongestChain), Height: 5,
+		})
+	})
+
+	t.Run("success - binary headers", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		body := append(rawHeader(t, fixtures.HeaderSourceHeight5), rawHeader(t, fixtures.HeaderSourceHeight6)...)
+
+		// when
+		res := bhs.API().Call(submitHeaders(body, "application/octet-stream"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var results []headers.SubmittedHeaderResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&results))
+		require.Len(t, results, 2)
+		assert.Equal(t, results[1].Hash, fixtures.HashHeight6.String())
+		assert.Equal(t, results[1].Height, int32(6))
+	})
+
+	t.Run("success - header from the future is quarantined", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		future := *fixtures.HeaderSourceHeight5
+		future.Timestamp = time.Now().Add(3 * time.Hour).Truncate(time.Second)
+		body := rawHeader(t, &future)
+
+		// when
+		res := bhs.API().Call(submitHeaders(body, "application/octet-stream"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+
+		var results []headers.SubmittedHeaderResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&results))
+		require.Len(t, results, 1)
+		assert.Equal(t, results[0].Status, headers.SubmitStatusQuarantined)
+		assert.Equal(t, results[0].Height, int32(5))
+	})
+
+	t.Run("failure - truncated header", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+		body := rawHeader(t, fixtures.HeaderSourceHeight5)[:79]
+
+		// when
+		res := bhs.API().Call(submitHeaders(body, "application/octet-stream"))
+
+		// then
+		assert.Equal(t, res.Code, http.StatusBadRequest)
+		require.JSONEq(t, "{\"code\":\"ErrInvalidRawHeaders\",\"message\":\"headers must be raw 80 bytes headers, binary or hex encoded\"}", res.Body.String())
+	})
+
+	t.Run("failure - body larger than the bulk headers limit", func(t *testing.T) {
+		for name, key := range map[string]string{"without idempotency key": "", "with idempotency key": "too-large"} {
+			t.Run(name, func(t *testing.T) {
+				// given
+				bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled(), testapp.WithBulkHeadersLimit(1))
+				defer cleanup()
+				hexHeader := hex.EncodeToString(rawHeader(t, fixtures.HeaderSourceHeight5))
+				body, _ := json.Marshal([]string{hexHeader, hexHeader})
+				req, err := submitHeaders(body, "application/json")
+				require.NoError(t, err)
+				if key != "" {
+					req.Header.Set(idempotency.KeyHeader, key)
+				}
+
+				// when
+				res := bhs.API().Call(req)
+
+				// then
+				assert.Equal(t, res.Code, http.StatusRequestEntityTooLarge)
+				require.JSONEq(t, `{"code":"ErrRequestBodyTooLarge","message":"request body is too large"}`, res.Body.String())
+			})
+		}
+	})
+}
+
+func rawHeader(t *testing.T, bs *domains.BlockHeaderSource) []byte {
+	var buf bytes.Buffer
+	header := wire.BlockHeader(*bs)
+	require.NoError(t, header.Serialize(&buf))
+	return buf.Bytes()
+}
+
+func submitHeaders(body []byte, contentType string) (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(
+		context.Background(),
+		http.MethodPost,
+		"/api/v1/chain/header",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
 func getHeaderByHash(hash string) (req *http.Request, err error) {
 	address := fmt.Sprintf("/api/v1/chain/header/%s", hash)
 	return http.NewRequestWithContext(
@@ -397,6 +1040,29 @@ func getHeaderByHeight(height, count int) (req *http.Request, err error) {
 	)
 }
 
+func getHeaderByHeightAccepting(height, count int, accept string) (req *http.Request, err error) {
+	req, err = getHeaderByHeight(height, count)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	return req, nil
+}
+
+func getHeaderByHeightInStates(height, count int, states string) (req *http.Request, err error) {
+	return getHeaderByHeightWithQuery(height, count, "state="+states)
+}
+
+func getHeaderByHeightWithQuery(height, count int, query string) (req *http.Request, err error) {
+	address := fmt.Sprintf("/api/v1/chain/header/byHeight?height=%d&count=%d&%s", height, count, query)
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		address,
+		nil,
+	)
+}
+
 func getHeaderAncestorsByHash(hash, ancestorHash string) (req *http.Request, err error) {
 	address := fmt.Sprintf("/api/v1/chain/header/%s/%s/ancestor", hash, ancestorHash)
 	return http.NewRequestWithContext(
@@ -407,6 +1073,44 @@ func getHeaderAncestorsByHash(hash, ancestorHash string) (req *http.Request, err
 	)
 }
 
+func getChainSegment(ancestorHash, descendantHash, query string) (req *http.Request, err error) {
+	address := fmt.Sprintf("/api/v1/chain/header/between/%s/%s?%s", ancestorHash, descendantHash, query)
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		address,
+		nil,
+	)
+}
+
+func getHeaderAtTime(t string) (req *http.Request, err error) {
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		"/api/v1/chain/header/byTime?time="+url.QueryEscape(t),
+		nil,
+	)
+}
+
+func getHeadersCounts(windows ...string) (req *http.Request, err error) {
+	query := url.Values{"window": windows}
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		"/api/v1/chain/header/counts?"+query.Encode(),
+		nil,
+	)
+}
+
+func getBranchDiff(hash, otherHash string) (req *http.Request, err error) {
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		fmt.Sprintf("/api/v1/chain/header/diff/%s/%s", hash, otherHash),
+		nil,
+	)
+}
+
 func getCommonAncestors(ancestors []string) (req *http.Request, err error) {
 	array, err := json.Marshal(ancestors)
 	if err != nil {
@@ -421,6 +1125,36 @@ func getCommonAncestors(ancestors []string) (req *http.Request, err error) {
 	)
 }
 
+func getHeadersBulk(request headers.BulkHeadersRequest) (req *http.Request, err error) {
+	return getHeadersBulkWithQuery(request, "")
+}
+
+func getHeadersBulkWithQuery(request headers.BulkHeadersRequest, query string) (req *http.Request, err error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodPost,
+		"/api/v1/chain/header/bulk?"+query,
+		bytes.NewReader(body),
+	)
+}
+
+func mapHeaders(request headers.HeadersMappingRequest) (req *http.Request, err error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodPost,
+		"/api/v1/chain/header/mapping",
+		bytes.NewReader(body),
+	)
+}
+
 func getHeadersState(hash string) (req *http.Request, err error) {
 	address := fmt.Sprintf("/api/v1/chain/header/state/%s", hash)
 	return http.NewRequestWithContext(
@@ -0,0 +1,74 @@
+package headers
+
+import (
+	"net/http"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/gin-gonic/gin"
+)
+
+// HeadersMappingRequest defines hashes and heights of the longest chain to map to each other.
+type HeadersMappingRequest struct {
+	Hashes  []string `json:"hashes"`
+	Heights []int32  `json:"heights"`
+}
+
+// HeadersMappingResponse defines headers found by the requested hashes and heights, in the order of the request.
+type HeadersMappingResponse struct {
+	ByHash         []HeaderLocationResponse `json:"byHash"`
+	ByHeight       []HeaderLocationResponse `json:"byHeight"`
+	UnknownHashes  []string                 `json:"unknownHashes"`
+	UnknownHeights []int32                  `json:"unknownHeights"`
+}
+
+// HeaderLocationResponse defines hash, height and state of a header.
+type HeaderLocationResponse struct {
+	Hash   string `json:"hash"`
+	Height int32  `json:"height"`
+	State  string `json:"state"`
+}
+
+// mapHeaders godoc.
+//
+//		@Summary Maps hashes to heights and heights to hashes in bulk
+//		@Description Finds heights and states of headers with given hashes and hashes of the longest chain at given heights.
+//		@Description Hashes and heights which are not found are listed separately, together there can be at most http.bulk_headers_limit of them.
+//		@Tags headers
+//		@Accept json
+//		@Produce json
+//		@Success 200 {object} HeadersMappingResponse
+//		@Router /chain/header/mapping [post]
+//		@Param request body HeadersMappingRequest true "JSON"
+//	 @Security Bearer
+func (h *handler) mapHeaders(c *gin.Context) {
+	var body HeadersMappingRequest
+	if err := c.BindJSON(&body); err != nil {
+		bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), h.log)
+		return
+	}
+	if h.bulkLimit > 0 && len(body.Hashes)+len(body.Heights) > h.bulkLimit {
+		bhserrors.ErrorResponse(c, bhserrors.ErrTooManyMappedHeaders, h.log)
+		return
+	}
+
+	mapping, err := h.service.MapHeaders(body.Hashes, body.Heights)
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, HeadersMappingResponse{
+		ByHash:         headerLocationsResponse(mapping.ByHash),
+		ByHeight:       headerLocationsResponse(mapping.ByHeight),
+		UnknownHashes:  mapping.UnknownHashes,
+		UnknownHeights: mapping.UnknownHeights,
+	})
+}
+
+func headerLocationsResponse(headers []*domains.BlockHeader) []HeaderLocationResponse {
+	res := make([]HeaderLocationResponse, 0, len(headers))
+	for _, h := range headers {
+		res = append(res, HeaderLocationResponse{Hash: h.Hash.String(), Height: h.Height, State: h.State.String()})
+	}
+	return res
+}
@@ -1,7 +1,11 @@
 package headers
 
 import (
+	"bytes"
+	"encoding/hex"
+
 	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 )
 
 // BlockHeaderResponse defines a single block header.
@@ -14,6 +18,14 @@ type BlockHeaderResponse struct {
 	DifficultyTarget uint32 `json:"difficultyTarget"`
 	Nonce            uint32 `json:"nonce"`
 	Work             string `json:"work"`
+	Raw              string `json:"raw,omitempty"`
+}
+
+// BulkHeadersRequest defines a request for headers starting from given height or hash.
+type BulkHeadersRequest struct {
+	Height *int   `json:"height,omitempty"`
+	Hash   string `json:"hash,omitempty"`
+	Count  int    `json:"count"`
 }
 
 // BlockHeaderStateResponse is an extended version of the BlockHeaderResponse
@@ -50,10 +62,113 @@ func mapToBlockHeadersResponses(headers []*domains.BlockHeader) []BlockHeaderRes
 	return blockHeadersResponse
 }
 
+// headerFields are fields of BlockHeaderResponse, together with height and state of the header,
+// which can be picked with the fields parameter. The raw field is the hex encoded serialized header.
+var headerFields = map[string]func(*domains.BlockHeader) any{
+	"hash":              func(h *domains.BlockHeader) any { return h.Hash.String() },
+	"version":           func(h *domains.BlockHeader) any { return h.Version },
+	"prevBlockHash":     func(h *domains.BlockHeader) any { return h.PreviousBlock.String() },
+	"merkleRoot":        func(h *domains.BlockHeader) any { return h.MerkleRoot.String() },
+	"creationTimestamp": func(h *domains.BlockHeader) any { return uint32(h.Timestamp.Unix()) },
+	"difficultyTarget":  func(h *domains.BlockHeader) any { return h.Bits },
+	"nonce":             func(h *domains.BlockHeader) any { return h.Nonce },
+	"work":              func(h *domains.BlockHeader) any { return h.Chainwork.String() },
+	"raw":               func(h *domains.BlockHeader) any { return rawHeader(h) },
+	"height":            func(h *domains.BlockHeader) any { return h.Height },
+	"state":             func(h *domains.BlockHeader) any { return h.State.String() },
+}
+
+// rawHeader serializes the header to 80 bytes in the wire format and encodes them as hex.
+func rawHeader(header *domains.BlockHeader) string {
+	wh := wire.BlockHeader{
+		Version:    header.Version,
+		PrevBlock:  header.PreviousBlock,
+		MerkleRoot: header.MerkleRoot,
+		Timestamp:  header.Timestamp,
+		Bits:       header.Bits,
+		Nonce:      header.Nonce,
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, wire.MaxBlockHeaderPayload))
+	// Writing to bytes.Buffer never fails.
+	_ = wh.Serialize(buf)
+	return hex.EncodeToString(buf.Bytes())
+}
+
+// newSparseBlockHeaderResponse maps a domain BlockHeader to an object containing only given fields.
+func newSparseBlockHeaderResponse(header *domains.BlockHeader, fields []string) map[string]any {
+	response := make(map[string]any, len(fields))
+	for _, f := range fields {
+		response[f] = headerFields[f](header)
+	}
+	return response
+}
+
+// headerResponse maps a domain BlockHeader to a BlockHeaderResponse shaped by response options.
+func headerResponse(header *domains.BlockHeader, opts responseOptions) any {
+	if len(opts.fields) > 0 {
+		return newSparseBlockHeaderResponse(header, opts.fields)
+	}
+
+	response := newBlockHeaderResponse(header)
+	if opts.includeRaw {
+		response.Raw = rawHeader(header)
+	}
+	return response
+}
+
+// headersResponse maps a slice of domain BlockHeader to BlockHeaderResponses shaped by response options.
+func headersResponse(headers []*domains.BlockHeader, opts responseOptions) any {
+	if len(opts.fields) == 0 && !opts.includeRaw {
+		return mapToBlockHeadersResponses(headers)
+	}
+
+	responses := make([]any, 0, len(headers))
+	for _, header := range headers {
+		responses = append(responses, headerResponse(header, opts))
+	}
+	return responses
+}
+
+// HeaderAtTimeResponse defines the header of the longest chain active at some moment.
+type HeaderAtTimeResponse struct {
+	Height         int32  `json:"height"`
+	MedianTimePast uint32 `json:"medianTimePast"`
+	Header         any    `json:"header"`
+}
+
+// newHeaderAtTimeResponse maps a domain HeaderAtTime to a transport HeaderAtTimeResponse.
+func newHeaderAtTimeResponse(h *domains.HeaderAtTime, opts responseOptions) HeaderAtTimeResponse {
+	return HeaderAtTimeResponse{
+		Height:         h.Header.Height,
+		MedianTimePast: uint32(h.MedianTimePast.Unix()),
+		Header:         headerResponse(h.Header, opts),
+	}
+}
+
+// BranchDiffResponse defines headers of two branches which are not shared by them.
+type BranchDiffResponse struct {
+	ForkPoint   any `json:"forkPoint"`
+	Branch      any `json:"branch"`
+	OtherBranch any `json:"otherBranch"`
+}
+
+// newBranchDiffResponse maps a domain BranchDiff to a transport BranchDiffResponse.
+func newBranchDiffResponse(diff *domains.BranchDiff, opts responseOptions) BranchDiffResponse {
+	return BranchDiffResponse{
+		ForkPoint:   headerResponse(diff.ForkPoint, opts),
+		Branch:      headersResponse(diff.Branch, opts),
+		OtherBranch: headersResponse(diff.OtherBranch, opts),
+	}
+}
+
 // newBlockHeaderStateResponse maps a domain BlockHeader to a transport BlockHeaderStateResponse.
-func newBlockHeaderStateResponse(header *domains.BlockHeader) BlockHeaderStateResponse {
+func newBlockHeaderStateResponse(header *domains.BlockHeader, includeRaw bool) BlockHeaderStateResponse {
+	headerResponse := newBlockHeaderResponse(header)
+	if includeRaw {
+		headerResponse.Raw = rawHeader(header)
+	}
 	return BlockHeaderStateResponse{
-		Header:    newBlockHeaderResponse(header),
+		Header:    headerResponse,
 		State:     header.State.String(),
 		ChainWork: header.CumulatedWork.String(),
 		Height:    header.Height,
@@ -0,0 +1,96 @@
+package headers
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+)
+
+// parseHeadersQuery reads the query of header listing endpoints, states are given as repeated
+// or comma separated state parameters, sort names the field and order is either asc or desc.
+func parseHeadersQuery(values url.Values) (domains.HeadersQuery, error) {
+	var query domains.HeadersQuery
+	for _, param := range values["state"] {
+		for _, s := range strings.Split(param, ",") {
+			state, ok := domains.ParseHeaderState(strings.ToUpper(strings.TrimSpace(s)))
+			if !ok {
+				return query, bhserrors.ErrInvalidHeaderState
+			}
+			query.States = append(query.States, state)
+		}
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		field, ok := domains.ParseHeadersSortField(strings.ToLower(sort))
+		if !ok {
+			return query, bhserrors.ErrInvalidHeadersSort
+		}
+		query.SortBy = field
+	}
+
+	switch strings.ToLower(values.Get("order")) {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, bhserrors.ErrInvalidHeadersSort
+	}
+	if query.Descending && query.SortBy == "" {
+		query.SortBy = domains.SortByHeight
+	}
+	return query, nil
+}
+
+// parseTime reads time given either as unix timestamp or as RFC 3339 time.
+func parseTime(s string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, bhserrors.ErrInvalidTimestamp
+	}
+	return t, nil
+}
+
+// responseOptions shape JSON header responses.
+type responseOptions struct {
+	// fields which header responses are trimmed to, all fields are returned when empty.
+	fields []string
+	// includeRaw adds the hex encoded serialized header.
+	includeRaw bool
+}
+
+// parseResponseOptions reads comma separated fields which header responses are trimmed to
+// and whether the serialized header is included.
+func parseResponseOptions(values url.Values) (responseOptions, error) {
+	var opts responseOptions
+	if param := values.Get("includeRaw"); param != "" {
+		includeRaw, err := strconv.ParseBool(param)
+		if err != nil {
+			return opts, bhserrors.ErrInvalidIncludeRaw
+		}
+		opts.includeRaw = includeRaw
+	}
+
+	param := values.Get("fields")
+	if param == "" {
+		return opts, nil
+	}
+	for _, f := range strings.Split(param, ",") {
+		f = strings.TrimSpace(f)
+		if _, ok := headerFields[f]; !ok {
+			return opts, bhserrors.ErrInvalidHeaderFields
+		}
+		opts.fields = append(opts.fields, f)
+	}
+	if opts.includeRaw && !slices.Contains(opts.fields, "raw") {
+		opts.fields = append(opts.fields, "raw")
+	}
+	return opts, nil
+}
@@ -0,0 +1,102 @@
+package headers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/protobuf"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/timeout"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ndjsonContentType is the content type of newline delimited JSON, one header per line.
+	ndjsonContentType = "application/x-ndjson"
+
+	// streamFlushInterval is the number of headers written between flushes of the response.
+	streamFlushInterval = 500
+)
+
+// wantsStream checks if the headers should be streamed instead of being serialized as a whole.
+func (h *handler) wantsStream(c *gin.Context, count int) bool {
+	if acceptsNDJSON(c) {
+		return true
+	}
+	if protobuf.Accepted(c.Request) {
+		return false
+	}
+	return h.streamThreshold > 0 && count > h.streamThreshold
+}
+
+func acceptsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), ndjsonContentType)
+}
+
+// streamHeadersByHeight writes headers as they are read from the database, either as NDJSON
+// or as a JSON array, extending the write deadline after every flushed chunk.
+func (h *handler) streamHeadersByHeight(c *gin.Context, height, count int, query domains.HeadersQuery) {
+	ndjson := acceptsNDJSON(c)
+	opts := optionsOf(c)
+	rc := http.NewResponseController(c.Writer)
+	enc := json.NewEncoder(c.Writer)
+	writeTimeout := timeout.WriteTimeout(c, h.writeTimeout)
+	ctx := c.Request.Context()
+	written := 0
+
+	start := func() {
+		if ndjson {
+			c.Header("Content-Type", ndjsonContentType)
+		} else {
+			c.Header("Content-Type", gin.MIMEJSON+"; charset=utf-8")
+		}
+		c.Status(http.StatusOK)
+		if !ndjson {
+			_, _ = c.Writer.WriteString("[")
+		}
+	}
+
+	err := h.service.StreamHeadersByHeight(height, count, query, func(bh *domains.BlockHeader) error {
+		if ctx.Err() != nil {
+			return bhserrors.ErrRequestTimeout
+		}
+		if written == 0 {
+			start()
+		} else if !ndjson {
+			if _, err := c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+
+		if err := enc.Encode(headerResponse(bh, opts)); err != nil {
+			return err
+		}
+
+		written++
+		if written%streamFlushInterval == 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	switch {
+	case err != nil && written == 0:
+		bhserrors.ErrorResponse(c, err, h.log)
+	case err != nil:
+		// Status and part of the body are already sent, the only thing left is to cut the response.
+		h.log.Error().Msgf("streaming headers from height %d interrupted: %v", height, err)
+		_ = c.Error(err)
+		c.Abort()
+	case written == 0:
+		start()
+		if !ndjson {
+			_, _ = c.Writer.WriteString("]")
+		}
+	case !ndjson:
+		_, _ = c.Writer.WriteString("]")
+	}
+}
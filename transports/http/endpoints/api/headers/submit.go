@@ -0,0 +1,155 @@
+package headers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Statuses of submitted headers.
+const (
+	SubmitStatusAdded       = "ADDED"
+	SubmitStatusExists      = "EXISTS"
+	SubmitStatusRejected    = "REJECTED"
+	SubmitStatusQuarantined = "QUARANTINED"
+)
+
+// submittedHeaderMaxSize is the largest size of a submitted header, a hex encoded one in JSON
+// takes twice as many bytes as a raw one, plus the quotes, a comma and some whitespace.
+const submittedHeaderMaxSize = 2*wire.MaxBlockHeaderPayload + 8
+
+// SubmittedHeaderResponse defines a result of adding a submitted header.
+type SubmittedHeaderResponse struct {
+	Hash   string `json:"hash"`
+	Status string `json:"status"`
+	State  string `json:"state,omitempty"`
+	Height int32  `json:"height"`
+}
+
+// submitHeaders godoc.
+//
+//		@Summary Submits raw headers
+//		@Description Adds raw 80 bytes headers the same way as headers received from peers, parents have to precede their children.
+//		@Description Headers are sent as application/octet-stream or as a JSON array of hex encoded headers.
+//		@Tags headers
+//		@Accept json,octet-stream
+//		@Produce json
+//		@Success 200 {object} []SubmittedHeaderResponse
+//		@Router /chain/header [post]
+//		@Param request body []string true "Hex encoded headers"
+//	 @Security Bearer
+func (h *handler) submitHeaders(c *gin.Context) {
+	raw, err := readRawHeaders(c, h.maxSubmitBody())
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	if h.bulkLimit > 0 && len(raw) > h.bulkLimit {
+		bhserrors.ErrorResponse(c, bhserrors.ErrTooManyHeaders, h.log)
+		return
+	}
+
+	results := make([]SubmittedHeaderResponse, 0, len(raw))
+	for _, r := range raw {
+		var header wire.BlockHeader
+		if err := header.Deserialize(bytes.NewReader(r)); err != nil {
+			bhserrors.ErrorResponse(c, bhserrors.ErrInvalidRawHeaders.Wrap(err), h.log)
+			return
+		}
+
+		result, err := h.addHeader(&header)
+		if err != nil {
+			bhserrors.ErrorResponse(c, bhserrors.ErrAddHeader.Wrap(err), h.log)
+			return
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+func (h *handler) addHeader(header *wire.BlockHeader) (SubmittedHeaderResponse, error) {
+	hash := header.BlockHash().String()
+
+	added, err := h.chains.Add(domains.BlockHeaderSource(*header))
+	switch {
+	case err == nil:
+		return SubmittedHeaderResponse{Hash: hash, Status: SubmitStatusAdded, State: added.State.String(), Height: added.Height}, nil
+	case service.BlockRejected.Is(err):
+		return SubmittedHeaderResponse{Hash: hash, Status: SubmitStatusRejected}, nil
+	case service.BlockQuarantined.Is(err):
+		return SubmittedHeaderResponse{Hash: hash, Status: SubmitStatusQuarantined, Height: added.Height}, nil
+	case service.HeaderAlreadyExists.Is(err):
+		existing, err := h.service.GetHeaderByHash(hash)
+		if err != nil {
+			return SubmittedHeaderResponse{}, err
+		}
+		return SubmittedHeaderResponse{Hash: hash, Status: SubmitStatusExists, State: existing.State.String(), Height: existing.Height}, nil
+	default:
+		return SubmittedHeaderResponse{}, err
+	}
+}
+
+// maxSubmitBody returns the size of the largest body of submitted headers, 0 when the number of headers isn't limited.
+func (h *handler) maxSubmitBody() int64 {
+	if h.bulkLimit <= 0 {
+		return 0
+	}
+	return int64(h.bulkLimit)*submittedHeaderMaxSize + 2
+}
+
+// readRawHeaders reads headers from the body, which is either concatenated binary headers or a JSON array of hex encoded headers.
+// A body larger than maxBody, unless it's 0, is rejected before it's read as a whole.
+func readRawHeaders(c *gin.Context, maxBody int64) ([][]byte, error) {
+	if maxBody > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+	}
+
+	var data []byte
+	if strings.HasPrefix(c.ContentType(), gin.MIMEJSON) {
+		var hexHeaders []string
+		if err := json.NewDecoder(c.Request.Body).Decode(&hexHeaders); err != nil {
+			return nil, bodyError(err)
+		}
+		for _, hh := range hexHeaders {
+			b, err := hex.DecodeString(hh)
+			if err != nil || len(b) != wire.MaxBlockHeaderPayload {
+				return nil, bhserrors.ErrInvalidRawHeaders
+			}
+			data = append(data, b...)
+		}
+	} else {
+		var err error
+		if data, err = io.ReadAll(c.Request.Body); err != nil {
+			return nil, bodyError(err)
+		}
+	}
+
+	if len(data) == 0 || len(data)%wire.MaxBlockHeaderPayload != 0 {
+		return nil, bhserrors.ErrInvalidRawHeaders
+	}
+
+	raw := make([][]byte, 0, len(data)/wire.MaxBlockHeaderPayload)
+	for i := 0; i < len(data); i += wire.MaxBlockHeaderPayload {
+		raw = append(raw, data[i:i+wire.MaxBlockHeaderPayload])
+	}
+	return raw, nil
+}
+
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return bhserrors.ErrRequestBodyTooLarge
+	}
+	return bhserrors.ErrBindBody.Wrap(err)
+}
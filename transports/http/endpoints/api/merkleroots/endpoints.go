@@ -1,6 +1,7 @@
 package merkleroots
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/protobuf"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
@@ -70,16 +72,17 @@ func (h *handler) merkleroots(c *gin.Context) {
 // Verify godoc.
 //
 //	@Summary Verifies Merkle roots inclusion in the longest chain
+//	@Description Merkle roots can be sent and returned as application/x-protobuf messages defined in headers.proto
 //	@Tags merkleroots
-//	@Accept */*
-//	@Produce json
+//	@Accept json,x-protobuf
+//	@Produce json,x-protobuf
 //	@Success 200 {array} merkleroots.ConfirmationsResponse
 //	@Router /chain/merkleroot/verify [post]
 //	@Param request body []domains.MerkleRootConfirmationRequestItem true "JSON"
 //	@Security Bearer
 func (h *handler) verify(c *gin.Context) {
-	var body []domains.MerkleRootConfirmationRequestItem
-	if err := c.BindJSON(&body); err != nil {
+	body, err := readConfirmationRequest(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, err.Error())
 		return
 	}
@@ -90,10 +93,31 @@ func (h *handler) verify(c *gin.Context) {
 	}
 
 	mrcs, err := h.service.GetMerkleRootsConfirmations(body)
-
-	if err == nil {
-		c.JSON(http.StatusOK, mapToMerkleRootsConfirmationsResponses(mrcs))
-	} else {
+	if err != nil {
 		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+
+	res := mapToMerkleRootsConfirmationsResponses(mrcs)
+	if protobuf.Accepted(c.Request) {
+		c.Data(http.StatusOK, protobuf.ContentType, protobuf.MarshalMerkleRootsConfirmations(res.ConfirmationState, mrcs))
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// readConfirmationRequest reads merkle roots to verify either from
+// the MerkleRootConfirmationRequest protobuf message or from JSON.
+func readConfirmationRequest(c *gin.Context) ([]domains.MerkleRootConfirmationRequestItem, error) {
+	if !protobuf.IsRequest(c.Request) {
+		var body []domains.MerkleRootConfirmationRequestItem
+		err := c.BindJSON(&body)
+		return body, err
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
 	}
+	return protobuf.UnmarshalMerkleRootConfirmationRequest(data)
 }
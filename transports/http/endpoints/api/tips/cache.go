@@ -0,0 +1,102 @@
+package tips
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/cache"
+	"github.com/bitcoin-sv/block-headers-service/notification"
+	"github.com/gin-gonic/gin"
+)
+
+// responseCache keeps serialized tip responses for a short time, so clients polling for the tip
+// don't translate directly into database queries. It's cleared whenever a new header is accepted.
+// When a shared cache is set, responses are kept there instead, so all instances serve the same ones.
+type responseCache struct {
+	ttl     time.Duration
+	shared  cache.Shared
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedResponse),
+	}
+}
+
+// Notify implements notification.Channel, invalidating cached responses on any header event.
+func (rc *responseCache) Notify(_ notification.Event) {
+	if rc.shared != nil {
+		rc.shared.Invalidate()
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	clear(rc.entries)
+}
+
+// serve writes the cached response under the key, or the result of the produce func when there is none.
+func (rc *responseCache) serve(c *gin.Context, key string, produce func() (any, error)) error {
+	if body, ok := rc.get(key); ok {
+		c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", body)
+		return nil
+	}
+
+	res, err := produce()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	rc.put(key, body)
+	c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", body)
+	return nil
+}
+
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	if rc.ttl <= 0 {
+		return nil, false
+	}
+
+	if rc.shared != nil {
+		return rc.shared.Get(key)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (rc *responseCache) put(key string, body []byte) {
+	if rc.ttl <= 0 {
+		return
+	}
+
+	if rc.shared != nil {
+		rc.shared.Set(key, body, rc.ttl)
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries[key] = cachedResponse{body: body, expiresAt: time.Now().Add(rc.ttl)}
+}
@@ -5,28 +5,46 @@ import (
 
 	"github.com/bitcoin-sv/block-headers-service/bhserrors"
 	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/cache"
+	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/etag"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
 
+const (
+	tipsCacheKey       = "tips"
+	longestTipCacheKey = "longest"
+)
+
 type handler struct {
-	service service.Headers
-	log     *zerolog.Logger
+	service  service.Headers
+	notifier *notification.Notifier
+	shared   cache.Shared
+	cache    *responseCache
+	log      *zerolog.Logger
 }
 
 // NewHandler creates new endpoint handler.
 func NewHandler(s *service.Services) router.APIEndpoints {
-	return &handler{service: s.Headers, log: s.Logger}
+	return &handler{service: s.Headers, notifier: s.Notifier, shared: s.SharedCache, cache: newResponseCache(0), log: s.Logger}
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
+	if cfg.TipCacheTTL > 0 && h.notifier != nil {
+		h.cache = newResponseCache(cfg.TipCacheTTL)
+		h.cache.shared = h.shared
+		h.notifier.AddChannel(h.cache)
+	}
+
 	tip := router.Group("/chain")
 	{
-		tip.GET("/tip", h.getTips)
-		tip.GET("/tip/longest", h.getTipLongestChain)
+		tip.GET("/tip", etag.Middleware(), h.getTips)
+		tip.GET("/tip/longest", etag.Middleware(), h.getTipLongestChain)
+		tip.GET("/forks", h.getForks)
 	}
 }
 
@@ -40,11 +58,15 @@ func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPCo
 //	@Router /chain/tip [get]
 //	@Security Bearer
 func (h *handler) getTips(c *gin.Context) {
-	tips, err := h.service.GetTips()
+	err := h.cache.serve(c, tipsCacheKey, func() (any, error) {
+		tips, err := h.service.GetTips()
+		if err != nil {
+			return nil, err
+		}
+		return mapToTipStateResponse(tips), nil
+	})
 
-	if err == nil {
-		c.JSON(http.StatusOK, mapToTipStateResponse(tips))
-	} else {
+	if err != nil {
 		bhserrors.ErrorResponse(c, err, h.log)
 	}
 }
@@ -59,6 +81,29 @@ func (h *handler) getTips(c *gin.Context) {
 //	@Router /chain/tip/longest [get]
 //	@Security Bearer
 func (h *handler) getTipLongestChain(c *gin.Context) {
-	tip := h.service.GetTip()
-	c.JSON(http.StatusOK, newTipStateResponse(tip))
+	err := h.cache.serve(c, longestTipCacheKey, func() (any, error) {
+		return newTipStateResponse(h.service.GetTip()), nil
+	})
+
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
+// getForks godoc.
+//
+//	@Summary Gets all known forks of the longest chain
+//	@Tags tip
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {array} []ForkResponse
+//	@Router /chain/forks [get]
+//	@Security Bearer
+func (h *handler) getForks(c *gin.Context) {
+	forks, err := h.service.GetForks()
+	if err != nil {
+		bhserrors.ErrorResponse(c, err, h.log)
+		return
+	}
+	c.JSON(http.StatusOK, mapToForkResponse(forks))
 }
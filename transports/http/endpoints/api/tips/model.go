@@ -61,3 +61,44 @@ func mapToTipStateResponse(headers []*domains.BlockHeader) []TipStateResponse {
 
 	return blockHeaderStatesResponse
 }
+
+// ForkPointResponse defines the header of the longest chain a fork branches off.
+type ForkPointResponse struct {
+	Hash   string `json:"hash"`
+	Height int32  `json:"height"`
+}
+
+// ForkResponse defines a stale chain competing with the longest chain.
+type ForkResponse struct {
+	Tip TipStateResponse `json:"tip"`
+	// ForkPoint is missing for an orphan fork.
+	ForkPoint *ForkPointResponse `json:"forkPoint,omitempty"`
+	Length    int32              `json:"length"`
+	// Orphan is set when a header of the fork is missing, so it can't be traced back to the longest chain.
+	Orphan bool `json:"orphan,omitempty"`
+	// WorkDeficit is the cumulated work the fork lacks to overtake the longest chain.
+	WorkDeficit *big.Int `json:"workDeficit" swaggertype:"string"`
+}
+
+// mapToForkResponse maps a slice of domain Fork to a slice of transport ForkResponse.
+func mapToForkResponse(forks []*domains.Fork) []ForkResponse {
+	forksResponse := make([]ForkResponse, 0, len(forks))
+
+	for _, fork := range forks {
+		response := ForkResponse{
+			Tip:         newTipStateResponse(fork.Tip),
+			Length:      fork.Length,
+			Orphan:      fork.Orphan,
+			WorkDeficit: fork.WorkDeficit,
+		}
+		if fork.ForkPoint != nil {
+			response.ForkPoint = &ForkPointResponse{
+				Hash:   fork.ForkPoint.Hash.String(),
+				Height: fork.ForkPoint.Height,
+			}
+		}
+		forksResponse = append(forksResponse, response)
+	}
+
+	return forksResponse
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"math/big"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/block-headers-service/domains"
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
@@ -122,6 +124,103 @@ func TestGetTipLongest(t *testing.T) {
 
 		assert.Equal(t, tip, expectedResult.body)
 	})
+
+	t.Run("cached tip is refreshed when new header is added", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled(), testapp.WithTipCacheTTL(time.Minute))
+		defer cleanup()
+
+		var tip tips.TipStateResponse
+		res := bhs.API().Call(getTipLongestChain())
+		json.NewDecoder(res.Body).Decode(&tip)
+		assert.Equal(t, tip.Height, 4)
+
+		// when
+		err := bhs.When().NewHeaderReceived(*fixtures.HeaderSourceHeight5)
+		assert.NoError(t, err)
+
+		// then
+		require.Eventually(t, func() bool {
+			res := bhs.API().Call(getTipLongestChain())
+			json.NewDecoder(res.Body).Decode(&tip)
+			return tip.Height == 5
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestGetTipLongestFromSharedCache(t *testing.T) {
+	// given
+	shared := newMapSharedCache()
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t,
+		testapp.WithLongestChain(),
+		testapp.WithAPIAuthorizationDisabled(),
+		testapp.WithTipCacheTTL(time.Minute),
+		testapp.WithSharedCache(shared),
+	)
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(getTipLongestChain())
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	require.JSONEq(t, res.Body.String(), string(shared.get("longest")))
+
+	// when
+	err := bhs.When().NewHeaderReceived(*fixtures.HeaderSourceHeight5)
+
+	// then
+	assert.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return shared.get("longest") == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGetForks(t *testing.T) {
+	t.Run("success - no forks", func(t *testing.T) {
+		// given
+		bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithLongestChain(), testapp.WithAPIAuthorizationDisabled())
+		defer cleanup()
+
+		// when
+		res := bhs.API().Call(getForks())
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		require.JSONEq(t, "[]", res.Body.String())
+	})
+}
+
+type mapSharedCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newMapSharedCache() *mapSharedCache {
+	return &mapSharedCache{entries: make(map[string][]byte)}
+}
+
+func (c *mapSharedCache) Get(key string) ([]byte, bool) {
+	value := c.get(key)
+	return value, value != nil
+}
+
+func (c *mapSharedCache) Set(key string, value []byte, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = value
+}
+
+func (c *mapSharedCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+func (c *mapSharedCache) get(key string) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key]
 }
 
 func getTips() (req *http.Request, err error) {
@@ -141,3 +240,12 @@ func getTipLongestChain() (req *http.Request, err error) {
 		nil,
 	)
 }
+
+func getForks() (req *http.Request, err error) {
+	return http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		"/api/v1/chain/forks",
+		nil,
+	)
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/notification"
 	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 )
@@ -17,25 +19,28 @@ type Webhooks interface {
 	CreateWebhook(authType, header, token, url string) (*notification.Webhook, error)
 	DeleteWebhook(value string) error
 	GetWebhookByURL(url string) (*notification.Webhook, error)
+	GetWebhooks() ([]*notification.Webhook, error)
 }
 
 type handler struct {
-	service Webhooks
-	log     *zerolog.Logger
+	service     Webhooks
+	idempotency service.Idempotency
+	log         *zerolog.Logger
 }
 
 // NewHandler creates new endpoint handler.
 func NewHandler(s *service.Services) router.APIEndpoints {
-	return &handler{service: s.Webhooks, log: s.Logger}
+	return &handler{service: s.Webhooks, idempotency: s.Idempotency, log: s.Logger}
 }
 
 // RegisterAPIEndpoints registers routes that are part of service API.
-func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, _ *config.HTTPConfig) {
+func (h *handler) RegisterAPIEndpoints(router *gin.RouterGroup, cfg *config.HTTPConfig) {
 	webhooks := router.Group("/webhook")
 	{
-		webhooks.POST("", h.registerWebhook)
+		webhooks.POST("", idempotency.Handler(h.idempotency, h.registerWebhook, idempotency.DefaultMaxBody, h.log))
 		webhooks.GET("", h.getWebhook)
-		webhooks.DELETE("", h.revokeWebhook)
+		webhooks.GET("/list", auth.RequireAdmin(h.listWebhooks, cfg.UseAuth))
+		webhooks.DELETE("", idempotency.Handler(h.idempotency, h.revokeWebhook, idempotency.DefaultMaxBody, h.log))
 	}
 }
 
@@ -97,6 +102,27 @@ func (h *handler) getWebhook(c *gin.Context) {
 	}
 }
 
+// listWebhooks godoc.
+//
+//	@Summary List webhooks
+//	@Description Returns all registered webhooks, requires the admin token
+//	@Tags webhooks
+//	@Accept */*
+//	@Produce json
+//	@Success 200 {object} []notification.Webhook
+//	@Router /webhook/list [get]
+//
+// @Security Bearer
+func (h *handler) listWebhooks(c *gin.Context) {
+	webhooks, err := h.service.GetWebhooks()
+
+	if err == nil {
+		c.JSON(http.StatusOK, webhooks)
+	} else {
+		bhserrors.ErrorResponse(c, err, h.log)
+	}
+}
+
 // revokeWebhook godoc.
 //
 //	@Summary Revoke webhook
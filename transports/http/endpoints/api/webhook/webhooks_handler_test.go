@@ -11,6 +11,7 @@ import (
 
 	"github.com/bitcoin-sv/block-headers-service/internal/tests/testapp"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/webhook"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,6 +65,31 @@ func TestMultipleIdenticalWebhooks(t *testing.T) {
 	require.JSONEq(t, expectedBodyResponse, res2.Body.String())
 }
 
+// TestCreateWebhookWithIdempotencyKey tests that a retried registration returns the first response.
+func TestCreateWebhookWithIdempotencyKey(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(createWebhookWithIdempotencyKey("retried", webhookURL))
+	retried := bhs.API().Call(createWebhookWithIdempotencyKey("retried", webhookURL))
+
+	// then
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, http.StatusOK, retried.Code)
+	require.Equal(t, "true", retried.Header().Get(idempotency.ReplayedHeader))
+	require.Empty(t, res.Header().Get(idempotency.ReplayedHeader))
+	require.JSONEq(t, res.Body.String(), retried.Body.String())
+
+	// when
+	reused := bhs.API().Call(createWebhookWithIdempotencyKey("retried", "http://localhost:8080/other"))
+
+	// then
+	require.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	require.JSONEq(t, `{"code":"ErrIdempotencyKeyMismatch","message":"idempotency key was already used with a different request"}`, reused.Body.String())
+}
+
 // TestRevokeWebhookEndpoint tests the webhook revocation.
 func TestRevokeWebhookEndpoint(t *testing.T) {
 	// setup
@@ -92,6 +118,36 @@ func TestRevokeWebhookEndpoint(t *testing.T) {
 	}
 }
 
+// TestListWebhooksEndpoint tests listing of registered webhooks.
+func TestListWebhooksEndpoint(t *testing.T) {
+	// setup
+	bhs, cleanup := testapp.NewTestBlockHeaderService(t, testapp.WithAPIAuthorizationDisabled())
+	defer cleanup()
+
+	// when
+	res := bhs.API().Call(createWebhook())
+
+	// then
+	if res.Code != http.StatusOK {
+		t.Fatalf("Expected to get status %d but instead got %d\n", http.StatusOK, res.Code)
+	}
+
+	// when
+	res2 := bhs.API().Call(listWebhooks())
+
+	// then
+	if res2.Code != http.StatusOK {
+		t.Fatalf("Expected to get status %d but instead got %d\n", http.StatusOK, res2.Code)
+	}
+
+	var webhooks []struct {
+		URL string `json:"url"`
+	}
+	require.NoError(t, json.NewDecoder(res2.Body).Decode(&webhooks))
+	require.Len(t, webhooks, 1)
+	require.Equal(t, webhookURL, webhooks[0].URL)
+}
+
 func createWebhook() (req *http.Request, err error) {
 	webhookBytes, err := json.Marshal(&preparedWebhook)
 	if err != nil {
@@ -102,7 +158,25 @@ func createWebhook() (req *http.Request, err error) {
 	return
 }
 
+func createWebhookWithIdempotencyKey(key, url string) (req *http.Request, err error) {
+	w := preparedWebhook
+	w.URL = url
+	webhookBytes, err := json.Marshal(&w)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal webhook: %w", err)
+	}
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/webhook", bytes.NewReader(webhookBytes))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(idempotency.KeyHeader, key)
+	return
+}
+
 func revokeWebhook(url string) (req *http.Request, err error) {
 	req, err = http.NewRequestWithContext(context.Background(), http.MethodDelete, "/api/v1/webhook?url="+url, nil)
 	return
 }
+
+func listWebhooks() (req *http.Request, err error) {
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/webhook/list", nil)
+	return
+}
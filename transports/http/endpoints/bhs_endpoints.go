@@ -6,7 +6,11 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/auth"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/compression"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/access"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/admin"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/archive"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/difficulty"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/merkleroots"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/network"
@@ -16,7 +20,10 @@ import (
 	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/status"
 	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/swagger"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/leader"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/maintenance"
 	httpserver "github.com/bitcoin-sv/block-headers-service/transports/http/server"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/timeout"
 	"github.com/gin-gonic/gin"
 )
 
@@ -31,15 +38,18 @@ func SetupRoutes(s *service.Services, cfg *config.HTTPConfig) httpserver.GinEngi
 		headers.NewHandler(s),
 		network.NewHandler(s),
 		tips.NewHandler(s),
+		difficulty.NewHandler(s),
 		webhook.NewHandler(s),
 		merkleroots.NewHandler(s),
+		archive.NewHandler(s),
+		admin.NewHandler(s),
 	}
 
 	if cfg.ProfilingEndpointsEnabled {
 		routes = append(routes, profile.NewHandler(s))
 	}
 
-	apiMiddlewares := toHandlers(auth.NewMiddleware(s, cfg))
+	apiMiddlewares := toHandlers(newAPIMiddlewares(s, cfg)...)
 
 	return func(engine *gin.Engine) {
 		rootRouter := engine.Group("")
@@ -58,6 +68,43 @@ func SetupRoutes(s *service.Services, cfg *config.HTTPConfig) httpserver.GinEngi
 	}
 }
 
+// SetupNetworkRoutes registers API endpoints of an additional network under /api/v1/{network}.
+// Root endpoints like status or swagger are registered only once by SetupRoutes.
+func SetupNetworkRoutes(networkName string, s *service.Services, cfg *config.HTTPConfig) httpserver.GinEngineOpt {
+	routes := []router.APIEndpoints{
+		access.NewHandler(s),
+		headers.NewHandler(s),
+		network.NewHandler(s),
+		tips.NewHandler(s),
+		difficulty.NewHandler(s),
+		webhook.NewHandler(s),
+		merkleroots.NewHandler(s),
+		archive.NewHandler(s),
+	}
+
+	apiMiddlewares := toHandlers(newAPIMiddlewares(s, cfg)...)
+
+	return func(engine *gin.Engine) {
+		apiRouter := engine.Group("/api/v1/"+networkName, apiMiddlewares...)
+		for _, r := range routes {
+			r.RegisterAPIEndpoints(apiRouter, cfg)
+		}
+	}
+}
+
+func newAPIMiddlewares(s *service.Services, cfg *config.HTTPConfig) []router.APIMiddleware {
+	middlewares := make([]router.APIMiddleware, 0)
+	if len(cfg.RouteTimeouts) > 0 {
+		// Deadlines are set before anything reads the request body.
+		middlewares = append(middlewares, timeout.NewMiddleware(cfg))
+	}
+	middlewares = append(middlewares, auth.NewMiddleware(s, cfg), leader.NewMiddleware(s), maintenance.NewMiddleware(s))
+	if cfg.Compression.Enabled {
+		middlewares = append(middlewares, compression.NewMiddleware(&cfg.Compression))
+	}
+	return middlewares
+}
+
 func toHandlers(middlewares ...router.APIMiddleware) []gin.HandlerFunc {
 	result := make([]gin.HandlerFunc, 0)
 	for _, m := range middlewares {
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryRoutes are routes which are requested with POST only to pass a query in the body, they don't write anything.
+var queryRoutes = []string{
+	"/chain/header/commonAncestor",
+	"/chain/header/bulk",
+	"/chain/header/mapping",
+	"/chain/merkleroot/verify",
+}
+
+// InstanceRoutes are write routes switching the state of the instance serving them, not the stored data. They're
+// served in the maintenance mode, so it can be disabled again, and on followers, which have their own mode and sync.
+var InstanceRoutes = []string{
+	"/admin/maintenance/mode",
+	"/admin/sync/pause",
+}
+
+// IsWriteRequest tells whether the request may write, which are requests with methods other than GET, HEAD and OPTIONS
+// except the queries passed in the body of POST requests.
+func IsWriteRequest(c *gin.Context) bool {
+	method := c.Request.Method
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return false
+	}
+	return !HasRouteSuffix(c.FullPath(), queryRoutes)
+}
+
+// HasRouteSuffix tells whether the full path of a route ends with any of the routes, regardless of the network prefix.
+func HasRouteSuffix(fullPath string, routes []string) bool {
+	for _, route := range routes {
+		if strings.HasSuffix(fullPath, route) {
+			return true
+		}
+	}
+	return false
+}
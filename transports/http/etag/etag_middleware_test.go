@@ -0,0 +1,72 @@
+package etag_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/etag"
+	"github.com/gin-gonic/gin"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/ok", etag.Middleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"hash": "abc"})
+	})
+	engine.GET("/missing", etag.Middleware(), func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"code": "ErrHeaderNotFound"})
+	})
+
+	t.Run("sets etag on successful response", func(t *testing.T) {
+		// when
+		res := call(engine, "/ok", "")
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		assert.NotEqual(t, res.Header().Get("ETag"), "")
+		assert.Equal(t, res.Body.String(), `{"hash":"abc"}`)
+	})
+
+	t.Run("responds with not modified when etag matches", func(t *testing.T) {
+		// given
+		tag := call(engine, "/ok", "").Header().Get("ETag")
+
+		// when
+		res := call(engine, "/ok", tag)
+
+		// then
+		assert.Equal(t, res.Code, http.StatusNotModified)
+		assert.Equal(t, res.Body.Len(), 0)
+	})
+
+	t.Run("responds with body when etag doesn't match", func(t *testing.T) {
+		// when
+		res := call(engine, "/ok", `"outdated"`)
+
+		// then
+		assert.Equal(t, res.Code, http.StatusOK)
+		assert.Equal(t, res.Body.String(), `{"hash":"abc"}`)
+	})
+
+	t.Run("doesn't set etag on error response", func(t *testing.T) {
+		// when
+		res := call(engine, "/missing", "")
+
+		// then
+		assert.Equal(t, res.Code, http.StatusNotFound)
+		assert.Equal(t, res.Header().Get("ETag"), "")
+	})
+}
+
+func call(engine *gin.Engine, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+	return res
+}
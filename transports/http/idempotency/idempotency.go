@@ -0,0 +1,145 @@
+// Package idempotency provides replaying responses of mutating requests retried with the same Idempotency-Key header.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// KeyHeader is the request header with the key identifying a request and its retries.
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader is set on responses which were stored for an earlier request with the same key.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+
+	// DefaultMaxBody is the largest body of a request read to identify it, enough for the JSON bodies of the mutating endpoints.
+	DefaultMaxBody = 1 << 20
+)
+
+// Handler wraps the handler of a mutating endpoint, so a request sent again with the same Idempotency-Key header
+// gets the stored response instead of being processed twice. Requests without the header are processed as usual,
+// and so are retries of requests which failed with a server error. Requests with a body larger than maxBody, unless it's 0, are rejected.
+func Handler(keys service.Idempotency, handler gin.HandlerFunc, maxBody int64, log *zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(KeyHeader)
+		if key == "" || keys == nil || !keys.Enabled() {
+			handler(c)
+			return
+		}
+		if len(key) > maxKeyLength {
+			bhserrors.ErrorResponse(c, bhserrors.ErrInvalidIdempotencyKey, log)
+			return
+		}
+
+		hash, err := requestHash(c, maxBody)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			bhserrors.ErrorResponse(c, bhserrors.ErrRequestBodyTooLarge, log)
+			return
+		}
+		if err != nil {
+			bhserrors.ErrorResponse(c, bhserrors.ErrBindBody.Wrap(err), log)
+			return
+		}
+
+		stored, err := keys.Begin(key, hash)
+		if err != nil {
+			bhserrors.ErrorResponse(c, err, log)
+			return
+		}
+		if stored != nil {
+			c.Header(ReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if !completed {
+				release(keys, key, log)
+			}
+		}()
+
+		handler(c)
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		err = keys.Complete(&domains.IdempotencyKey{
+			Key:         key,
+			RequestHash: hash,
+			StatusCode:  w.Status(),
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		})
+		if err != nil {
+			// The key is released, so a retry is processed again instead of waiting for a response which is never stored.
+			if log != nil {
+				log.Error().Msgf("failed to store response of request with idempotency key %s: %v", key, err)
+			}
+			return
+		}
+		completed = true
+	}
+}
+
+func release(keys service.Idempotency, key string, log *zerolog.Logger) {
+	if err := keys.Release(key); err != nil && log != nil {
+		log.Error().Msgf("failed to release idempotency key %s: %v", key, err)
+	}
+}
+
+// requestHash identifies the request by its method, URI, credentials and body, which is read and put back for the handler.
+// The credentials are included, so a key used by one client doesn't replay its response to another.
+func requestHash(c *gin.Context, maxBody int64) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		if maxBody > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+		}
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	for _, part := range []string{c.Request.Method, c.Request.URL.RequestURI(), c.GetHeader("Authorization")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordingWriter passes the response through and keeps a copy of its body.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,53 @@
+package idempotency_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+// failingKeys begins every key and fails to store the responses.
+type failingKeys struct {
+	released []string
+}
+
+func (k *failingKeys) Enabled() bool { return true }
+
+func (k *failingKeys) Begin(string, string) (*domains.IdempotencyKey, error) { return nil, nil }
+
+func (k *failingKeys) Complete(*domains.IdempotencyKey) error { return errors.New("database is down") }
+
+func (k *failingKeys) Release(key string) error {
+	k.released = append(k.released, key)
+	return nil
+}
+
+func (k *failingKeys) Start() {}
+
+func (k *failingKeys) Stop() {}
+
+func TestHandlerReleasesKeyWhenResponseIsNotStored(t *testing.T) {
+	// given
+	gin.SetMode(gin.TestMode)
+	keys := &failingKeys{}
+	engine := gin.New()
+	engine.POST("/", idempotency.Handler(keys, func(c *gin.Context) { c.Status(http.StatusOK) }, idempotency.DefaultMaxBody, nil))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set(idempotency.KeyHeader, "key")
+
+	// when
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	// then
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, len(keys.released), 1)
+	assert.Equal(t, keys.released[0], "key")
+}
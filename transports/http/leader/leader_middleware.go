@@ -0,0 +1,31 @@
+// Package leader provides rejecting write requests on instances which don't hold the leader lease.
+package leader
+
+import (
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware rejects write requests on followers, so headers, tokens and webhooks are written by the leader only.
+type Middleware struct {
+	elector *service.LeaderElector
+}
+
+// NewMiddleware creates Middleware of the leader elector of the services, it accepts all requests without one.
+func NewMiddleware(s *service.Services) *Middleware {
+	return &Middleware{elector: s.Leader}
+}
+
+// ApplyToAPI is a middleware which rejects write requests with ErrNotLeader while the instance isn't the leader,
+// except requests switching the maintenance mode or the sync pause of the instance itself.
+func (m *Middleware) ApplyToAPI(c *gin.Context) {
+	if m.elector == nil || m.elector.IsLeader() || !router.IsWriteRequest(c) {
+		return
+	}
+	if router.HasRouteSuffix(c.FullPath(), router.InstanceRoutes) {
+		return
+	}
+	bhserrors.AbortWithErrorResponse(c, bhserrors.ErrNotLeader, nil)
+}
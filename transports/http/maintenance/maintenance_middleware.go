@@ -0,0 +1,40 @@
+// Package maintenance provides rejecting write requests while the service is in the maintenance mode.
+package maintenance
+
+import (
+	"github.com/bitcoin-sv/block-headers-service/bhserrors"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	router "github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/routes"
+	"github.com/gin-gonic/gin"
+)
+
+// ModeHeader is the response header set while the maintenance mode is enabled, the served data may be stale then.
+const ModeHeader = "X-Maintenance-Mode"
+
+// Middleware marks responses and rejects write requests while the maintenance mode is enabled.
+type Middleware struct {
+	maintenance service.Maintenance
+}
+
+// NewMiddleware creates Middleware of the maintenance mode held by the maintenance service.
+func NewMiddleware(s *service.Services) *Middleware {
+	return &Middleware{maintenance: s.Maintenance}
+}
+
+// ApplyToAPI is a middleware which sets the maintenance mode header and rejects write requests with ErrMaintenanceMode,
+// or ErrLowDiskSpace when the mode is entered because of low free disk space.
+func (m *Middleware) ApplyToAPI(c *gin.Context) {
+	if !m.maintenance.ModeEnabled() {
+		return
+	}
+
+	c.Header(ModeHeader, "true")
+	if !router.IsWriteRequest(c) || router.HasRouteSuffix(c.FullPath(), router.InstanceRoutes) {
+		return
+	}
+	if m.maintenance.LowDiskSpace() {
+		bhserrors.AbortWithErrorResponse(c, bhserrors.ErrLowDiskSpace, nil)
+		return
+	}
+	bhserrors.AbortWithErrorResponse(c, bhserrors.ErrMaintenanceMode, nil)
+}
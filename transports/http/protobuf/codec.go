@@ -0,0 +1,191 @@
+// Package protobuf encodes messages defined in headers.proto, which are served by REST endpoints
+// as an alternative to JSON for clients accepting application/x-protobuf.
+package protobuf
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ContentType is the content type of protobuf requests and responses.
+const ContentType = "application/x-protobuf"
+
+// Field numbers of messages in headers.proto.
+const (
+	blockHeaderHash              protowire.Number = 1
+	blockHeaderVersion           protowire.Number = 2
+	blockHeaderPrevBlockHash     protowire.Number = 3
+	blockHeaderMerkleRoot        protowire.Number = 4
+	blockHeaderCreationTimestamp protowire.Number = 5
+	blockHeaderDifficultyTarget  protowire.Number = 6
+	blockHeaderNonce             protowire.Number = 7
+	blockHeaderWork              protowire.Number = 8
+
+	blockHeadersHeaders protowire.Number = 1
+
+	requestItemMerkleRoot  protowire.Number = 1
+	requestItemBlockHeight protowire.Number = 2
+
+	requestItems protowire.Number = 1
+
+	confirmationBlockHash    protowire.Number = 1
+	confirmationBlockHeight  protowire.Number = 2
+	confirmationMerkleRoot   protowire.Number = 3
+	confirmationConfirmation protowire.Number = 4
+
+	confirmationsState         protowire.Number = 1
+	confirmationsConfirmations protowire.Number = 2
+)
+
+var errMalformed = errors.New("malformed protobuf message")
+
+// Accepted checks if the client accepts protobuf responses.
+func Accepted(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), ContentType)
+}
+
+// IsRequest checks if the request body is encoded with protobuf.
+func IsRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), ContentType)
+}
+
+// MarshalBlockHeaders encodes headers as the BlockHeaders message.
+func MarshalBlockHeaders(headers []*domains.BlockHeader) []byte {
+	var b []byte
+	for _, h := range headers {
+		var m []byte
+		m = appendHash(m, blockHeaderHash, h.Hash.String())
+		m = appendInt32(m, blockHeaderVersion, h.Version)
+		m = appendHash(m, blockHeaderPrevBlockHash, h.PreviousBlock.String())
+		m = appendHash(m, blockHeaderMerkleRoot, h.MerkleRoot.String())
+		m = appendUint32(m, blockHeaderCreationTimestamp, uint32(h.Timestamp.Unix()))
+		m = appendUint32(m, blockHeaderDifficultyTarget, h.Bits)
+		m = appendUint32(m, blockHeaderNonce, h.Nonce)
+		m = appendString(m, blockHeaderWork, h.Chainwork.String())
+
+		b = protowire.AppendTag(b, blockHeadersHeaders, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+	return b
+}
+
+// MarshalMerkleRootsConfirmations encodes the result of merkle roots verification as the MerkleRootsConfirmations message.
+func MarshalMerkleRootsConfirmations(state domains.MerkleRootConfirmationState, confirmations []*domains.MerkleRootConfirmation) []byte {
+	b := appendString(nil, confirmationsState, string(state))
+	for _, c := range confirmations {
+		var m []byte
+		m = appendHash(m, confirmationBlockHash, c.Hash)
+		m = appendInt32(m, confirmationBlockHeight, c.BlockHeight)
+		m = appendHash(m, confirmationMerkleRoot, c.MerkleRoot)
+		m = appendString(m, confirmationConfirmation, string(c.Confirmation))
+
+		b = protowire.AppendTag(b, confirmationsConfirmations, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+	return b
+}
+
+// UnmarshalMerkleRootConfirmationRequest decodes the MerkleRootConfirmationRequest message.
+func UnmarshalMerkleRootConfirmationRequest(b []byte) ([]domains.MerkleRootConfirmationRequestItem, error) {
+	items := make([]domains.MerkleRootConfirmationRequestItem, 0)
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		if num != requestItems || typ != protowire.BytesType {
+			return skip(num, typ, v)
+		}
+		m, n := protowire.ConsumeBytes(v)
+		if n < 0 {
+			return n, errMalformed
+		}
+		item, err := unmarshalRequestItem(m)
+		if err != nil {
+			return n, err
+		}
+		items = append(items, item)
+		return n, nil
+	})
+	return items, err
+}
+
+func unmarshalRequestItem(b []byte) (domains.MerkleRootConfirmationRequestItem, error) {
+	var item domains.MerkleRootConfirmationRequestItem
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		switch {
+		case num == requestItemMerkleRoot && typ == protowire.BytesType:
+			root, n := protowire.ConsumeBytes(v)
+			item.MerkleRoot = hex.EncodeToString(root)
+			return n, nil
+		case num == requestItemBlockHeight && typ == protowire.VarintType:
+			height, n := protowire.ConsumeVarint(v)
+			item.BlockHeight = int32(height)
+			return n, nil
+		default:
+			return skip(num, typ, v)
+		}
+	})
+	return item, err
+}
+
+// consumeFields calls fn with the number, type and encoded value of every field of the message,
+// fn returns the length of the value it consumed.
+func consumeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errMalformed
+		}
+		b = b[n:]
+
+		n, err := fn(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return errMalformed
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func skip(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+	return protowire.ConsumeFieldValue(num, typ, v), nil
+}
+
+// appendHash appends the hash as bytes in the byte order of its hex representation, fields with zero value are omitted.
+func appendHash(b []byte, num protowire.Number, hash string) []byte {
+	raw, err := hex.DecodeString(hash)
+	if err != nil || len(raw) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, raw)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendInt32(b []byte, num protowire.Number, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(v)))
+}
+
+func appendUint32(b []byte, num protowire.Number, v uint32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
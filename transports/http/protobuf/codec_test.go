@@ -0,0 +1,143 @@
+package protobuf
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestMarshalBlockHeaders(t *testing.T) {
+	// given
+	db, _ := fixtures.LongestChain()
+	headers := []*domains.BlockHeader{&db[1], &db[2]}
+
+	// when
+	b := MarshalBlockHeaders(headers)
+
+	// then
+	decoded := make([]map[protowire.Number][]byte, 0)
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		assert.Equal(t, num, blockHeadersHeaders)
+		m, n := protowire.ConsumeBytes(v)
+		decoded = append(decoded, decodeMessage(t, m))
+		return n, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(decoded), 2)
+
+	for i, h := range headers {
+		hash, _ := hex.DecodeString(h.Hash.String())
+		prev, _ := hex.DecodeString(h.PreviousBlock.String())
+		assert.EqualBytes(t, decoded[i][blockHeaderHash], hash)
+		assert.EqualBytes(t, decoded[i][blockHeaderPrevBlockHash], prev)
+		assert.Equal(t, string(decoded[i][blockHeaderWork]), h.Chainwork.String())
+		assert.Equal(t, varint(t, decoded[i][blockHeaderNonce]), uint64(h.Nonce))
+		assert.Equal(t, varint(t, decoded[i][blockHeaderCreationTimestamp]), uint64(h.Timestamp.Unix()))
+	}
+}
+
+func TestMarshalMerkleRootsConfirmations(t *testing.T) {
+	// given
+	confirmations := []*domains.MerkleRootConfirmation{
+		{
+			Hash:         fixtures.HashHeight1.String(),
+			BlockHeight:  1,
+			MerkleRoot:   fixtures.HeaderSourceHeight1.MerkleRoot.String(),
+			Confirmation: domains.Confirmed,
+		},
+		{
+			BlockHeight:  2,
+			MerkleRoot:   fixtures.HeaderSourceHeight1.MerkleRoot.String(),
+			Confirmation: domains.Invalid,
+		},
+	}
+
+	// when
+	b := MarshalMerkleRootsConfirmations(domains.Invalid, confirmations)
+
+	// then
+	var state string
+	decoded := make([]map[protowire.Number][]byte, 0)
+	err := consumeFields(b, func(num protowire.Number, _ protowire.Type, v []byte) (int, error) {
+		m, n := protowire.ConsumeBytes(v)
+		if num == confirmationsState {
+			state = string(m)
+		} else {
+			decoded = append(decoded, decodeMessage(t, m))
+		}
+		return n, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, state, string(domains.Invalid))
+	assert.Equal(t, len(decoded), 2)
+
+	_, hashSet := decoded[1][confirmationBlockHash]
+	assert.Equal(t, hashSet, false)
+	assert.Equal(t, varint(t, decoded[1][confirmationBlockHeight]), 2)
+	assert.Equal(t, string(decoded[1][confirmationConfirmation]), string(domains.Invalid))
+}
+
+func TestUnmarshalMerkleRootConfirmationRequest(t *testing.T) {
+	// given
+	root := fixtures.HeaderSourceHeight1.MerkleRoot.String()
+	rawRoot, _ := hex.DecodeString(root)
+
+	var item []byte
+	item = protowire.AppendTag(item, requestItemMerkleRoot, protowire.BytesType)
+	item = protowire.AppendBytes(item, rawRoot)
+	item = protowire.AppendTag(item, requestItemBlockHeight, protowire.VarintType)
+	item = protowire.AppendVarint(item, 1)
+	item = protowire.AppendTag(item, 99, protowire.Fixed32Type)
+	item = protowire.AppendFixed32(item, 7)
+
+	var b []byte
+	b = protowire.AppendTag(b, requestItems, protowire.BytesType)
+	b = protowire.AppendBytes(b, item)
+
+	// when
+	items, err := UnmarshalMerkleRootConfirmationRequest(b)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(items), 1)
+	assert.Equal(t, items[0], domains.MerkleRootConfirmationRequestItem{MerkleRoot: root, BlockHeight: 1})
+}
+
+func TestUnmarshalMalformedMerkleRootConfirmationRequest(t *testing.T) {
+	// given
+	b := protowire.AppendTag(nil, requestItems, protowire.BytesType)
+	b = protowire.AppendVarint(b, 10)
+
+	// when
+	_, err := UnmarshalMerkleRootConfirmationRequest(b)
+
+	// then
+	assert.IsError(t, err, "malformed protobuf message")
+}
+
+func decodeMessage(t *testing.T, b []byte) map[protowire.Number][]byte {
+	fields := make(map[protowire.Number][]byte)
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		n := protowire.ConsumeFieldValue(num, typ, v)
+		if typ == protowire.BytesType {
+			fields[num], _ = protowire.ConsumeBytes(v)
+		} else {
+			fields[num] = v[:n]
+		}
+		return n, nil
+	})
+	assert.NoError(t, err)
+	return fields
+}
+
+func varint(t *testing.T, b []byte) uint64 {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		t.Fatalf("invalid varint %x", b)
+	}
+	return v
+}
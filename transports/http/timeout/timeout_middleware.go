@@ -0,0 +1,81 @@
+// Package timeout provides overriding the global timeouts of the HTTP server for groups of routes.
+package timeout
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/gin-gonic/gin"
+)
+
+// writeTimeoutKey is the key of the write timeout of the route in the gin context.
+const writeTimeoutKey = "timeout.write"
+
+// Middleware applies timeouts configured for the requested route.
+type Middleware struct {
+	routes []config.RouteTimeoutConfig
+}
+
+// NewMiddleware creates Middleware of the route timeouts listed in the config.
+func NewMiddleware(cfg *config.HTTPConfig) *Middleware {
+	return &Middleware{routes: cfg.RouteTimeouts}
+}
+
+// ApplyToAPI is a middleware which sets deadlines of the connection and of the request context for the route.
+// The deadline of the request context is read only by streaming of headers, which stops once it's exceeded.
+func (m *Middleware) ApplyToAPI(c *gin.Context) {
+	rt, ok := m.routeOf(c.FullPath())
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	rc := http.NewResponseController(c.Writer)
+	if rt.ReadTimeout > 0 {
+		_ = rc.SetReadDeadline(now.Add(rt.ReadTimeout))
+	}
+	if rt.WriteTimeout > 0 {
+		_ = rc.SetWriteDeadline(now.Add(rt.WriteTimeout))
+		c.Set(writeTimeoutKey, rt.WriteTimeout)
+	}
+	if rt.HandlerTimeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), rt.HandlerTimeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+
+	c.Next()
+}
+
+// WriteTimeout returns the write timeout of the requested route, or the fallback when it's not overridden.
+func WriteTimeout(c *gin.Context, fallback time.Duration) time.Duration {
+	if d, ok := c.Get(writeTimeoutKey); ok {
+		return d.(time.Duration)
+	}
+	return fallback
+}
+
+// routeOf returns the most specific configured route which the path belongs to.
+func (m *Middleware) routeOf(fullPath string) (config.RouteTimeoutConfig, bool) {
+	var found config.RouteTimeoutConfig
+	ok := false
+	for _, rt := range m.routes {
+		if covers(fullPath, rt.Route) && len(rt.Route) > len(found.Route) {
+			found, ok = rt, true
+		}
+	}
+	return found, ok
+}
+
+// covers checks if the route is a part of the path ending at the boundary of a path segment.
+func covers(fullPath, route string) bool {
+	i := strings.Index(fullPath, route)
+	if i < 0 {
+		return false
+	}
+	rest := fullPath[i+len(route):]
+	return rest == "" || strings.HasPrefix(rest, "/")
+}
@@ -0,0 +1,75 @@
+package timeout_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/timeout"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := timeout.NewMiddleware(&config.HTTPConfig{
+		RouteTimeouts: []config.RouteTimeoutConfig{
+			{Route: "/chain/merkleroot", WriteTimeout: time.Minute},
+			{Route: "/chain/merkleroot/verify", WriteTimeout: 2 * time.Minute, HandlerTimeout: 2 * time.Minute},
+			{Route: "/chain/tip", HandlerTimeout: 5 * time.Second},
+		},
+	})
+
+	var writeTimeout, handlerTimeout time.Duration
+	record := func(c *gin.Context) {
+		writeTimeout = timeout.WriteTimeout(c, 10*time.Second)
+		handlerTimeout = 0
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			handlerTimeout = time.Until(deadline).Round(time.Second)
+		}
+		c.Status(http.StatusOK)
+	}
+
+	engine := gin.New()
+	api := engine.Group("/api/v1", m.ApplyToAPI)
+	api.POST("/chain/merkleroot/verify", record)
+	api.GET("/chain/merkleroot", record)
+	api.GET("/chain/tip/longest", record)
+	api.GET("/chain/tips", record)
+
+	t.Run("applies the most specific route", func(t *testing.T) {
+		// when
+		call(engine, http.MethodPost, "/api/v1/chain/merkleroot/verify")
+
+		// then
+		assert.Equal(t, writeTimeout, 2*time.Minute)
+		assert.Equal(t, handlerTimeout, 2*time.Minute)
+	})
+
+	t.Run("applies the route group", func(t *testing.T) {
+		// when
+		call(engine, http.MethodGet, "/api/v1/chain/tip/longest")
+
+		// then
+		assert.Equal(t, writeTimeout, 10*time.Second)
+		assert.Equal(t, handlerTimeout, 5*time.Second)
+	})
+
+	t.Run("keeps global timeouts of other routes", func(t *testing.T) {
+		// when
+		call(engine, http.MethodGet, "/api/v1/chain/tips")
+
+		// then
+		assert.Equal(t, writeTimeout, 10*time.Second)
+		assert.Equal(t, handlerTimeout, time.Duration(0))
+	})
+}
+
+func call(engine *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+	return res
+}
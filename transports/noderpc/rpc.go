@@ -0,0 +1,202 @@
+package noderpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+)
+
+// Client calls the JSON-RPC of a node.
+type Client struct {
+	url        string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client of the node's JSON-RPC at given url, authorized with rpcuser and rpcpassword of the node.
+func NewClient(url, user, password string, timeout time.Duration) *Client {
+	return &Client{
+		url:        url,
+		user:       user,
+		password:   password,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("node rpc error %d: %s", e.Code, e.Message)
+}
+
+// BestBlock returns height, hash and time of the node's best block.
+func (c *Client) BestBlock() (height int32, hash string, timestamp time.Time, err error) {
+	results, err := c.call([]rpcRequest{{Method: "getbestblockhash", Params: []any{}}})
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+	if err := json.Unmarshal(results[0], &hash); err != nil {
+		return 0, "", time.Time{}, fmt.Errorf("getbestblockhash: %w", err)
+	}
+
+	results, err = c.call([]rpcRequest{{Method: "getblockheader", Params: []any{hash, true}}})
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+	var header struct {
+		Height int32 `json:"height"`
+		Time   int64 `json:"time"`
+	}
+	if err := json.Unmarshal(results[0], &header); err != nil {
+		return 0, "", time.Time{}, fmt.Errorf("getblockheader %s: %w", hash, err)
+	}
+
+	return header.Height, hash, time.Unix(header.Time, 0), nil
+}
+
+// getBlockCount returns the height of the node's best chain.
+func (c *Client) getBlockCount() (int, error) {
+	results, err := c.call([]rpcRequest{{Method: "getblockcount", Params: []any{}}})
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := json.Unmarshal(results[0], &count); err != nil {
+		return 0, fmt.Errorf("getblockcount: %w", err)
+	}
+	return count, nil
+}
+
+// getBlockHashes returns hashes of the node's best chain in the given height range.
+func (c *Client) getBlockHashes(from, to int) ([]string, error) {
+	requests := make([]rpcRequest, 0, to-from+1)
+	for height := from; height <= to; height++ {
+		requests = append(requests, rpcRequest{Method: "getblockhash", Params: []any{height}})
+	}
+
+	results, err := c.call(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(results))
+	for i, r := range results {
+		if err := json.Unmarshal(r, &hashes[i]); err != nil {
+			return nil, fmt.Errorf("getblockhash: %w", err)
+		}
+	}
+	return hashes, nil
+}
+
+// getBlockHeaders returns headers with the given hashes, in the same order.
+func (c *Client) getBlockHeaders(hashes []string) ([]*wire.BlockHeader, error) {
+	requests := make([]rpcRequest, 0, len(hashes))
+	for _, hash := range hashes {
+		// verbose=false makes the node return the serialized header.
+		requests = append(requests, rpcRequest{Method: "getblockheader", Params: []any{hash, false}})
+	}
+
+	results, err := c.call(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make([]*wire.BlockHeader, len(results))
+	for i, r := range results {
+		var raw string
+		if err := json.Unmarshal(r, &raw); err != nil {
+			return nil, fmt.Errorf("getblockheader %s: %w", hashes[i], err)
+		}
+		b, err := hex.DecodeString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("getblockheader %s: %w", hashes[i], err)
+		}
+
+		headers[i] = &wire.BlockHeader{}
+		if err := headers[i].Deserialize(bytes.NewReader(b)); err != nil {
+			return nil, fmt.Errorf("getblockheader %s: %w", hashes[i], err)
+		}
+	}
+	return headers, nil
+}
+
+// call sends the requests to the node as a single JSON-RPC batch and returns their results in the same order.
+func (c *Client) call(requests []rpcRequest) ([]json.RawMessage, error) {
+	for i := range requests {
+		requests[i].JSONRPC = "1.0"
+		requests[i].ID = i
+	}
+
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" || c.password != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("node responded with status %d", res.StatusCode)
+	}
+
+	var responses []rpcResponse
+	if err := json.NewDecoder(res.Body).Decode(&responses); err != nil {
+		return nil, err
+	}
+
+	// Responses of a batch can come in any order.
+	results := make([]json.RawMessage, len(requests))
+	for _, r := range responses {
+		if r.ID < 0 || r.ID >= len(requests) {
+			return nil, fmt.Errorf("node responded with unknown id %d", r.ID)
+		}
+		if r.Error != nil {
+			return nil, fmt.Errorf("%s: %w", requests[r.ID].Method, r.Error)
+		}
+		results[r.ID] = r.Result
+	}
+	for i, r := range results {
+		if r == nil {
+			return nil, fmt.Errorf("node did not respond to %s", requests[i].Method)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,147 @@
+// Package noderpc provides a headers source which follows a node over its JSON-RPC
+// instead of the p2p network.
+package noderpc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/rs/zerolog"
+)
+
+type server struct {
+	cfg            *config.NodeRPCConfig
+	rewindDepth    int
+	headersService service.Headers
+	chainService   service.Chains
+	rpc            *Client
+	log            *zerolog.Logger
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a new server which syncs headers from the configured node.
+//
+//revive:disable:unexported-return
+func NewServer(
+	cfg *config.AppConfig,
+	headersService service.Headers,
+	chainService service.Chains,
+	log *zerolog.Logger,
+) *server {
+	serverLogger := log.With().Str("service", "node-rpc").Logger()
+	return &server{
+		cfg:            cfg.NodeRPC,
+		rewindDepth:    cfg.P2P.BlocksForForkConfirmation,
+		headersService: headersService,
+		chainService:   chainService,
+		rpc:            NewClient(cfg.NodeRPC.URL, cfg.NodeRPC.User, cfg.NodeRPC.Password, cfg.NodeRPC.RequestTimeout),
+		log:            &serverLogger,
+		quit:           make(chan struct{}),
+	}
+}
+
+//revive:enable:unexported-return
+
+// Start starts polling the node in the background.
+func (s *server) Start() error {
+	s.log.Info().Msgf("Syncing headers from node %s", s.cfg.URL)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.syncWithNode()
+
+			select {
+			case <-ticker.C:
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops polling the node and waits for the pending batch to finish.
+func (s *server) Shutdown() error {
+	close(s.quit)
+	s.wg.Wait()
+	s.log.Info().Msg("Node RPC sync shutdown complete")
+	return nil
+}
+
+// syncWithNode fetches batches until the local tip reaches the node's tip. Every round
+// starts a few blocks below the local tip so reorganizations on the node are picked up.
+func (s *server) syncWithNode() {
+	count, err := s.rpc.getBlockCount()
+	if err != nil {
+		s.log.Error().Msgf("failed to get block count from node: %v", err)
+		return
+	}
+
+	for height := max(int(s.headersService.GetTipHeight())-s.rewindDepth, 0); height <= count; height += s.cfg.BatchSize {
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+
+		to := min(height+s.cfg.BatchSize-1, count)
+		batch, err := s.fetchHeaders(height, to)
+		if err != nil {
+			s.log.Error().Msgf("failed to fetch headers from node: %v", err)
+			return
+		}
+
+		added, err := s.addHeaders(batch)
+		if service.WritesPaused.Is(err) {
+			s.log.Debug().Msg("maintenance mode is enabled, syncing with node is paused")
+			return
+		}
+		if err != nil {
+			s.log.Error().Msgf("failed to add headers from node: %v", err)
+			return
+		}
+		if added > 0 {
+			s.log.Info().Msgf("Synced %d headers from node, current tip height %d", added, s.headersService.GetTipHeight())
+		}
+	}
+}
+
+func (s *server) fetchHeaders(from, to int) ([]*wire.BlockHeader, error) {
+	hashes, err := s.rpc.getBlockHashes(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.rpc.getBlockHeaders(hashes)
+}
+
+func (s *server) addHeaders(batch []*wire.BlockHeader) (int, error) {
+	added := 0
+	for _, h := range batch {
+		if _, err := s.chainService.Add(domains.BlockHeaderSource(*h)); err != nil {
+			if service.HeaderAlreadyExists.Is(err) {
+				continue
+			}
+			if service.BlockRejected.Is(err) || service.BlockQuarantined.Is(err) || service.HeaderCreationFail.Is(err) {
+				s.log.Warn().Msgf("skipping header %s received from node: %v", h.BlockHash(), err)
+				continue
+			}
+			return added, fmt.Errorf("header %s: %w", h.BlockHash(), err)
+		}
+		added++
+	}
+	return added, nil
+}
@@ -0,0 +1,104 @@
+package noderpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/rs/zerolog"
+)
+
+func TestFetchHeadersBatchesRequests(t *testing.T) {
+	// given
+	genesis := chaincfg.MainNetParams.GenesisBlock.Header
+	var raw bytes.Buffer
+	_ = genesis.Serialize(&raw)
+
+	calls := 0
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, user, "user")
+		assert.Equal(t, password, "secret")
+
+		var requests []rpcRequest
+		_ = json.NewDecoder(r.Body).Decode(&requests)
+		calls++
+
+		// Answer in reverse order, the client has to match responses by id.
+		responses := make([]map[string]any, 0, len(requests))
+		for i := len(requests) - 1; i >= 0; i-- {
+			var result any
+			switch requests[i].Method {
+			case "getblockhash":
+				result = genesis.BlockHash().String()
+			case "getblockheader":
+				assert.Equal(t, requests[i].Params[1], any(false))
+				result = hex.EncodeToString(raw.Bytes())
+			}
+			responses = append(responses, map[string]any{"id": requests[i].ID, "result": result, "error": nil})
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+	}))
+	defer node.Close()
+
+	s := newTestServer(node.URL)
+
+	// when
+	batch, err := s.fetchHeaders(0, 2)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, calls, 2)
+	assert.Equal(t, len(batch), 3)
+	assert.Equal(t, batch[2].BlockHash(), genesis.BlockHash())
+}
+
+func TestFetchHeadersFailsOnRPCError(t *testing.T) {
+	// given
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"id":0,"result":null,"error":{"code":-8,"message":"Block height out of range"}}]`)
+	}))
+	defer node.Close()
+
+	s := newTestServer(node.URL)
+
+	// when
+	_, err := s.fetchHeaders(10, 10)
+
+	// then
+	assert.IsError(t, err, "getblockhash: node rpc error -8: Block height out of range")
+}
+
+func TestFetchHeadersFailsOnNodeStatus(t *testing.T) {
+	// given
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer node.Close()
+
+	s := newTestServer(node.URL)
+
+	// when
+	_, err := s.fetchHeaders(0, 10)
+
+	// then
+	assert.IsError(t, err, "node responded with status 401")
+}
+
+func newTestServer(nodeURL string) *server {
+	cfg := config.GetDefaultAppConfig()
+	cfg.NodeRPC.Enabled = true
+	cfg.NodeRPC.URL = nodeURL
+	cfg.NodeRPC.User = "user"
+	cfg.NodeRPC.Password = "secret"
+	log := zerolog.Nop()
+
+	return NewServer(cfg, nil, nil, &log)
+}
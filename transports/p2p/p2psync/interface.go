@@ -5,6 +5,9 @@
 package p2psync
 
 import (
+	"net"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
 	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
 	"github.com/bitcoin-sv/block-headers-service/internal/wire"
@@ -35,6 +38,10 @@ type Config struct {
 
 	MinSyncPeerNetworkSpeed   uint64
 	BlocksForForkConfirmation int
+	MaxPendingHeaders         int
+	PeerSelection             *config.PeerSelectionConfig
+	// PreferredPeer is the resolved address of PeerSelection.PreferredPeer.
+	PreferredPeer net.Addr
 
 	Services    *service.Services
 	Checkpoints []chaincfg.Checkpoint
@@ -5,10 +5,8 @@
 package p2psync
 
 import (
-	"crypto/rand"
 	"fmt"
 	"math"
-	"math/big"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -89,6 +87,10 @@ type pauseMsg struct {
 	unpause <-chan struct{}
 }
 
+// restartSyncMsg is a message type to be sent across the message channel for
+// syncing again from the current tip, after headers above it were deleted.
+type restartSyncMsg struct{}
+
 // syncPeerState stores additional info about the sync peer.
 type syncPeerState struct {
 	recvBytes         uint64
@@ -149,16 +151,31 @@ type SyncManager struct {
 	peerStates    map[*peerpkg.Peer]*peerpkg.SyncState
 
 	// The following fields are used for headers-first mode.
-	headersFirstMode bool
-	startHeader      *domains.BlockHeader
-	nextCheckpoint   *chaincfg.Checkpoint
-	checkpoints      []chaincfg.Checkpoint
+	headersFirstMode   bool
+	startHeader        *domains.BlockHeader
+	nextCheckpoint     *chaincfg.Checkpoint
+	checkpoints        []chaincfg.Checkpoint
+	disableCheckpoints bool
 
 	// minSyncPeerNetworkSpeed is the minimum speed allowed for
 	// a sync peer.
 	minSyncPeerNetworkSpeed uint64
 	blocksToConfirmFork     int
 
+	// preferredPeer is the resolved address of the peer always picked for
+	// syncing when it's a candidate, preferLowLatency picks the peer with the
+	// lowest latency otherwise, as measured by pingMicros.
+	preferredPeer    string
+	preferLowLatency bool
+	pingMicros       func(*peerpkg.Peer) int64
+
+	// The following fields bound the number of received headers queued in msgChan,
+	// so peers are not read from while the manager can't keep up with them.
+	maxPendingHeaders int
+	pendingHeaders    int
+	pendingMtx        sync.Mutex
+	pendingCond       *sync.Cond
+
 	Services *service.Services
 }
 
@@ -235,20 +252,13 @@ func (sm *SyncManager) startSync() {
 
 	var bestPeer *peerpkg.Peer
 
-	// Try to select a random peer that is at a higher block height,
-	// if that is not available then use a random peer at the same
+	// Try to select a peer that is at a higher block height,
+	// if that is not available then use a peer at the same
 	// height and hope they find blocks.
 	if len(bestPeers) > 0 {
-		randInt, err := rand.Int(rand.Reader, big.NewInt(int64(len(bestPeers))))
-
-		if err == nil {
-			bestPeer = bestPeers[int(randInt.Int64())]
-		}
+		bestPeer = sm.pickSyncPeer(bestPeers)
 	} else if len(okPeers) > 0 {
-		randInt, err := rand.Int(rand.Reader, big.NewInt(int64(len(okPeers))))
-		if err == nil {
-			bestPeer = okPeers[int(randInt.Int64())]
-		}
+		bestPeer = sm.pickSyncPeer(okPeers)
 	}
 
 	// Start syncing from the best peer if one was selected.
@@ -376,6 +386,12 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peerpkg.Peer) {
 	// Start syncing by choosing the best candidate if needed.
 	if isSyncCandidate && sm.syncPeer == nil {
 		sm.startSync()
+		return
+	}
+
+	// The preferred peer takes over the sync as soon as it connects.
+	if isSyncCandidate && sm.isPreferredPeer(peer) {
+		sm.switchToBetterSyncPeer()
 	}
 }
 
@@ -390,12 +406,16 @@ func (sm *SyncManager) handleCheckSyncPeer() {
 		return
 	}
 
+	if sm.switchToBetterSyncPeer() {
+		return
+	}
+
 	// Update network stats at the end of this tick.
 	defer sm.syncPeerState.updateNetwork(sm.syncPeer)
 
 	// Check network speed of the sync peer and its last block time. If we're currently
-	// flushing the cache skip this round.
-	if (sm.syncPeerState.validNetworkSpeed(sm.minSyncPeerNetworkSpeed) < maxNetworkViolations) &&
+	// flushing the cache skip this round. The preferred peer is kept however slow it is.
+	if (sm.isPreferredPeer(sm.syncPeer) || sm.syncPeerState.validNetworkSpeed(sm.minSyncPeerNetworkSpeed) < maxNetworkViolations) &&
 		(time.Since(sm.syncPeerState.lastBlockTime) <= maxLastBlockTime) {
 		return
 	}
@@ -473,6 +493,36 @@ func (sm *SyncManager) updateSyncPeer() {
 	sm.startSync()
 }
 
+// handleRestartSync drops the current sync peer and starts syncing from the
+// current tip with the checkpoints reset to its height.
+func (sm *SyncManager) handleRestartSync() {
+	height := sm.Services.Headers.GetTipHeight()
+	sm.log.Info().Msgf("Restarting sync from height %d", height)
+	sm.resetHeaderState(height)
+
+	if sm.syncPeer != nil {
+		sm.syncPeer.SetSyncPeer(false)
+		sm.syncPeer = nil
+		sm.syncPeerState = nil
+	}
+
+	// Peers which were behind the previous tip can be candidates again.
+	for peer, state := range sm.peerStates {
+		state.SyncCandidate = sm.isSyncCandidate(peer)
+	}
+
+	sm.startSync()
+}
+
+// resetHeaderState initializes the next checkpoint based on the height.
+func (sm *SyncManager) resetHeaderState(height int32) {
+	if sm.disableCheckpoints {
+		return
+	}
+	sm.nextCheckpoint = sm.findNextHeaderCheckpoint(height)
+	sm.headersFirstMode = sm.nextCheckpoint == nil
+}
+
 // current returns true if we believe we are synced with our peers, false if we
 // still have blocks to check.
 func (sm *SyncManager) current() bool {
@@ -526,18 +576,37 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	receivedCheckpoint := false
 	var finalHash *chainhash.Hash
 	for _, blockHeader := range msg.Headers {
-		h, addErr := sm.Services.Chains.Add(domains.BlockHeaderSource(*blockHeader))
+		h, addErr := sm.Services.P2PChains.Add(domains.BlockHeaderSource(*blockHeader))
 
 		if service.HeaderAlreadyExists.Is(addErr) {
 			continue
 		}
 
+		// The rest of headers is requested again with the next block announced once the maintenance mode is over.
+		if service.WritesPaused.Is(addErr) {
+			sm.log.Info().Msgf("Maintenance mode is enabled, skipping %d headers from peer %s", numHeaders, peer)
+			return
+		}
+
+		// The rest of headers is requested again once the sync is resumed.
+		if service.SyncPaused.Is(addErr) {
+			sm.log.Info().Msgf("P2P sync is paused, skipping %d headers from peer %s", numHeaders, peer)
+			return
+		}
+
 		if service.BlockRejected.Is(addErr) {
 			sm.peerNotifier.BanPeer(peer)
 			peer.Disconnect()
 			return
 		}
 
+		// Headers following the quarantined one would become orphans, the peer is not banned,
+		// since a header ahead of our time can be caused by a skewed clock.
+		if service.BlockQuarantined.Is(addErr) {
+			sm.log.Warn().Msgf("Header %v from peer %s quarantined, skipping the rest of headers: %v", blockHeader.BlockHash(), peer, addErr)
+			return
+		}
+
 		if service.HeaderSaveFail.Is(addErr) {
 			sm.log.Error().Msgf("Couldn't save header %v in database, because of %+v", h, addErr)
 			continue
@@ -766,6 +835,7 @@ out:
 
 			case *headersMsg:
 				sm.handleHeadersMsg(msg)
+				sm.releasePendingHeaders(len(msg.headers.Headers))
 
 			case *donePeerMsg:
 				sm.log.Info().Msgf("[Event] donePeerMsg")
@@ -787,6 +857,10 @@ out:
 				sm.log.Info().Msgf("[Event] isCurrentMsg")
 				msg.reply <- sm.current()
 
+			case restartSyncMsg:
+				sm.log.Info().Msgf("[Event] restartSyncMsg")
+				sm.handleRestartSync()
+
 			case pauseMsg:
 				sm.log.Info().Msgf("[Event] pauseMsg")
 				// Wait until the sender unpauses the manager.
@@ -837,9 +911,44 @@ func (sm *SyncManager) QueueHeaders(headers *wire.MsgHeaders, peer *peerpkg.Peer
 		return
 	}
 
+	if !sm.reservePendingHeaders(len(headers.Headers)) {
+		return
+	}
 	sm.msgChan <- &headersMsg{headers: headers, peer: peer}
 }
 
+// reservePendingHeaders blocks until n more headers can be queued without exceeding
+// the limit of pending headers. A single message is always let through when nothing
+// else is pending. It returns false when the manager is shutting down.
+func (sm *SyncManager) reservePendingHeaders(n int) bool {
+	if sm.maxPendingHeaders <= 0 {
+		return true
+	}
+
+	sm.pendingMtx.Lock()
+	defer sm.pendingMtx.Unlock()
+	for sm.pendingHeaders > 0 && sm.pendingHeaders+n > sm.maxPendingHeaders {
+		if atomic.LoadInt32(&sm.shutdown) != 0 {
+			return false
+		}
+		sm.pendingCond.Wait()
+	}
+	sm.pendingHeaders += n
+	return true
+}
+
+// releasePendingHeaders frees the room of n processed headers.
+func (sm *SyncManager) releasePendingHeaders(n int) {
+	if sm.maxPendingHeaders <= 0 {
+		return
+	}
+
+	sm.pendingMtx.Lock()
+	sm.pendingHeaders -= n
+	sm.pendingCond.Broadcast()
+	sm.pendingMtx.Unlock()
+}
+
 // DonePeer informs the blockmanager that a peer has disconnected.
 func (sm *SyncManager) DonePeer(peer *peerpkg.Peer, done chan struct{}) {
 	// Ignore if we are shutting down.
@@ -873,10 +982,26 @@ func (sm *SyncManager) Stop() {
 
 	sm.log.Info().Msgf("Sync manager shutting down")
 	close(sm.quit)
+
+	// Wake up peers waiting for room for their headers, so they can quit.
+	sm.pendingMtx.Lock()
+	sm.pendingCond.Broadcast()
+	sm.pendingMtx.Unlock()
+
 	sm.wg.Wait()
 	sm.log.Info().Msgf("Sync manager stopped")
 }
 
+// RestartSync makes the sync manager sync again from the current tip.
+func (sm *SyncManager) RestartSync() {
+	// Ignore if we are shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	sm.msgChan <- restartSyncMsg{}
+}
+
 // IsCurrent returns whether or not the sync manager believes it is synced with
 // the connected peers.
 func (sm *SyncManager) IsCurrent() bool {
@@ -898,17 +1023,23 @@ func New(config *Config, peers map[*peerpkg.Peer]*peerpkg.SyncState) (*SyncManag
 		quit:                    make(chan struct{}),
 		minSyncPeerNetworkSpeed: config.MinSyncPeerNetworkSpeed,
 		blocksToConfirmFork:     config.BlocksForForkConfirmation,
+		maxPendingHeaders:       config.MaxPendingHeaders,
 		Services:                config.Services,
 		checkpoints:             config.Checkpoints,
+		disableCheckpoints:      config.DisableCheckpoints,
+		pingMicros:              (*peerpkg.Peer).LastPingMicros,
 	}
+	if config.PreferredPeer != nil {
+		sm.preferredPeer = normalizedAddr(config.PreferredPeer.String())
+	}
+	if ps := config.PeerSelection; ps != nil {
+		sm.preferLowLatency = ps.PreferLowLatency
+	}
+	sm.pendingCond = sync.NewCond(&sm.pendingMtx)
 
 	if !config.DisableCheckpoints {
 		// Initialize the next checkpoint based on the current height.
-		height := config.Services.Headers.GetTipHeight()
-		sm.nextCheckpoint = sm.findNextHeaderCheckpoint(height)
-		if sm.nextCheckpoint == nil {
-			sm.headersFirstMode = true
-		}
+		sm.resetHeaderState(config.Services.Headers.GetTipHeight())
 	} else {
 		syncManagerLogger.Info().Msg("Checkpoints are disabled")
 	}
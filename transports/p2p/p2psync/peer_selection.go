@@ -0,0 +1,104 @@
+package p2psync
+
+import (
+	"crypto/rand"
+	"math/big"
+	"net"
+
+	peerpkg "github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
+)
+
+// latencySwitchRatio is how many times lower latency a candidate has to have, compared to
+// the sync peer, for the sync to move to it when the low latency peers are preferred.
+const latencySwitchRatio = 2
+
+// pickSyncPeer returns the preferred peer if it's among the candidates. Otherwise it's
+// the one with the lowest measured latency when low latency is preferred, or a random one.
+func (sm *SyncManager) pickSyncPeer(candidates []*peerpkg.Peer) *peerpkg.Peer {
+	for _, peer := range candidates {
+		if sm.isPreferredPeer(peer) {
+			return peer
+		}
+	}
+
+	if sm.preferLowLatency {
+		if peer := sm.lowestLatencyPeer(candidates); peer != nil {
+			return peer
+		}
+	}
+
+	randInt, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
+	if err != nil {
+		return nil
+	}
+	return candidates[int(randInt.Int64())]
+}
+
+func (sm *SyncManager) isPreferredPeer(peer *peerpkg.Peer) bool {
+	return sm.preferredPeer != "" && normalizedAddr(peer.Addr()) == sm.preferredPeer
+}
+
+// normalizedAddr returns the ip:port address in the same form as the resolved preferred peer,
+// so e.g. an IPv6 address matches however it was written. Addresses with a host name are left as they are.
+func normalizedAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return addr
+	}
+	return net.JoinHostPort(ip.String(), port)
+}
+
+// switchToBetterSyncPeer moves the sync to the preferred peer, or to a peer with a much lower
+// latency when low latency is preferred, if one is not behind the sync peer. The previous sync
+// peer stays connected. It returns true if the sync peer was switched.
+func (sm *SyncManager) switchToBetterSyncPeer() bool {
+	if sm.syncPeer == nil || sm.isPreferredPeer(sm.syncPeer) {
+		return false
+	}
+
+	candidates := make([]*peerpkg.Peer, 0, len(sm.peerStates))
+	for peer, state := range sm.peerStates {
+		if peer != sm.syncPeer && state.SyncCandidate && peer.LastBlock() >= sm.syncPeer.LastBlock() {
+			candidates = append(candidates, peer)
+		}
+	}
+
+	var better *peerpkg.Peer
+	for _, peer := range candidates {
+		if sm.isPreferredPeer(peer) {
+			better = peer
+		}
+	}
+	if better == nil && sm.preferLowLatency {
+		current := sm.pingMicros(sm.syncPeer)
+		if peer := sm.lowestLatencyPeer(candidates); peer != nil && (current == 0 || sm.pingMicros(peer)*latencySwitchRatio < current) {
+			better = peer
+		}
+	}
+	if better == nil {
+		return false
+	}
+
+	sm.log.Info().Msgf("Switching sync peer from %s to %s", sm.syncPeer, better)
+	sm.syncPeer.SetSyncPeer(false)
+	sm.syncPeer = nil
+	sm.syncPeerState = nil
+	sm.startSync()
+	return true
+}
+
+// lowestLatencyPeer returns the peer with the lowest measured latency, or nil if none was measured yet.
+func (sm *SyncManager) lowestLatencyPeer(peers []*peerpkg.Peer) *peerpkg.Peer {
+	var lowest *peerpkg.Peer
+	for _, peer := range peers {
+		latency := sm.pingMicros(peer)
+		if latency > 0 && (lowest == nil || latency < sm.pingMicros(lowest)) {
+			lowest = peer
+		}
+	}
+	return lowest
+}
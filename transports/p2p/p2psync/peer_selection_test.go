@@ -0,0 +1,227 @@
+package p2psync
+
+import (
+	"net"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	peerpkg "github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
+	"github.com/rs/zerolog"
+)
+
+// testPeer describes a connected peer by its address, height and the last measured ping.
+type testPeer struct {
+	addr   string
+	height int32
+	ping   int64
+}
+
+func TestPickSyncPeer(t *testing.T) {
+	testCases := map[string]struct {
+		preferredPeer    string
+		preferLowLatency bool
+		peers            []testPeer
+		expected         string
+	}{
+		"preferred peer": {
+			preferredPeer: "10.0.0.2:8333",
+			peers: []testPeer{
+				{addr: "10.0.0.1:8333", ping: 10},
+				{addr: "10.0.0.2:8333", ping: 500},
+			},
+			expected: "10.0.0.2:8333",
+		},
+		"preferred peer written differently": {
+			preferredPeer: "[::ffff:10.0.0.2]:8333",
+			peers: []testPeer{
+				{addr: "10.0.0.1:8333"},
+				{addr: "10.0.0.2:8333"},
+			},
+			expected: "10.0.0.2:8333",
+		},
+		"preferred peer over the lowest latency": {
+			preferredPeer:    "10.0.0.2:8333",
+			preferLowLatency: true,
+			peers: []testPeer{
+				{addr: "10.0.0.1:8333", ping: 10},
+				{addr: "10.0.0.2:8333", ping: 500},
+			},
+			expected: "10.0.0.2:8333",
+		},
+		"lowest latency": {
+			preferLowLatency: true,
+			peers: []testPeer{
+				{addr: "10.0.0.1:8333", ping: 300},
+				{addr: "10.0.0.2:8333", ping: 100},
+				{addr: "10.0.0.3:8333", ping: 200},
+			},
+			expected: "10.0.0.2:8333",
+		},
+		"lowest latency skips unmeasured peers": {
+			preferLowLatency: true,
+			peers: []testPeer{
+				{addr: "10.0.0.1:8333", ping: 0},
+				{addr: "10.0.0.2:8333", ping: 300},
+			},
+			expected: "10.0.0.2:8333",
+		},
+		"single peer": {
+			peers: []testPeer{
+				{addr: "10.0.0.1:8333"},
+			},
+			expected: "10.0.0.1:8333",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			sm, peers := newTestSyncManager(t, tc.preferredPeer, tc.preferLowLatency, tc.peers)
+
+			// when
+			picked := sm.pickSyncPeer(peers)
+
+			// then
+			assert.Equal(t, picked.Addr(), tc.expected)
+		})
+	}
+}
+
+func TestSwitchToBetterSyncPeer(t *testing.T) {
+	testCases := map[string]struct {
+		preferredPeer    string
+		preferLowLatency bool
+		syncPeer         testPeer
+		peers            []testPeer
+		expectedSwitch   bool
+		expectedSyncPeer string
+	}{
+		"to the preferred peer": {
+			preferredPeer: "10.0.0.2:8333",
+			syncPeer:      testPeer{addr: "10.0.0.1:8333", height: 100},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 100},
+			},
+			expectedSwitch:   true,
+			expectedSyncPeer: "10.0.0.2:8333",
+		},
+		"not to the preferred peer behind the sync peer": {
+			preferredPeer: "10.0.0.2:8333",
+			syncPeer:      testPeer{addr: "10.0.0.1:8333", height: 100},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 99},
+			},
+			expectedSwitch:   false,
+			expectedSyncPeer: "10.0.0.1:8333",
+		},
+		"not away from the preferred peer": {
+			preferredPeer:    "10.0.0.1:8333",
+			preferLowLatency: true,
+			syncPeer:         testPeer{addr: "10.0.0.1:8333", height: 100, ping: 1000},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 100, ping: 10},
+			},
+			expectedSwitch:   false,
+			expectedSyncPeer: "10.0.0.1:8333",
+		},
+		"to a much lower latency peer": {
+			preferLowLatency: true,
+			syncPeer:         testPeer{addr: "10.0.0.1:8333", height: 100, ping: 300},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 100, ping: 100},
+			},
+			expectedSwitch:   true,
+			expectedSyncPeer: "10.0.0.2:8333",
+		},
+		"not to a slightly lower latency peer": {
+			preferLowLatency: true,
+			syncPeer:         testPeer{addr: "10.0.0.1:8333", height: 100, ping: 300},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 100, ping: 150},
+			},
+			expectedSwitch:   false,
+			expectedSyncPeer: "10.0.0.1:8333",
+		},
+		"to a measured peer when the sync peer isn't measured yet": {
+			preferLowLatency: true,
+			syncPeer:         testPeer{addr: "10.0.0.1:8333", height: 100},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 100, ping: 100},
+			},
+			expectedSwitch:   true,
+			expectedSyncPeer: "10.0.0.2:8333",
+		},
+		"not on latency when low latency isn't preferred": {
+			syncPeer: testPeer{addr: "10.0.0.1:8333", height: 100, ping: 1000},
+			peers: []testPeer{
+				{addr: "10.0.0.2:8333", height: 100, ping: 10},
+			},
+			expectedSwitch:   false,
+			expectedSyncPeer: "10.0.0.1:8333",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			sm, peers := newTestSyncManager(t, tc.preferredPeer, tc.preferLowLatency, append([]testPeer{tc.syncPeer}, tc.peers...))
+			sm.syncPeer = peers[0]
+			sm.syncPeer.SetSyncPeer(true)
+
+			// when
+			switched := sm.switchToBetterSyncPeer()
+
+			// then
+			assert.Equal(t, switched, tc.expectedSwitch)
+			assert.Equal(t, sm.syncPeer.Addr(), tc.expectedSyncPeer)
+			assert.Equal(t, peers[0].SyncPeer(), !tc.expectedSwitch)
+		})
+	}
+}
+
+// newTestSyncManager returns a sync manager, with a short chain, for the given sync candidates.
+func newTestSyncManager(t *testing.T, preferredPeer string, preferLowLatency bool, candidates []testPeer) (*SyncManager, []*peerpkg.Peer) {
+	t.Helper()
+	log := zerolog.Nop()
+
+	db, _ := fixtures.LongestChainWithFork()
+	var array []domains.BlockHeader = db
+	repo := &repository.Repositories{
+		Headers: testrepository.NewHeadersTestRepository(&array),
+	}
+
+	pings := make(map[*peerpkg.Peer]int64, len(candidates))
+	peers := make([]*peerpkg.Peer, 0, len(candidates))
+	states := make(map[*peerpkg.Peer]*peerpkg.SyncState, len(candidates))
+	for _, c := range candidates {
+		peer, err := peerpkg.NewOutboundPeer(&peerpkg.Config{Log: &log}, c.addr)
+		assert.NoError(t, err)
+		peer.UpdateLastBlockHeight(c.height)
+		pings[peer] = c.ping
+		peers = append(peers, peer)
+		states[peer] = &peerpkg.SyncState{SyncCandidate: true}
+	}
+
+	sm := &SyncManager{
+		log:                &log,
+		peerStates:         states,
+		disableCheckpoints: true,
+		preferLowLatency:   preferLowLatency,
+		pingMicros:         func(peer *peerpkg.Peer) int64 { return pings[peer] },
+		Services: &service.Services{
+			Headers: service.NewHeaderService(repo, config.GetDefaultAppConfig().P2P, &log),
+		},
+	}
+	if preferredPeer != "" {
+		addr, err := net.ResolveTCPAddr("tcp", preferredPeer)
+		assert.NoError(t, err)
+		sm.preferredPeer = normalizedAddr(addr.String())
+	}
+	return sm, peers
+}
@@ -0,0 +1,48 @@
+package p2putil
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket allowing burst messages at once and refilling
+// at rate messages per second.
+type RateLimiter struct {
+	mtx    sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter with a full bucket.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Allow takes a token from the bucket at the given time and reports whether
+// there was one to take.
+func (l *RateLimiter) Allow(now time.Time) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	if l.last.IsZero() || now.After(l.last) {
+		l.last = now
+	}
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
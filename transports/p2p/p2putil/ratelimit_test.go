@@ -0,0 +1,34 @@
+package p2putil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	// given
+	limiter := NewRateLimiter(2, 3)
+	now := time.Unix(1700000000, 0)
+
+	// when
+	burst := allowed(limiter, now, 4)
+	afterHalfSecond := allowed(limiter, now.Add(500*time.Millisecond), 2)
+	afterIdle := allowed(limiter, now.Add(time.Minute), 4)
+
+	// then
+	assert.Equal(t, burst, 3)
+	assert.Equal(t, afterHalfSecond, 1)
+	assert.Equal(t, afterIdle, 3)
+}
+
+func allowed(limiter *RateLimiter, at time.Time, messages int) int {
+	count := 0
+	for i := 0; i < messages; i++ {
+		if limiter.Allow(at) {
+			count++
+		}
+	}
+	return count
+}
@@ -274,6 +274,10 @@ type Config struct {
 	// inventory to a peer.
 	TrickleInterval time.Duration
 
+	// PingInterval is the duration between pings, which measure the round-trip
+	// latency of the peer. The default pingInterval is used when it's 0.
+	PingInterval time.Duration
+
 	// TstAllowSelfConnection is only used to allow the tests to bypass the self
 	// connection detecting and disconnect logic since they intentionally
 	// do so for testing purposes.
@@ -463,6 +467,7 @@ type Peer struct {
 	prevGetHdrsMtx     sync.Mutex
 	prevGetHdrsBegin   *chainhash.Hash
 	prevGetHdrsStop    *chainhash.Hash
+	pendingGetHdrs     int // Number of getheaders requests not answered yet.
 
 	// These fields keep track of statistics for the peer and are protected
 	// by the statsMtx mutex.
@@ -491,6 +496,9 @@ type Peer struct {
 type State struct {
 	IP   string `json:"ip"`
 	Port int    `json:"port"`
+	// LatencyMicros is the round-trip time of the last ping, 0 until the peer answers one.
+	LatencyMicros int64 `json:"latencyMicros"`
+	SyncPeer      bool  `json:"syncPeer"`
 }
 
 // String returns the peer's address and directionality as a human-readable
@@ -701,11 +709,25 @@ func (p *Peer) WantsHeaders() bool {
 	return sendHeadersPreferred
 }
 
+// LastPingMicros returns the round-trip time of the last ping answered by the
+// peer in microseconds, or 0 when none was answered yet.
+//
+// This function is safe for concurrent access.
+func (p *Peer) LastPingMicros() int64 {
+	p.statsMtx.RLock()
+	lastPingMicros := p.lastPingMicros
+	p.statsMtx.RUnlock()
+
+	return lastPingMicros
+}
+
 // ToPeerState return PeerState of the peer on which this method was called.
 func (p *Peer) ToPeerState() State {
 	return State{
-		IP:   p.NA().IP.String(),
-		Port: int(p.na.Port),
+		IP:            p.NA().IP.String(),
+		Port:          int(p.na.Port),
+		LatencyMicros: p.LastPingMicros(),
+		SyncPeer:      p.SyncPeer(),
 	}
 }
 
@@ -832,10 +854,27 @@ func (p *Peer) PushGetHeadersMsg(locator domains.BlockLocator, stopHash *chainha
 	p.prevGetHdrsMtx.Lock()
 	p.prevGetHdrsBegin = beginHash
 	p.prevGetHdrsStop = stopHash
+	p.pendingGetHdrs++
 	p.prevGetHdrsMtx.Unlock()
 	return nil
 }
 
+// AnswersGetHeaders reports whether a received headers message is the reply
+// to a getheaders request sent to the peer, in which case the request is
+// counted as answered.
+//
+// This function is safe for concurrent access.
+func (p *Peer) AnswersGetHeaders() bool {
+	p.prevGetHdrsMtx.Lock()
+	defer p.prevGetHdrsMtx.Unlock()
+
+	if p.pendingGetHdrs == 0 {
+		return false
+	}
+	p.pendingGetHdrs--
+	return true
+}
+
 // PushRejectMsg sends a reject message for the provided command, reject code,
 // reject reason, and hash.  The hash will only be used when the command is a tx
 // or block and should be nil in other cases.  The wait parameter will cause the
@@ -1707,20 +1746,22 @@ cleanup:
 }
 
 // pingHandler periodically pings the peer.  It must be run as a goroutine.
+// The first ping is sent right away, so the latency is known before the sync
+// peer is selected.
 func (p *Peer) pingHandler() {
-	pingTicker := time.NewTicker(pingInterval)
+	interval := p.cfg.PingInterval
+	if interval <= 0 {
+		interval = pingInterval
+	}
+	pingTicker := time.NewTicker(interval)
 	defer pingTicker.Stop()
 
+	p.sendPing()
 out:
 	for {
 		select {
 		case <-pingTicker.C:
-			nonce, err := wire.RandomUint64()
-			if err != nil {
-				p.cfg.Log.Error().Msgf("Not sending ping to %s: %v", p, err)
-				continue
-			}
-			p.QueueMessage(wire.NewMsgPing(nonce), nil)
+			p.sendPing()
 
 		case <-p.quit:
 			break out
@@ -1728,6 +1769,15 @@ out:
 	}
 }
 
+func (p *Peer) sendPing() {
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		p.cfg.Log.Error().Msgf("Not sending ping to %s: %v", p, err)
+		return
+	}
+	p.QueueMessage(wire.NewMsgPing(nonce), nil)
+}
+
 // QueueMessage adds the passed bitcoin message to the peer send queue.
 //
 // This function is safe for concurrent access.
@@ -111,6 +111,12 @@ type updatePeerHeightsMsg struct {
 	originPeer *peer.Peer
 }
 
+// banPeerMsg packages a peer to ban along with how long the ban lasts.
+type banPeerMsg struct {
+	peer     *peer.Peer
+	duration time.Duration
+}
+
 // server provides a bitcoin server for handling communications to and from
 // bitcoin peers.
 type server struct {
@@ -124,7 +130,7 @@ type server struct {
 	syncManager       *p2psync.SyncManager
 	newPeers          chan *serverPeer
 	donePeers         chan *serverPeer
-	banPeers          chan *peer.Peer
+	banPeers          chan banPeerMsg
 	query             chan interface{}
 	relayInv          chan relayMsg
 	broadcast         chan broadcastMsg
@@ -136,6 +142,9 @@ type server struct {
 	wireServices      wire.ServiceFlag
 	p2pConfig         *config.P2PConfig
 	log               *zerolog.Logger
+
+	// preferredPeer is the resolved address of the configured preferred peer, if any.
+	preferredPeer net.Addr
 }
 
 // handleUpdatePeerHeight updates the heights of all peers who were known to
@@ -284,15 +293,16 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 
 // handleBanPeerMsg deals with banning peers.  It is invoked from the
 // peerHandler goroutine.
-func (s *server) handleBanPeerMsg(state *peerState, p *peer.Peer) {
+func (s *server) handleBanPeerMsg(state *peerState, bmsg banPeerMsg) {
+	p := bmsg.peer
 	host, _, err := net.SplitHostPort(p.Addr())
 	if err != nil {
 		s.log.Debug().Msgf("can't split ban peer %s %v", p.Addr(), err)
 		return
 	}
 	direction := logging.DirectionString(p.Inbound())
-	s.log.Info().Msgf("Banned peer %s (%s) for %v", host, direction, s.p2pConfig.BanDuration)
-	state.banned[host] = time.Now().Add(s.p2pConfig.BanDuration)
+	s.log.Info().Msgf("Banned peer %s (%s) for %v", host, direction, bmsg.duration)
+	state.banned[host] = time.Now().Add(bmsg.duration)
 }
 
 // handleBroadcastMsg deals with broadcasting messages to peers.  It is invoked
@@ -499,6 +509,7 @@ func initUserAgentComments(excessiveBlockSize uint32) []string {
 // for disconnection.
 func (s *server) inboundPeerConnected(conn net.Conn, log *zerolog.Logger) {
 	sp := newServerPeer(s, false, log)
+	sp.limitInboundMessages()
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
 	// Peer log
 	// srvrconfigs.Log.Infof("[Server] inboundPeer: %#v", sp.Peer)
@@ -576,6 +587,15 @@ func (s *server) peerHandler() {
 		}, s.log)
 	go s.connManager.Start()
 
+	// Keep a permanent connection to the preferred peer, so it's reconnected
+	// whenever it drops and can take the sync over again.
+	if s.preferredPeer != nil {
+		go s.connManager.Connect(&connmgr.ConnReq{
+			Addr:      s.preferredPeer,
+			Permanent: true,
+		})
+	}
+
 out:
 	for {
 		select {
@@ -595,8 +615,8 @@ out:
 			s.handleUpdatePeerHeights(state, umsg)
 
 		// Peer to ban.
-		case p := <-s.banPeers:
-			s.handleBanPeerMsg(state, p)
+		case bmsg := <-s.banPeers:
+			s.handleBanPeerMsg(state, bmsg)
 
 		// Message to broadcast to all connected peers except those
 		// which are excluded by the message.
@@ -648,7 +668,13 @@ func (s *server) AddPeer(sp *serverPeer) {
 
 // BanPeer bans a peer that has already been connected to the server by ip.
 func (s *server) BanPeer(p *peer.Peer) {
-	s.banPeers <- p
+	s.banPeers <- banPeerMsg{peer: p, duration: s.p2pConfig.BanDuration}
+}
+
+// TempBanPeer bans a peer that has already been connected to the server by ip
+// for the given duration instead of the configured ban duration.
+func (s *server) TempBanPeer(p *peer.Peer, duration time.Duration) {
+	s.banPeers <- banPeerMsg{peer: p, duration: duration}
 }
 
 // RelayInventory relays the passed inventory vector to all connected peers
@@ -739,6 +765,11 @@ func (s *server) Shutdown() error {
 	return nil
 }
 
+// RestartSync makes the sync manager sync again from the current tip.
+func (s *server) RestartSync() {
+	s.syncManager.RestartSync()
+}
+
 // WaitForShutdown blocks until the main listener and peer handlers are stopped.
 func (s *server) WaitForShutdown() {
 	s.wg.Wait()
@@ -824,7 +855,7 @@ func newServer(chainParams *chaincfg.Params, services *service.Services,
 		addrManager:       amgr,
 		newPeers:          make(chan *serverPeer, config.MaxPeers),
 		donePeers:         make(chan *serverPeer, config.MaxPeers),
-		banPeers:          make(chan *peer.Peer, config.MaxPeers),
+		banPeers:          make(chan banPeerMsg, config.MaxPeers),
 		query:             make(chan interface{}),
 		relayInv:          make(chan relayMsg, config.MaxPeers),
 		broadcast:         make(chan broadcastMsg, config.MaxPeers),
@@ -837,6 +868,13 @@ func newServer(chainParams *chaincfg.Params, services *service.Services,
 		log:               log,
 	}
 
+	if ps := p2pCfg.PeerSelection; ps != nil && ps.PreferredPeer != "" {
+		s.preferredPeer, err = p2putil.AddrStringToNetAddr(ps.PreferredPeer, p2pCfg.BsvdLookup)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve the preferred peer %s: %w", ps.PreferredPeer, err)
+		}
+	}
+
 	s.syncManager, err = p2psync.New(&p2psync.Config{
 		PeerNotifier:              &s,
 		ChainParams:               s.chainParams,
@@ -844,6 +882,9 @@ func newServer(chainParams *chaincfg.Params, services *service.Services,
 		MaxPeers:                  config.MaxPeers,
 		MinSyncPeerNetworkSpeed:   config.MinSyncPeerNetworkSpeed,
 		BlocksForForkConfirmation: p2pCfg.BlocksForForkConfirmation,
+		MaxPendingHeaders:         p2pCfg.MaxPendingHeaders,
+		PeerSelection:             p2pCfg.PeerSelection,
+		PreferredPeer:             s.preferredPeer,
 		Logger:                    log,
 		Services:                  services,
 		Checkpoints:               config.Checkpoints,
@@ -11,6 +11,7 @@ import (
 	"github.com/bitcoin-sv/block-headers-service/internal/wire"
 	"github.com/bitcoin-sv/block-headers-service/transports/p2p/addrmgr"
 	"github.com/bitcoin-sv/block-headers-service/transports/p2p/connmgr"
+	"github.com/bitcoin-sv/block-headers-service/transports/p2p/p2putil"
 	"github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
 	"github.com/rs/zerolog"
 )
@@ -31,6 +32,11 @@ type serverPeer struct {
 	knownAddresses map[string]struct{}
 	quit           chan struct{}
 	log            *zerolog.Logger
+
+	// Limiters of inbound messages, nil when rate limiting is disabled.
+	invLimiter     *p2putil.RateLimiter
+	headersLimiter *p2putil.RateLimiter
+	addrLimiter    *p2putil.RateLimiter
 }
 
 // newServerPeer returns a new serverPeer instance. The peer needs to be set by
@@ -46,6 +52,35 @@ func newServerPeer(s *server, isPersistent bool, log *zerolog.Logger) *serverPee
 	}
 }
 
+// limitInboundMessages sets up the configured limits of inv, headers and addr
+// messages. It's only done for inbound peers, the outbound ones are chosen by
+// us. Only unsolicited messages are counted, see rateLimited.
+func (sp *serverPeer) limitInboundMessages() {
+	rl := sp.server.p2pConfig.RateLimit
+	if rl == nil || !rl.Enabled {
+		return
+	}
+	sp.invLimiter = p2putil.NewRateLimiter(rl.Inv.Rate, rl.Inv.Burst)
+	sp.headersLimiter = p2putil.NewRateLimiter(rl.Headers.Rate, rl.Headers.Burst)
+	sp.addrLimiter = p2putil.NewRateLimiter(rl.Addr.Rate, rl.Addr.Burst)
+}
+
+// rateLimited reports whether the peer exceeded the limit of the given
+// message type, in which case it's temporarily banned and disconnected.
+// Messages of the sync peer aren't limited, it legitimately sends headers
+// as fast as we request them, an inbound peer can become the sync peer too.
+func (sp *serverPeer) rateLimited(limiter *p2putil.RateLimiter, msg wire.Message) bool {
+	if limiter == nil || sp.SyncPeer() || limiter.Allow(time.Now()) {
+		return false
+	}
+
+	banDuration := sp.server.p2pConfig.RateLimit.BanDuration
+	sp.log.Warn().Msgf("Peer %s exceeded the rate limit of %s messages -- banning for %v", sp.Peer, msg.Command(), banDuration)
+	sp.server.TempBanPeer(sp.Peer, banDuration)
+	sp.Disconnect()
+	return true
+}
+
 // newestBlock returns the current best block hash and height using the format
 // required by the configuration for the peer package.
 func (sp *serverPeer) newestBlock() (*chainhash.Hash, int32, error) {
@@ -177,6 +212,9 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) *wire.MsgRej
 // accordingly.  We pass the message down to blockmanager which will call
 // QueueMessage with any appropriate responses.
 func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
+	if sp.rateLimited(sp.invLimiter, msg) {
+		return
+	}
 	if len(msg.InvList) > 0 {
 		sp.server.syncManager.QueueInv(msg, sp.Peer)
 	}
@@ -185,9 +223,18 @@ func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
 // OnHeaders is invoked when a peer receives a headers bitcoin
 // message.  The message is passed down to the sync manager.
 func (sp *serverPeer) OnHeaders(_ *peer.Peer, msg *wire.MsgHeaders) {
+	if sp.headersRateLimited(msg) {
+		return
+	}
 	sp.server.syncManager.QueueHeaders(msg, sp.Peer)
 }
 
+// headersRateLimited is rateLimited for headers messages, replies to our
+// getheaders requests, e.g. after an announced block, aren't counted.
+func (sp *serverPeer) headersRateLimited(msg *wire.MsgHeaders) bool {
+	return !sp.AnswersGetHeaders() && sp.rateLimited(sp.headersLimiter, msg)
+}
+
 // OnGetHeaders is invoked when a peer receives a getheaders bitcoin
 // message.
 func (sp *serverPeer) OnGetHeaders(_ *peer.Peer, msg *wire.MsgGetHeaders) {
@@ -280,6 +327,10 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 // OnAddr is invoked when a peer receives an addr bitcoin message and is
 // used to notify the server about advertised addresses.
 func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
+	if sp.rateLimited(sp.addrLimiter, msg) {
+		return
+	}
+
 	// Ignore old style addresses which don't include a timestamp.
 	if sp.ProtocolVersion() < wire.NetAddressTimeVersion {
 		return
@@ -342,5 +393,14 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		Services:          sp.server.wireServices,
 		ProtocolVersion:   uint32(70013),
 		TrickleInterval:   config.TrickleInterval,
+		PingInterval:      pingInterval(sp.server.p2pConfig),
+	}
+}
+
+// pingInterval returns the configured interval of measuring the latency of peers, 0 means the peer default.
+func pingInterval(cfg *config.P2PConfig) time.Duration {
+	if cfg.PeerSelection == nil {
+		return 0
 	}
+	return cfg.PeerSelection.PingInterval
 }
@@ -0,0 +1,79 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
+	"github.com/rs/zerolog"
+)
+
+func TestHeadersRateLimit(t *testing.T) {
+	testCases := map[string]struct {
+		syncPeer          bool
+		getHeadersSent    int
+		expectedLimitedAt int
+	}{
+		"unsolicited headers": {
+			expectedLimitedAt: 2,
+		},
+		"replies to getheaders": {
+			getHeadersSent:    3,
+			expectedLimitedAt: 5,
+		},
+		"sync peer": {
+			syncPeer:          true,
+			expectedLimitedAt: 0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			sp := newRateLimitedTestPeer()
+			sp.SetSyncPeer(tc.syncPeer)
+			for i := 0; i < tc.getHeadersSent; i++ {
+				stopHash := chainhash.Hash{byte(i + 1)}
+				assert.NoError(t, sp.PushGetHeadersMsg(domains.BlockLocator{&chainhash.Hash{}}, &stopHash))
+			}
+
+			// when
+			limitedAt := 0
+			for i := 1; i <= 5 && limitedAt == 0; i++ {
+				if sp.headersRateLimited(wire.NewMsgHeaders()) {
+					limitedAt = i
+				}
+			}
+
+			// then
+			assert.Equal(t, limitedAt, tc.expectedLimitedAt)
+			assert.Equal(t, len(sp.server.banPeers), min(tc.expectedLimitedAt, 1))
+		})
+	}
+}
+
+// newRateLimitedTestPeer returns an inbound peer allowed to send a single headers message.
+func newRateLimitedTestPeer() *serverPeer {
+	log := zerolog.Nop()
+	s := &server{
+		banPeers: make(chan banPeerMsg, 1),
+		p2pConfig: &config.P2PConfig{
+			RateLimit: &config.RateLimitConfig{
+				Enabled:     true,
+				BanDuration: time.Minute,
+				Inv:         &config.MessageRateConfig{Rate: 0.001, Burst: 1},
+				Headers:     &config.MessageRateConfig{Rate: 0.001, Burst: 1},
+				Addr:        &config.MessageRateConfig{Rate: 0.001, Burst: 1},
+			},
+		},
+	}
+	sp := newServerPeer(s, false, &log)
+	sp.limitInboundMessages()
+	sp.Peer = peer.NewInboundPeer(&peer.Config{Log: &log})
+	return sp
+}
@@ -0,0 +1,161 @@
+package replica
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/rs/zerolog"
+)
+
+// Source is a headers source which runs in the background until it is shut down.
+type Source interface {
+	Start() error
+	Shutdown() error
+}
+
+type fallbackServer struct {
+	p2p           Source
+	upstream      *server
+	network       service.Network
+	fallbackAfter time.Duration
+	log           *zerolog.Logger
+
+	lastPeerSeen time.Time
+	fallingBack  bool
+
+	wg sync.WaitGroup
+}
+
+// NewFallbackServer wraps the p2p server, so headers are fetched from the configured upstream
+// instance while no p2p peer is connected. Headers of the upstream are verified before they are stored.
+//
+//revive:disable:unexported-return
+func NewFallbackServer(
+	cfg *config.AppConfig,
+	p2p Source,
+	network service.Network,
+	headersService service.Headers,
+	chainService service.Chains,
+	log *zerolog.Logger,
+) *fallbackServer {
+	upstream := newUpstreamServer(cfg, headersService, chainService, log)
+
+	return &fallbackServer{
+		p2p:           p2p,
+		upstream:      upstream,
+		network:       network,
+		fallbackAfter: cfg.Federation.FallbackAfter,
+		log:           upstream.log,
+	}
+}
+
+// NewUpstreamServer creates a server which keeps fetching headers from the configured upstream instance,
+// verifying them before they are stored. It is the only source of headers when p2p is disabled.
+func NewUpstreamServer(
+	cfg *config.AppConfig,
+	headersService service.Headers,
+	chainService service.Chains,
+	log *zerolog.Logger,
+) *server {
+	return newUpstreamServer(cfg, headersService, chainService, log)
+}
+
+//revive:enable:unexported-return
+
+func newUpstreamServer(
+	cfg *config.AppConfig,
+	headersService service.Headers,
+	chainService service.Chains,
+	log *zerolog.Logger,
+) *server {
+	upstreamLogger := log.With().Str("service", "federation").Logger()
+	return &server{
+		cfg: &config.ReplicaConfig{
+			Enabled:        true,
+			PrimaryURL:     cfg.Federation.UpstreamURL,
+			AuthToken:      cfg.Federation.AuthToken,
+			PollInterval:   cfg.Federation.PollInterval,
+			BatchSize:      cfg.Federation.BatchSize,
+			RequestTimeout: cfg.Federation.RequestTimeout,
+		},
+		source:         "upstream",
+		verifier:       newVerifier(cfg.P2P.GetNetParams(), cfg.P2P.DisableCheckpoints, headersService),
+		rewindDepth:    cfg.P2P.BlocksForForkConfirmation,
+		headersService: headersService,
+		chainService:   chainService,
+		httpClient:     &http.Client{Timeout: cfg.Federation.RequestTimeout},
+		log:            &upstreamLogger,
+		quit:           make(chan struct{}),
+	}
+}
+
+// Start starts the p2p server and watching its peers in the background.
+func (f *fallbackServer) Start() error {
+	if err := f.p2p.Start(); err != nil {
+		return err
+	}
+
+	f.log.Info().Msgf("Falling back to %s after %s without p2p peers", f.upstream.cfg.PrimaryURL, f.fallbackAfter)
+	f.lastPeerSeen = time.Now()
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(f.upstream.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				if f.shouldFallBack(now) {
+					f.upstream.syncWithPrimary()
+				}
+			case <-f.upstream.quit:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops watching the peers and shuts the p2p server down.
+func (f *fallbackServer) Shutdown() error {
+	close(f.upstream.quit)
+	f.wg.Wait()
+	return f.p2p.Shutdown()
+}
+
+// shouldFallBack reports whether headers should be fetched from the upstream, which is
+// when no peer has been connected for the configured time.
+func (f *fallbackServer) shouldFallBack(now time.Time) bool {
+	if f.network.GetPeersCount() > 0 {
+		if f.fallingBack {
+			f.log.Info().Msg("P2P peers are connected again, stopped fetching headers from upstream")
+			f.fallingBack = false
+		}
+		f.lastPeerSeen = now
+		return false
+	}
+
+	if now.Sub(f.lastPeerSeen) < f.fallbackAfter {
+		return false
+	}
+
+	if !f.fallingBack {
+		f.log.Warn().Msgf("No p2p peers since %s, fetching headers from upstream", f.lastPeerSeen.Format(time.RFC3339))
+		f.fallingBack = true
+	}
+	return true
+}
+
+// RestartSync restarts syncing of the p2p server, the upstream always syncs from the current tip.
+func (s *fallbackServer) RestartSync() {
+	if syncer, ok := s.p2p.(service.Syncer); ok {
+		syncer.RestartSync()
+	}
+}
@@ -0,0 +1,37 @@
+package replica
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	peerpkg "github.com/bitcoin-sv/block-headers-service/transports/p2p/peer"
+	"github.com/rs/zerolog"
+)
+
+type peersCount int
+
+func (c *peersCount) GetPeers() []peerpkg.State { return nil }
+func (c *peersCount) GetPeersCount() int        { return int(*c) }
+
+func TestShouldFallBackAfterNoPeersForConfiguredTime(t *testing.T) {
+	// given
+	start := time.Now()
+	peers := peersCount(1)
+	log := zerolog.Nop()
+	f := &fallbackServer{network: &peers, fallbackAfter: time.Minute, log: &log, lastPeerSeen: start}
+
+	// when
+	withPeers := f.shouldFallBack(start.Add(time.Minute))
+	peers = 0
+	shortlyWithoutPeers := f.shouldFallBack(start.Add(time.Minute + time.Second))
+	longWithoutPeers := f.shouldFallBack(start.Add(2 * time.Minute))
+	peers = 2
+	peersBack := f.shouldFallBack(start.Add(3 * time.Minute))
+
+	// then
+	assert.Equal(t, withPeers, false)
+	assert.Equal(t, shortlyWithoutPeers, false)
+	assert.Equal(t, longWithoutPeers, true)
+	assert.Equal(t, peersBack, false)
+}
@@ -0,0 +1,238 @@
+// Package replica provides a headers source which follows a primary Block Headers Service
+// over its HTTP API instead of the p2p network.
+package replica
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
+	"github.com/rs/zerolog"
+)
+
+const byHeightPath = "/api/v1/chain/header/byHeight"
+
+type server struct {
+	cfg *config.ReplicaConfig
+	// source names the instance headers are fetched from in logs and errors.
+	source string
+	// verifier checks headers before they are stored, nil when the instance is trusted.
+	verifier       *verifier
+	rewindDepth    int
+	headersService service.Headers
+	chainService   service.Chains
+	httpClient     *http.Client
+	log            *zerolog.Logger
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a new replica server which replicates headers of the configured primary.
+//
+//revive:disable:unexported-return
+func NewServer(
+	cfg *config.AppConfig,
+	headersService service.Headers,
+	chainService service.Chains,
+	log *zerolog.Logger,
+) *server {
+	serverLogger := log.With().Str("service", "replica").Logger()
+	return &server{
+		cfg:            cfg.Replica,
+		source:         "primary",
+		rewindDepth:    cfg.P2P.BlocksForForkConfirmation,
+		headersService: headersService,
+		chainService:   chainService,
+		httpClient:     &http.Client{Timeout: cfg.Replica.RequestTimeout},
+		log:            &serverLogger,
+		quit:           make(chan struct{}),
+	}
+}
+
+//revive:enable:unexported-return
+
+// Start starts polling the primary in the background.
+func (s *server) Start() error {
+	s.log.Info().Msgf("Replicating headers from %s", s.cfg.PrimaryURL)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.syncWithPrimary()
+
+			select {
+			case <-ticker.C:
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops polling the primary and waits for the pending batch to finish.
+func (s *server) Shutdown() error {
+	close(s.quit)
+	s.wg.Wait()
+	s.log.Info().Msg("Replica shutdown complete")
+	return nil
+}
+
+// syncWithPrimary fetches batches until the replica reaches the primary's tip. Every round
+// starts a few blocks below the local tip so reorganizations on the primary are picked up.
+func (s *server) syncWithPrimary() {
+	height := max(int(s.headersService.GetTipHeight())-s.rewindDepth, 0)
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+
+		batch, err := s.fetchHeaders(height, s.cfg.BatchSize)
+		if err != nil {
+			s.log.Error().Msgf("failed to fetch headers from %s: %v", s.source, err)
+			return
+		}
+
+		added, err := s.addHeaders(batch)
+		if service.WritesPaused.Is(err) {
+			s.log.Debug().Msgf("maintenance mode is enabled, replicating from %s is paused", s.source)
+			return
+		}
+		if err != nil {
+			s.log.Error().Msgf("failed to add headers from %s: %v", s.source, err)
+			return
+		}
+		if added > 0 {
+			s.log.Info().Msgf("Replicated %d headers from %s, current tip height %d", added, s.source, s.headersService.GetTipHeight())
+		}
+
+		if len(batch) < s.cfg.BatchSize {
+			return
+		}
+		height += s.cfg.BatchSize
+	}
+}
+
+func (s *server) addHeaders(batch []headers.BlockHeaderResponse) (int, error) {
+	added := 0
+	for _, h := range batch {
+		bs, err := toBlockHeaderSource(h)
+		if err != nil {
+			return added, err
+		}
+
+		if s.verifier != nil {
+			if err := s.verifier.verify(h, bs); err != nil {
+				return added, err
+			}
+		}
+
+		if _, err := s.chainService.Add(*bs); err != nil {
+			if service.HeaderAlreadyExists.Is(err) {
+				continue
+			}
+			if service.BlockRejected.Is(err) || service.BlockQuarantined.Is(err) || service.HeaderCreationFail.Is(err) {
+				s.log.Warn().Msgf("skipping header %s received from %s: %v", h.Hash, s.source, err)
+				continue
+			}
+			return added, fmt.Errorf("header %s: %w", h.Hash, err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+func (s *server) fetchHeaders(height, count int) ([]headers.BlockHeaderResponse, error) {
+	url := fmt.Sprintf("%s%s?height=%d&count=%d", strings.TrimSuffix(s.cfg.PrimaryURL, "/"), byHeightPath, height, count)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.AuthToken)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with status %d", s.source, res.StatusCode)
+	}
+
+	var batch []headers.BlockHeaderResponse
+	if err := json.NewDecoder(res.Body).Decode(&batch); err != nil {
+		return nil, err
+	}
+
+	return orderByParent(batch), nil
+}
+
+// orderByParent orders the batch so every header comes after its parent,
+// as the range query on the primary does not guarantee any order.
+func orderByParent(batch []headers.BlockHeaderResponse) []headers.BlockHeaderResponse {
+	pending := make(map[string]bool, len(batch))
+	for _, h := range batch {
+		pending[h.Hash] = true
+	}
+
+	ordered := make([]headers.BlockHeaderResponse, 0, len(batch))
+	for len(ordered) < len(batch) {
+		progress := false
+		for _, h := range batch {
+			if pending[h.Hash] && !pending[h.PreviousBlock] {
+				ordered = append(ordered, h)
+				delete(pending, h.Hash)
+				progress = true
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+	return ordered
+}
+
+func toBlockHeaderSource(h headers.BlockHeaderResponse) (*domains.BlockHeaderSource, error) {
+	prevBlock, err := chainhash.NewHashFromStr(h.PreviousBlock)
+	if err != nil {
+		return nil, err
+	}
+	merkleRoot, err := chainhash.NewHashFromStr(h.MerkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domains.BlockHeaderSource{
+		Version:    h.Version,
+		PrevBlock:  *prevBlock,
+		MerkleRoot: *merkleRoot,
+		Timestamp:  time.Unix(int64(h.Timestamp), 0),
+		Bits:       h.DifficultyTarget,
+		Nonce:      h.Nonce,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package replica
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
+	"github.com/rs/zerolog"
+)
+
+func TestFetchHeadersOrdersByParent(t *testing.T) {
+	// given
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, byHeightPath)
+		assert.Equal(t, r.URL.Query().Get("height"), "5")
+		assert.Equal(t, r.Header.Get("Authorization"), "Bearer secret")
+
+		_ = json.NewEncoder(w).Encode([]headers.BlockHeaderResponse{
+			{Hash: "c", PreviousBlock: "b"},
+			{Hash: "a", PreviousBlock: "genesis"},
+			{Hash: "b", PreviousBlock: "a"},
+		})
+	}))
+	defer primary.Close()
+
+	s := newTestServer(primary.URL + "/")
+
+	// when
+	batch, err := s.fetchHeaders(5, 3)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(batch), 3)
+	assert.Equal(t, batch[0].Hash, "a")
+	assert.Equal(t, batch[1].Hash, "b")
+	assert.Equal(t, batch[2].Hash, "c")
+}
+
+func TestFetchHeadersFailsOnPrimaryError(t *testing.T) {
+	// given
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer primary.Close()
+
+	s := newTestServer(primary.URL)
+
+	// when
+	_, err := s.fetchHeaders(0, 10)
+
+	// then
+	assert.IsError(t, err, "primary responded with status 401")
+}
+
+func newTestServer(primaryURL string) *server {
+	cfg := config.GetDefaultAppConfig()
+	cfg.Replica.Enabled = true
+	cfg.Replica.PrimaryURL = primaryURL
+	cfg.Replica.AuthToken = "secret"
+	log := zerolog.Nop()
+
+	return NewServer(cfg, nil, nil, &log)
+}
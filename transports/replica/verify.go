@@ -0,0 +1,70 @@
+package replica
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
+)
+
+// headerLookup finds stored headers by hash.
+type headerLookup interface {
+	GetHeaderByHash(hash string) (*domains.BlockHeader, error)
+}
+
+// verifier checks headers received from an untrusted instance before they are stored.
+type verifier struct {
+	powLimit    *big.Int
+	checkpoints map[int32]chainhash.Hash
+	headers     headerLookup
+	hasher      service.BlockHasher
+}
+
+func newVerifier(params *chaincfg.Params, disableCheckpoints bool, headers headerLookup) *verifier {
+	checkpoints := make(map[int32]chainhash.Hash)
+	if !disableCheckpoints {
+		for _, c := range params.Checkpoints {
+			checkpoints[c.Height] = *c.Hash
+		}
+	}
+
+	return &verifier{
+		powLimit:    params.PowLimit,
+		checkpoints: checkpoints,
+		headers:     headers,
+		hasher:      service.DefaultBlockHasher(),
+	}
+}
+
+// verify checks the header hashes to the claimed hash with enough proof of work,
+// extends a stored header and matches the checkpoint on its height.
+func (v *verifier) verify(h headers.BlockHeaderResponse, bs *domains.BlockHeaderSource) error {
+	hash := chainhash.Hash(v.hasher.BlockHash(bs))
+	if hash.String() != h.Hash {
+		return fmt.Errorf("header %s hashes to %s", h.Hash, hash)
+	}
+
+	target := domains.CompactToBig(bs.Bits)
+	if target.Sign() <= 0 || target.Cmp(v.powLimit) > 0 {
+		return fmt.Errorf("header %s has target out of range", h.Hash)
+	}
+	if domains.HashToBig(&hash).Cmp(target) > 0 {
+		return fmt.Errorf("header %s has insufficient proof of work", h.Hash)
+	}
+
+	parent, err := v.headers.GetHeaderByHash(bs.PrevBlock.String())
+	if err != nil || parent == nil {
+		return fmt.Errorf("header %s does not extend a known header", h.Hash)
+	}
+
+	height := parent.Height + 1
+	if checkpoint, ok := v.checkpoints[height]; ok && checkpoint != hash {
+		return fmt.Errorf("header %s does not match checkpoint at height %d", h.Hash, height)
+	}
+
+	return nil
+}
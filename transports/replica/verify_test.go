@@ -0,0 +1,132 @@
+package replica
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/transports/http/endpoints/api/headers"
+)
+
+type headersByHash map[string]*domains.BlockHeader
+
+func (m headersByHash) GetHeaderByHash(hash string) (*domains.BlockHeader, error) {
+	if h, ok := m[hash]; ok {
+		return h, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestVerifyAcceptsMinedHeader(t *testing.T) {
+	// given
+	v, bs := newRegtestVerifier(), minedRegtestHeader(t)
+
+	// when
+	err := v.verify(responseOf(bs), bs)
+
+	// then
+	assert.NoError(t, err)
+}
+
+func TestVerifyRejectsHeaderWithDifferentHash(t *testing.T) {
+	// given
+	v, bs := newRegtestVerifier(), minedRegtestHeader(t)
+	res := responseOf(bs)
+	actual := res.Hash
+	res.Hash = chainhash.Hash{}.String()
+
+	// when
+	err := v.verify(res, bs)
+
+	// then
+	assert.IsError(t, err, fmt.Sprintf("header %s hashes to %s", res.Hash, actual))
+}
+
+func TestVerifyRejectsInvalidHeaders(t *testing.T) {
+	testCases := map[string]struct {
+		modify   func(v *verifier, bs *domains.BlockHeaderSource)
+		expected string
+	}{
+		"target above pow limit": {
+			modify:   func(_ *verifier, bs *domains.BlockHeaderSource) { bs.Bits = 0x217fffff },
+			expected: "header %s has target out of range",
+		},
+		"not enough work": {
+			modify:   func(_ *verifier, bs *domains.BlockHeaderSource) { bs.Bits = 0x1d00ffff },
+			expected: "header %s has insufficient proof of work",
+		},
+		"unknown parent": {
+			modify:   func(v *verifier, _ *domains.BlockHeaderSource) { v.headers = headersByHash{} },
+			expected: "header %s does not extend a known header",
+		},
+		"checkpoint mismatch": {
+			modify:   func(v *verifier, _ *domains.BlockHeaderSource) { v.checkpoints[1] = chainhash.Hash{1} },
+			expected: "header %s does not match checkpoint at height 1",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			v, bs := newRegtestVerifier(), minedRegtestHeader(t)
+			tc.modify(v, bs)
+			res := responseOf(bs)
+
+			// when
+			err := v.verify(res, bs)
+
+			// then
+			assert.IsError(t, err, fmt.Sprintf(tc.expected, res.Hash))
+		})
+	}
+}
+
+func newRegtestVerifier() *verifier {
+	genesis := chaincfg.RegressionNetParams.GenesisHash
+	return newVerifier(&chaincfg.RegressionNetParams, false, headersByHash{
+		genesis.String(): {Height: 0, Hash: *genesis},
+	})
+}
+
+// minedRegtestHeader returns a child of the regtest genesis with valid proof of work.
+func minedRegtestHeader(t *testing.T) *domains.BlockHeaderSource {
+	t.Helper()
+
+	genesis := chaincfg.RegressionNetParams.GenesisBlock.Header
+	bs := &domains.BlockHeaderSource{
+		Version:    genesis.Version,
+		PrevBlock:  genesis.BlockHash(),
+		MerkleRoot: genesis.MerkleRoot,
+		Timestamp:  genesis.Timestamp.Add(10 * time.Minute),
+		Bits:       genesis.Bits,
+	}
+
+	v := newRegtestVerifier()
+	target := domains.CompactToBig(bs.Bits)
+	for ; bs.Nonce < 1000; bs.Nonce++ {
+		hash := chainhash.Hash(v.hasher.BlockHash(bs))
+		if domains.HashToBig(&hash).Cmp(target) <= 0 {
+			return bs
+		}
+	}
+	t.Fatal("failed to mine regtest header")
+	return nil
+}
+
+func responseOf(bs *domains.BlockHeaderSource) headers.BlockHeaderResponse {
+	hash := chainhash.Hash(newRegtestVerifier().hasher.BlockHash(bs))
+	return headers.BlockHeaderResponse{
+		Hash:             hash.String(),
+		Version:          bs.Version,
+		PreviousBlock:    bs.PrevBlock.String(),
+		MerkleRoot:       bs.MerkleRoot.String(),
+		Timestamp:        uint32(bs.Timestamp.Unix()),
+		DifficultyTarget: bs.Bits,
+		Nonce:            bs.Nonce,
+	}
+}
@@ -0,0 +1,219 @@
+// Package simulation provides a headers source which replays headers from a local file
+// instead of syncing over the p2p network, so the service can be developed offline.
+package simulation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg/chainhash"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/rs/zerolog"
+)
+
+// pausedRetryInterval is the delay between tries to add a header while the maintenance mode is enabled.
+const pausedRetryInterval = time.Second
+
+type server struct {
+	cfg          *config.SimulationConfig
+	params       *chaincfg.Params
+	chainService service.Chains
+	log          *zerolog.Logger
+
+	// recent are the latest replayed headers, the injected reorgs fork off them.
+	recent []*wire.BlockHeader
+	reorgs int
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a new server which replays headers from the configured file.
+//
+//revive:disable:unexported-return
+func NewServer(cfg *config.AppConfig, chainService service.Chains, log *zerolog.Logger) *server {
+	serverLogger := log.With().Str("service", "simulation").Logger()
+	return &server{
+		cfg:          cfg.Simulation,
+		params:       cfg.P2P.GetNetParams(),
+		chainService: chainService,
+		log:          &serverLogger,
+		quit:         make(chan struct{}),
+	}
+}
+
+//revive:enable:unexported-return
+
+// Start opens the file and replays it in the background.
+func (s *server) Start() error {
+	f, err := os.Open(s.cfg.FilePath)
+	if err != nil {
+		return fmt.Errorf("cannot open simulation file: %w", err)
+	}
+	s.log.Info().Msgf("Replaying headers from %s", s.cfg.FilePath)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { _ = f.Close() }()
+
+		if err := s.replay(bufio.NewReader(f)); err != nil {
+			s.log.Error().Msgf("Replaying headers stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops replaying and waits for the pending header to be added.
+func (s *server) Shutdown() error {
+	close(s.quit)
+	s.wg.Wait()
+	s.log.Info().Msg("Simulation shutdown complete")
+	return nil
+}
+
+// replay adds headers read from r one by one. Headers which are already stored are skipped without waiting,
+// so a restarted simulation continues from the tip.
+func (s *server) replay(r io.Reader) error {
+	replayed := 0
+	for height := 0; ; height++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+
+		var h wire.BlockHeader
+		if err := h.Deserialize(r); err != nil {
+			if errors.Is(err, io.EOF) {
+				s.log.Info().Msgf("Replayed all %d headers of the file", height)
+				return nil
+			}
+			return fmt.Errorf("cannot read header at height %d: %w", height, err)
+		}
+		if height == 0 && h.BlockHash() != *s.params.GenesisHash {
+			return fmt.Errorf("file doesn't start with the genesis of %s", s.params.Name)
+		}
+
+		added, err := s.add(&h)
+		if err != nil {
+			return err
+		}
+		s.remember(&h)
+		if !added {
+			continue
+		}
+
+		replayed++
+		if s.cfg.ReorgEvery > 0 && replayed%s.cfg.ReorgEvery == 0 {
+			s.injectReorg()
+		}
+
+		select {
+		case <-time.After(s.cfg.Interval):
+		case <-s.quit:
+			return nil
+		}
+	}
+}
+
+// add adds the header, it returns false when the header is already stored.
+// While the maintenance mode is enabled it waits until the header can be added.
+func (s *server) add(h *wire.BlockHeader) (bool, error) {
+	_, err := s.chainService.Add(domains.BlockHeaderSource(*h))
+	for service.WritesPaused.Is(err) {
+		select {
+		case <-time.After(pausedRetryInterval):
+		case <-s.quit:
+			return false, nil
+		}
+		_, err = s.chainService.Add(domains.BlockHeaderSource(*h))
+	}
+	if err != nil {
+		if service.HeaderAlreadyExists.Is(err) {
+			return false, nil
+		}
+		if service.BlockRejected.Is(err) || service.BlockQuarantined.Is(err) {
+			s.log.Warn().Msgf("Replayed header %s was not added: %v", h.BlockHash(), err)
+			return true, nil
+		}
+		return false, fmt.Errorf("cannot add header %s: %w", h.BlockHash(), err)
+	}
+	return true, nil
+}
+
+// remember keeps enough of the latest headers to fork a reorg off them and compute its timestamps.
+func (s *server) remember(h *wire.BlockHeader) {
+	s.recent = append(s.recent, h)
+	if keep := s.cfg.ReorgDepth + domains.MedianTimeBlocks; len(s.recent) > keep {
+		s.recent = s.recent[len(s.recent)-keep:]
+	}
+}
+
+// injectReorg adds a branch forking off ReorgDepth headers below the tip, which is one header longer,
+// so it becomes the longest chain until replayed headers outgrow it again.
+// Headers of the branch don't have valid proof of work, but their bits follow the difficulty adjustment,
+// so they're accepted also with the difficulty validation enabled.
+func (s *server) injectReorg() {
+	depth := s.cfg.ReorgDepth
+	if len(s.recent) <= depth {
+		return
+	}
+	s.reorgs++
+
+	fork := len(s.recent) - 1 - depth
+	parent := s.recent[fork]
+	timestamps := make([]time.Time, 0, domains.MedianTimeBlocks)
+	for _, h := range s.recent[max(0, fork-domains.MedianTimeBlocks+1) : fork+1] {
+		timestamps = append(timestamps, h.Timestamp)
+	}
+
+	for i := 0; i <= depth; i++ {
+		replaced := s.recent[min(fork+1+i, len(s.recent)-1)]
+		// Later than any of the previous timestamps, so it's always after the median time past.
+		timestamp := latest(timestamps).Add(time.Second)
+		bits, err := s.chainService.RequiredBits(parent.BlockHash().String(), timestamp)
+		if err != nil {
+			s.log.Warn().Msgf("Injected reorg stopped, cannot compute bits after %s: %v", parent.BlockHash(), err)
+			return
+		}
+		h := &wire.BlockHeader{
+			Version:    replaced.Version,
+			PrevBlock:  parent.BlockHash(),
+			MerkleRoot: chainhash.DoubleHashH([]byte(fmt.Sprintf("simulated reorg %d header %d", s.reorgs, i))),
+			Timestamp:  timestamp,
+			Bits:       bits,
+		}
+		if _, err := s.chainService.Add(domains.BlockHeaderSource(*h)); err != nil {
+			s.log.Warn().Msgf("Injected reorg stopped at header %s: %v", h.BlockHash(), err)
+			return
+		}
+
+		timestamps = append(timestamps, h.Timestamp)
+		if len(timestamps) > domains.MedianTimeBlocks {
+			timestamps = timestamps[1:]
+		}
+		parent = h
+	}
+	s.log.Info().Msgf("Injected reorg replacing %d headers after %s", depth, s.recent[fork].BlockHash())
+}
+
+func latest(timestamps []time.Time) time.Time {
+	var l time.Time
+	for _, t := range timestamps {
+		if t.After(l) {
+			l = t
+		}
+	}
+	return l
+}
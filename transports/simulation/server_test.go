@@ -0,0 +1,170 @@
+package simulation
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/block-headers-service/config"
+	"github.com/bitcoin-sv/block-headers-service/domains"
+	"github.com/bitcoin-sv/block-headers-service/internal/chaincfg"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/assert"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/fixtures"
+	"github.com/bitcoin-sv/block-headers-service/internal/tests/testrepository"
+	"github.com/bitcoin-sv/block-headers-service/internal/wire"
+	"github.com/bitcoin-sv/block-headers-service/repository"
+	"github.com/bitcoin-sv/block-headers-service/service"
+	"github.com/rs/zerolog"
+)
+
+func TestReplayAddsHeadersFromFile(t *testing.T) {
+	// given
+	s, repo := newTestServer(&config.SimulationConfig{})
+
+	// when
+	err := s.replay(headersFile(t, true))
+
+	// then
+	assert.NoError(t, err)
+	tip, err := repo.Headers.GetTip()
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Hash, *fixtures.HashHeight6)
+}
+
+func TestReplayInjectsReorgs(t *testing.T) {
+	// given
+	s, repo := newTestServer(&config.SimulationConfig{ReorgEvery: 3, ReorgDepth: 1})
+
+	// when
+	err := s.replay(headersFile(t, true))
+
+	// then
+	assert.NoError(t, err)
+
+	// The first injected branch is outgrown by replayed headers, the second one replaces the replayed tip.
+	tip, err := repo.Headers.GetTip()
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Height, 7)
+	for hash, state := range map[string]domains.HeaderState{
+		fixtures.HashHeight4.String(): domains.LongestChain,
+		fixtures.HashHeight5.String(): domains.LongestChain,
+		fixtures.HashHeight6.String(): domains.Stale,
+	} {
+		h, err := repo.Headers.GetHeaderByHash(hash)
+		assert.NoError(t, err)
+		assert.Equal(t, h.State, state)
+	}
+}
+
+func TestReplayInjectsReorgsFollowingDifficultyAdjustment(t *testing.T) {
+	// given
+	params := chaincfg.MainNetParams
+	params.DaaForkHeight = 0
+	file, last := daaHeadersFile(t, &params)
+	db, _ := fixtures.StartingChain()
+	s, repo := newTestServerOf(&params, db, &config.SimulationConfig{ReorgEvery: daaHeaders, ReorgDepth: 2})
+
+	// when
+	err := s.replay(file)
+
+	// then
+	assert.NoError(t, err)
+	tip, err := repo.Headers.GetTip()
+	assert.NoError(t, err)
+	assert.Equal(t, tip.Height, int32(daaHeaders+1))
+	replaced, err := repo.Headers.GetHeaderByHash(last.BlockHash().String())
+	assert.NoError(t, err)
+	assert.Equal(t, replaced.State, domains.Stale)
+}
+
+func TestReplayRequiresGenesisOfNetwork(t *testing.T) {
+	// given
+	s, _ := newTestServer(&config.SimulationConfig{})
+
+	// when
+	err := s.replay(headersFile(t, false))
+
+	// then
+	assert.IsError(t, err, "file doesn't start with the genesis of mainnet")
+}
+
+// daaHeaders is the number of replayed headers, enough for the difficulty adjustment algorithm to compute bits of the last ones.
+const daaHeaders = 150
+
+func newTestServer(cfg *config.SimulationConfig) (*server, repository.Repositories) {
+	db, _ := fixtures.StartingChain()
+	return newTestServerOf(&chaincfg.MainNetParams, db, cfg)
+}
+
+func newTestServerOf(params *chaincfg.Params, db []domains.BlockHeader, cfg *config.SimulationConfig) (*server, repository.Repositories) {
+	log := zerolog.Nop()
+	repo := testrepository.NewTestRepositories(&db)
+
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.Simulation = cfg
+	s := NewServer(appConfig, newTestChainsOf(params, &repo), &log)
+	s.params = params
+	return s, repo
+}
+
+// newTestChainsOf returns the chains service rejecting headers which bits don't follow the difficulty adjustment.
+func newTestChainsOf(params *chaincfg.Params, repo *repository.Repositories) service.Chains {
+	log := zerolog.Nop()
+	return service.NewChainsService(
+		repo,
+		params,
+		&log,
+		service.DefaultBlockHasher(),
+		noNotification{},
+		nil,
+		0,
+		true,
+		&config.TimestampValidationConfig{MaxFutureDrift: 2 * time.Hour, MedianTimePast: true},
+		config.NewMedianTime(&log),
+	)
+}
+
+// headersFile returns headers from height 1 up to height 6 of mainnet, preceded by the genesis when withGenesis is set.
+func headersFile(t *testing.T, withGenesis bool) *bytes.Buffer {
+	var file bytes.Buffer
+	if withGenesis {
+		genesis := chaincfg.MainNetParams.GenesisBlock.Header
+		assert.NoError(t, genesis.Serialize(&file))
+	}
+	for _, bs := range []*domains.BlockHeaderSource{
+		fixtures.HeaderSourceHeight1, fixtures.HeaderSourceHeight2, fixtures.HeaderSourceHeight3,
+		fixtures.HeaderSourceHeight4, fixtures.HeaderSourceHeight5, fixtures.HeaderSourceHeight6,
+	} {
+		h := wire.BlockHeader(*bs)
+		assert.NoError(t, h.Serialize(&file))
+	}
+	return &file
+}
+
+// daaHeadersFile returns daaHeaders headers following the genesis six minutes apart, with bits
+// required by the difficulty adjustment, preceded by the genesis. It returns the last header too.
+func daaHeadersFile(t *testing.T, params *chaincfg.Params) (*bytes.Buffer, *wire.BlockHeader) {
+	db, _ := fixtures.StartingChain()
+	repo := testrepository.NewTestRepositories(&db)
+	chains := newTestChainsOf(params, &repo)
+
+	var file bytes.Buffer
+	h := params.GenesisBlock.Header
+	assert.NoError(t, h.Serialize(&file))
+	for i := 1; i <= daaHeaders; i++ {
+		timestamp := h.Timestamp.Add(6 * time.Minute)
+		bits, err := chains.RequiredBits(h.BlockHash().String(), timestamp)
+		assert.NoError(t, err)
+		h = wire.BlockHeader{Version: 1, PrevBlock: h.BlockHash(), Timestamp: timestamp, Bits: bits, Nonce: uint32(i)}
+		_, err = chains.Add(domains.BlockHeaderSource(h))
+		assert.NoError(t, err)
+		assert.NoError(t, h.Serialize(&file))
+	}
+	return &file, &h
+}
+
+type noNotification struct{}
+
+func (noNotification) Notify(any) {}
+
+func (noNotification) NotifyLatest(any) {}
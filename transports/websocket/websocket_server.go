@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/bitcoin-sv/block-headers-service/config"
 	"github.com/bitcoin-sv/block-headers-service/service"
 	"github.com/centrifugal/centrifuge"
 	"github.com/gin-gonic/gin"
@@ -30,13 +31,47 @@ type server struct {
 	log            *zerolog.Logger
 }
 
+// ServerOpt configures the node of the websocket server.
+type ServerOpt func(node *centrifuge.Node) error
+
+// WithRedisBroker makes the server exchange published messages through Redis, so messages published by
+// any instance connected to the same Redis are delivered to the clients of all of them.
+func WithRedisBroker(cfg *config.RedisConfig) ServerOpt {
+	return func(node *centrifuge.Node) error {
+		shard, err := centrifuge.NewRedisShard(node, centrifuge.RedisShardConfig{
+			Address:  cfg.Address,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot connect to redis: %w", err)
+		}
+
+		broker, err := centrifuge.NewRedisBroker(node, centrifuge.RedisBrokerConfig{
+			Prefix: cfg.Prefix,
+			Shards: []*centrifuge.RedisShard{shard},
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create redis broker: %w", err)
+		}
+
+		node.SetBroker(broker)
+		return nil
+	}
+}
+
 // NewServer creates new websocket server.
-func NewServer(log *zerolog.Logger, services *service.Services, isAuthenticationOn bool) (Server, error) {
+func NewServer(log *zerolog.Logger, services *service.Services, isAuthenticationOn bool, opts ...ServerOpt) (Server, error) {
 	websocketLogger := log.With().Str("subservice", "websocket-server").Logger()
 	node, err := newNode(&websocketLogger)
 	if err != nil {
 		return nil, err
 	}
+	for _, opt := range opts {
+		if err := opt(node); err != nil {
+			return nil, err
+		}
+	}
 	s := &server{
 		node:           node,
 		isAuthRequired: isAuthenticationOn,